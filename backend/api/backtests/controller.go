@@ -0,0 +1,276 @@
+package backtests
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mgordon34/kornet-kover/internal/storage"
+)
+
+// AddBacktestRun stores a finished run together with every bet it placed.
+// Returns the id of the new run.
+func AddBacktestRun(run BacktestRun, bets []BacktestBet) (int, error) {
+	db := storage.GetDB()
+	txn, err := db.Begin(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	defer txn.Rollback(context.Background())
+
+	sqlStmt := `
+    INSERT INTO backtest_runs (job_id, strat_id, strat_name, sport, config, start_date, end_date, model_version,
+        bets, wins, losses, staked, profit, roi, win_rate, max_drawdown, clv_bets, avg_clv_line, avg_clv_prob, beat_close_rate)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+    RETURNING id`
+	var runId int
+	err = txn.QueryRow(context.Background(), sqlStmt, run.JobId, run.StratId, run.StratName, run.Sport, run.Config, run.StartDate, run.EndDate,
+		run.ModelVersion, run.Bets, run.Wins, run.Losses, run.Staked, run.Profit, run.ROI, run.WinRate, run.MaxDrawdown,
+		run.CLVBets, run.AvgCLVLine, run.AvgCLVProb, run.BeatCloseRate).Scan(&runId)
+	if err != nil {
+		return 0, fmt.Errorf("error adding backtest run: %w", err)
+	}
+
+	var betsInterface [][]interface{}
+	for _, bet := range bets {
+		betsInterface = append(betsInterface, []interface{}{
+			runId,
+			bet.Date,
+			bet.PlayerIndex,
+			bet.LineId,
+			bet.Stat,
+			bet.Side,
+			bet.Line,
+			bet.Odds,
+			bet.Prediction,
+			bet.Diff,
+			bet.PDiff,
+			bet.BetSize,
+			bet.Actual,
+			bet.Result,
+			bet.Profit,
+			bet.ClosingLine,
+			bet.ClosingOdds,
+			bet.CLVLine,
+			bet.CLVProb,
+		})
+	}
+
+	_, err = txn.CopyFrom(
+		context.Background(),
+		pgx.Identifier{"backtest_bets"},
+		[]string{
+			"run_id",
+			"date",
+			"player_index",
+			"line_id",
+			"stat",
+			"side",
+			"line",
+			"odds",
+			"prediction",
+			"diff",
+			"pdiff",
+			"bet_size",
+			"actual",
+			"result",
+			"profit",
+			"closing_line",
+			"closing_odds",
+			"clv_line",
+			"clv_prob",
+		},
+		pgx.CopyFromRows(betsInterface),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error adding bets for backtest run %d: %w", runId, err)
+	}
+
+	if err := txn.Commit(context.Background()); err != nil {
+		return 0, err
+	}
+	log.Printf("Added backtest run %d for %s with %d bets", runId, run.StratName, len(bets))
+
+	return runId, nil
+}
+
+func GetBacktestRun(runId int) (BacktestRun, error) {
+	db := storage.GetDB()
+
+	sql := `
+    SELECT * from backtest_runs
+    WHERE id = ($1)`
+
+	row, err := db.Query(context.Background(), sql, runId)
+	if err != nil {
+		return BacktestRun{}, fmt.Errorf("error querying backtest run %d: %w", runId, err)
+	}
+	run, err := pgx.CollectExactlyOneRow(row, pgx.RowToStructByName[BacktestRun])
+	if err != nil {
+		return run, fmt.Errorf("error getting backtest run %d: %w", runId, err)
+	}
+
+	return run, nil
+}
+
+// GetBacktestRuns returns runs newest first, limited to a strategy when stratId is set
+func GetBacktestRuns(stratId int) ([]BacktestRun, error) {
+	db := storage.GetDB()
+
+	sql := `
+    SELECT * from backtest_runs
+    WHERE ($1)::int = 0 OR strat_id = ($1)
+    ORDER BY created_at DESC, id DESC`
+
+	row, err := db.Query(context.Background(), sql, stratId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying backtest runs: %w", err)
+	}
+	runs, err := pgx.CollectRows(row, pgx.RowToStructByName[BacktestRun])
+	if err != nil {
+		return runs, fmt.Errorf("error getting backtest runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+func GetBacktestBets(runId int) ([]BacktestBet, error) {
+	db := storage.GetDB()
+
+	sql := `
+    SELECT * from backtest_bets
+    WHERE run_id = ($1)
+    ORDER BY date, id`
+
+	row, err := db.Query(context.Background(), sql, runId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying bets for backtest run %d: %w", runId, err)
+	}
+	bets, err := pgx.CollectRows(row, pgx.RowToStructByName[BacktestBet])
+	if err != nil {
+		return bets, fmt.Errorf("error getting bets for backtest run %d: %w", runId, err)
+	}
+
+	return bets, nil
+}
+
+func AddBacktestJob(job BacktestJob) (int, error) {
+	db := storage.GetDB()
+
+	sqlStmt := `
+    INSERT INTO backtest_jobs (sport, start_date, end_date, status, total_days)
+    VALUES ($1, $2, $3, $4, $5)
+    RETURNING id`
+	var jobId int
+	err := db.QueryRow(context.Background(), sqlStmt, job.Sport, job.StartDate, job.EndDate, job.Status, job.TotalDays).Scan(&jobId)
+	if err != nil {
+		return 0, fmt.Errorf("error adding backtest job: %w", err)
+	}
+
+	return jobId, nil
+}
+
+func GetBacktestJob(jobId int) (BacktestJob, error) {
+	db := storage.GetDB()
+
+	sql := `
+    SELECT * from backtest_jobs
+    WHERE id = ($1)`
+
+	row, err := db.Query(context.Background(), sql, jobId)
+	if err != nil {
+		return BacktestJob{}, fmt.Errorf("error querying backtest job %d: %w", jobId, err)
+	}
+	job, err := pgx.CollectExactlyOneRow(row, pgx.RowToStructByName[BacktestJob])
+	if err != nil {
+		return job, fmt.Errorf("error getting backtest job %d: %w", jobId, err)
+	}
+
+	return job, nil
+}
+
+// UpdateBacktestJobProgress marks the job running with the number of dates processed so far
+func UpdateBacktestJobProgress(jobId int, completedDays int) error {
+	db := storage.GetDB()
+
+	sql := `
+    UPDATE backtest_jobs
+    SET status = ($2), completed_days = ($3)
+    WHERE id = ($1)`
+
+	_, err := db.Exec(context.Background(), sql, jobId, JobRunning, completedDays)
+	if err != nil {
+		return fmt.Errorf("error updating progress for backtest job %d: %w", jobId, err)
+	}
+	return nil
+}
+
+func FinishBacktestJob(jobId int, status string, errMsg *string) error {
+	db := storage.GetDB()
+
+	sql := `
+    UPDATE backtest_jobs
+    SET status = ($2), error = ($3), finished_at = ($4)
+    WHERE id = ($1)`
+
+	_, err := db.Exec(context.Background(), sql, jobId, status, errMsg, time.Now())
+	if err != nil {
+		return fmt.Errorf("error finishing backtest job %d: %w", jobId, err)
+	}
+	return nil
+}
+
+func GetBacktestRunsForJob(jobId int) ([]BacktestRun, error) {
+	db := storage.GetDB()
+
+	sql := `
+    SELECT * from backtest_runs
+    WHERE job_id = ($1)
+    ORDER BY id`
+
+	row, err := db.Query(context.Background(), sql, jobId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying runs for backtest job %d: %w", jobId, err)
+	}
+	runs, err := pgx.CollectRows(row, pgx.RowToStructByName[BacktestRun])
+	if err != nil {
+		return runs, fmt.Errorf("error getting runs for backtest job %d: %w", jobId, err)
+	}
+
+	return runs, nil
+}
+
+// GetBacktestBetsForJob returns a page of bets across every run of the job
+// along with the total number of bets
+func GetBacktestBetsForJob(jobId int, limit int, offset int) ([]BacktestBet, int, error) {
+	db := storage.GetDB()
+
+	var total int
+	countSql := `
+    SELECT COUNT(*) from backtest_bets b
+    INNER JOIN backtest_runs r ON r.id = b.run_id
+    WHERE r.job_id = ($1)`
+	if err := db.QueryRow(context.Background(), countSql, jobId).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error counting bets for backtest job %d: %w", jobId, err)
+	}
+
+	sql := `
+    SELECT b.* from backtest_bets b
+    INNER JOIN backtest_runs r ON r.id = b.run_id
+    WHERE r.job_id = ($1)
+    ORDER BY b.run_id, b.date, b.id
+    LIMIT ($2) OFFSET ($3)`
+
+	row, err := db.Query(context.Background(), sql, jobId, limit, offset)
+	if err != nil {
+		return nil, total, fmt.Errorf("error querying bets for backtest job %d: %w", jobId, err)
+	}
+	bets, err := pgx.CollectRows(row, pgx.RowToStructByName[BacktestBet])
+	if err != nil {
+		return bets, total, fmt.Errorf("error getting bets for backtest job %d: %w", jobId, err)
+	}
+
+	return bets, total, nil
+}
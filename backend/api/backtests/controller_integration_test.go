@@ -0,0 +1,123 @@
+//go:build integration
+// +build integration
+
+package backtests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mgordon34/kornet-kover/api/players"
+	"github.com/mgordon34/kornet-kover/internal/storage"
+)
+
+func TestAddAndGetBacktestRun(t *testing.T) {
+	storage.UseLocalDBForIntegrationTests(t)
+	storage.InitTables()
+
+	players.AddPlayers([]players.Player{{Index: "btrunit01", Sport: "nba", Name: "Backtest Run Player"}})
+
+	date := time.Date(2097, 1, 2, 0, 0, 0, 0, time.UTC)
+	run := BacktestRun{
+		StratName:    "Integration Run",
+		Sport:        "nba",
+		Config:       json.RawMessage(`{"MinOdds": 200}`),
+		StartDate:    date,
+		EndDate:      date.AddDate(0, 0, 1),
+		ModelVersion: 1,
+		Bets:         2,
+		Wins:         1,
+		Losses:       1,
+		Staked:       200,
+		Profit:       150,
+		ROI:          .75,
+		WinRate:      .5,
+		MaxDrawdown:  100,
+	}
+	bets := []BacktestBet{
+		{Date: date, PlayerIndex: "btrunit01", LineId: 1, Stat: "points", Side: "Over", Line: 20.5, Odds: 250, Prediction: 24, BetSize: 100, Actual: 22, Result: "win", Profit: 250},
+		{Date: date.AddDate(0, 0, 1), PlayerIndex: "btrunit01", LineId: 2, Stat: "points", Side: "Over", Line: 25.5, Odds: 300, Prediction: 27, BetSize: 100, Actual: 18, Result: "loss", Profit: -100},
+	}
+
+	runID, err := AddBacktestRun(run, bets)
+	if err != nil || runID == 0 {
+		t.Fatalf("AddBacktestRun() id=%d err=%v", runID, err)
+	}
+
+	got, err := GetBacktestRun(runID)
+	if err != nil {
+		t.Fatalf("GetBacktestRun() error = %v", err)
+	}
+	if got.StratName != run.StratName || got.StratId != nil || got.Bets != 2 || got.Profit != 150 || got.CreatedAt.IsZero() {
+		t.Fatalf("unexpected run: %+v", got)
+	}
+	var config map[string]int
+	if err := json.Unmarshal(got.Config, &config); err != nil || config["MinOdds"] != 200 {
+		t.Fatalf("unexpected config snapshot %s err=%v", got.Config, err)
+	}
+
+	gotBets, err := GetBacktestBets(runID)
+	if err != nil || len(gotBets) != 2 {
+		t.Fatalf("GetBacktestBets() len=%d err=%v", len(gotBets), err)
+	}
+	if gotBets[0].Result != "win" || gotBets[1].Profit != -100 || gotBets[0].RunId != runID {
+		t.Fatalf("unexpected bets: %+v", gotBets)
+	}
+
+	runs, err := GetBacktestRuns(0)
+	if err != nil || len(runs) == 0 {
+		t.Fatalf("GetBacktestRuns() len=%d err=%v", len(runs), err)
+	}
+
+	if _, err := GetBacktestRun(-1); err == nil {
+		t.Fatalf("expected missing run error")
+	}
+}
+
+func TestBacktestJobFlow(t *testing.T) {
+	storage.UseLocalDBForIntegrationTests(t)
+	storage.InitTables()
+
+	players.AddPlayers([]players.Player{{Index: "btjobit01", Sport: "nba", Name: "Backtest Job Player"}})
+
+	date := time.Date(2097, 2, 1, 0, 0, 0, 0, time.UTC)
+	jobID, err := AddBacktestJob(BacktestJob{Sport: "nba", StartDate: date, EndDate: date, Status: JobQueued, TotalDays: 1})
+	if err != nil || jobID == 0 {
+		t.Fatalf("AddBacktestJob() id=%d err=%v", jobID, err)
+	}
+	if err := UpdateBacktestJobProgress(jobID, 1); err != nil {
+		t.Fatalf("UpdateBacktestJobProgress() error = %v", err)
+	}
+	job, err := GetBacktestJob(jobID)
+	if err != nil || job.Status != JobRunning || job.CompletedDays != 1 || job.FinishedAt != nil {
+		t.Fatalf("GetBacktestJob() = %+v err=%v", job, err)
+	}
+
+	run := BacktestRun{JobId: &jobID, StratName: "Job Run", Sport: "nba", Config: json.RawMessage(`{}`), StartDate: date, EndDate: date, ModelVersion: 1}
+	var bets []BacktestBet
+	for i := 0; i < 3; i++ {
+		bets = append(bets, BacktestBet{Date: date, PlayerIndex: "btjobit01", LineId: i, Stat: "points", Side: "Over", Line: 20.5, Odds: 200, BetSize: 100, Result: "loss", Profit: -100})
+	}
+	if _, err := AddBacktestRun(run, bets); err != nil {
+		t.Fatalf("AddBacktestRun() error = %v", err)
+	}
+
+	errMsg := "stopped"
+	if err := FinishBacktestJob(jobID, JobFailed, &errMsg); err != nil {
+		t.Fatalf("FinishBacktestJob() error = %v", err)
+	}
+	job, err = GetBacktestJob(jobID)
+	if err != nil || job.Status != JobFailed || job.Error == nil || *job.Error != errMsg || job.FinishedAt == nil {
+		t.Fatalf("finished job = %+v err=%v", job, err)
+	}
+
+	runs, err := GetBacktestRunsForJob(jobID)
+	if err != nil || len(runs) != 1 || runs[0].JobId == nil || *runs[0].JobId != jobID {
+		t.Fatalf("GetBacktestRunsForJob() = %+v err=%v", runs, err)
+	}
+	page, total, err := GetBacktestBetsForJob(jobID, 2, 2)
+	if err != nil || total != 3 || len(page) != 1 || page[0].LineId != 2 {
+		t.Fatalf("GetBacktestBetsForJob() = %+v total=%d err=%v", page, total, err)
+	}
+}
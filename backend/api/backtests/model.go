@@ -0,0 +1,74 @@
+package backtests
+
+import (
+    "encoding/json"
+    "time"
+)
+
+const (
+    JobQueued       = "queued"
+    JobRunning      = "running"
+    JobCompleted    = "completed"
+    JobFailed       = "failed"
+)
+
+type BacktestJob struct {
+    Id              int         `json:"id"`
+    Sport           string      `json:"sport"`
+    StartDate       time.Time   `json:"start_date"`
+    EndDate         time.Time   `json:"end_date"`
+    Status          string      `json:"status"`
+    CompletedDays   int         `json:"completed_days"`
+    TotalDays       int         `json:"total_days"`
+    Error           *string     `json:"error"`
+    CreatedAt       time.Time   `json:"created_at"`
+    FinishedAt      *time.Time  `json:"finished_at"`
+}
+
+type BacktestRun struct {
+    Id              int                 `json:"id"`
+    JobId           *int                `json:"job_id"`
+    StratId         *int                `json:"strat_id"`
+    StratName       string              `json:"strat_name"`
+    Sport           string              `json:"sport"`
+    Config          json.RawMessage     `json:"config"`
+    StartDate       time.Time           `json:"start_date"`
+    EndDate         time.Time           `json:"end_date"`
+    ModelVersion    int                 `json:"model_version"`
+    Bets            int                 `json:"bets"`
+    Wins            int                 `json:"wins"`
+    Losses          int                 `json:"losses"`
+    Staked          float32             `json:"staked"`
+    Profit          float32             `json:"profit"`
+    ROI             float32             `json:"roi"`
+    WinRate         float32             `json:"win_rate"`
+    MaxDrawdown     float32             `json:"max_drawdown"`
+    CLVBets         int                 `json:"clv_bets"`
+    AvgCLVLine      float32             `json:"avg_clv_line"`
+    AvgCLVProb      float32             `json:"avg_clv_prob"`
+    BeatCloseRate   float32             `json:"beat_close_rate"`
+    CreatedAt       time.Time           `json:"created_at"`
+}
+
+type BacktestBet struct {
+    Id              int         `json:"id"`
+    RunId           int         `json:"run_id"`
+    Date            time.Time   `json:"date"`
+    PlayerIndex     string      `json:"player_index"`
+    LineId          int         `json:"line_id"`
+    Stat            string      `json:"stat"`
+    Side            string      `json:"side"`
+    Line            float32     `json:"line"`
+    Odds            int         `json:"odds"`
+    Prediction      float32     `json:"prediction"`
+    Diff            float32     `json:"diff"`
+    PDiff           float32     `json:"pdiff"`
+    BetSize         float32     `json:"bet_size"`
+    Actual          float32     `json:"actual"`
+    Result          string      `json:"result"`
+    Profit          float32     `json:"profit"`
+    ClosingLine     *float32    `json:"closing_line"`
+    ClosingOdds     *int        `json:"closing_odds"`
+    CLVLine         *float32    `json:"clv_line"`
+    CLVProb         *float32    `json:"clv_prob"`
+}
@@ -0,0 +1,24 @@
+package backtests
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBacktestRunJSON(t *testing.T) {
+	stratID := 3
+	run := BacktestRun{Id: 1, StratId: &stratID, StratName: "Alt Points", Config: json.RawMessage(`{"MinOdds":200}`), Bets: 2}
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	config, ok := decoded["config"].(map[string]interface{})
+	if !ok || config["MinOdds"] != float64(200) || decoded["strat_id"] != float64(3) {
+		t.Fatalf("unexpected run json: %s", data)
+	}
+}
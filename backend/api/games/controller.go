@@ -52,7 +52,7 @@ func GetLastGame() (Game, error) {
 	return game, nil
 }
 
-func GetGamesForDate(sport sports.Sport, date time.Time) ([]Game, error) {
+func GetGamesForDate(ctx context.Context, sport sports.Sport, date time.Time) ([]Game, error) {
 	db := storage.GetDB()
 
 	sql := `
@@ -60,7 +60,7 @@ func GetGamesForDate(sport sports.Sport, date time.Time) ([]Game, error) {
     WHERE date = ($1) AND sport = ($2)
     ORDER BY date ASC`
 
-	row, err := db.Query(context.Background(), sql, date, sport)
+	row, err := db.Query(ctx, sql, date, sport)
 	if err != nil {
 		return nil, fmt.Errorf("error querying games for date %s: %w", date.Format("2006-01-02"), err)
 	}
@@ -49,7 +49,7 @@ func TestAddAndQueryGames(t *testing.T) {
 		t.Fatalf("GetLastGame() returned invalid row: %+v", last)
 	}
 
-	games, err := GetGamesForDate(sports.NBA, date)
+	games, err := GetGamesForDate(context.Background(), sports.NBA, date)
 	if err != nil {
 		t.Fatalf("GetGamesForDate() error = %v", err)
 	}
@@ -62,7 +62,7 @@ func TestGetGamesForDateNoResults(t *testing.T) {
 	storage.UseLocalDBForIntegrationTests(t)
 	storage.InitTables()
 
-	games, err := GetGamesForDate(sports.NBA, time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC))
+	games, err := GetGamesForDate(context.Background(), sports.NBA, time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC))
 	if err != nil {
 		t.Fatalf("GetGamesForDate() error = %v", err)
 	}
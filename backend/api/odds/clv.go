@@ -0,0 +1,69 @@
+package odds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mgordon34/kornet-kover/internal/sports"
+	"github.com/mgordon34/kornet-kover/internal/storage"
+)
+
+// LineCLV is how a bet compares to the closing line. Both values are signed
+// so positive means the bet beat the close.
+type LineCLV struct {
+	// LineMove is how many points the line moved in the bet's favor
+	LineMove float32 `json:"line_move"`
+	// ProbDelta is the closing implied probability less the bet's
+	ProbDelta float32 `json:"prob_delta"`
+}
+
+// CalculateCLV compares the line that was bet against the closing line for
+// the same stat and side
+func CalculateCLV(side string, line float32, odds int, closingLine float32, closingOdds int) LineCLV {
+	move := closingLine - line
+	if strings.EqualFold(side, "under") {
+		move = -move
+	}
+	return LineCLV{
+		LineMove:  move,
+		ProbDelta: float32(ImpliedProbability(closingOdds) - ImpliedProbability(odds)),
+	}
+}
+
+// GetClosingLinesForDate returns the last line posted on the date for every
+// market of the given type
+func GetClosingLinesForDate(ctx context.Context, sport sports.Sport, date time.Time, lineType string) (map[MarketKey]PlayerLine, error) {
+	db := storage.GetDB()
+
+	partition := "player_index, stat, side"
+	if lineType == "alternate" {
+		partition += ", line"
+	}
+	sql := fmt.Sprintf(`
+    SELECT id, sport, player_index, timestamp, stat, side, type, line, odds, link, bookmaker FROM (
+        SELECT *, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY timestamp DESC, id DESC) as rn
+        FROM player_lines
+        WHERE timestamp >= ($1) AND timestamp < ($2) AND type = ($3) AND sport = ($4)
+    ) pl
+    WHERE rn = 1`, partition)
+
+	rows, err := db.Query(ctx, sql, date, date.AddDate(0, 0, 1), lineType, sport)
+	if err != nil {
+		return nil, fmt.Errorf("error querying closing lines for %v: %w", date, err)
+	}
+	defer rows.Close()
+	lines, err := pgx.CollectRows(rows, pgx.RowToStructByName[PlayerLine])
+	if err != nil {
+		return nil, fmt.Errorf("error getting closing lines for %v: %w", date, err)
+	}
+
+	closing := make(map[MarketKey]PlayerLine, len(lines))
+	for _, line := range lines {
+		closing[NewMarketKey(line)] = line
+	}
+	return closing, nil
+}
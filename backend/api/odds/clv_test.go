@@ -0,0 +1,23 @@
+package odds
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculateCLV(t *testing.T) {
+	over := CalculateCLV("Over", 20.5, -110, 21.5, -130)
+	if over.LineMove != 1 || over.ProbDelta <= 0 {
+		t.Fatalf("over that beat the close = %+v", over)
+	}
+
+	under := CalculateCLV("Under", 20.5, -110, 21.5, -110)
+	if under.LineMove != -1 || under.ProbDelta != 0 {
+		t.Fatalf("under that moved against us = %+v", under)
+	}
+
+	price := CalculateCLV("Over", 20.5, 150, 20.5, 120)
+	if price.LineMove != 0 || math.Abs(float64(price.ProbDelta)-(100.0/220-.4)) > 1e-6 {
+		t.Fatalf("price only move = %+v", price)
+	}
+}
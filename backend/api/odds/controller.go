@@ -41,6 +41,7 @@ func AddPlayerLines(playerLines []PlayerLine) {
             playerLine.Line,
             playerLine.Odds,
             playerLine.Link,
+            playerLine.Bookmaker,
         })
     }
 
@@ -57,6 +58,7 @@ func AddPlayerLines(playerLines []PlayerLine) {
             "line",
             "odds",
             "link",
+            "bookmaker",
         },
         pgx.CopyFromRows(teamsInterface),
     )
@@ -67,8 +69,8 @@ func AddPlayerLines(playerLines []PlayerLine) {
 
 	_, err = txn.Exec(
         context.Background(),
-        `INSERT INTO player_lines (sport, player_index, timestamp, stat, side, type, line, odds, link)
-        SELECT sport, player_index, timestamp, stat, side, type, line, odds, link FROM player_lines_temp
+        `INSERT INTO player_lines (sport, player_index, timestamp, stat, side, type, line, odds, link, bookmaker)
+        SELECT sport, player_index, timestamp, stat, side, type, line, odds, link, bookmaker FROM player_lines_temp
         ON CONFLICT DO NOTHING`,
     )
 	if err != nil {
@@ -86,18 +88,18 @@ func GetPlayerLinesForDate(sport sports.Sport, date time.Time, lineType string)
     endDate := date.AddDate(0, 0, 1)
 
     db := storage.GetDB()
-    sql := `SELECT pl.id, pl.sport, pl.player_index, pl.timestamp, pl.stat, pl.side, pl.type, pl.line, pl.odds, pl.link FROM player_lines pl INNER JOIN
-                (select player_index, stat, side, line, max(timestamp) as latest from player_lines where (timestamp between ($1) and ($2)) and type = ($3) and sport = ($4) group by player_index, stat, side, line) mpl 
-                on pl.timestamp = mpl.latest and pl.player_index = mpl.player_index and pl.stat = mpl.stat and pl.side = mpl.side and pl.line = mpl.line;`
+    sql := `SELECT pl.id, pl.sport, pl.player_index, pl.timestamp, pl.stat, pl.side, pl.type, pl.line, pl.odds, pl.link, pl.bookmaker FROM player_lines pl INNER JOIN
+                (select player_index, stat, side, line, bookmaker, max(timestamp) as latest from player_lines where (timestamp between ($1) and ($2)) and type = ($3) and sport = ($4) group by player_index, stat, side, line, bookmaker) mpl 
+                on pl.timestamp = mpl.latest and pl.player_index = mpl.player_index and pl.stat = mpl.stat and pl.side = mpl.side and pl.line = mpl.line and pl.bookmaker = mpl.bookmaker;`
 
     rows, err := db.Query(context.Background(), sql, date, endDate, lineType, sport)
     if err != nil {
-        log.Fatal("Error querying for player lines: ", err)
+        return nil, fmt.Errorf("error querying for player lines on %v: %w", date, err)
     }
     defer rows.Close()
     pLines, err := pgx.CollectRows(rows, pgx.RowToStructByName[PlayerLine])
     if err != nil {
-        log.Fatal("Error converting rows to playerLines: ", err)
+        return nil, fmt.Errorf("error converting rows to player lines: %w", err)
     }
 
     return pLines, nil
@@ -106,13 +108,16 @@ func GetPlayerLinesForDate(sport sports.Sport, date time.Time, lineType string)
 type PlayerOdds struct {
     Over    PlayerLine
     Under   PlayerLine
+    // Fair is the consensus no-vig probability of each side, set when the
+    // over and under share a line that some source posted both sides of
+    Fair    *FairProbability
 }
 
 func GetLastLine(oddsType string) (PlayerLine, error) {
     db := storage.GetDB()
 
     sql := `
-	SELECT id, sport, player_index, timestamp, stat, side, type, line, odds, link from player_lines
+	SELECT id, sport, player_index, timestamp, stat, side, type, line, odds, link, bookmaker from player_lines
 	where type = ($1)
     ORDER BY timestamp DESC
     LIMIT 1`
@@ -127,24 +132,30 @@ func GetLastLine(oddsType string) (PlayerLine, error) {
     return pLine, nil
 }
 
-func GetPlayerOddsForDate(sport sports.Sport, date time.Time) (map[string]map[string]PlayerOdds, error) {
+// GetPlayerOddsForDate returns the mainline the selector picks for each side of
+// every player's stats, with fair probabilities attached. A nil selector uses
+// DefaultLineSelector.
+func GetPlayerOddsForDate(ctx context.Context, sport sports.Sport, date time.Time, selector LineSelector) (map[string]map[string]PlayerOdds, error) {
     oddsMap := make(map[string]map[string]PlayerOdds)
 
-    lines, err := GetPlayerLinesForDate(sport, date, "mainline")
+    postings, err := GetLinePostingsForDate(ctx, sport, date, "mainline")
     if err != nil {
         return oddsMap, err
     }
-    for _, line := range lines {
+    for _, line := range SelectLines(date, postings, selector) {
         addLineToOddsMap(oddsMap, line)
     }
+    attachFairProbabilities(oddsMap, postings, DefaultDevigMethod)
 
     return oddsMap, nil
 }
 
-func GetAlternatePlayerOddsForDate(sport sports.Sport, date time.Time) (map[string]map[string][]PlayerLine, error) {
+// GetAlternatePlayerOddsForDate returns the alternate line the selector picks at
+// every line value of every player's stats. A nil selector uses DefaultLineSelector.
+func GetAlternatePlayerOddsForDate(ctx context.Context, sport sports.Sport, date time.Time, selector LineSelector) (map[string]map[string][]PlayerLine, error) {
     oddsMap := make(map[string]map[string][]PlayerLine)
 
-    lines, err := GetPlayerLinesForDate(sport, date, "alternate")
+    lines, err := SelectPlayerLinesForDate(ctx, sport, date, "alternate", selector)
     if err != nil {
         return oddsMap, err
     }
@@ -175,9 +186,9 @@ func addLineToOddsMap(oddsMap map[string]map[string]PlayerOdds, line PlayerLine)
     }
 
     pOdds := oddsMap[line.PlayerIndex][line.Stat]
-    if line.Side == "Over" && isLineCloser(pOdds.Over, line, 0) {
+    if line.Side == "Over" {
         pOdds.Over = line
-    } else if line.Side == "Under" && isLineCloser(pOdds.Under, line, 0) {
+    } else if line.Side == "Under" {
         pOdds.Under = line
     }
     oddsMap[line.PlayerIndex][line.Stat] = pOdds
@@ -194,12 +205,14 @@ func isLineCloser(currLine PlayerLine, newLine PlayerLine, target int) bool {
 }
 
 func getDistanceFromTarget(odds int, target int) float64 {
-	var normalizedOdds int
+	return math.Abs(float64(normalizeOdds(odds) - target))
+}
+
+// normalizeOdds shifts american odds so even money is 0 and the scale has no
+// gap between -100 and +100
+func normalizeOdds(odds int) int {
 	if odds < 0 {
-		normalizedOdds = odds + 100
-	} else {
-		normalizedOdds = odds - 100
+		return odds + 100
 	}
-
-	return math.Abs(float64(normalizedOdds - target))
+	return odds - 100
 }
@@ -4,6 +4,7 @@
 package odds
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -45,19 +46,76 @@ func TestOddsDatabaseFlows(t *testing.T) {
 		t.Fatalf("expected at least two main lines, got %d", len(mainLines))
 	}
 
-	oddsMap, err := GetPlayerOddsForDate(sports.NBA, date)
+	oddsMap, err := GetPlayerOddsForDate(context.Background(), sports.NBA, date, nil)
 	if err != nil {
 		t.Fatalf("GetPlayerOddsForDate() error = %v", err)
 	}
 	if oddsMap["oddsit01"]["points"].Over.Odds != -110 {
 		t.Fatalf("expected closest over odds -110, got %d", oddsMap["oddsit01"]["points"].Over.Odds)
 	}
+	if fair := oddsMap["oddsit01"]["points"].Fair; fair == nil || fair.Over+fair.Under < .999 || fair.Over <= fair.Under {
+		t.Fatalf("expected a devigged pair favouring the over, got %+v", fair)
+	}
+	for _, selector := range []LineSelector{EarliestLine{}, LatestLine{Before: 150 * time.Minute}} {
+		oddsMap, err := GetPlayerOddsForDate(context.Background(), sports.NBA, date, selector)
+		if err != nil {
+			t.Fatalf("GetPlayerOddsForDate(%s) error = %v", selector.Name(), err)
+		}
+		if over := oddsMap["oddsit01"]["points"].Over; over.Odds != -120 || over.Link != "a" {
+			t.Fatalf("expected %s to pick the first over, got %+v", selector.Name(), over)
+		}
+		if under := oddsMap["oddsit01"]["points"].Under; selector.Name() == "earliest" && under.Odds != -105 {
+			t.Fatalf("expected the only under, got %+v", under)
+		}
+	}
+
+	postings, err := GetLinePostingsForDate(context.Background(), sports.NBA, date, "mainline")
+	if err != nil || len(postings) != 3 || postings[0].Link != "a" {
+		t.Fatalf("GetLinePostingsForDate() = %+v, err=%v", postings, err)
+	}
 
-	altMap, err := GetAlternatePlayerOddsForDate(sports.NBA, date)
+	altMap, err := GetAlternatePlayerOddsForDate(context.Background(), sports.NBA, date, nil)
 	if err != nil {
 		t.Fatalf("GetAlternatePlayerOddsForDate() error = %v", err)
 	}
 	if len(altMap["oddsit01"]["points"]) == 0 {
 		t.Fatalf("expected alternate lines for oddsit01")
 	}
+
+	closing, err := GetClosingLinesForDate(context.Background(), sports.NBA, date, "mainline")
+	if err != nil {
+		t.Fatalf("GetClosingLinesForDate() error = %v", err)
+	}
+	if line := closing[MarketKey{PlayerIndex: "oddsit01", Stat: "points", Side: "Over"}]; line.Odds != -110 || line.Link != "b" {
+		t.Fatalf("expected the latest over to close, got %+v", line)
+	}
+	altClosing, err := GetClosingLinesForDate(context.Background(), sports.NBA, date, "alternate")
+	if err != nil {
+		t.Fatalf("GetClosingLinesForDate(alternate) error = %v", err)
+	}
+	if line := altClosing[MarketKey{PlayerIndex: "oddsit01", Stat: "points", Side: "Over", Line: 24.5}]; line.Odds != 180 {
+		t.Fatalf("expected alternate closing line at 24.5, got %+v", line)
+	}
+
+	AddPlayerLines([]PlayerLine{
+		{Sport: "nba", PlayerIndex: "oddsit01", Timestamp: ts2, Stat: "points", Side: "Over", Type: "mainline", Line: 21.5, Odds: -102, Link: "e", Bookmaker: "fanduel"},
+	})
+	best, err := GetPlayerOddsForDate(context.Background(), sports.NBA, date, BestPrice{})
+	if err != nil {
+		t.Fatalf("GetPlayerOddsForDate(best price) error = %v", err)
+	}
+	if over := best["oddsit01"]["points"].Over; over.Odds != -102 || over.Bookmaker != "fanduel" {
+		t.Fatalf("expected the fanduel price posted at the same time to be stored and win, got %+v", over)
+	}
+
+	AddPlayerLines([]PlayerLine{
+		{Sport: "nba", PlayerIndex: "oddsit01", Timestamp: ts2, Stat: "points", Side: "Under", Type: "mainline", Line: 21.5, Odds: 110, Link: "f", Bookmaker: "draftkings"},
+	})
+	opportunities, err := ScanOpportunities(sports.NBA, date, OpportunityConfig{})
+	if err != nil {
+		t.Fatalf("ScanOpportunities() error = %v", err)
+	}
+	if len(opportunities) == 0 || !opportunities[0].Arbitrage || opportunities[0].OverSource != "fanduel" || opportunities[0].UnderSource != "draftkings" {
+		t.Fatalf("expected a fanduel over/draftkings under arb, got %+v", opportunities)
+	}
 }
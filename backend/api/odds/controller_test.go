@@ -2,10 +2,9 @@ package odds
 
 import "testing"
 
-func TestAddLineToOddsMap_SelectsCloserOdds(t *testing.T) {
+func TestAddLineToOddsMap_PlacesSides(t *testing.T) {
 	oddsMap := map[string]map[string]PlayerOdds{}
 
-	addLineToOddsMap(oddsMap, PlayerLine{PlayerIndex: "p1", Stat: "points", Side: "Over", Odds: -130, Line: 20.5})
 	addLineToOddsMap(oddsMap, PlayerLine{PlayerIndex: "p1", Stat: "points", Side: "Over", Odds: -110, Line: 21.5})
 	addLineToOddsMap(oddsMap, PlayerLine{PlayerIndex: "p1", Stat: "points", Side: "Under", Odds: -125, Line: 20.5})
 
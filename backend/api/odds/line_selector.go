@@ -0,0 +1,215 @@
+package odds
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/mgordon34/kornet-kover/internal/sports"
+	"github.com/mgordon34/kornet-kover/internal/storage"
+)
+
+// MarketKey identifies the market a line belongs to. Alternate lines are a
+// market per line value, mainlines are one market that moves between numbers.
+type MarketKey struct {
+	PlayerIndex string
+	Stat        string
+	Side        string
+	Line        float32
+}
+
+func NewMarketKey(line PlayerLine) MarketKey {
+	key := MarketKey{PlayerIndex: line.PlayerIndex, Stat: line.Stat, Side: line.Side}
+	if line.Type == "alternate" {
+		key.Line = line.Line
+	}
+	return key
+}
+
+// LineSelector chooses the line to bet in a market from every time it was
+// posted on the date. Postings come in the order they were posted.
+type LineSelector interface {
+	Name() string
+	Select(date time.Time, postings []PlayerLine) (PlayerLine, bool)
+}
+
+// DefaultLineSelector keeps the line on the board nearest even odds
+var DefaultLineSelector LineSelector = ClosestToOdds{}
+
+// LatestLine takes the last line posted. Games carry no start time, so Before,
+// when set, stands in for it as an offset from the start of the date and lines
+// posted after it are ignored.
+type LatestLine struct {
+	Before time.Duration
+}
+
+func (l LatestLine) Name() string {
+	if l.Before > 0 {
+		return fmt.Sprintf("latest before +%v", l.Before)
+	}
+	return "latest"
+}
+
+func (l LatestLine) Select(date time.Time, postings []PlayerLine) (PlayerLine, bool) {
+	for i := len(postings) - 1; i >= 0; i-- {
+		if l.Before <= 0 || postings[i].Timestamp.Before(date.Add(l.Before)) {
+			return postings[i], true
+		}
+	}
+	return PlayerLine{}, false
+}
+
+// EarliestLine takes the first line posted on the date
+type EarliestLine struct{}
+
+func (e EarliestLine) Name() string {
+	return "earliest"
+}
+
+func (e EarliestLine) Select(date time.Time, postings []PlayerLine) (PlayerLine, bool) {
+	if len(postings) == 0 {
+		return PlayerLine{}, false
+	}
+	return postings[0], true
+}
+
+// BestPrice takes the best paying book in the market. Books can hang a
+// mainline at different numbers, so prices are only compared at the number
+// nearest even odds.
+type BestPrice struct{}
+
+func (b BestPrice) Name() string {
+	return "best price"
+}
+
+func (b BestPrice) Select(date time.Time, postings []PlayerLine) (PlayerLine, bool) {
+	best, ok := ClosestToOdds{}.Select(date, postings)
+	if !ok {
+		return PlayerLine{}, false
+	}
+	for _, line := range currentBoard(postings) {
+		if line.Line == best.Line && line.Odds > best.Odds {
+			best = line
+		}
+	}
+	return best, true
+}
+
+// ClosestToOdds takes the line on the board whose odds are nearest Target,
+// given in american odds. Zero means even money.
+type ClosestToOdds struct {
+	Target int
+}
+
+func (c ClosestToOdds) Name() string {
+	if c.Target == 0 {
+		return "closest to +100"
+	}
+	return fmt.Sprintf("closest to %+d", c.Target)
+}
+
+func (c ClosestToOdds) Select(date time.Time, postings []PlayerLine) (PlayerLine, bool) {
+	board := currentBoard(postings)
+	if len(board) == 0 {
+		return PlayerLine{}, false
+	}
+	target := 0
+	if c.Target != 0 {
+		target = normalizeOdds(c.Target)
+	}
+	closest := board[0]
+	for _, line := range board[1:] {
+		if isLineCloser(closest, line, target) {
+			closest = line
+		}
+	}
+	return closest, true
+}
+
+// currentBoard keeps each book's latest posting of each line value, dropping
+// prices that were no longer on offer
+func currentBoard(postings []PlayerLine) []PlayerLine {
+	type offer struct {
+		bookmaker string
+		line      float32
+	}
+	var board []PlayerLine
+	index := make(map[offer]int)
+	for _, posting := range postings {
+		key := offer{bookmaker: posting.Bookmaker, line: posting.Line}
+		if i, ok := index[key]; ok {
+			board[i] = posting
+			continue
+		}
+		index[key] = len(board)
+		board = append(board, posting)
+	}
+	return board
+}
+
+// SelectLines groups postings by market and keeps the line the selector picks
+// for each, in the order markets were first posted. A nil selector uses
+// DefaultLineSelector.
+func SelectLines(date time.Time, postings []PlayerLine, selector LineSelector) []PlayerLine {
+	if selector == nil {
+		selector = DefaultLineSelector
+	}
+
+	keys, markets := groupMarkets(postings)
+	var selected []PlayerLine
+	for _, key := range keys {
+		if line, ok := selector.Select(date, markets[key]); ok {
+			selected = append(selected, line)
+		}
+	}
+	return selected
+}
+
+// groupMarkets splits postings by market, returning the markets in the order
+// they were first posted
+func groupMarkets(postings []PlayerLine) ([]MarketKey, map[MarketKey][]PlayerLine) {
+	var keys []MarketKey
+	markets := make(map[MarketKey][]PlayerLine)
+	for _, posting := range postings {
+		key := NewMarketKey(posting)
+		if _, ok := markets[key]; !ok {
+			keys = append(keys, key)
+		}
+		markets[key] = append(markets[key], posting)
+	}
+	return keys, markets
+}
+
+// GetLinePostingsForDate returns every line of the type posted on the date in
+// the order they were posted
+func GetLinePostingsForDate(ctx context.Context, sport sports.Sport, date time.Time, lineType string) ([]PlayerLine, error) {
+	db := storage.GetDB()
+	sql := `
+    SELECT id, sport, player_index, timestamp, stat, side, type, line, odds, link, bookmaker FROM player_lines
+    WHERE timestamp >= ($1) AND timestamp < ($2) AND type = ($3) AND sport = ($4)
+    ORDER BY timestamp, id`
+
+	rows, err := db.Query(ctx, sql, date, date.AddDate(0, 0, 1), lineType, sport)
+	if err != nil {
+		return nil, fmt.Errorf("error querying line postings for %v: %w", date, err)
+	}
+	defer rows.Close()
+	postings, err := pgx.CollectRows(rows, pgx.RowToStructByName[PlayerLine])
+	if err != nil {
+		return nil, fmt.Errorf("error getting line postings for %v: %w", date, err)
+	}
+
+	return postings, nil
+}
+
+// SelectPlayerLinesForDate returns the line the selector picks in every market
+// of the type on the date
+func SelectPlayerLinesForDate(ctx context.Context, sport sports.Sport, date time.Time, lineType string, selector LineSelector) ([]PlayerLine, error) {
+	postings, err := GetLinePostingsForDate(ctx, sport, date, lineType)
+	if err != nil {
+		return nil, err
+	}
+	return SelectLines(date, postings, selector), nil
+}
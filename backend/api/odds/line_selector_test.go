@@ -0,0 +1,115 @@
+package odds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewMarketKey(t *testing.T) {
+	main := NewMarketKey(PlayerLine{PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "mainline", Line: 20.5})
+	if main != (MarketKey{PlayerIndex: "p1", Stat: "points", Side: "Over"}) {
+		t.Fatalf("mainline key should ignore the line, got %+v", main)
+	}
+	alt := NewMarketKey(PlayerLine{PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "alternate", Line: 24.5})
+	if alt.Line != 24.5 {
+		t.Fatalf("alternate key should keep the line, got %+v", alt)
+	}
+}
+
+// overPostings is a points over that opens at 20.5 -130, moves to 21.5 -110
+// and comes back to 20.5 at +105 late in the day
+func overPostings(date time.Time) []PlayerLine {
+	return []PlayerLine{
+		{Id: 1, PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "mainline", Line: 20.5, Odds: -130, Timestamp: date.Add(10 * time.Hour)},
+		{Id: 2, PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "mainline", Line: 21.5, Odds: -110, Timestamp: date.Add(14 * time.Hour)},
+		{Id: 3, PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "mainline", Line: 20.5, Odds: 105, Timestamp: date.Add(20 * time.Hour)},
+	}
+}
+
+func TestLineSelectors(t *testing.T) {
+	date := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	postings := overPostings(date)
+
+	tests := []struct {
+		selector LineSelector
+		name     string
+		want     int
+	}{
+		{LatestLine{}, "latest", 3},
+		{LatestLine{Before: 19 * time.Hour}, "latest before +19h0m0s", 2},
+		{EarliestLine{}, "earliest", 1},
+		{BestPrice{}, "best price", 3},
+		{ClosestToOdds{}, "closest to +100", 3},
+		{ClosestToOdds{Target: -115}, "closest to -115", 2},
+	}
+	for _, tt := range tests {
+		got, ok := tt.selector.Select(date, postings)
+		if !ok || got.Id != tt.want {
+			t.Fatalf("%s selected %+v, want id %d", tt.name, got, tt.want)
+		}
+		if tt.selector.Name() != tt.name {
+			t.Fatalf("Name() = %q, want %q", tt.selector.Name(), tt.name)
+		}
+	}
+
+	for _, selector := range []LineSelector{LatestLine{}, EarliestLine{}, BestPrice{}, ClosestToOdds{}} {
+		if _, ok := selector.Select(date, nil); ok {
+			t.Fatalf("%s selected a line from no postings", selector.Name())
+		}
+	}
+	if _, ok := (LatestLine{Before: time.Hour}).Select(date, postings); ok {
+		t.Fatalf("expected no line posted before the cutoff")
+	}
+}
+
+func TestCurrentBoardDropsStalePrices(t *testing.T) {
+	board := currentBoard(overPostings(time.Time{}))
+	if len(board) != 2 || board[0].Id != 3 || board[1].Id != 2 {
+		t.Fatalf("unexpected board: %+v", board)
+	}
+}
+
+func TestSelectLinesGroupsByMarket(t *testing.T) {
+	date := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	postings := append(overPostings(date),
+		PlayerLine{Id: 4, PlayerIndex: "p1", Stat: "points", Side: "Under", Type: "mainline", Line: 20.5, Odds: -120},
+		PlayerLine{Id: 5, PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "alternate", Line: 24.5, Odds: 180},
+		PlayerLine{Id: 6, PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "alternate", Line: 25.5, Odds: 210},
+		PlayerLine{Id: 7, PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "alternate", Line: 24.5, Odds: 170},
+	)
+
+	got := SelectLines(date, postings, nil)
+	var ids []int
+	for _, line := range got {
+		ids = append(ids, line.Id)
+	}
+	if len(ids) != 4 || ids[0] != 3 || ids[1] != 4 || ids[2] != 7 || ids[3] != 6 {
+		t.Fatalf("SelectLines() ids = %v, want [3 4 7 6]", ids)
+	}
+
+	got = SelectLines(date, postings, EarliestLine{})
+	if len(got) != 4 || got[0].Id != 1 || got[2].Id != 5 {
+		t.Fatalf("SelectLines(earliest) = %+v", got)
+	}
+}
+
+func TestBestPriceComparesBooksAtTheMainNumber(t *testing.T) {
+	date := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	postings := []PlayerLine{
+		{Id: 1, Type: "mainline", Bookmaker: "fanduel", Line: 20.5, Odds: -115, Timestamp: date.Add(time.Hour)},
+		{Id: 2, Type: "mainline", Bookmaker: "draftkings", Line: 20.5, Odds: -120, Timestamp: date.Add(time.Hour)},
+		{Id: 3, Type: "mainline", Bookmaker: "williamhill_us", Line: 22.5, Odds: 140, Timestamp: date.Add(2 * time.Hour)},
+		{Id: 4, Type: "mainline", Bookmaker: "draftkings", Line: 20.5, Odds: -105, Timestamp: date.Add(3 * time.Hour)},
+		{Id: 5, Type: "mainline", Bookmaker: "fanduel", Line: 20.5, Odds: -125, Timestamp: date.Add(4 * time.Hour)},
+	}
+
+	if got, ok := (BestPrice{}).Select(date, postings); !ok || got.Id != 4 {
+		t.Fatalf("BestPrice selected %+v, want draftkings -105", got)
+	}
+	if board := currentBoard(postings); len(board) != 3 || board[0].Id != 5 || board[1].Id != 4 {
+		t.Fatalf("expected the latest posting per book, got %+v", board)
+	}
+	if _, ok := (BestPrice{}).Select(date, nil); ok {
+		t.Fatalf("BestPrice selected a line from no postings")
+	}
+}
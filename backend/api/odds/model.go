@@ -13,4 +13,5 @@ type PlayerLine struct {
     Line            float32   `json:"line"`
     Odds            int       `json:"odds"`
     Link            string    `json:"link"`
+    Bookmaker       string    `json:"bookmaker"`
 }
@@ -0,0 +1,250 @@
+package odds
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
+
+// unknownSource labels prices stored before lines recorded their bookmaker
+const unknownSource = "unknown"
+
+// defaultMinMiddleGap is the smallest gap between an over and an under that
+// leaves a whole number for the result to land on between half point lines
+const defaultMinMiddleGap = 1
+
+// Opportunity is an over and an under on the same player and stat from
+// different sources that can't both lose, or that can both win
+type Opportunity struct {
+	PlayerIndex string     `json:"player_index"`
+	Stat        string     `json:"stat"`
+	Over        PlayerLine `json:"over"`
+	OverSource  string     `json:"over_source"`
+	Under       PlayerLine `json:"under"`
+	UnderSource string     `json:"under_source"`
+	// ImpliedTotal is the two prices' implied probabilities added together
+	ImpliedTotal float64 `json:"implied_total"`
+	// Arbitrage is set when the under is at or above the over's line and the
+	// prices imply under 100%, so staking in proportion to them returns Margin
+	// on the total staked whatever the result
+	Arbitrage bool    `json:"arbitrage"`
+	Margin    float64 `json:"margin"`
+	// Middle is set when the under's line is at least the configured gap above
+	// the over's, so a result between them wins both bets
+	Middle bool    `json:"middle"`
+	Gap    float32 `json:"gap"`
+}
+
+type OpportunityConfig struct {
+	// MinMiddleGap defaults to defaultMinMiddleGap
+	MinMiddleGap float32
+	// MaxMiddleImplied drops middles whose prices imply more than this. Zero
+	// keeps every middle.
+	MaxMiddleImplied float64
+}
+
+// LineSource is where a price came from
+func LineSource(line PlayerLine) string {
+	if line.Bookmaker == "" {
+		return unknownSource
+	}
+	return line.Bookmaker
+}
+
+// currentOffers keeps every source's latest price at each line in each market
+func currentOffers(postings []PlayerLine) []PlayerLine {
+	keys, markets := groupMarkets(postings)
+	var offers []PlayerLine
+	for _, key := range keys {
+		offers = append(offers, currentBoard(markets[key])...)
+	}
+	return offers
+}
+
+// FindOpportunities pairs every over with every under from a different source
+// on the same player and stat, and keeps the pairs that are an arbitrage or a
+// middle. Arbs come first by margin, then middles by gap.
+func FindOpportunities(lines []PlayerLine, config OpportunityConfig) []Opportunity {
+	if config.MinMiddleGap <= 0 {
+		config.MinMiddleGap = defaultMinMiddleGap
+	}
+
+	type prop struct {
+		playerIndex string
+		stat        string
+	}
+	overs := make(map[prop][]PlayerLine)
+	unders := make(map[prop][]PlayerLine)
+	for _, line := range lines {
+		key := prop{playerIndex: line.PlayerIndex, stat: line.Stat}
+		switch line.Side {
+		case "Over":
+			overs[key] = append(overs[key], line)
+		case "Under":
+			unders[key] = append(unders[key], line)
+		}
+	}
+
+	var opportunities []Opportunity
+	for key, propOvers := range overs {
+		for _, over := range propOvers {
+			for _, under := range unders[key] {
+				if LineSource(over) == LineSource(under) {
+					continue
+				}
+				gap := under.Line - over.Line
+				if gap < 0 {
+					continue
+				}
+				implied := ImpliedProbability(over.Odds) + ImpliedProbability(under.Odds)
+				opportunity := Opportunity{
+					PlayerIndex:  key.playerIndex,
+					Stat:         key.stat,
+					Over:         over,
+					OverSource:   LineSource(over),
+					Under:        under,
+					UnderSource:  LineSource(under),
+					ImpliedTotal: implied,
+					Gap:          gap,
+				}
+				if implied > 0 && implied < 1 {
+					opportunity.Arbitrage = true
+					opportunity.Margin = 1/implied - 1
+				}
+				if gap >= config.MinMiddleGap && (config.MaxMiddleImplied <= 0 || implied <= config.MaxMiddleImplied) {
+					opportunity.Middle = true
+				}
+				if opportunity.Arbitrage || opportunity.Middle {
+					opportunities = append(opportunities, opportunity)
+				}
+			}
+		}
+	}
+
+	sort.Slice(opportunities, func(i, j int) bool {
+		a, b := opportunities[i], opportunities[j]
+		if a.Arbitrage != b.Arbitrage {
+			return a.Arbitrage
+		}
+		if a.Margin != b.Margin {
+			return a.Margin > b.Margin
+		}
+		if a.Gap != b.Gap {
+			return a.Gap > b.Gap
+		}
+		if a.PlayerIndex != b.PlayerIndex {
+			return a.PlayerIndex < b.PlayerIndex
+		}
+		if a.Stat != b.Stat {
+			return a.Stat < b.Stat
+		}
+		return a.Over.Line < b.Over.Line || (a.Over.Line == b.Over.Line && a.Under.Line < b.Under.Line)
+	})
+
+	return opportunities
+}
+
+// ScanOpportunities finds the arbs and middles among the latest mainline and
+// alternate prices every source posted on the date
+func ScanOpportunities(sport sports.Sport, date time.Time, config OpportunityConfig) ([]Opportunity, error) {
+	var offers []PlayerLine
+	for _, lineType := range []string{"mainline", "alternate"} {
+		postings, err := GetLinePostingsForDate(context.Background(), sport, date, lineType)
+		if err != nil {
+			return nil, err
+		}
+		offers = append(offers, currentOffers(postings)...)
+	}
+
+	return FindOpportunities(offers, config), nil
+}
+
+type OpportunityServiceDeps struct {
+	ScanOpportunities func(sport sports.Sport, date time.Time, config OpportunityConfig) ([]Opportunity, error)
+	Now               func() time.Time
+	LoadLocation      func(name string) (*time.Location, error)
+}
+
+type OpportunityService struct {
+	deps OpportunityServiceDeps
+}
+
+func NewOpportunityService(deps OpportunityServiceDeps) *OpportunityService {
+	if deps.ScanOpportunities == nil {
+		deps.ScanOpportunities = ScanOpportunities
+	}
+	if deps.Now == nil {
+		deps.Now = time.Now
+	}
+	if deps.LoadLocation == nil {
+		deps.LoadLocation = time.LoadLocation
+	}
+	return &OpportunityService{deps: deps}
+}
+
+// GetOpportunitiesHandler scans the sport's lines on the date, defaulting to
+// nba and today. min_gap and max_middle_implied tune which middles are kept.
+func (s *OpportunityService) GetOpportunitiesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sport := sports.Sport(c.DefaultQuery("sport", string(sports.NBA)))
+		if _, err := sports.GetConfig(sport); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var date time.Time
+		if param := c.Query("date"); param != "" {
+			parsed, err := time.Parse("2006-01-02", param)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date"})
+				return
+			}
+			date = parsed
+		} else {
+			loc, err := s.deps.LoadLocation("America/New_York")
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load timezone"})
+				return
+			}
+			t := s.deps.Now().In(loc)
+			date = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		}
+
+		var config OpportunityConfig
+		if param := c.Query("min_gap"); param != "" {
+			gap, err := strconv.ParseFloat(param, 32)
+			if err != nil || gap < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid min_gap %q", param)})
+				return
+			}
+			config.MinMiddleGap = float32(gap)
+		}
+		if param := c.Query("max_middle_implied"); param != "" {
+			implied, err := strconv.ParseFloat(param, 64)
+			if err != nil || implied < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid max_middle_implied %q", param)})
+				return
+			}
+			config.MaxMiddleImplied = implied
+		}
+
+		opportunities, err := s.deps.ScanOpportunities(sport, date, config)
+		if err != nil {
+			log.Println("Error in ScanOpportunities:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if opportunities == nil {
+			opportunities = []Opportunity{}
+		}
+		c.JSON(http.StatusOK, opportunities)
+	}
+}
@@ -0,0 +1,124 @@
+package odds
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
+
+func TestFindOpportunities(t *testing.T) {
+	lines := []PlayerLine{
+		{PlayerIndex: "p1", Stat: "points", Side: "Over", Line: 20.5, Odds: 110, Bookmaker: "fanduel"},
+		{PlayerIndex: "p1", Stat: "points", Side: "Under", Line: 20.5, Odds: 105, Bookmaker: "draftkings"},
+		{PlayerIndex: "p1", Stat: "points", Side: "Under", Line: 20.5, Odds: -140, Bookmaker: "fanduel"},
+		{PlayerIndex: "p1", Stat: "points", Side: "Under", Line: 19.5, Odds: 150, Bookmaker: "draftkings"},
+		{PlayerIndex: "p2", Stat: "rebounds", Side: "Over", Line: 7.5, Odds: -110},
+		{PlayerIndex: "p2", Stat: "rebounds", Side: "Under", Line: 9.5, Odds: -115, Bookmaker: "williamhill_us"},
+		{PlayerIndex: "p2", Stat: "rebounds", Side: "Under", Line: 8, Odds: -105, Bookmaker: "fanduel"},
+	}
+
+	got := FindOpportunities(lines, OpportunityConfig{})
+	if len(got) != 2 {
+		t.Fatalf("FindOpportunities() = %+v, want an arb and a middle", got)
+	}
+
+	arb := got[0]
+	wantImplied := 100.0/210 + 100.0/205
+	if !arb.Arbitrage || arb.Middle || arb.OverSource != "fanduel" || arb.UnderSource != "draftkings" {
+		t.Fatalf("unexpected arb: %+v", arb)
+	}
+	if math.Abs(arb.ImpliedTotal-wantImplied) > 1e-9 || math.Abs(arb.Margin-(1/wantImplied-1)) > 1e-9 {
+		t.Fatalf("arb implied = %v margin = %v", arb.ImpliedTotal, arb.Margin)
+	}
+
+	middle := got[1]
+	if !middle.Middle || middle.Arbitrage || middle.Gap != 2 || middle.OverSource != "unknown" || middle.UnderSource != "williamhill_us" {
+		t.Fatalf("unexpected middle: %+v", middle)
+	}
+
+	if got := FindOpportunities(lines, OpportunityConfig{MinMiddleGap: 3}); len(got) != 1 || !got[0].Arbitrage {
+		t.Fatalf("expected a wider gap to drop the middle, got %+v", got)
+	}
+	if got := FindOpportunities(lines, OpportunityConfig{MaxMiddleImplied: 1.02}); len(got) != 1 {
+		t.Fatalf("expected the middle's vig to exceed the cap, got %+v", got)
+	}
+}
+
+func TestCurrentOffersKeepsLatestPricePerSource(t *testing.T) {
+	offers := currentOffers([]PlayerLine{
+		{Id: 1, PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "mainline", Line: 20.5, Odds: -110, Bookmaker: "fanduel"},
+		{Id: 2, PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "mainline", Line: 20.5, Odds: -105, Bookmaker: "draftkings"},
+		{Id: 3, PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "mainline", Line: 20.5, Odds: 100, Bookmaker: "fanduel"},
+		{Id: 4, PlayerIndex: "p1", Stat: "points", Side: "Under", Type: "mainline", Line: 20.5, Odds: -120, Bookmaker: "fanduel"},
+	})
+	if len(offers) != 3 || offers[0].Id != 3 || offers[1].Id != 2 || offers[2].Id != 4 {
+		t.Fatalf("unexpected offers: %+v", offers)
+	}
+}
+
+func TestGetOpportunitiesHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var gotSport sports.Sport
+	var gotDate time.Time
+	var gotConfig OpportunityConfig
+	svc := NewOpportunityService(OpportunityServiceDeps{
+		ScanOpportunities: func(sport sports.Sport, date time.Time, config OpportunityConfig) ([]Opportunity, error) {
+			gotSport, gotDate, gotConfig = sport, date, config
+			if sport == sports.WNBA {
+				return nil, errors.New("db down")
+			}
+			if config.MinMiddleGap == 2 {
+				return nil, nil
+			}
+			return []Opportunity{{PlayerIndex: "p1", Arbitrage: true}}, nil
+		},
+		Now: func() time.Time { return time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC) },
+	})
+	r := gin.New()
+	r.GET("/odds/opportunities", svc.GetOpportunitiesHandler())
+
+	get := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		return rec
+	}
+
+	rec := get("/odds/opportunities")
+	var body []Opportunity
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &body) != nil || len(body) != 1 {
+		t.Fatalf("default request = %d %s", rec.Code, rec.Body.String())
+	}
+	if gotSport != sports.NBA || gotDate.Format("2006-01-02") != "2026-01-01" {
+		t.Fatalf("expected today in New York for nba, got %s %v", gotSport, gotDate)
+	}
+
+	rec = get("/odds/opportunities?sport=mlb&date=2025-06-01&min_gap=2&max_middle_implied=1.05")
+	if rec.Code != http.StatusOK || rec.Body.String() != "[]" {
+		t.Fatalf("empty scan = %d %s", rec.Code, rec.Body.String())
+	}
+	if gotSport != sports.MLB || !gotDate.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)) || gotConfig.MinMiddleGap != 2 || gotConfig.MaxMiddleImplied != 1.05 {
+		t.Fatalf("unexpected scan args %s %v %+v", gotSport, gotDate, gotConfig)
+	}
+
+	for _, url := range []string{
+		"/odds/opportunities?sport=cricket",
+		"/odds/opportunities?date=bad",
+		"/odds/opportunities?min_gap=-1",
+		"/odds/opportunities?max_middle_implied=x",
+	} {
+		if rec := get(url); rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want 400", url, rec.Code)
+		}
+	}
+	if rec := get("/odds/opportunities?sport=wnba"); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("scan error status = %d, want 500", rec.Code)
+	}
+}
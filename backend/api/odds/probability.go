@@ -0,0 +1,210 @@
+package odds
+
+import (
+	"fmt"
+	"math"
+)
+
+// DevigMethod is how the bookmaker's margin is taken out of an over/under pair
+type DevigMethod string
+
+const (
+	// Multiplicative scales both sides by the overround
+	Multiplicative DevigMethod = "multiplicative"
+	// Additive takes an equal share of the overround off each side
+	Additive DevigMethod = "additive"
+	// Shin models the margin as protection against insiders, which takes more
+	// off longshots
+	Shin DevigMethod = "shin"
+	// Power raises both sides to the exponent that makes them sum to one
+	Power DevigMethod = "power"
+)
+
+// DefaultDevigMethod is used for the fair probabilities attached to PlayerOdds
+const DefaultDevigMethod = Multiplicative
+
+// devigIterations bounds the bisection searches for Shin and Power, which
+// narrows the interval well past float precision
+const devigIterations = 100
+
+// FairProbability is the chance of each side hitting once vig is removed
+type FairProbability struct {
+	Over  float64 `json:"over"`
+	Under float64 `json:"under"`
+}
+
+// ImpliedProbability converts american odds to the break-even win probability
+func ImpliedProbability(odds int) float64 {
+	if odds == 0 {
+		return 0
+	}
+	if odds < 0 {
+		return -float64(odds) / (-float64(odds) + 100)
+	}
+	return 100 / (float64(odds) + 100)
+}
+
+// DecimalOdds converts american odds to the total returned per unit staked
+func DecimalOdds(odds int) float64 {
+	if odds == 0 {
+		return 0
+	}
+	if odds < 0 {
+		return 1 + 100/-float64(odds)
+	}
+	return 1 + float64(odds)/100
+}
+
+// ExpectedValue is the expected profit per unit staked at the odds when the
+// bet wins with the given probability
+func ExpectedValue(winProbability float64, odds int) float64 {
+	if odds == 0 {
+		return 0
+	}
+	return winProbability*(DecimalOdds(odds)-1) - (1 - winProbability)
+}
+
+// Devig removes the margin from an over/under pair using the method
+func Devig(overOdds int, underOdds int, method DevigMethod) (FairProbability, error) {
+	over, under := ImpliedProbability(overOdds), ImpliedProbability(underOdds)
+	if over <= 0 || under <= 0 {
+		return FairProbability{}, fmt.Errorf("cannot devig odds %d/%d", overOdds, underOdds)
+	}
+
+	switch method {
+	case Multiplicative, "":
+		total := over + under
+		return FairProbability{Over: over / total, Under: under / total}, nil
+	case Additive:
+		margin := (over + under - 1) / 2
+		fairOver := math.Min(math.Max(over-margin, 0), 1)
+		return FairProbability{Over: fairOver, Under: 1 - fairOver}, nil
+	case Shin:
+		return devigShin(over, under), nil
+	case Power:
+		return devigPower(over, under), nil
+	}
+
+	return FairProbability{}, fmt.Errorf("unknown devig method %q", method)
+}
+
+// devigShin finds the insider share z at which Shin's probabilities sum to
+// one. Their sum falls as z grows, so it is bisected.
+func devigShin(over float64, under float64) FairProbability {
+	total := over + under
+	if total <= 1 {
+		return FairProbability{Over: over / total, Under: under / total}
+	}
+	shin := func(p float64, z float64) float64 {
+		return (math.Sqrt(z*z+4*(1-z)*p*p/total) - z) / (2 * (1 - z))
+	}
+
+	low, high := 0.0, 1.0
+	for i := 0; i < devigIterations; i++ {
+		z := (low + high) / 2
+		if shin(over, z)+shin(under, z) > 1 {
+			low = z
+		} else {
+			high = z
+		}
+	}
+	z := (low + high) / 2
+	fairOver := shin(over, z)
+	return FairProbability{Over: fairOver, Under: 1 - fairOver}
+}
+
+// devigPower finds the exponent k with over^k + under^k = 1. The sum falls as
+// k grows, so it is bisected.
+func devigPower(over float64, under float64) FairProbability {
+	low, high := 0.0, 1.0
+	for math.Pow(over, high)+math.Pow(under, high) > 1 {
+		high *= 2
+	}
+	for i := 0; i < devigIterations; i++ {
+		k := (low + high) / 2
+		if math.Pow(over, k)+math.Pow(under, k) > 1 {
+			low = k
+		} else {
+			high = k
+		}
+	}
+	k := (low + high) / 2
+	fairOver := math.Pow(over, k)
+	return FairProbability{Over: fairOver, Under: 1 - fairOver}
+}
+
+// consensusFairProbability devigs every source's latest over/under pair at the
+// line and averages them. It is false when no source has both sides posted.
+func consensusFairProbability(offers []PlayerLine, line float32, method DevigMethod) (FairProbability, bool) {
+	overs := make(map[string]PlayerLine)
+	unders := make(map[string]PlayerLine)
+	var sources []string
+	for _, offer := range offers {
+		if offer.Line != line {
+			continue
+		}
+		source := LineSource(offer)
+		if _, seen := overs[source]; !seen {
+			if _, seen := unders[source]; !seen {
+				sources = append(sources, source)
+			}
+		}
+		switch offer.Side {
+		case "Over":
+			overs[source] = offer
+		case "Under":
+			unders[source] = offer
+		}
+	}
+
+	var consensus FairProbability
+	pairs := 0
+	for _, source := range sources {
+		over, hasOver := overs[source]
+		under, hasUnder := unders[source]
+		if !hasOver || !hasUnder {
+			continue
+		}
+		fair, err := Devig(over.Odds, under.Odds, method)
+		if err != nil {
+			continue
+		}
+		consensus.Over += fair.Over
+		consensus.Under += fair.Under
+		pairs++
+	}
+	if pairs == 0 {
+		return FairProbability{}, false
+	}
+	consensus.Over /= float64(pairs)
+	consensus.Under /= float64(pairs)
+	return consensus, true
+}
+
+// attachFairProbabilities sets the consensus fair probability on every odds
+// entry whose over and under share a line
+func attachFairProbabilities(oddsMap map[string]map[string]PlayerOdds, postings []PlayerLine, method DevigMethod) {
+	type prop struct {
+		playerIndex string
+		stat        string
+	}
+	offers := make(map[prop][]PlayerLine)
+	for _, offer := range currentOffers(postings) {
+		key := prop{playerIndex: offer.PlayerIndex, stat: offer.Stat}
+		offers[key] = append(offers[key], offer)
+	}
+
+	for playerIndex, stats := range oddsMap {
+		for stat, pOdds := range stats {
+			if pOdds.Over.Odds == 0 || pOdds.Under.Odds == 0 || pOdds.Over.Line != pOdds.Under.Line {
+				continue
+			}
+			fair, ok := consensusFairProbability(offers[prop{playerIndex: playerIndex, stat: stat}], pOdds.Over.Line, method)
+			if !ok {
+				continue
+			}
+			pOdds.Fair = &fair
+			stats[stat] = pOdds
+		}
+	}
+}
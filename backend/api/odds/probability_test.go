@@ -0,0 +1,111 @@
+package odds
+
+import (
+	"math"
+	"testing"
+)
+
+func TestImpliedProbability(t *testing.T) {
+	cases := map[int]float64{-110: 110.0 / 210, 100: .5, 150: .4, -200: 2.0 / 3, 0: 0}
+	for odds, want := range cases {
+		if got := ImpliedProbability(odds); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("ImpliedProbability(%d) = %v, want %v", odds, got, want)
+		}
+	}
+}
+
+func TestDecimalOddsAndExpectedValue(t *testing.T) {
+	cases := map[int]float64{-200: 1.5, 100: 2, 150: 2.5, 0: 0}
+	for odds, want := range cases {
+		if got := DecimalOdds(odds); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("DecimalOdds(%d) = %v, want %v", odds, got, want)
+		}
+	}
+
+	if got := ExpectedValue(.5, 150); math.Abs(got-.25) > 1e-9 {
+		t.Fatalf("ExpectedValue(.5, +150) = %v, want .25", got)
+	}
+	if got := ExpectedValue(ImpliedProbability(-110), -110); math.Abs(got) > 1e-9 {
+		t.Fatalf("EV at the break-even probability should be 0, got %v", got)
+	}
+	if ExpectedValue(.5, 0) != 0 {
+		t.Fatalf("EV without a price should be 0")
+	}
+}
+
+func TestDevig(t *testing.T) {
+	// A favourite/longshot pair with about 4.5% overround
+	overOdds, underOdds := -250, 190
+	over, under := ImpliedProbability(overOdds), ImpliedProbability(underOdds)
+
+	results := map[DevigMethod]FairProbability{}
+	for _, method := range []DevigMethod{Multiplicative, Additive, Shin, Power} {
+		fair, err := Devig(overOdds, underOdds, method)
+		if err != nil {
+			t.Fatalf("Devig(%s) error = %v", method, err)
+		}
+		if math.Abs(fair.Over+fair.Under-1) > 1e-9 {
+			t.Fatalf("Devig(%s) = %+v does not sum to 1", method, fair)
+		}
+		if fair.Over >= over || fair.Under >= under {
+			t.Fatalf("Devig(%s) = %+v should shrink both sides of %v/%v", method, fair, over, under)
+		}
+		results[method] = fair
+	}
+
+	if got := results[Multiplicative].Over; math.Abs(got-over/(over+under)) > 1e-9 {
+		t.Fatalf("multiplicative over = %v", got)
+	}
+	if got := results[Additive].Over; math.Abs(got-(over-(over+under-1)/2)) > 1e-9 {
+		t.Fatalf("additive over = %v", got)
+	}
+	// Shin and power take more of the margin off the longshot than scaling does
+	if results[Shin].Under >= results[Multiplicative].Under || results[Power].Under >= results[Multiplicative].Under {
+		t.Fatalf("expected shin and power to shade the longshot: %+v", results)
+	}
+
+	even, _ := Devig(-110, -110, Shin)
+	if math.Abs(even.Over-.5) > 1e-9 {
+		t.Fatalf("an even pair should devig to .5, got %+v", even)
+	}
+	arb, _ := Devig(110, 105, Power)
+	if math.Abs(arb.Over+arb.Under-1) > 1e-9 || arb.Over >= arb.Under {
+		t.Fatalf("a pair under 100%% should still normalize, got %+v", arb)
+	}
+	if arb, _ := Devig(110, 105, Shin); math.Abs(arb.Over+arb.Under-1) > 1e-9 {
+		t.Fatalf("shin should fall back to scaling for a pair under 100%%, got %+v", arb)
+	}
+
+	if _, err := Devig(0, -110, Multiplicative); err == nil {
+		t.Fatalf("expected error for a missing price")
+	}
+	if _, err := Devig(-110, -110, "median"); err == nil {
+		t.Fatalf("expected error for an unknown method")
+	}
+}
+
+func TestAttachFairProbabilities(t *testing.T) {
+	postings := []PlayerLine{
+		{PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "mainline", Line: 20.5, Odds: -120, Bookmaker: "fanduel"},
+		{PlayerIndex: "p1", Stat: "points", Side: "Under", Type: "mainline", Line: 20.5, Odds: 100, Bookmaker: "fanduel"},
+		{PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "mainline", Line: 20.5, Odds: -110, Bookmaker: "draftkings"},
+		{PlayerIndex: "p1", Stat: "points", Side: "Under", Type: "mainline", Line: 20.5, Odds: -110, Bookmaker: "draftkings"},
+		{PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "mainline", Line: 20.5, Odds: 105, Bookmaker: "williamhill_us"},
+		{PlayerIndex: "p2", Stat: "points", Side: "Over", Type: "mainline", Line: 10.5, Odds: -110, Bookmaker: "fanduel"},
+		{PlayerIndex: "p2", Stat: "points", Side: "Under", Type: "mainline", Line: 11.5, Odds: -110, Bookmaker: "draftkings"},
+	}
+	oddsMap := map[string]map[string]PlayerOdds{}
+	for _, line := range SelectLines(postings[0].Timestamp, postings, BestPrice{}) {
+		addLineToOddsMap(oddsMap, line)
+	}
+	attachFairProbabilities(oddsMap, postings, Multiplicative)
+
+	fanduel, _ := Devig(-120, 100, Multiplicative)
+	fair := oddsMap["p1"]["points"].Fair
+	if fair == nil || math.Abs(fair.Over-(fanduel.Over+.5)/2) > 1e-9 {
+		t.Fatalf("expected the average of the fanduel and draftkings pairs, got %+v", fair)
+	}
+	if oddsMap["p2"]["points"].Fair != nil {
+		t.Fatalf("sides on different lines should have no fair probability")
+	}
+}
@@ -20,6 +20,7 @@ type PicksServiceDeps struct {
 	GetPropPicks   func(userID int, date time.Time) ([]PropPickFormatted, error)
 	GetPropPick    func(pickID int) (PropPick, error)
 	GetBettorPicks func(userID int, date time.Time) ([]BettorPickRow, error)
+	GetGradedPicks func(query GradedPicksQuery) ([]GradedPick, error)
 	Now            func() time.Time
 	LoadLocation   func(name string) (*time.Location, error)
 }
@@ -38,6 +39,9 @@ func NewPicksService(deps PicksServiceDeps) *PicksService {
 	if deps.GetBettorPicks == nil {
 		deps.GetBettorPicks = getBettorPicks
 	}
+	if deps.GetGradedPicks == nil {
+		deps.GetGradedPicks = GetGradedPicks
+	}
 	if deps.Now == nil {
 		deps.Now = time.Now
 	}
@@ -115,6 +119,59 @@ func (s *PicksService) GetBettorPropPicksHandler() gin.HandlerFunc {
 	}
 }
 
+func (s *PicksService) GetGradedPicksHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var query GradedPicksQuery
+		userID, err := strconv.Atoi(c.Query("user_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+			return
+		}
+		query.UserId = userID
+		if stratParam := c.Query("strat_id"); stratParam != "" {
+			stratID, err := strconv.Atoi(stratParam)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid strat_id"})
+				return
+			}
+			query.StratId = stratID
+		}
+		query.StartDate, err = ParseOptionalDate(c.Query("start"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date"})
+			return
+		}
+		query.EndDate, err = ParseOptionalDate(c.Query("end"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date"})
+			return
+		}
+
+		graded, err := s.deps.GetGradedPicks(query)
+		if err != nil {
+			log.Println("Error in GetGradedPicks:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if graded == nil {
+			graded = []GradedPick{}
+		}
+		c.JSON(http.StatusOK, graded)
+	}
+}
+
+// ParseOptionalDate parses a YYYY-MM-DD query value, returning nil when it is empty
+func ParseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
 func addPropPick(pick PropPick) (int, error) {
 	db := storage.GetDB()
 
@@ -318,7 +375,9 @@ func getPropPick(stratId int) (PropPick, error) {
 	db := storage.GetDB()
 
 	sql := `
-    SELECT * from prop_picks
+    SELECT id, strat_id, line_id, valid, date, actual, result, profit,
+        closing_line, closing_odds, clv_line, clv_prob
+    FROM prop_picks
     WHERE id=($1)`
 
 	row, _ := db.Query(context.Background(), sql, stratId)
@@ -360,6 +419,7 @@ type BettorPickRow struct {
 	Line       float32 `db:"line"`
 	Stat       string  `db:"stat"`
 	Odds       int     `db:"odds"`
+	Bookmaker  string  `db:"bookmaker"`
 	Points     float32 `db:"points"`
 	Rebounds   float32 `db:"rebounds"`
 	Assists    float32 `db:"assists"`
@@ -372,6 +432,7 @@ type BettorPick struct {
 	PlayerName  string  `json:"player_name"`
 	Team        string  `json:"team"`
 	LineDisplay string  `json:"line_display"`
+	Bookmaker   string  `json:"bookmaker"`
 	Predicted   float32 `json:"predicted"`
 }
 
@@ -403,7 +464,9 @@ func getPredictedValue(stat string, points, rebounds, assists, threes float32) f
 	}
 }
 
-// getBettorPicks retrieves prop picks formatted for bettors
+// getBettorPicks retrieves prop picks formatted for bettors. Each pick shows the
+// best price any book currently has on its line, which may not be the book the
+// pick was made from.
 func getBettorPicks(userId int, date time.Time) ([]BettorPickRow, error) {
 	db := storage.GetDB()
 
@@ -417,7 +480,8 @@ func getBettorPicks(userId int, date time.Time) ([]BettorPickRow, error) {
         pl.side,
         pl.line,
         pl.stat,
-        pl.odds,
+        COALESCE(best.odds, pl.odds) as odds,
+        COALESCE(best.bookmaker, pl.bookmaker) as bookmaker,
         COALESCE(npp.points, 0) as points,
         COALESCE(npp.rebounds, 0) as rebounds,
         COALESCE(npp.assists, 0) as assists,
@@ -428,6 +492,18 @@ func getBettorPicks(userId int, date time.Time) ([]BettorPickRow, error) {
     INNER JOIN players p ON p.index = pl.player_index
     LEFT JOIN active_rosters ar ON ar.player_index = pl.player_index AND ar.sport = pl.sport
     LEFT JOIN teams t ON t.index = ar.team_index
+    LEFT JOIN LATERAL (
+        SELECT books.odds, books.bookmaker FROM (
+            SELECT DISTINCT ON (bpl.bookmaker) bpl.odds, bpl.bookmaker
+            FROM player_lines bpl
+            WHERE bpl.sport = pl.sport AND bpl.player_index = pl.player_index AND bpl.stat = pl.stat
+                AND bpl.side = pl.side AND bpl.type = pl.type AND bpl.line = pl.line
+                AND bpl.timestamp >= pp.date AND bpl.timestamp < pp.date + 1
+            ORDER BY bpl.bookmaker, bpl.timestamp DESC
+        ) books
+        ORDER BY books.odds DESC
+        LIMIT 1
+    ) best ON true
     LEFT JOIN LATERAL (
         SELECT points, rebounds, assists, threes
         FROM nba_pip_predictions npp
@@ -498,6 +574,7 @@ func groupBettorPicksByStrategy(rows []BettorPickRow) []BettorStrategyPicks {
 			PlayerName:  row.PlayerName,
 			Team:        team,
 			LineDisplay: formatBettorLineDisplay(row.Side, row.Stat, row.Line, row.Odds),
+			Bookmaker:   row.Bookmaker,
 			Predicted:   predicted,
 		})
 		pickMap[row.StratID] = pPick
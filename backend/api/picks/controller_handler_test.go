@@ -131,3 +131,47 @@ func TestGetBettorPropPicksHandler(t *testing.T) {
 		t.Fatalf("status = %d, want 200", rec3.Code)
 	}
 }
+
+func TestGetGradedPicksHandler(t *testing.T) {
+	var got GradedPicksQuery
+	svc := NewPicksService(PicksServiceDeps{GetGradedPicks: func(query GradedPicksQuery) ([]GradedPick, error) {
+		got = query
+		return nil, nil
+	}})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/prop-picks/graded", svc.GetGradedPicksHandler())
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prop-picks/graded?user_id=1&strat_id=2&start=2026-01-01&end=2026-01-31", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "[]" {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if got.UserId != 1 || got.StratId != 2 || got.StartDate == nil || got.EndDate == nil || got.EndDate.Day() != 31 {
+		t.Fatalf("unexpected query: %+v", got)
+	}
+
+	for _, url := range []string{
+		"/prop-picks/graded?user_id=bad",
+		"/prop-picks/graded?user_id=1&strat_id=bad",
+		"/prop-picks/graded?user_id=1&start=bad",
+		"/prop-picks/graded?user_id=1&end=bad",
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s status = %d, want 400", url, rec.Code)
+		}
+	}
+
+	r2 := gin.New()
+	r2.GET("/prop-picks/graded", NewPicksService(PicksServiceDeps{GetGradedPicks: func(query GradedPicksQuery) ([]GradedPick, error) {
+		return nil, errors.New("db down")
+	}}).GetGradedPicksHandler())
+	rec2 := httptest.NewRecorder()
+	r2.ServeHTTP(rec2, httptest.NewRequest(http.MethodGet, "/prop-picks/graded?user_id=1", nil))
+	if rec2.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec2.Code)
+	}
+}
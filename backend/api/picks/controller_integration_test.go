@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mgordon34/kornet-kover/api/games"
 	"github.com/mgordon34/kornet-kover/api/odds"
 	"github.com/mgordon34/kornet-kover/api/players"
 	"github.com/mgordon34/kornet-kover/api/teams"
@@ -44,7 +45,7 @@ func TestPicksDatabaseFlows(t *testing.T) {
 	}
 	lineID := lines[0].Id
 
-	players.AddPIPPrediction([]players.NBAPIPPrediction{{PlayerIndex: "picksit01", Date: date, Version: players.CurrNBAPIPPredVersion(), NumGames: 5, Minutes: 30, Points: 22, Rebounds: 7, Assists: 5, Threes: 2, Usg: 20, Ortg: 110, Drtg: 107}})
+	players.AddPIPPrediction(sports.NBA, []players.NBAPIPPrediction{{PlayerIndex: "picksit01", Date: date, Version: players.CurrNBAPIPPredVersion(), NumGames: 5, Minutes: 30, Points: 22, Rebounds: 7, Assists: 5, Threes: 2, Usg: 20, Ortg: 110, Drtg: 107}})
 
 	err = players.UpdateRosters([]players.PlayerRoster{{Sport: "nba", PlayerIndex: "picksit01", TeamIndex: "PKH", Status: "Available", AvgMins: 30}})
 	if err != nil {
@@ -85,3 +86,111 @@ func TestPicksDatabaseFlows(t *testing.T) {
 		t.Fatalf("expected no valid picks after invalidation, got %d", len(rowsAfter))
 	}
 }
+
+func TestGradePropPicks(t *testing.T) {
+	storage.UseLocalDBForIntegrationTests(t)
+	storage.InitTables()
+	db := storage.GetDB()
+
+	teams.AddTeams([]teams.Team{{Index: "GRH", Name: "Grade Home"}, {Index: "GRA", Name: "Grade Away"}})
+	players.AddPlayers([]players.Player{
+		{Index: "gradeit01", Sport: "nba", Name: "Grade Played"},
+		{Index: "gradeit02", Sport: "nba", Name: "Grade Inactive"},
+	})
+
+	var userID int
+	err := db.QueryRow(context.Background(), `INSERT INTO users (name, email, password) VALUES ($1, $2, $3) RETURNING id`, "grade-user", "grade-user@example.com", "pw").Scan(&userID)
+	if err != nil {
+		t.Fatalf("insert user error = %v", err)
+	}
+	var stratID int
+	err = db.QueryRow(context.Background(), `INSERT INTO strategies (user_id, name) VALUES ($1, $2) RETURNING id`, userID, "Grade Strategy").Scan(&stratID)
+	if err != nil {
+		t.Fatalf("insert strategy error = %v", err)
+	}
+
+	date := time.Date(2098, 4, 4, 0, 0, 0, 0, time.UTC)
+	gameID, err := games.AddGame(games.Game{Sport: "nba", HomeIndex: "GRH", AwayIndex: "GRA", HomeScore: 100, AwayScore: 90, Date: date})
+	if err != nil {
+		t.Fatalf("AddGame() error = %v", err)
+	}
+	players.AddPlayerGames(sports.NBA, []players.PlayerGame{{PlayerIndex: "gradeit01", Game: gameID, TeamIndex: "GRH", Minutes: 30, Points: 25, Rebounds: 4, Assists: 3, Threes: 2}})
+
+	odds.AddPlayerLines([]odds.PlayerLine{
+		{Sport: "nba", PlayerIndex: "gradeit01", Timestamp: date.Add(time.Hour), Stat: "points", Side: "Over", Type: "mainline", Line: 20.5, Odds: -110, Link: "x"},
+		{Sport: "nba", PlayerIndex: "gradeit02", Timestamp: date.Add(time.Hour), Stat: "points", Side: "Over", Type: "mainline", Line: 10.5, Odds: -110, Link: "x"},
+	})
+	lines, err := odds.GetPlayerLinesForDate(sports.NBA, date, "mainline")
+	if err != nil || len(lines) != 2 {
+		t.Fatalf("GetPlayerLinesForDate() = %d, err=%v", len(lines), err)
+	}
+	var toAdd []PropPick
+	for _, line := range lines {
+		toAdd = append(toAdd, PropPick{StratId: stratID, LineId: line.Id, Valid: true, Date: date})
+	}
+	if err := AddPropPicks(toAdd); err != nil {
+		t.Fatalf("AddPropPicks() error = %v", err)
+	}
+	// the played line moves up after the pick, closing the pick with value
+	odds.AddPlayerLines([]odds.PlayerLine{
+		{Sport: "nba", PlayerIndex: "gradeit01", Timestamp: date.Add(3 * time.Hour), Stat: "points", Side: "Over", Type: "mainline", Line: 21.5, Odds: -125, Link: "x"},
+	})
+
+	if _, err := GradePropPicks(sports.NBA, date); err != nil {
+		t.Fatalf("GradePropPicks() same day error = %v", err)
+	}
+	graded, err := GetGradedPicks(GradedPicksQuery{UserId: userID})
+	if err != nil || len(graded) != 0 {
+		t.Fatalf("picks on the cutoff date should stay ungraded, got %d err=%v", len(graded), err)
+	}
+
+	if _, err := GradePropPicks(sports.NBA, date.AddDate(0, 0, 1)); err != nil {
+		t.Fatalf("GradePropPicks() error = %v", err)
+	}
+	start := date
+	graded, err = GetGradedPicks(GradedPicksQuery{UserId: userID, StratId: stratID, StartDate: &start, EndDate: &start})
+	if err != nil || len(graded) != 2 {
+		t.Fatalf("GetGradedPicks() len=%d err=%v", len(graded), err)
+	}
+	results := map[string]GradedPick{}
+	for _, pick := range graded {
+		results[pick.PlayerName] = pick
+	}
+	if played := results["Grade Played"]; played.Result != ResultWin || played.Actual == nil || *played.Actual != 25 || played.Profit <= 0 {
+		t.Fatalf("unexpected graded win: %+v", played)
+	}
+	if played := results["Grade Played"]; played.CLVLine == nil || *played.CLVLine != 1 || played.CLVProb == nil || *played.CLVProb <= 0 {
+		t.Fatalf("unexpected clv on the moved line: %+v", played)
+	}
+	if inactive := results["Grade Inactive"]; inactive.CLVLine == nil || *inactive.CLVLine != 0 || *inactive.CLVProb != 0 {
+		t.Fatalf("a pick on the closing line should have no clv: %+v", inactive)
+	}
+	if inactive := results["Grade Inactive"]; inactive.Result != ResultVoid || inactive.Actual != nil || inactive.Profit != 0 {
+		t.Fatalf("unexpected graded void: %+v", inactive)
+	}
+
+	stored, err := getPropPick(results["Grade Played"].Id)
+	if err != nil || stored.Result == nil || *stored.Result != ResultWin || stored.Actual == nil || *stored.Actual != 25 {
+		t.Fatalf("getPropPick() on a graded pick got=%+v err=%v", stored, err)
+	}
+	if stored.ClosingLine == nil || *stored.ClosingLine != 21.5 || stored.ClosingOdds == nil || *stored.ClosingOdds != -125 || stored.CLVLine == nil || *stored.CLVLine != 1 || stored.CLVProb == nil {
+		t.Fatalf("getPropPick() should read back the closing line and clv: %+v", stored)
+	}
+
+	byStrat, err := GetGradedPicks(GradedPicksQuery{StratId: stratID})
+	if err != nil || len(byStrat) != 2 {
+		t.Fatalf("GetGradedPicks() by strategy len=%d err=%v", len(byStrat), err)
+	}
+	if report := BuildPerformanceReport(byStrat); report.Summary.Bets != 1 || report.Summary.Voids != 1 {
+		t.Fatalf("unexpected performance summary: %+v", report.Summary)
+	}
+
+	count, err := GradePropPicks(sports.NBA, date.AddDate(0, 0, 1))
+	if err != nil || count != 0 {
+		t.Fatalf("GradePropPicks() should not regrade, count=%d err=%v", count, err)
+	}
+
+	if _, err := GradePropPicks(sports.MLB, date); err == nil {
+		t.Fatalf("expected unsupported sport error")
+	}
+}
@@ -51,7 +51,7 @@ func TestBettorHelpers(t *testing.T) {
 func TestGroupBettorPicksByStrategy(t *testing.T) {
 	team := "DEN"
 	rows := []BettorPickRow{
-		{ID: 2, StratID: 2, StratName: "B", PlayerName: "p2", TeamName: &team, Side: "Over", Stat: "points", Line: 20.5, Odds: -110, Points: 24},
+		{ID: 2, StratID: 2, StratName: "B", PlayerName: "p2", TeamName: &team, Side: "Over", Stat: "points", Line: 20.5, Odds: -110, Bookmaker: "fanduel", Points: 24},
 		{ID: 1, StratID: 1, StratName: "A", PlayerName: "p1", Side: "Under", Stat: "rebounds", Line: 8.5, Odds: -105, Rebounds: 7},
 	}
 
@@ -62,8 +62,8 @@ func TestGroupBettorPicksByStrategy(t *testing.T) {
 	if out[0].StratID != 1 || out[1].StratID != 2 {
 		t.Fatalf("unexpected strat ordering: %+v", out)
 	}
-	if out[1].Picks[0].Team != "DEN" {
-		t.Fatalf("team should be populated")
+	if out[1].Picks[0].Team != "DEN" || out[1].Picks[0].Bookmaker != "fanduel" {
+		t.Fatalf("team and bookmaker should be populated")
 	}
 	if out[0].Picks[0].Predicted != 7 {
 		t.Fatalf("predicted rebounds = %v, want 7", out[0].Picks[0].Predicted)
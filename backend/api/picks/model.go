@@ -8,4 +8,11 @@ type PropPick struct {
     LineId          int         `json:"line_id"`
     Valid           bool        `json:"valid"`
     Date            time.Time   `json:"date"`
+    Actual          *float32    `json:"actual"`
+    Result          *string     `json:"result"`
+    Profit          *float32    `json:"profit"`
+    ClosingLine     *float32    `json:"closing_line"`
+    ClosingOdds     *int        `json:"closing_odds"`
+    CLVLine         *float32    `json:"clv_line"`
+    CLVProb         *float32    `json:"clv_prob"`
 }
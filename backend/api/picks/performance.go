@@ -0,0 +1,162 @@
+package picks
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PerformanceSummary aggregates graded picks staked at one unit each.
+// Pushes count towards the amount staked, voids are left out entirely.
+// Closing line value is averaged over the picks that have a closing line.
+type PerformanceSummary struct {
+	Bets                int     `json:"bets"`
+	Wins                int     `json:"wins"`
+	Losses              int     `json:"losses"`
+	Pushes              int     `json:"pushes"`
+	Voids               int     `json:"voids"`
+	WinRate             float32 `json:"win_rate"`
+	Units               float32 `json:"units"`
+	ROI                 float32 `json:"roi"`
+	MaxDrawdown         float32 `json:"max_drawdown"`
+	LongestLosingStreak int     `json:"longest_losing_streak"`
+	CLVBets             int     `json:"clv_bets"`
+	AvgCLVLine          float32 `json:"avg_clv_line"`
+	AvgCLVProb          float32 `json:"avg_clv_prob"`
+	BeatCloseRate       float32 `json:"beat_close_rate"`
+}
+
+type PerformanceBucket struct {
+	Key string `json:"key"`
+	PerformanceSummary
+}
+
+type PerformanceReport struct {
+	Summary PerformanceSummary  `json:"summary"`
+	ByStat  []PerformanceBucket `json:"by_stat"`
+	BySide  []PerformanceBucket `json:"by_side"`
+	ByOdds  []PerformanceBucket `json:"by_odds"`
+}
+
+// OddsBucketKey groups american odds into the 100 wide brackets used by the
+// backtester breakdown. Favorites fall into the 0 bracket and anything past
+// +1000 into the 1000 bracket.
+func OddsBucketKey(odds int) int {
+	if odds < 0 {
+		return 0
+	}
+	if odds >= 1000 {
+		return 1000
+	}
+	return odds / 100 * 100
+}
+
+// SummarizePicks expects picks in the order they were placed so drawdown and
+// streaks are measured over time
+func SummarizePicks(picks []GradedPick) PerformanceSummary {
+	var summary PerformanceSummary
+	var streak int
+	var peakUnits float32
+	var beatClose int
+
+	for _, pick := range picks {
+		if pick.CLVLine != nil && pick.CLVProb != nil {
+			summary.CLVBets++
+			summary.AvgCLVLine += *pick.CLVLine
+			summary.AvgCLVProb += *pick.CLVProb
+			if *pick.CLVProb > 0 {
+				beatClose++
+			}
+		}
+
+		switch pick.Result {
+		case ResultWin:
+			summary.Wins++
+			streak = 0
+		case ResultLoss:
+			summary.Losses++
+			streak++
+			if streak > summary.LongestLosingStreak {
+				summary.LongestLosingStreak = streak
+			}
+		case ResultPush:
+			summary.Pushes++
+		case ResultVoid:
+			summary.Voids++
+			continue
+		default:
+			continue
+		}
+
+		summary.Bets++
+		summary.Units += pick.Profit
+		if summary.Units > peakUnits {
+			peakUnits = summary.Units
+		}
+		if peakUnits-summary.Units > summary.MaxDrawdown {
+			summary.MaxDrawdown = peakUnits - summary.Units
+		}
+	}
+
+	if summary.Wins+summary.Losses > 0 {
+		summary.WinRate = float32(summary.Wins) / float32(summary.Wins+summary.Losses)
+	}
+	if summary.Bets > 0 {
+		summary.ROI = summary.Units / float32(summary.Bets)
+	}
+	if summary.CLVBets > 0 {
+		summary.AvgCLVLine /= float32(summary.CLVBets)
+		summary.AvgCLVProb /= float32(summary.CLVBets)
+		summary.BeatCloseRate = float32(beatClose) / float32(summary.CLVBets)
+	}
+
+	return summary
+}
+
+func BuildPerformanceReport(picks []GradedPick) PerformanceReport {
+	return PerformanceReport{
+		Summary: SummarizePicks(picks),
+		ByStat:  bucketPicks(picks, func(pick GradedPick) string { return pick.Stat }),
+		BySide:  bucketPicks(picks, func(pick GradedPick) string { return pick.Side }),
+		ByOdds:  bucketOdds(picks),
+	}
+}
+
+func bucketPicks(picks []GradedPick, keyFn func(GradedPick) string) []PerformanceBucket {
+	var keys []string
+	groups := make(map[string][]GradedPick)
+	for _, pick := range picks {
+		key := keyFn(pick)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], pick)
+	}
+
+	sort.Strings(keys)
+	buckets := []PerformanceBucket{}
+	for _, key := range keys {
+		buckets = append(buckets, PerformanceBucket{Key: key, PerformanceSummary: SummarizePicks(groups[key])})
+	}
+
+	return buckets
+}
+
+func bucketOdds(picks []GradedPick) []PerformanceBucket {
+	var keys []int
+	groups := make(map[int][]GradedPick)
+	for _, pick := range picks {
+		key := OddsBucketKey(pick.Odds)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], pick)
+	}
+
+	sort.Ints(keys)
+	buckets := []PerformanceBucket{}
+	for _, key := range keys {
+		buckets = append(buckets, PerformanceBucket{Key: fmt.Sprint(key), PerformanceSummary: SummarizePicks(groups[key])})
+	}
+
+	return buckets
+}
@@ -0,0 +1,89 @@
+package picks
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOddsBucketKey(t *testing.T) {
+	cases := map[int]int{-150: 0, 0: 0, 99: 0, 100: 100, 250: 200, 999: 900, 1000: 1000, 2500: 1000}
+	for odds, want := range cases {
+		if got := OddsBucketKey(odds); got != want {
+			t.Fatalf("OddsBucketKey(%d) = %d, want %d", odds, got, want)
+		}
+	}
+}
+
+func TestSummarizePicks(t *testing.T) {
+	graded := []GradedPick{
+		{Result: ResultWin, Profit: 2},
+		{Result: ResultLoss, Profit: -1},
+		{Result: ResultVoid},
+		{Result: ResultLoss, Profit: -1},
+		{Result: ResultPush},
+		{Result: ResultLoss, Profit: -1},
+		{Result: ResultWin, Profit: 1},
+	}
+
+	summary := SummarizePicks(graded)
+	if summary.Bets != 6 || summary.Wins != 2 || summary.Losses != 3 || summary.Pushes != 1 || summary.Voids != 1 {
+		t.Fatalf("unexpected counts: %+v", summary)
+	}
+	if summary.Units != 0 || summary.ROI != 0 {
+		t.Fatalf("unexpected units/roi: %+v", summary)
+	}
+	if math.Abs(float64(summary.WinRate-.4)) > 1e-6 {
+		t.Fatalf("WinRate = %v", summary.WinRate)
+	}
+	if summary.MaxDrawdown != 3 || summary.LongestLosingStreak != 3 {
+		t.Fatalf("unexpected drawdown/streak: %+v", summary)
+	}
+
+	if empty := SummarizePicks(nil); empty != (PerformanceSummary{}) {
+		t.Fatalf("empty summary = %+v", empty)
+	}
+}
+
+func TestSummarizePicksClosingLineValue(t *testing.T) {
+	value := func(v float32) *float32 { return &v }
+	graded := []GradedPick{
+		{Result: ResultWin, Profit: 1, CLVLine: value(1), CLVProb: value(.04)},
+		{Result: ResultLoss, Profit: -1, CLVLine: value(-.5), CLVProb: value(-.02)},
+		{Result: ResultVoid, CLVLine: value(1.5), CLVProb: value(.01)},
+		{Result: ResultLoss, Profit: -1},
+	}
+
+	summary := SummarizePicks(graded)
+	if summary.CLVBets != 3 || summary.AvgCLVLine != 2.0/3 || math.Abs(float64(summary.AvgCLVProb-.01)) > 1e-6 {
+		t.Fatalf("unexpected clv averages: %+v", summary)
+	}
+	if math.Abs(float64(summary.BeatCloseRate)-2.0/3) > 1e-6 {
+		t.Fatalf("BeatCloseRate = %v", summary.BeatCloseRate)
+	}
+}
+
+func TestBuildPerformanceReport(t *testing.T) {
+	graded := []GradedPick{
+		{Stat: "points", Side: "Over", Odds: -110, Result: ResultWin, Profit: .91},
+		{Stat: "rebounds", Side: "Over", Odds: 250, Result: ResultLoss, Profit: -1},
+		{Stat: "points", Side: "Under", Odds: 1200, Result: ResultWin, Profit: 12},
+	}
+
+	report := BuildPerformanceReport(graded)
+	if report.Summary.Bets != 3 {
+		t.Fatalf("unexpected summary: %+v", report.Summary)
+	}
+	if len(report.ByStat) != 2 || report.ByStat[0].Key != "points" || report.ByStat[0].Wins != 2 {
+		t.Fatalf("unexpected stat buckets: %+v", report.ByStat)
+	}
+	if len(report.BySide) != 2 || report.BySide[0].Key != "Over" || report.BySide[0].Bets != 2 {
+		t.Fatalf("unexpected side buckets: %+v", report.BySide)
+	}
+	if len(report.ByOdds) != 3 || report.ByOdds[0].Key != "0" || report.ByOdds[1].Key != "200" || report.ByOdds[2].Key != "1000" {
+		t.Fatalf("unexpected odds buckets: %+v", report.ByOdds)
+	}
+
+	if empty := BuildPerformanceReport(nil); empty.ByStat == nil || len(empty.ByOdds) != 0 {
+		t.Fatalf("empty report should have empty buckets: %+v", empty)
+	}
+}
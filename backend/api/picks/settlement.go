@@ -0,0 +1,316 @@
+package picks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mgordon34/kornet-kover/api/odds"
+	"github.com/mgordon34/kornet-kover/internal/sports"
+	"github.com/mgordon34/kornet-kover/internal/storage"
+)
+
+const (
+	ResultWin  = "win"
+	ResultLoss = "loss"
+	ResultPush = "push"
+	ResultVoid = "void"
+)
+
+// boxScore is the table a sport's picks are graded against and the column
+// expression holding each prop stat in it
+type boxScore struct {
+	table string
+	stats map[string]string
+}
+
+var basketballStats = map[string]string{
+	"points":   "pg.points",
+	"rebounds": "pg.rebounds",
+	"assists":  "pg.assists",
+	"threes":   "pg.threes",
+}
+
+// playerGamesTables maps a sport to the box score its picks are graded against
+var playerGamesTables = map[sports.Sport]boxScore{
+	sports.NBA:  {table: "nba_player_games", stats: basketballStats},
+	sports.WNBA: {table: "wnba_player_games", stats: basketballStats},
+	sports.NHL: {table: "nhl_player_games", stats: map[string]string{
+		"shots":   "pg.shots",
+		"points":  "pg.points",
+		"goals":   "pg.goals",
+		"assists": "pg.assists",
+		"saves":   "pg.saves",
+	}},
+	sports.NFL: {table: "nfl_player_games", stats: map[string]string{
+		"pass_yards": "pg.pass_yards",
+		"rush_yards": "pg.rush_yards",
+		"receptions": "pg.receptions",
+		"rec_yards":  "pg.rec_yards",
+		"touchdowns": "pg.rush_tds + pg.rec_tds",
+	}},
+}
+
+// actualSQL is the CASE picking a line's stat out of the box score row
+func (b boxScore) actualSQL() string {
+	var sb strings.Builder
+	sb.WriteString("CASE pl.stat")
+	for _, stat := range b.statNames() {
+		fmt.Fprintf(&sb, "\n            WHEN '%s' THEN %s", stat, b.stats[stat])
+	}
+	sb.WriteString("\n        END")
+	return sb.String()
+}
+
+// statsSQL lists the gradable stats for an IN clause
+func (b boxScore) statsSQL() string {
+	var quoted []string
+	for _, stat := range b.statNames() {
+		quoted = append(quoted, fmt.Sprintf("'%s'", stat))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (b boxScore) statNames() []string {
+	names := make([]string, 0, len(b.stats))
+	for stat := range b.stats {
+		names = append(names, stat)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CanGradeSport reports whether picks for the sport can be graded against a
+// box score table
+func CanGradeSport(sport sports.Sport) bool {
+	_, ok := playerGamesTables[sport]
+	return ok
+}
+
+// UngradedPick is a prop pick joined to its line and the player's box score.
+// Actual is nil when the player did not record a game on the pick date.
+// ClosingLine and ClosingOdds are the last line posted on the pick date for
+// the same market, nil when none was recorded.
+type UngradedPick struct {
+	Id          int      `db:"id"`
+	Side        string   `db:"side"`
+	Stat        string   `db:"stat"`
+	Line        float32  `db:"line"`
+	Odds        int      `db:"odds"`
+	Actual      *float32 `db:"actual"`
+	ClosingLine *float32 `db:"closing_line"`
+	ClosingOdds *int     `db:"closing_odds"`
+}
+
+type PickGrade struct {
+	Id          int
+	Actual      *float32
+	Result      string
+	Profit      float32
+	ClosingLine *float32
+	ClosingOdds *int
+	CLV         *odds.LineCLV
+}
+
+// OddsProfit returns the winnings on a stake at the given american odds
+func OddsProfit(stake float32, odds int) float32 {
+	if odds < 0 {
+		return float32((100 / math.Abs(float64(odds))) * float64(stake))
+	}
+	return (float32(odds) / 100) * stake
+}
+
+// GradePick settles a pick for a one unit stake and measures it against the
+// closing line when there is one
+func GradePick(pick UngradedPick) PickGrade {
+	grade := PickGrade{Id: pick.Id, Actual: pick.Actual, ClosingLine: pick.ClosingLine, ClosingOdds: pick.ClosingOdds}
+	if pick.ClosingLine != nil && pick.ClosingOdds != nil {
+		clv := odds.CalculateCLV(pick.Side, pick.Line, pick.Odds, *pick.ClosingLine, *pick.ClosingOdds)
+		grade.CLV = &clv
+	}
+	if pick.Actual == nil {
+		grade.Result = ResultVoid
+		return grade
+	}
+
+	actual := *pick.Actual
+	switch {
+	case actual == pick.Line:
+		grade.Result = ResultPush
+	case strings.EqualFold(pick.Side, "over") && actual > pick.Line,
+		strings.EqualFold(pick.Side, "under") && actual < pick.Line:
+		grade.Result = ResultWin
+		grade.Profit = OddsProfit(1, pick.Odds)
+	default:
+		grade.Result = ResultLoss
+		grade.Profit = -1
+	}
+
+	return grade
+}
+
+// GradePropPicks settles every valid, ungraded pick for the sport dated before
+// the given day, once box scores for that day have been ingested.
+// Returns the number of picks graded.
+func GradePropPicks(sport sports.Sport, before time.Time) (int, error) {
+	picks, err := getUngradedPicks(sport, before)
+	if err != nil {
+		return 0, err
+	}
+	if len(picks) == 0 {
+		return 0, nil
+	}
+
+	var grades []PickGrade
+	for _, pick := range picks {
+		grades = append(grades, GradePick(pick))
+	}
+	if err := updatePickGrades(grades); err != nil {
+		return 0, err
+	}
+	log.Printf("Graded %d %s prop picks", len(grades), sport)
+
+	return len(grades), nil
+}
+
+func getUngradedPicks(sport sports.Sport, before time.Time) ([]UngradedPick, error) {
+	box, ok := playerGamesTables[sport]
+	if !ok {
+		return nil, fmt.Errorf("grading picks is not supported for sport %s", sport)
+	}
+	db := storage.GetDB()
+
+	sql := fmt.Sprintf(`
+    SELECT pp.id, pl.side, pl.stat, pl.line, pl.odds,
+        %s::real as actual,
+        cl.line as closing_line, cl.odds as closing_odds
+    FROM prop_picks pp
+    INNER JOIN player_lines pl ON pl.id = pp.line_id
+    LEFT JOIN LATERAL (
+        SELECT pg.*
+        FROM %s pg
+        INNER JOIN games g ON g.id = pg.game
+        WHERE pg.player_index = pl.player_index AND g.date = pp.date
+        LIMIT 1
+    ) pg ON true
+    LEFT JOIN LATERAL (
+        SELECT cl.line, cl.odds
+        FROM player_lines cl
+        WHERE cl.sport = pl.sport AND cl.player_index = pl.player_index AND cl.stat = pl.stat
+            AND cl.side = pl.side AND cl.type = pl.type
+            AND (pl.type <> 'alternate' OR cl.line = pl.line)
+            AND cl.timestamp >= pp.date AND cl.timestamp < pp.date + 1
+        ORDER BY cl.timestamp DESC, cl.id DESC
+        LIMIT 1
+    ) cl ON true
+    WHERE pp.valid = true
+        AND pp.result IS NULL
+        AND pl.sport = ($1)
+        AND pl.stat IN (%s)
+        AND pp.date < ($2)
+        AND EXISTS (SELECT 1 FROM games g WHERE g.sport = ($1) AND g.date = pp.date)
+    ORDER BY pp.id`, box.actualSQL(), box.table, box.statsSQL())
+
+	rows, err := db.Query(context.Background(), sql, string(sport), before)
+	if err != nil {
+		return nil, fmt.Errorf("error querying ungraded picks: %w", err)
+	}
+	defer rows.Close()
+
+	picks, err := pgx.CollectRows(rows, pgx.RowToStructByName[UngradedPick])
+	if err != nil {
+		return picks, fmt.Errorf("error getting ungraded picks: %w", err)
+	}
+
+	return picks, nil
+}
+
+func updatePickGrades(grades []PickGrade) error {
+	db := storage.GetDB()
+	txn, err := db.Begin(context.Background())
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback(context.Background())
+
+	sql := `
+    UPDATE prop_picks
+    SET actual = ($2), result = ($3), profit = ($4), closing_line = ($5), closing_odds = ($6), clv_line = ($7), clv_prob = ($8)
+    WHERE id = ($1)`
+	for _, grade := range grades {
+		var clvLine, clvProb *float32
+		if grade.CLV != nil {
+			clvLine, clvProb = &grade.CLV.LineMove, &grade.CLV.ProbDelta
+		}
+		if _, err := txn.Exec(context.Background(), sql, grade.Id, grade.Actual, grade.Result, grade.Profit,
+			grade.ClosingLine, grade.ClosingOdds, clvLine, clvProb); err != nil {
+			return fmt.Errorf("error grading prop pick %d: %w", grade.Id, err)
+		}
+	}
+
+	return txn.Commit(context.Background())
+}
+
+type GradedPicksQuery struct {
+	UserId    int
+	StratId   int
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+type GradedPick struct {
+	Id         int       `json:"id" db:"id"`
+	StratId    int       `json:"strat_id" db:"strat_id"`
+	StratName  string    `json:"strat_name" db:"strat_name"`
+	PlayerName string    `json:"player_name" db:"player_name"`
+	Side       string    `json:"side" db:"side"`
+	Stat       string    `json:"stat" db:"stat"`
+	Line       float32   `json:"line" db:"line"`
+	Odds       int       `json:"odds" db:"odds"`
+	Date       time.Time `json:"date" db:"date"`
+	Actual     *float32  `json:"actual" db:"actual"`
+	Result     string    `json:"result" db:"result"`
+	Profit     float32   `json:"profit" db:"profit"`
+	CLVLine    *float32  `json:"clv_line" db:"clv_line"`
+	CLVProb    *float32  `json:"clv_prob" db:"clv_prob"`
+}
+
+// GetGradedPicks returns settled picks in date order, optionally narrowed to a
+// user, a strategy and an inclusive date range
+func GetGradedPicks(query GradedPicksQuery) ([]GradedPick, error) {
+	db := storage.GetDB()
+
+	sql := `
+    SELECT pp.id, s.id as strat_id, s.name as strat_name, p.name as player_name,
+        pl.side, pl.stat, pl.line, pl.odds, pp.date, pp.actual, pp.result, pp.profit,
+        pp.clv_line, pp.clv_prob
+    FROM prop_picks pp
+    INNER JOIN strategies s ON s.id = pp.strat_id
+    INNER JOIN player_lines pl ON pl.id = pp.line_id
+    INNER JOIN players p ON p.index = pl.player_index
+    WHERE pp.result IS NOT NULL
+        AND (($1)::int = 0 OR s.user_id = ($1))
+        AND (($2)::int = 0 OR s.id = ($2))
+        AND (($3)::date IS NULL OR pp.date >= ($3))
+        AND (($4)::date IS NULL OR pp.date <= ($4))
+    ORDER BY pp.date, s.id, pp.id`
+
+	rows, err := db.Query(context.Background(), sql, query.UserId, query.StratId, query.StartDate, query.EndDate)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Error querying graded picks for user %d strat %d: %v", query.UserId, query.StratId, err))
+	}
+	defer rows.Close()
+
+	picks, err := pgx.CollectRows(rows, pgx.RowToStructByName[GradedPick])
+	if err != nil {
+		return picks, errors.New(fmt.Sprintf("Error getting graded picks for user %d strat %d: %v", query.UserId, query.StratId, err))
+	}
+
+	return picks, nil
+}
@@ -0,0 +1,80 @@
+package picks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
+
+func TestGradePick(t *testing.T) {
+	value := func(v float32) *float32 { return &v }
+
+	cases := []struct {
+		name   string
+		pick   UngradedPick
+		result string
+		profit float32
+	}{
+		{"over win", UngradedPick{Side: "Over", Line: 20.5, Odds: -110, Actual: value(25)}, ResultWin, 100.0 / 110.0},
+		{"over loss", UngradedPick{Side: "Over", Line: 20.5, Odds: -110, Actual: value(18)}, ResultLoss, -1},
+		{"under win plus odds", UngradedPick{Side: "Under", Line: 8.5, Odds: 150, Actual: value(6)}, ResultWin, 1.5},
+		{"under loss", UngradedPick{Side: "Under", Line: 8.5, Odds: 150, Actual: value(9)}, ResultLoss, -1},
+		{"push", UngradedPick{Side: "Over", Line: 20, Odds: -110, Actual: value(20)}, ResultPush, 0},
+		{"did not play", UngradedPick{Side: "Over", Line: 20.5, Odds: -110}, ResultVoid, 0},
+	}
+	for _, tc := range cases {
+		grade := GradePick(tc.pick)
+		if grade.Result != tc.result || grade.Profit != tc.profit {
+			t.Fatalf("%s: got %s %.4f, want %s %.4f", tc.name, grade.Result, grade.Profit, tc.result, tc.profit)
+		}
+	}
+}
+
+func TestOddsProfit(t *testing.T) {
+	if got := OddsProfit(100, -200); got != 50 {
+		t.Fatalf("OddsProfit(100, -200) = %v", got)
+	}
+	if got := OddsProfit(100, 250); got != 250 {
+		t.Fatalf("OddsProfit(100, 250) = %v", got)
+	}
+}
+
+func TestGradePickClosingLineValue(t *testing.T) {
+	value := func(v float32) *float32 { return &v }
+	price := func(v int) *int { return &v }
+
+	grade := GradePick(UngradedPick{Side: "Over", Line: 20.5, Odds: 100, Actual: value(18), ClosingLine: value(21.5), ClosingOdds: price(-120)})
+	if grade.Result != ResultLoss || grade.CLV == nil || grade.CLV.LineMove != 1 || grade.CLV.ProbDelta <= 0 {
+		t.Fatalf("unexpected graded clv %+v %+v", grade, grade.CLV)
+	}
+	if *grade.ClosingLine != 21.5 || *grade.ClosingOdds != -120 {
+		t.Fatalf("closing line not kept on grade: %+v", grade)
+	}
+
+	if grade := GradePick(UngradedPick{Side: "Under", Line: 8.5, Odds: 150, Actual: value(6)}); grade.CLV != nil {
+		t.Fatalf("expected no clv without a closing line, got %+v", grade.CLV)
+	}
+}
+
+func TestBoxScoreStatSQL(t *testing.T) {
+	for _, sport := range []sports.Sport{sports.NBA, sports.WNBA, sports.NHL, sports.NFL} {
+		if !CanGradeSport(sport) {
+			t.Fatalf("expected %s picks to be gradable", sport)
+		}
+	}
+	if CanGradeSport(sports.MLB) {
+		t.Fatalf("mlb picks have no box score to grade against")
+	}
+
+	nhl := playerGamesTables[sports.NHL]
+	if got := nhl.statsSQL(); got != "'assists', 'goals', 'points', 'saves', 'shots'" {
+		t.Fatalf("nhl statsSQL() = %s", got)
+	}
+	if got := nhl.actualSQL(); !strings.Contains(got, "WHEN 'shots' THEN pg.shots") || !strings.HasPrefix(got, "CASE pl.stat") || !strings.HasSuffix(got, "END") {
+		t.Fatalf("nhl actualSQL() = %s", got)
+	}
+	if got := playerGamesTables[sports.NFL].actualSQL(); !strings.Contains(got, "WHEN 'touchdowns' THEN pg.rush_tds + pg.rec_tds") {
+		t.Fatalf("nfl actualSQL() = %s", got)
+	}
+}
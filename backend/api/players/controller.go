@@ -66,7 +66,7 @@ func GetPlayer(index string) (Player, error) {
 
 	rows, err := db.Query(context.Background(), sql, index)
 	if err != nil {
-		log.Fatal("Error querying for player index: ", err)
+		return Player{}, fmt.Errorf("error querying for player %v: %w", index, err)
 	}
 	defer rows.Close()
 
@@ -121,14 +121,53 @@ func PlayerNameToIndex(nameMap map[string]string, playerName string) (string, er
 	return index, nil
 }
 
-func AddPlayerGames(pGames []PlayerGame) {
+// basketballTables are where a basketball league's box scores and
+// predictions are kept. The leagues share a schema
+type basketballTables struct {
+	games       string
+	predictions string
+}
+
+var basketballLeagues = map[sports.Sport]basketballTables{
+	sports.NBA:  {games: "nba_player_games", predictions: "nba_pip_predictions"},
+	sports.WNBA: {games: "wnba_player_games", predictions: "wnba_pip_predictions"},
+}
+
+func basketballTablesFor(sport sports.Sport) (basketballTables, error) {
+	tables, ok := basketballLeagues[sport]
+	if !ok {
+		return basketballTables{}, fmt.Errorf("basketball stats are not supported for sport %s", sport)
+	}
+	return tables, nil
+}
+
+// SeasonYear is the season a date falls in. NBA and NHL seasons are named for
+// the year they end in and NFL seasons for the year they start in, the other
+// leagues play within a calendar year
+func SeasonYear(sport sports.Sport, date time.Time) int {
+	if sport == sports.NBA || sport == sports.NHL {
+		return utils.DateToNBAYear(date)
+	}
+	if sport == sports.NFL && date.Month() < time.August {
+		return date.Year() - 1
+	}
+	return date.Year()
+}
+
+// AddPlayerGames stores box scores for a basketball league
+func AddPlayerGames(sport sports.Sport, pGames []PlayerGame) {
+	tables, err := basketballTablesFor(sport)
+	if err != nil {
+		log.Printf("Skipping player games: %v", err)
+		return
+	}
 	db := storage.GetDB()
 	txn, _ := db.Begin(context.Background())
-	_, err := txn.Exec(
+	_, err = txn.Exec(
 		context.Background(),
 		`CREATE TEMP TABLE player_games_temp
         ON COMMIT DROP
-        AS SELECT * FROM nba_player_games
+        AS SELECT * FROM `+tables.games+`
         WITH NO DATA`,
 	)
 	if err != nil {
@@ -178,7 +217,7 @@ func AddPlayerGames(pGames []PlayerGame) {
 
 	_, err = txn.Exec(
 		context.Background(),
-		` INSERT INTO nba_player_games (player_index, game, team_index, minutes, points, rebounds, assists, threes, usg, ortg, drtg)
+		` INSERT INTO `+tables.games+` (player_index, game, team_index, minutes, points, rebounds, assists, threes, usg, ortg, drtg)
         SELECT player_index, game, team_index, minutes, points, rebounds, assists, threes, usg, ortg, drtg FROM player_games_temp
         ON CONFLICT DO NOTHING`,
 	)
@@ -353,6 +392,168 @@ func AddMLBPlayerGamesPitching(pGames []MLBPlayerGamePitching) {
 
 }
 
+// AddNHLPlayerGames stores skater and goalie box scores
+func AddNHLPlayerGames(pGames []NHLPlayerGame) error {
+	db := storage.GetDB()
+	txn, err := db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("error starting NHL player games transaction: %w", err)
+	}
+	defer txn.Rollback(context.Background())
+
+	_, err = txn.Exec(
+		context.Background(),
+		`CREATE TEMP TABLE nhl_player_games_temp
+        ON COMMIT DROP
+        AS SELECT * FROM nhl_player_games
+        WITH NO DATA`,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating NHL player games temp table: %w", err)
+	}
+	var playersInterface [][]interface{}
+	for _, pGame := range pGames {
+		playersInterface = append(
+			playersInterface,
+			[]interface{}{
+				pGame.PlayerIndex,
+				pGame.Game,
+				pGame.TeamIndex,
+				pGame.TimeOnIce,
+				pGame.Goals,
+				pGame.Assists,
+				pGame.Points,
+				pGame.Shots,
+				pGame.PlusMinus,
+				pGame.PenaltyMins,
+				pGame.ShotsAgainst,
+				pGame.Saves,
+				pGame.GoalsAgainst,
+			},
+		)
+	}
+
+	_, err = txn.CopyFrom(
+		context.Background(),
+		pgx.Identifier{"nhl_player_games_temp"},
+		[]string{
+			"player_index",
+			"game",
+			"team_index",
+			"time_on_ice",
+			"goals",
+			"assists",
+			"points",
+			"shots",
+			"plus_minus",
+			"pen_min",
+			"shots_against",
+			"saves",
+			"goals_against",
+		},
+		pgx.CopyFromRows(playersInterface),
+	)
+	if err != nil {
+		return fmt.Errorf("error copying NHL player games: %w", err)
+	}
+
+	_, err = txn.Exec(
+		context.Background(),
+		` INSERT INTO nhl_player_games (player_index, game, team_index, time_on_ice, goals, assists, points, shots, plus_minus, pen_min, shots_against, saves, goals_against)
+        SELECT player_index, game, team_index, time_on_ice, goals, assists, points, shots, plus_minus, pen_min, shots_against, saves, goals_against FROM nhl_player_games_temp
+        ON CONFLICT DO NOTHING`,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting NHL player games: %w", err)
+	}
+
+	return txn.Commit(context.Background())
+}
+
+// AddNFLPlayerGames stores offensive box scores
+func AddNFLPlayerGames(pGames []NFLPlayerGame) error {
+	db := storage.GetDB()
+	txn, err := db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("error starting NFL player games transaction: %w", err)
+	}
+	defer txn.Rollback(context.Background())
+
+	_, err = txn.Exec(
+		context.Background(),
+		`CREATE TEMP TABLE nfl_player_games_temp
+        ON COMMIT DROP
+        AS SELECT * FROM nfl_player_games
+        WITH NO DATA`,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating NFL player games temp table: %w", err)
+	}
+	var playersInterface [][]interface{}
+	for _, pGame := range pGames {
+		playersInterface = append(
+			playersInterface,
+			[]interface{}{
+				pGame.PlayerIndex,
+				pGame.Game,
+				pGame.TeamIndex,
+				pGame.Snaps,
+				pGame.PassCompletions,
+				pGame.PassAttempts,
+				pGame.PassYards,
+				pGame.PassTDs,
+				pGame.Interceptions,
+				pGame.RushAttempts,
+				pGame.RushYards,
+				pGame.RushTDs,
+				pGame.Targets,
+				pGame.Receptions,
+				pGame.RecYards,
+				pGame.RecTDs,
+			},
+		)
+	}
+
+	_, err = txn.CopyFrom(
+		context.Background(),
+		pgx.Identifier{"nfl_player_games_temp"},
+		[]string{
+			"player_index",
+			"game",
+			"team_index",
+			"snaps",
+			"pass_completions",
+			"pass_attempts",
+			"pass_yards",
+			"pass_tds",
+			"interceptions",
+			"rush_attempts",
+			"rush_yards",
+			"rush_tds",
+			"targets",
+			"receptions",
+			"rec_yards",
+			"rec_tds",
+		},
+		pgx.CopyFromRows(playersInterface),
+	)
+	if err != nil {
+		return fmt.Errorf("error copying NFL player games: %w", err)
+	}
+
+	_, err = txn.Exec(
+		context.Background(),
+		` INSERT INTO nfl_player_games (player_index, game, team_index, snaps, pass_completions, pass_attempts, pass_yards, pass_tds, interceptions, rush_attempts, rush_yards, rush_tds, targets, receptions, rec_yards, rec_tds)
+        SELECT player_index, game, team_index, snaps, pass_completions, pass_attempts, pass_yards, pass_tds, interceptions, rush_attempts, rush_yards, rush_tds, targets, receptions, rec_yards, rec_tds FROM nfl_player_games_temp
+        ON CONFLICT DO NOTHING`,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting NFL player games: %w", err)
+	}
+
+	return txn.Commit(context.Background())
+}
+
 func AddMLBPlayByPlays(pbp []MLBPlayByPlay) {
 	db := storage.GetDB()
 	txn, _ := db.Begin(context.Background())
@@ -425,16 +626,20 @@ func AddMLBPlayByPlays(pbp []MLBPlayByPlay) {
 	}
 }
 
-func GetPlayerStats(player string, startDate time.Time, endDate time.Time) (PlayerAvg, error) {
+func GetPlayerStats(sport sports.Sport, player string, startDate time.Time, endDate time.Time) (PlayerAvg, error) {
+	tables, err := basketballTablesFor(sport)
+	if err != nil {
+		return NBAAvg{}, err
+	}
 	db := storage.GetDB()
 	sql := `SELECT count(*) as num_games, avg(minutes) as minutes, avg(points) as points, avg(rebounds) as rebounds, 
-            avg(assists) as assists, avg(threes) as threes, avg(usg) as usg, avg(ortg) as ortg, avg(drtg) as drtg FROM nba_player_games
-                left join games on games.id = nba_player_games.game
-                where nba_player_games.player_index = ($1) and nba_player_games.minutes > 10 and games.date between ($2) and ($3)`
+            avg(assists) as assists, avg(threes) as threes, avg(usg) as usg, avg(ortg) as ortg, avg(drtg) as drtg FROM ` + tables.games + `
+                left join games on games.id = ` + tables.games + `.game
+                where ` + tables.games + `.player_index = ($1) and ` + tables.games + `.minutes > 10 and games.date between ($2) and ($3)`
 
 	rows, err := db.Query(context.Background(), sql, player, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
 	if err != nil {
-		log.Fatal("Error querying for player stats: ", err)
+		return NBAAvg{}, fmt.Errorf("error querying stats for %v: %w", player, err)
 	}
 	defer rows.Close()
 
@@ -446,6 +651,94 @@ func GetPlayerStats(player string, startDate time.Time, endDate time.Time) (Play
 	return stats, nil
 }
 
+type nbaStatMoments struct {
+	NumGames    int     `db:"num_games"`
+	Points      float32 `db:"points"`
+	PointsVar   float32 `db:"points_var"`
+	Rebounds    float32 `db:"rebounds"`
+	ReboundsVar float32 `db:"rebounds_var"`
+	Assists     float32 `db:"assists"`
+	AssistsVar  float32 `db:"assists_var"`
+	Threes      float32 `db:"threes"`
+	ThreesVar   float32 `db:"threes_var"`
+}
+
+// GetPlayerStatMoments returns the mean and variance of each counting stat
+// over the player's games in the window, using the same games as
+// GetPlayerStats
+func GetPlayerStatMoments(sport sports.Sport, player string, startDate time.Time, endDate time.Time) (map[string]StatMoments, error) {
+	tables, err := basketballTablesFor(sport)
+	if err != nil {
+		return nil, err
+	}
+	db := storage.GetDB()
+	sql := `SELECT count(*) as num_games,
+            coalesce(avg(points), 0) as points, coalesce(var_samp(points), 0) as points_var,
+            coalesce(avg(rebounds), 0) as rebounds, coalesce(var_samp(rebounds), 0) as rebounds_var,
+            coalesce(avg(assists), 0) as assists, coalesce(var_samp(assists), 0) as assists_var,
+            coalesce(avg(threes), 0) as threes, coalesce(var_samp(threes), 0) as threes_var FROM ` + tables.games + `
+                left join games on games.id = ` + tables.games + `.game
+                where ` + tables.games + `.player_index = ($1) and ` + tables.games + `.minutes > 10 and games.date between ($2) and ($3)`
+
+	rows, err := db.Query(context.Background(), sql, player, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("error querying stat moments for %v: %w", player, err)
+	}
+	defer rows.Close()
+
+	m, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[nbaStatMoments])
+	if err != nil {
+		return nil, fmt.Errorf("error getting stat moments for %v: %w", player, err)
+	}
+
+	return map[string]StatMoments{
+		"points":   {NumGames: m.NumGames, Mean: m.Points, Variance: m.PointsVar},
+		"rebounds": {NumGames: m.NumGames, Mean: m.Rebounds, Variance: m.ReboundsVar},
+		"assists":  {NumGames: m.NumGames, Mean: m.Assists, Variance: m.AssistsVar},
+		"threes":   {NumGames: m.NumGames, Mean: m.Threes, Variance: m.ThreesVar},
+	}, nil
+}
+
+type mlbStatMoments struct {
+	NumGames    int     `db:"num_games"`
+	Hits        float32 `db:"hits"`
+	HitsVar     float32 `db:"hits_var"`
+	HomeRuns    float32 `db:"home_runs"`
+	HomeRunsVar float32 `db:"home_runs_var"`
+	RBIs        float32 `db:"rbis"`
+	RBIsVar     float32 `db:"rbis_var"`
+}
+
+// GetMLBPlayerStatMoments returns the mean and variance of the batting stats
+// with prop markets over the batter's games in the window, using the same
+// games as GetMLBStats
+func GetMLBPlayerStatMoments(player string, startDate time.Time, endDate time.Time) (map[string]StatMoments, error) {
+	db := storage.GetDB()
+	sql := `SELECT count(*) as num_games,
+            coalesce(avg(hits), 0) as hits, coalesce(var_samp(hits), 0) as hits_var,
+            coalesce(avg(home_runs), 0) as home_runs, coalesce(var_samp(home_runs), 0) as home_runs_var,
+            coalesce(avg(rbis), 0) as rbis, coalesce(var_samp(rbis), 0) as rbis_var FROM mlb_player_games_batting
+                left join games on games.id = mlb_player_games_batting.game
+                where mlb_player_games_batting.player_index = ($1) and games.date between ($2) and ($3)`
+
+	rows, err := db.Query(context.Background(), sql, player, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("error querying stat moments for %v: %w", player, err)
+	}
+	defer rows.Close()
+
+	m, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[mlbStatMoments])
+	if err != nil {
+		return nil, fmt.Errorf("error getting stat moments for %v: %w", player, err)
+	}
+
+	return map[string]StatMoments{
+		"hits":      {NumGames: m.NumGames, Mean: m.Hits, Variance: m.HitsVar},
+		"home_runs": {NumGames: m.NumGames, Mean: m.HomeRuns, Variance: m.HomeRunsVar},
+		"rbis":      {NumGames: m.NumGames, Mean: m.RBIs, Variance: m.RBIsVar},
+	}, nil
+}
+
 func GetMLBStats(player string, startDate time.Time, endDate time.Time) (MLBBattingAvg, error) {
 	db := storage.GetDB()
 	sql := `SELECT count(*) as num_games, avg(at_bats) as at_bats, avg(runs) as runs, avg(hits) as hits, avg(rbis) as rbis, avg(home_runs) as home_runs, avg(walks) as walks, avg(strikeouts) as strikeouts, avg(pas) as pas, avg(pitches) as pitches, avg(strikes) as strikes, avg(ba) as ba, avg(obp) as obp, avg(slg) as slg, avg(ops) as ops, avg(wpa) as wpa FROM mlb_player_games_batting
@@ -454,7 +747,7 @@ func GetMLBStats(player string, startDate time.Time, endDate time.Time) (MLBBatt
 
 	rows, err := db.Query(context.Background(), sql, player, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
 	if err != nil {
-		log.Fatal("Error querying for player stats: ", err)
+		return MLBBattingAvg{}, fmt.Errorf("error querying MLB stats for %v: %w", player, err)
 	}
 	defer rows.Close()
 
@@ -466,6 +759,141 @@ func GetMLBStats(player string, startDate time.Time, endDate time.Time) (MLBBatt
 	return stats, nil
 }
 
+func GetNHLStats(player string, startDate time.Time, endDate time.Time) (NHLAvg, error) {
+	db := storage.GetDB()
+	sql := `SELECT count(*) as num_games, avg(time_on_ice) as time_on_ice, avg(goals) as goals, avg(assists) as assists, avg(points) as points,
+            avg(shots) as shots, avg(shots_against) as shots_against, avg(saves) as saves, avg(goals_against) as goals_against FROM nhl_player_games
+                left join games on games.id = nhl_player_games.game
+                where nhl_player_games.player_index = ($1) and games.date between ($2) and ($3)`
+
+	rows, err := db.Query(context.Background(), sql, player, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
+	if err != nil {
+		return NHLAvg{}, fmt.Errorf("error querying NHL stats for %v: %w", player, err)
+	}
+	defer rows.Close()
+
+	stats, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[NHLAvg])
+	if err != nil {
+		return NHLAvg{}, err
+	}
+
+	return stats, nil
+}
+
+type nhlStatMoments struct {
+	NumGames  int     `db:"num_games"`
+	Shots     float32 `db:"shots"`
+	ShotsVar  float32 `db:"shots_var"`
+	Points    float32 `db:"points"`
+	PointsVar float32 `db:"points_var"`
+	Saves     float32 `db:"saves"`
+	SavesVar  float32 `db:"saves_var"`
+}
+
+// GetNHLPlayerStatMoments returns the mean and variance of the stats with
+// prop markets over the player's games in the window, using the same games
+// as GetNHLStats
+func GetNHLPlayerStatMoments(player string, startDate time.Time, endDate time.Time) (map[string]StatMoments, error) {
+	db := storage.GetDB()
+	sql := `SELECT count(*) as num_games,
+            coalesce(avg(shots), 0) as shots, coalesce(var_samp(shots), 0) as shots_var,
+            coalesce(avg(points), 0) as points, coalesce(var_samp(points), 0) as points_var,
+            coalesce(avg(saves), 0) as saves, coalesce(var_samp(saves), 0) as saves_var FROM nhl_player_games
+                left join games on games.id = nhl_player_games.game
+                where nhl_player_games.player_index = ($1) and games.date between ($2) and ($3)`
+
+	rows, err := db.Query(context.Background(), sql, player, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("error querying stat moments for %v: %w", player, err)
+	}
+	defer rows.Close()
+
+	m, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[nhlStatMoments])
+	if err != nil {
+		return nil, fmt.Errorf("error getting stat moments for %v: %w", player, err)
+	}
+
+	return map[string]StatMoments{
+		"shots":  {NumGames: m.NumGames, Mean: m.Shots, Variance: m.ShotsVar},
+		"points": {NumGames: m.NumGames, Mean: m.Points, Variance: m.PointsVar},
+		"saves":  {NumGames: m.NumGames, Mean: m.Saves, Variance: m.SavesVar},
+	}, nil
+}
+
+// nflAvgColumns averages nfl_player_games into an NFLAvg. Touchdowns are the
+// ones the player scored, so passing touchdowns aren't counted.
+const nflAvgColumns = `count(*) as num_games, avg(snaps) as snaps, avg(pass_attempts) as pass_attempts, avg(pass_completions) as pass_completions,
+            avg(pass_yards) as pass_yards, avg(pass_tds) as pass_tds, avg(rush_attempts) as rush_attempts, avg(rush_yards) as rush_yards,
+            avg(targets) as targets, avg(receptions) as receptions, avg(rec_yards) as rec_yards, avg(rush_tds + rec_tds) as touchdowns`
+
+func GetNFLStats(player string, startDate time.Time, endDate time.Time) (NFLAvg, error) {
+	db := storage.GetDB()
+	sql := `SELECT ` + nflAvgColumns + ` FROM nfl_player_games
+                left join games on games.id = nfl_player_games.game
+                where nfl_player_games.player_index = ($1) and games.date between ($2) and ($3)`
+
+	rows, err := db.Query(context.Background(), sql, player, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
+	if err != nil {
+		return NFLAvg{}, fmt.Errorf("error querying NFL stats for %v: %w", player, err)
+	}
+	defer rows.Close()
+
+	stats, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[NFLAvg])
+	if err != nil {
+		return NFLAvg{}, err
+	}
+
+	return stats, nil
+}
+
+type nflStatMoments struct {
+	NumGames      int     `db:"num_games"`
+	PassYards     float32 `db:"pass_yards"`
+	PassYardsVar  float32 `db:"pass_yards_var"`
+	RushYards     float32 `db:"rush_yards"`
+	RushYardsVar  float32 `db:"rush_yards_var"`
+	Receptions    float32 `db:"receptions"`
+	ReceptionsVar float32 `db:"receptions_var"`
+	RecYards      float32 `db:"rec_yards"`
+	RecYardsVar   float32 `db:"rec_yards_var"`
+	Touchdowns    float32 `db:"touchdowns"`
+	TouchdownsVar float32 `db:"touchdowns_var"`
+}
+
+// GetNFLPlayerStatMoments returns the mean and variance of the stats with
+// prop markets over the player's games in the window, using the same games
+// as GetNFLStats
+func GetNFLPlayerStatMoments(player string, startDate time.Time, endDate time.Time) (map[string]StatMoments, error) {
+	db := storage.GetDB()
+	sql := `SELECT count(*) as num_games,
+            coalesce(avg(pass_yards), 0) as pass_yards, coalesce(var_samp(pass_yards), 0) as pass_yards_var,
+            coalesce(avg(rush_yards), 0) as rush_yards, coalesce(var_samp(rush_yards), 0) as rush_yards_var,
+            coalesce(avg(receptions), 0) as receptions, coalesce(var_samp(receptions), 0) as receptions_var,
+            coalesce(avg(rec_yards), 0) as rec_yards, coalesce(var_samp(rec_yards), 0) as rec_yards_var,
+            coalesce(avg(rush_tds + rec_tds), 0) as touchdowns, coalesce(var_samp(rush_tds + rec_tds), 0) as touchdowns_var FROM nfl_player_games
+                left join games on games.id = nfl_player_games.game
+                where nfl_player_games.player_index = ($1) and games.date between ($2) and ($3)`
+
+	rows, err := db.Query(context.Background(), sql, player, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("error querying stat moments for %v: %w", player, err)
+	}
+	defer rows.Close()
+
+	m, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[nflStatMoments])
+	if err != nil {
+		return nil, fmt.Errorf("error getting stat moments for %v: %w", player, err)
+	}
+
+	return map[string]StatMoments{
+		"pass_yards": {NumGames: m.NumGames, Mean: m.PassYards, Variance: m.PassYardsVar},
+		"rush_yards": {NumGames: m.NumGames, Mean: m.RushYards, Variance: m.RushYardsVar},
+		"receptions": {NumGames: m.NumGames, Mean: m.Receptions, Variance: m.ReceptionsVar},
+		"rec_yards":  {NumGames: m.NumGames, Mean: m.RecYards, Variance: m.RecYardsVar},
+		"touchdowns": {NumGames: m.NumGames, Mean: m.Touchdowns, Variance: m.TouchdownsVar},
+	}, nil
+}
+
 type Relationship int
 
 const (
@@ -473,7 +901,7 @@ const (
 	Opponent
 )
 
-func GetPlayersForGame(gameId int, homeIndex string, playerGameTable string, sortString string) (map[string][]Player, error) {
+func GetPlayersForGame(ctx context.Context, gameId int, homeIndex string, playerGameTable string, sortString string) (map[string][]Player, error) {
 	playerMap := make(map[string][]Player)
 	db := storage.GetDB()
 	sql := `SELECT pl.index, pl.name, pg.team_index FROM players pl
@@ -481,9 +909,9 @@ func GetPlayersForGame(gameId int, homeIndex string, playerGameTable string, sor
                  LEFT JOIN games gg ON gg.id=pg.game
                  WHERE gg.id=($1)
                  ORDER BY pg.` + sortString + ` DESC`
-	rows, err := db.Query(context.Background(), sql, gameId)
+	rows, err := db.Query(ctx, sql, gameId)
 	if err != nil {
-		log.Fatal("Error querying for player games: ", err)
+		return playerMap, fmt.Errorf("error querying players for game %d: %w", gameId, err)
 	}
 	defer rows.Close()
 	for rows.Next() {
@@ -491,7 +919,7 @@ func GetPlayersForGame(gameId int, homeIndex string, playerGameTable string, sor
 		var teamIndex string
 		err = rows.Scan(&player.Index, &player.Name, &teamIndex)
 		if err != nil {
-			log.Fatal("Error converting rows to playerLines: ", err)
+			return playerMap, fmt.Errorf("error scanning players for game %d: %w", gameId, err)
 		}
 
 		if teamIndex == homeIndex {
@@ -514,14 +942,14 @@ func GetMLBPlayersMissingHandedness() ([]Player, error) {
 			NOT (pl.details ? 'batting_handedness' AND pl.details ? 'pitching_handedness'))`
 	rows, err := db.Query(context.Background(), sql)
 	if err != nil {
-		log.Fatal("Error querying for no handedness players: ", err)
+		return playerSlice, fmt.Errorf("error querying for players missing handedness: %w", err)
 	}
 	defer rows.Close()
 	for rows.Next() {
 		var player Player
 		err = rows.Scan(&player.Index, &player.Name, &player.Details)
 		if err != nil {
-			log.Fatal("Error converting rows to player: ", err)
+			return playerSlice, fmt.Errorf("error scanning players missing handedness: %w", err)
 		}
 		playerSlice = append(playerSlice, player)
 	}
@@ -549,19 +977,23 @@ type PlayerStatInfo struct {
 	NBAAvg
 }
 
-func GetPlayerStatsForGames(gameIds []string) (map[string]PlayerAvg, error) {
+func GetPlayerStatsForGames(ctx context.Context, sport sports.Sport, gameIds []string) (map[string]PlayerAvg, error) {
 	playerMap := make(map[string]PlayerAvg)
+	tables, err := basketballTablesFor(sport)
+	if err != nil {
+		return playerMap, err
+	}
 	db := storage.GetDB()
-	sql := `SELECT player_index, 1 as num_games, minutes, points, rebounds, assists, threes, usg, ortg, drtg FROM nba_player_games
-                left join games gg on gg.id = nba_player_games.game
+	sql := `SELECT player_index, 1 as num_games, minutes, points, rebounds, assists, threes, usg, ortg, drtg FROM ` + tables.games + `
+                left join games gg on gg.id = ` + tables.games + `.game
                 where gg.id IN (%s)`
 
 	param := strings.Join(gameIds, ",")
 	sql = fmt.Sprintf(sql, param)
 
-	rows, err := db.Query(context.Background(), sql)
+	rows, err := db.Query(ctx, sql)
 	if err != nil {
-		log.Fatal("Error querying for player stats: ", err)
+		return playerMap, fmt.Errorf("error querying stats for games: %w", err)
 	}
 	defer rows.Close()
 
@@ -582,7 +1014,7 @@ type MLBPlayerStatInfo struct {
 	MLBBattingAvg
 }
 
-func GetMLBBattingStatsForGames(gameIds []string) (map[string]MLBBattingAvg, error) {
+func GetMLBBattingStatsForGames(ctx context.Context, gameIds []string) (map[string]MLBBattingAvg, error) {
 	playerMap := make(map[string]MLBBattingAvg)
 	db := storage.GetDB()
 	sql := `SELECT player_index, 1 as num_games, at_bats, runs, hits, rbis, home_runs, walks, strikeouts, pas, pitches, strikes, ba, obp, slg, ops, wpa FROM mlb_player_games_batting
@@ -592,9 +1024,9 @@ func GetMLBBattingStatsForGames(gameIds []string) (map[string]MLBBattingAvg, err
 	param := strings.Join(gameIds, ",")
 	sql = fmt.Sprintf(sql, param)
 
-	rows, err := db.Query(context.Background(), sql)
+	rows, err := db.Query(ctx, sql)
 	if err != nil {
-		log.Fatal("Error querying for player stats: ", err)
+		return playerMap, fmt.Errorf("error querying MLB stats for games: %w", err)
 	}
 	defer rows.Close()
 
@@ -610,85 +1042,207 @@ func GetMLBBattingStatsForGames(gameIds []string) (map[string]MLBBattingAvg, err
 	return playerMap, nil
 }
 
-func GetPlayerPerByYear(sport sports.Sport, player string, startDate time.Time, endDate time.Time) map[int]PlayerAvg {
-	playerStats := make(map[int]PlayerAvg)
-
-	for d := startDate; !d.After(endDate); d = d.AddDate(1, 0, 0) {
-		useDate := d.AddDate(1, 0, 0)
-		if useDate.After(endDate) {
-			useDate = endDate
-		}
-
-		switch sport {
-		case sports.NBA:
-			yearlyStats, _ := GetPlayerStats(player, d, useDate)
-			if yearlyStats.IsValid() {
-				playerStats[utils.DateToNBAYear(d)] = yearlyStats.ConvertToPer()
-			}
-		case sports.MLB:
-			yearlyStats, _ := GetMLBStats(player, d, useDate)
-			if yearlyStats.IsValid() {
-				playerStats[d.Year()] = yearlyStats.ConvertToPer()
-			}
-		}
-	}
-
-	return playerStats
+type NHLPlayerStatInfo struct {
+	PlayerIndex string `json:"player_index"`
+	NHLAvg
 }
 
-func GetMLBPlayerStatsWithPlayer(player string, defender string, startDate time.Time, endDate time.Time) (MLBBattingAvg, error) {
+func GetNHLStatsForGames(ctx context.Context, gameIds []string) (map[string]NHLAvg, error) {
+	playerMap := make(map[string]NHLAvg)
 	db := storage.GetDB()
-	sql := `SELECT 
-            COUNT(*) FILTER (WHERE result != 'Not Completed') as pas,
-            COUNT(*) FILTER (WHERE result = 'HR') as home_runs,
-            COUNT(*) FILTER (WHERE result IN ('1B','2B','3B','HR')) as hits,
-            COUNT(*) FILTER (WHERE result = 'SO') as strikeouts
-            FROM mlb_play_by_plays
-                left join games gg on gg.id = mlb_play_by_plays.game
-                where mlb_play_by_plays.batter_index = ($1) and mlb_play_by_plays.pitcher_index = ($2) and gg.date between ($3) and ($4)`
+	sql := `SELECT player_index, 1 as num_games, time_on_ice, goals, assists, points, shots, shots_against, saves, goals_against FROM nhl_player_games
+                left join games gg on gg.id = nhl_player_games.game
+                where gg.id IN (%s)`
 
-	rows, err := db.Query(context.Background(), sql, player, defender, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
+	param := strings.Join(gameIds, ",")
+	sql = fmt.Sprintf(sql, param)
+
+	rows, err := db.Query(ctx, sql)
 	if err != nil {
-		log.Fatal("Error querying for player stats: ", err)
+		return playerMap, fmt.Errorf("error querying NHL stats for games: %w", err)
 	}
 	defer rows.Close()
 
-	var stats MLBBattingAvg
-	if rows.Next() {
-		err = rows.Scan(&stats.PAs, &stats.HomeRuns, &stats.Hits, &stats.Strikeouts)
-		if err != nil {
-			log.Printf("Error scanning row: %v", err)
-			return MLBBattingAvg{}, err
-		}
-	} else {
-		log.Printf("No rows returned")
-		return MLBBattingAvg{}, nil
+	stats, err := pgx.CollectRows(rows, pgx.RowToStructByName[NHLPlayerStatInfo])
+	if err != nil {
+		return playerMap, err
 	}
 
-	return stats, nil
+	for _, stat := range stats {
+		playerMap[stat.PlayerIndex] = stat.NHLAvg
+	}
+
+	return playerMap, nil
 }
 
-func GetPlayerStatsWithPlayer(player string, defender string, relationship Relationship, startDate time.Time, endDate time.Time) (PlayerAvg, error) {
-	db := storage.GetDB()
+type NFLPlayerStatInfo struct {
+	PlayerIndex string `json:"player_index"`
+	NFLAvg
+}
+
+func GetNFLStatsForGames(ctx context.Context, gameIds []string) (map[string]NFLAvg, error) {
+	playerMap := make(map[string]NFLAvg)
+	db := storage.GetDB()
+	sql := `SELECT player_index, 1 as num_games, snaps, pass_attempts, pass_completions, pass_yards, pass_tds, rush_attempts, rush_yards,
+            targets, receptions, rec_yards, rush_tds + rec_tds as touchdowns FROM nfl_player_games
+                left join games gg on gg.id = nfl_player_games.game
+                where gg.id IN (%s)`
+
+	param := strings.Join(gameIds, ",")
+	sql = fmt.Sprintf(sql, param)
+
+	rows, err := db.Query(ctx, sql)
+	if err != nil {
+		return playerMap, fmt.Errorf("error querying NFL stats for games: %w", err)
+	}
+	defer rows.Close()
+
+	stats, err := pgx.CollectRows(rows, pgx.RowToStructByName[NFLPlayerStatInfo])
+	if err != nil {
+		return playerMap, err
+	}
+
+	for _, stat := range stats {
+		playerMap[stat.PlayerIndex] = stat.NFLAvg
+	}
+
+	return playerMap, nil
+}
+
+// GetStatsForGames returns each player's line from the games, batting lines
+// for MLB
+func GetStatsForGames(ctx context.Context, sport sports.Sport, gameIds []string) (map[string]PlayerAvg, error) {
+	switch sport {
+	case sports.NBA, sports.WNBA:
+		return GetPlayerStatsForGames(ctx, sport, gameIds)
+	case sports.MLB:
+		battingMap, err := GetMLBBattingStatsForGames(ctx, gameIds)
+		if err != nil {
+			return nil, err
+		}
+		playerMap := make(map[string]PlayerAvg, len(battingMap))
+		for player, stats := range battingMap {
+			playerMap[player] = stats
+		}
+		return playerMap, nil
+	case sports.NHL:
+		nhlMap, err := GetNHLStatsForGames(ctx, gameIds)
+		if err != nil {
+			return nil, err
+		}
+		playerMap := make(map[string]PlayerAvg, len(nhlMap))
+		for player, stats := range nhlMap {
+			playerMap[player] = stats
+		}
+		return playerMap, nil
+	case sports.NFL:
+		nflMap, err := GetNFLStatsForGames(ctx, gameIds)
+		if err != nil {
+			return nil, err
+		}
+		playerMap := make(map[string]PlayerAvg, len(nflMap))
+		for player, stats := range nflMap {
+			playerMap[player] = stats
+		}
+		return playerMap, nil
+	}
+
+	return nil, fmt.Errorf("game stats are not supported for sport %s", sport)
+}
+
+func GetPlayerPerByYear(sport sports.Sport, player string, startDate time.Time, endDate time.Time) map[int]PlayerAvg {
+	playerStats := make(map[int]PlayerAvg)
+
+	for d := startDate; !d.After(endDate); d = d.AddDate(1, 0, 0) {
+		useDate := d.AddDate(1, 0, 0)
+		if useDate.After(endDate) {
+			useDate = endDate
+		}
+
+		switch sport {
+		case sports.NBA, sports.WNBA:
+			yearlyStats, _ := GetPlayerStats(sport, player, d, useDate)
+			if yearlyStats.IsValid() {
+				playerStats[SeasonYear(sport, d)] = yearlyStats.ConvertToPer()
+			}
+		case sports.MLB:
+			yearlyStats, _ := GetMLBStats(player, d, useDate)
+			if yearlyStats.IsValid() {
+				playerStats[d.Year()] = yearlyStats.ConvertToPer()
+			}
+		case sports.NHL:
+			yearlyStats, _ := GetNHLStats(player, d, useDate)
+			if yearlyStats.IsValid() {
+				playerStats[SeasonYear(sport, d)] = yearlyStats.ConvertToPer()
+			}
+		case sports.NFL:
+			yearlyStats, _ := GetNFLStats(player, d, useDate)
+			if yearlyStats.IsValid() {
+				playerStats[SeasonYear(sport, d)] = yearlyStats.ConvertToPer()
+			}
+		}
+	}
+
+	return playerStats
+}
+
+func GetMLBPlayerStatsWithPlayer(player string, defender string, startDate time.Time, endDate time.Time) (MLBBattingAvg, error) {
+	db := storage.GetDB()
+	sql := `SELECT 
+            COUNT(*) FILTER (WHERE result != 'Not Completed') as pas,
+            COUNT(*) FILTER (WHERE result = 'HR') as home_runs,
+            COUNT(*) FILTER (WHERE result IN ('1B','2B','3B','HR')) as hits,
+            COUNT(*) FILTER (WHERE result = 'SO') as strikeouts
+            FROM mlb_play_by_plays
+                left join games gg on gg.id = mlb_play_by_plays.game
+                where mlb_play_by_plays.batter_index = ($1) and mlb_play_by_plays.pitcher_index = ($2) and gg.date between ($3) and ($4)`
+
+	rows, err := db.Query(context.Background(), sql, player, defender, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
+	if err != nil {
+		return MLBBattingAvg{}, fmt.Errorf("error querying MLB stats for %v against %v: %w", player, defender, err)
+	}
+	defer rows.Close()
+
+	var stats MLBBattingAvg
+	if rows.Next() {
+		err = rows.Scan(&stats.PAs, &stats.HomeRuns, &stats.Hits, &stats.Strikeouts)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			return MLBBattingAvg{}, err
+		}
+	} else {
+		log.Printf("No rows returned")
+		return MLBBattingAvg{}, nil
+	}
+
+	return stats, nil
+}
+
+func GetPlayerStatsWithPlayer(sport sports.Sport, player string, defender string, relationship Relationship, startDate time.Time, endDate time.Time) (PlayerAvg, error) {
+	tables, err := basketballTablesFor(sport)
+	if err != nil {
+		return NBAAvg{}, err
+	}
+	db := storage.GetDB()
 	sql := `SELECT count(*) as num_games, avg(minutes) as minutes, avg(points) as points, avg(rebounds) as rebounds, 
-            avg(assists) as assists, avg(threes) as threes, avg(usg) as usg, avg(ortg) as ortg, avg(drtg) as drtg FROM nba_player_games
-                left join games gg on gg.id = nba_player_games.game
-                where nba_player_games.player_index = ($1) and nba_player_games.minutes > 10 and gg.date between ($3) and ($4)`
+            avg(assists) as assists, avg(threes) as threes, avg(usg) as usg, avg(ortg) as ortg, avg(drtg) as drtg FROM ` + tables.games + `
+                left join games gg on gg.id = ` + tables.games + `.game
+                where ` + tables.games + `.player_index = ($1) and ` + tables.games + `.minutes > 10 and gg.date between ($3) and ($4)`
 	opponent_filter := `
         AND (
             SELECT COUNT(*) FROM games ga
-            LEFT JOIN nba_player_games pg ON pg.game=ga.id
+            LEFT JOIN ` + tables.games + ` pg ON pg.game=ga.id
             WHERE ga.id=gg.id AND pg.player_index IN (($1),($2))
         ) > 1`
 	teammate_filter := `
          AND (
              SELECT COUNT(*) FROM games ga
-             LEFT JOIN nba_player_games pg ON pg.game=ga.id
+             LEFT JOIN ` + tables.games + ` pg ON pg.game=ga.id
              WHERE ga.id=gg.id AND pg.player_index=($1)
          ) = 1
          AND (
              SELECT COUNT(*) FROM games ga
-             LEFT JOIN nba_player_games pg ON pg.game=ga.id
+             LEFT JOIN ` + tables.games + ` pg ON pg.game=ga.id
              WHERE ga.id=gg.id AND pg.player_index=($2)
          ) = 0`
 
@@ -701,7 +1255,7 @@ func GetPlayerStatsWithPlayer(player string, defender string, relationship Relat
 
 	rows, err := db.Query(context.Background(), sql, player, defender, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
 	if err != nil {
-		log.Fatal("Error querying for player stats: ", err)
+		return NBAAvg{}, fmt.Errorf("error querying stats for %v against %v: %w", player, defender, err)
 	}
 	defer rows.Close()
 
@@ -713,7 +1267,7 @@ func GetPlayerStatsWithPlayer(player string, defender string, relationship Relat
 	return stats, nil
 }
 
-func GetPlayerPerWithPlayerByYear(player string, defender string, relationship Relationship, startDate time.Time, endDate time.Time) map[int]PlayerAvg {
+func GetPlayerPerWithPlayerByYear(sport sports.Sport, player string, defender string, relationship Relationship, startDate time.Time, endDate time.Time) map[int]PlayerAvg {
 	playerStats := make(map[int]PlayerAvg)
 
 	for d := startDate; !d.After(endDate); d = d.AddDate(1, 0, 0) {
@@ -722,8 +1276,8 @@ func GetPlayerPerWithPlayerByYear(player string, defender string, relationship R
 			useDate = endDate
 		}
 
-		yearlyStats, _ := GetPlayerStatsWithPlayer(player, defender, relationship, d, useDate)
-		playerStats[utils.DateToNBAYear(d)] = yearlyStats.ConvertToPer()
+		yearlyStats, _ := GetPlayerStatsWithPlayer(sport, player, defender, relationship, d, useDate)
+		playerStats[SeasonYear(sport, d)] = yearlyStats.ConvertToPer()
 	}
 
 	return playerStats
@@ -745,14 +1299,102 @@ func GetMLBPlayerPerWithPlayerByYear(player string, defender string, startDate t
 	return playerStats
 }
 
-func AddPIPPrediction(pPreds []NBAPIPPrediction) {
+// GetNHLPlayerStatsWithPlayer averages the player's games against the other
+// player's team
+func GetNHLPlayerStatsWithPlayer(player string, defender string, startDate time.Time, endDate time.Time) (NHLAvg, error) {
+	db := storage.GetDB()
+	sql := `SELECT count(*) as num_games, avg(time_on_ice) as time_on_ice, avg(goals) as goals, avg(assists) as assists, avg(points) as points,
+            avg(shots) as shots, avg(shots_against) as shots_against, avg(saves) as saves, avg(goals_against) as goals_against FROM nhl_player_games
+                left join games gg on gg.id = nhl_player_games.game
+                where nhl_player_games.player_index = ($1) and gg.date between ($3) and ($4)
+                AND EXISTS (
+                    SELECT 1 FROM nhl_player_games op
+                    WHERE op.game=gg.id AND op.player_index=($2) AND op.team_index <> nhl_player_games.team_index
+                )`
+
+	rows, err := db.Query(context.Background(), sql, player, defender, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
+	if err != nil {
+		return NHLAvg{}, fmt.Errorf("error querying NHL stats for %v against %v: %w", player, defender, err)
+	}
+	defer rows.Close()
+
+	stats, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[NHLAvg])
+	if err != nil {
+		return NHLAvg{}, err
+	}
+
+	return stats, nil
+}
+
+func GetNHLPlayerPerWithPlayerByYear(player string, defender string, startDate time.Time, endDate time.Time) map[int]PlayerAvg {
+	playerStats := make(map[int]PlayerAvg)
+
+	for d := startDate; !d.After(endDate); d = d.AddDate(1, 0, 0) {
+		useDate := d.AddDate(1, 0, 0)
+		if useDate.After(endDate) {
+			useDate = endDate
+		}
+
+		yearlyStats, _ := GetNHLPlayerStatsWithPlayer(player, defender, d, useDate)
+		playerStats[SeasonYear(sports.NHL, d)] = yearlyStats.ConvertToPer()
+	}
+
+	return playerStats
+}
+
+// GetNFLPlayerStatsAgainstTeam averages the player's games against the team.
+// Football matchups are read against the whole defense rather than a player.
+func GetNFLPlayerStatsAgainstTeam(player string, team string, startDate time.Time, endDate time.Time) (NFLAvg, error) {
+	db := storage.GetDB()
+	sql := `SELECT ` + nflAvgColumns + ` FROM nfl_player_games
+                left join games gg on gg.id = nfl_player_games.game
+                where nfl_player_games.player_index = ($1) and gg.date between ($3) and ($4)
+                AND ($2) IN (gg.home_index, gg.away_index) AND nfl_player_games.team_index <> ($2)`
+
+	rows, err := db.Query(context.Background(), sql, player, team, startDate.Format(time.DateOnly), endDate.AddDate(0, 0, -1).Format(time.DateOnly))
+	if err != nil {
+		return NFLAvg{}, fmt.Errorf("error querying NFL stats for %v against %v: %w", player, team, err)
+	}
+	defer rows.Close()
+
+	stats, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[NFLAvg])
+	if err != nil {
+		return NFLAvg{}, err
+	}
+
+	return stats, nil
+}
+
+func GetNFLPlayerPerAgainstTeamByYear(player string, team string, startDate time.Time, endDate time.Time) map[int]PlayerAvg {
+	playerStats := make(map[int]PlayerAvg)
+
+	for d := startDate; !d.After(endDate); d = d.AddDate(1, 0, 0) {
+		useDate := d.AddDate(1, 0, 0)
+		if useDate.After(endDate) {
+			useDate = endDate
+		}
+
+		yearlyStats, _ := GetNFLPlayerStatsAgainstTeam(player, team, d, useDate)
+		playerStats[SeasonYear(sports.NFL, d)] = yearlyStats.ConvertToPer()
+	}
+
+	return playerStats
+}
+
+// AddPIPPrediction stores predictions for a basketball league
+func AddPIPPrediction(sport sports.Sport, pPreds []NBAPIPPrediction) {
+	tables, err := basketballTablesFor(sport)
+	if err != nil {
+		log.Printf("Skipping PIPPredictions: %v", err)
+		return
+	}
 	db := storage.GetDB()
 	txn, _ := db.Begin(context.Background())
-	_, err := txn.Exec(
+	_, err = txn.Exec(
 		context.Background(),
 		`CREATE TEMP TABLE pip_prediction_temp
         ON COMMIT DROP
-        AS SELECT * FROM nba_pip_predictions
+        AS SELECT * FROM `+tables.predictions+`
         WITH NO DATA`,
 	)
 	if err != nil {
@@ -804,7 +1446,7 @@ func AddPIPPrediction(pPreds []NBAPIPPrediction) {
 
 	_, err = txn.Exec(
 		context.Background(),
-		` INSERT INTO nba_pip_predictions (player_index, date, version, num_games, minutes, points, rebounds, assists, threes, usg, ortg, drtg)
+		` INSERT INTO `+tables.predictions+` (player_index, date, version, num_games, minutes, points, rebounds, assists, threes, usg, ortg, drtg)
         SELECT player_index, date, version, num_games, minutes, points, rebounds, assists, threes, usg, ortg, drtg FROM pip_prediction_temp
         ON CONFLICT (player_index, date, version) DO UPDATE
         SET num_games=excluded.num_games, minutes=excluded.minutes, points=excluded.points, rebounds=excluded.rebounds,
@@ -819,15 +1461,19 @@ func AddPIPPrediction(pPreds []NBAPIPPrediction) {
 	}
 }
 
-func GetPIPPredictionsForDate(date time.Time) ([]NBAPIPPrediction, error) {
+func GetPIPPredictionsForDate(sport sports.Sport, date time.Time) ([]NBAPIPPrediction, error) {
 	var pipPreds []NBAPIPPrediction
+	tables, err := basketballTablesFor(sport)
+	if err != nil {
+		return pipPreds, err
+	}
 	db := storage.GetDB()
-	sql := `SELECT player_index, date, version, num_games, minutes, points, rebounds, assists, threes, usg, ortg, drtg FROM nba_pip_predictions
+	sql := `SELECT player_index, date, version, num_games, minutes, points, rebounds, assists, threes, usg, ortg, drtg FROM ` + tables.predictions + `
                 where date=($1)`
 
 	rows, err := db.Query(context.Background(), sql, date.Format(time.DateOnly))
 	if err != nil {
-		log.Fatal("Error querying for NBAPIPPredictions: ", err)
+		return pipPreds, fmt.Errorf("error querying PIP predictions for %v: %w", date, err)
 	}
 	defer rows.Close()
 
@@ -839,9 +1485,13 @@ func GetPIPPredictionsForDate(date time.Time) ([]NBAPIPPrediction, error) {
 	return pipPreds, nil
 }
 
-func GetPlayerPIPPrediction(playerIndex string, date time.Time) (NBAPIPPrediction, error) {
+func GetPlayerPIPPrediction(sport sports.Sport, playerIndex string, date time.Time) (NBAPIPPrediction, error) {
+	tables, err := basketballTablesFor(sport)
+	if err != nil {
+		return NBAPIPPrediction{}, err
+	}
 	db := storage.GetDB()
-	sql := `SELECT player_index, date, version, num_games, minutes, points, rebounds, assists, threes, usg, ortg, drtg FROM nba_pip_predictions
+	sql := `SELECT player_index, date, version, num_games, minutes, points, rebounds, assists, threes, usg, ortg, drtg FROM ` + tables.predictions + `
                 where date=($1) and player_index=($2)`
 
 	rows, err := db.Query(context.Background(), sql, date.Format(time.DateOnly), playerIndex)
@@ -858,6 +1508,317 @@ func GetPlayerPIPPrediction(playerIndex string, date time.Time) (NBAPIPPredictio
 	return pipPred, nil
 }
 
+func AddMLBPIPPrediction(pPreds []MLBPIPPrediction) error {
+	db := storage.GetDB()
+	txn, err := db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("error starting MLBPIPPrediction transaction: %w", err)
+	}
+	defer txn.Rollback(context.Background())
+
+	_, err = txn.Exec(
+		context.Background(),
+		`CREATE TEMP TABLE mlb_pip_prediction_temp
+        ON COMMIT DROP
+        AS SELECT * FROM mlb_pip_predictions
+        WITH NO DATA`,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating MLBPIPPrediction temp table: %w", err)
+	}
+	var predsInterface [][]interface{}
+	for _, pPred := range pPreds {
+		predsInterface = append(
+			predsInterface,
+			[]interface{}{
+				pPred.PlayerIndex,
+				pPred.Date,
+				pPred.Version,
+				pPred.NumGames,
+				pPred.AtBats,
+				pPred.Runs,
+				pPred.Hits,
+				pPred.RBIs,
+				pPred.HomeRuns,
+				pPred.Walks,
+				pPred.Strikeouts,
+				pPred.PAs,
+				pPred.Pitches,
+				pPred.Strikes,
+				pPred.BA,
+				pPred.OBP,
+				pPred.SLG,
+				pPred.OPS,
+				pPred.WPA,
+			},
+		)
+	}
+
+	_, err = txn.CopyFrom(
+		context.Background(),
+		pgx.Identifier{"mlb_pip_prediction_temp"},
+		[]string{
+			"player_index",
+			"date",
+			"version",
+			"num_games",
+			"at_bats",
+			"runs",
+			"hits",
+			"rbis",
+			"home_runs",
+			"walks",
+			"strikeouts",
+			"pas",
+			"pitches",
+			"strikes",
+			"ba",
+			"obp",
+			"slg",
+			"ops",
+			"wpa",
+		},
+		pgx.CopyFromRows(predsInterface),
+	)
+	if err != nil {
+		return fmt.Errorf("error copying MLBPIPPredictions: %w", err)
+	}
+
+	_, err = txn.Exec(
+		context.Background(),
+		` INSERT INTO mlb_pip_predictions (player_index, date, version, num_games, at_bats, runs, hits, rbis, home_runs, walks, strikeouts, pas, pitches, strikes, ba, obp, slg, ops, wpa)
+        SELECT player_index, date, version, num_games, at_bats, runs, hits, rbis, home_runs, walks, strikeouts, pas, pitches, strikes, ba, obp, slg, ops, wpa FROM mlb_pip_prediction_temp
+        ON CONFLICT (player_index, date, version) DO UPDATE
+        SET num_games=excluded.num_games, at_bats=excluded.at_bats, runs=excluded.runs, hits=excluded.hits, rbis=excluded.rbis,
+        home_runs=excluded.home_runs, walks=excluded.walks, strikeouts=excluded.strikeouts, pas=excluded.pas, pitches=excluded.pitches,
+        strikes=excluded.strikes, ba=excluded.ba, obp=excluded.obp, slg=excluded.slg, ops=excluded.ops, wpa=excluded.wpa`,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting MLBPIPPredictions: %w", err)
+	}
+
+	return txn.Commit(context.Background())
+}
+
+func GetMLBPlayerPIPPrediction(playerIndex string, date time.Time) (MLBPIPPrediction, error) {
+	db := storage.GetDB()
+	sql := `SELECT player_index, date, version, num_games, at_bats, runs, hits, rbis, home_runs, walks, strikeouts, pas, pitches, strikes, ba, obp, slg, ops, wpa FROM mlb_pip_predictions
+                where date=($1) and player_index=($2) and version=($3)`
+
+	rows, err := db.Query(context.Background(), sql, date.Format(time.DateOnly), playerIndex, CurrMLBPIPPredVersion())
+	if err != nil {
+		return MLBPIPPrediction{}, err
+	}
+	defer rows.Close()
+
+	pipPred, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[MLBPIPPrediction])
+	if err != nil {
+		return MLBPIPPrediction{}, err
+	}
+
+	return pipPred, nil
+}
+
+func AddNHLPIPPrediction(pPreds []NHLPIPPrediction) error {
+	db := storage.GetDB()
+	txn, err := db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("error starting NHLPIPPrediction transaction: %w", err)
+	}
+	defer txn.Rollback(context.Background())
+
+	_, err = txn.Exec(
+		context.Background(),
+		`CREATE TEMP TABLE nhl_pip_prediction_temp
+        ON COMMIT DROP
+        AS SELECT * FROM nhl_pip_predictions
+        WITH NO DATA`,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating NHLPIPPrediction temp table: %w", err)
+	}
+	var predsInterface [][]interface{}
+	for _, pPred := range pPreds {
+		predsInterface = append(
+			predsInterface,
+			[]interface{}{
+				pPred.PlayerIndex,
+				pPred.Date,
+				pPred.Version,
+				pPred.NumGames,
+				pPred.TimeOnIce,
+				pPred.Goals,
+				pPred.Assists,
+				pPred.Points,
+				pPred.Shots,
+				pPred.ShotsAgainst,
+				pPred.Saves,
+				pPred.GoalsAgainst,
+			},
+		)
+	}
+
+	_, err = txn.CopyFrom(
+		context.Background(),
+		pgx.Identifier{"nhl_pip_prediction_temp"},
+		[]string{
+			"player_index",
+			"date",
+			"version",
+			"num_games",
+			"time_on_ice",
+			"goals",
+			"assists",
+			"points",
+			"shots",
+			"shots_against",
+			"saves",
+			"goals_against",
+		},
+		pgx.CopyFromRows(predsInterface),
+	)
+	if err != nil {
+		return fmt.Errorf("error copying NHLPIPPredictions: %w", err)
+	}
+
+	_, err = txn.Exec(
+		context.Background(),
+		` INSERT INTO nhl_pip_predictions (player_index, date, version, num_games, time_on_ice, goals, assists, points, shots, shots_against, saves, goals_against)
+        SELECT player_index, date, version, num_games, time_on_ice, goals, assists, points, shots, shots_against, saves, goals_against FROM nhl_pip_prediction_temp
+        ON CONFLICT (player_index, date, version) DO UPDATE
+        SET num_games=excluded.num_games, time_on_ice=excluded.time_on_ice, goals=excluded.goals, assists=excluded.assists, points=excluded.points,
+        shots=excluded.shots, shots_against=excluded.shots_against, saves=excluded.saves, goals_against=excluded.goals_against`,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting NHLPIPPredictions: %w", err)
+	}
+
+	return txn.Commit(context.Background())
+}
+
+func GetNHLPlayerPIPPrediction(playerIndex string, date time.Time) (NHLPIPPrediction, error) {
+	db := storage.GetDB()
+	sql := `SELECT player_index, date, version, num_games, time_on_ice, goals, assists, points, shots, shots_against, saves, goals_against FROM nhl_pip_predictions
+                where date=($1) and player_index=($2) and version=($3)`
+
+	rows, err := db.Query(context.Background(), sql, date.Format(time.DateOnly), playerIndex, CurrNHLPIPPredVersion())
+	if err != nil {
+		return NHLPIPPrediction{}, err
+	}
+	defer rows.Close()
+
+	pipPred, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[NHLPIPPrediction])
+	if err != nil {
+		return NHLPIPPrediction{}, err
+	}
+
+	return pipPred, nil
+}
+
+func AddNFLPIPPrediction(pPreds []NFLPIPPrediction) error {
+	db := storage.GetDB()
+	txn, err := db.Begin(context.Background())
+	if err != nil {
+		return fmt.Errorf("error starting NFLPIPPrediction transaction: %w", err)
+	}
+	defer txn.Rollback(context.Background())
+
+	_, err = txn.Exec(
+		context.Background(),
+		`CREATE TEMP TABLE nfl_pip_prediction_temp
+        ON COMMIT DROP
+        AS SELECT * FROM nfl_pip_predictions
+        WITH NO DATA`,
+	)
+	if err != nil {
+		return fmt.Errorf("error creating NFLPIPPrediction temp table: %w", err)
+	}
+	var predsInterface [][]interface{}
+	for _, pPred := range pPreds {
+		predsInterface = append(
+			predsInterface,
+			[]interface{}{
+				pPred.PlayerIndex,
+				pPred.Date,
+				pPred.Version,
+				pPred.NumGames,
+				pPred.Snaps,
+				pPred.PassAttempts,
+				pPred.PassCompletions,
+				pPred.PassYards,
+				pPred.PassTDs,
+				pPred.RushAttempts,
+				pPred.RushYards,
+				pPred.Targets,
+				pPred.Receptions,
+				pPred.RecYards,
+				pPred.Touchdowns,
+			},
+		)
+	}
+
+	_, err = txn.CopyFrom(
+		context.Background(),
+		pgx.Identifier{"nfl_pip_prediction_temp"},
+		[]string{
+			"player_index",
+			"date",
+			"version",
+			"num_games",
+			"snaps",
+			"pass_attempts",
+			"pass_completions",
+			"pass_yards",
+			"pass_tds",
+			"rush_attempts",
+			"rush_yards",
+			"targets",
+			"receptions",
+			"rec_yards",
+			"touchdowns",
+		},
+		pgx.CopyFromRows(predsInterface),
+	)
+	if err != nil {
+		return fmt.Errorf("error copying NFLPIPPredictions: %w", err)
+	}
+
+	_, err = txn.Exec(
+		context.Background(),
+		` INSERT INTO nfl_pip_predictions (player_index, date, version, num_games, snaps, pass_attempts, pass_completions, pass_yards, pass_tds, rush_attempts, rush_yards, targets, receptions, rec_yards, touchdowns)
+        SELECT player_index, date, version, num_games, snaps, pass_attempts, pass_completions, pass_yards, pass_tds, rush_attempts, rush_yards, targets, receptions, rec_yards, touchdowns FROM nfl_pip_prediction_temp
+        ON CONFLICT (player_index, date, version) DO UPDATE
+        SET num_games=excluded.num_games, snaps=excluded.snaps, pass_attempts=excluded.pass_attempts, pass_completions=excluded.pass_completions,
+        pass_yards=excluded.pass_yards, pass_tds=excluded.pass_tds, rush_attempts=excluded.rush_attempts, rush_yards=excluded.rush_yards,
+        targets=excluded.targets, receptions=excluded.receptions, rec_yards=excluded.rec_yards, touchdowns=excluded.touchdowns`,
+	)
+	if err != nil {
+		return fmt.Errorf("error inserting NFLPIPPredictions: %w", err)
+	}
+
+	return txn.Commit(context.Background())
+}
+
+func GetNFLPlayerPIPPrediction(playerIndex string, date time.Time) (NFLPIPPrediction, error) {
+	db := storage.GetDB()
+	sql := `SELECT player_index, date, version, num_games, snaps, pass_attempts, pass_completions, pass_yards, pass_tds, rush_attempts, rush_yards,
+            targets, receptions, rec_yards, touchdowns FROM nfl_pip_predictions
+                where date=($1) and player_index=($2) and version=($3)`
+
+	rows, err := db.Query(context.Background(), sql, date.Format(time.DateOnly), playerIndex, CurrNFLPIPPredVersion())
+	if err != nil {
+		return NFLPIPPrediction{}, err
+	}
+	defer rows.Close()
+
+	pipPred, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[NFLPIPPrediction])
+	if err != nil {
+		return NFLPIPPrediction{}, err
+	}
+
+	return pipPred, nil
+}
+
 func CalculatePIPFactor(controlMap map[int]PlayerAvg, relatedMap map[int]PlayerAvg) PlayerAvg {
 	var totals PlayerAvg
 	for year := range relatedMap {
@@ -872,8 +1833,8 @@ func CalculatePIPFactor(controlMap map[int]PlayerAvg, relatedMap map[int]PlayerA
 	return totals
 }
 
-func GetOrCreatePrediction(playerIndex string, date time.Time) PlayerAvg {
-	pipPred, err := GetPlayerPIPPrediction(playerIndex, date)
+func GetOrCreatePrediction(sport sports.Sport, playerIndex string, date time.Time) PlayerAvg {
+	pipPred, err := GetPlayerPIPPrediction(sport, playerIndex, date)
 	if err != nil {
 		log.Println("Failed to find PIPPrediction: ", err)
 	}
@@ -988,13 +1949,15 @@ func UpdateRosters(rosterSlots []PlayerRoster) error {
 	return nil
 }
 
-func GetActiveRosters() (map[string][]PlayerRoster, error) {
+// GetActiveRosters returns the sport's active players by team
+func GetActiveRosters(sport sports.Sport) (map[string][]PlayerRoster, error) {
 	rosterMap := make(map[string][]PlayerRoster)
 	db := storage.GetDB()
 	sql := `SELECT id, sport, player_index, team_index, status, avg_minutes FROM active_rosters
+            WHERE sport = ($1)
             ORDER BY avg_minutes DESC`
 
-	rows, err := db.Query(context.Background(), sql)
+	rows, err := db.Query(context.Background(), sql, sport)
 	if err != nil {
 		msg := fmt.Sprint("Error querying for active roster: ", err)
 		log.Println(msg)
@@ -93,7 +93,7 @@ func TestPlayerControllerDatabaseFlows(t *testing.T) {
 	p3 := "nbac" + suffix
 	AddPlayers([]Player{{Index: p1, Sport: "nba", Name: "NBA A " + suffix}, {Index: p2, Sport: "nba", Name: "NBA B " + suffix}, {Index: p3, Sport: "nba", Name: "NBA C " + suffix}})
 
-	AddPlayerGames([]PlayerGame{
+	AddPlayerGames(sports.NBA, []PlayerGame{
 		{PlayerIndex: p1, Game: g1, TeamIndex: home, Minutes: 30, Points: 20, Rebounds: 8, Assists: 6, Threes: 2, Usg: 24, Ortg: 110, Drtg: 106},
 		{PlayerIndex: p1, Game: g2, TeamIndex: home, Minutes: 32, Points: 24, Rebounds: 9, Assists: 7, Threes: 3, Usg: 25, Ortg: 112, Drtg: 105},
 		{PlayerIndex: p2, Game: g1, TeamIndex: home, Minutes: 28, Points: 15, Rebounds: 5, Assists: 4, Threes: 1, Usg: 20, Ortg: 108, Drtg: 107},
@@ -105,27 +105,32 @@ func TestPlayerControllerDatabaseFlows(t *testing.T) {
 		t.Fatalf("GetPlayer() got=%+v err=%v", gotPlayer, err)
 	}
 
-	stats, err := GetPlayerStats(p1, nbaDate, nbaDate.AddDate(0, 0, 2))
+	stats, err := GetPlayerStats(sports.NBA, p1, nbaDate, nbaDate.AddDate(0, 0, 2))
 	if err != nil || !stats.IsValid() {
 		t.Fatalf("GetPlayerStats() stats=%+v err=%v", stats, err)
 	}
 
-	playerMap, err := GetPlayersForGame(g1, home, "nba_player_games", "minutes")
+	moments, err := GetPlayerStatMoments(sports.NBA, p1, nbaDate, nbaDate.AddDate(0, 0, 2))
+	if err != nil || moments["points"].NumGames != 1 || moments["points"].Mean == 0 || moments["points"].Variance != 0 {
+		t.Fatalf("GetPlayerStatMoments() moments=%+v err=%v", moments, err)
+	}
+
+	playerMap, err := GetPlayersForGame(context.Background(), g1, home, "nba_player_games", "minutes")
 	if err != nil || len(playerMap["home"]) == 0 || len(playerMap["away"]) == 0 {
 		t.Fatalf("GetPlayersForGame() map=%+v err=%v", playerMap, err)
 	}
 
-	statMap, err := GetPlayerStatsForGames([]string{fmt.Sprintf("%d", g1)})
+	statMap, err := GetPlayerStatsForGames(context.Background(), sports.NBA, []string{fmt.Sprintf("%d", g1)})
 	if err != nil || len(statMap) == 0 {
 		t.Fatalf("GetPlayerStatsForGames() len=%d err=%v", len(statMap), err)
 	}
 
-	opp, err := GetPlayerStatsWithPlayer(p1, p3, Opponent, nbaDate, nbaDate.AddDate(0, 0, 2))
+	opp, err := GetPlayerStatsWithPlayer(sports.NBA, p1, p3, Opponent, nbaDate, nbaDate.AddDate(0, 0, 2))
 	if err != nil || !opp.IsValid() {
 		t.Fatalf("GetPlayerStatsWithPlayer opponent err=%v stats=%+v", err, opp)
 	}
 
-	teamMateLike, err := GetPlayerStatsWithPlayer(p1, "missing"+suffix, Teammate, nbaDate, nbaDate.AddDate(0, 0, 2))
+	teamMateLike, err := GetPlayerStatsWithPlayer(sports.NBA, p1, "missing"+suffix, Teammate, nbaDate, nbaDate.AddDate(0, 0, 2))
 	if err != nil || !teamMateLike.IsValid() {
 		t.Fatalf("GetPlayerStatsWithPlayer teammate-filter err=%v stats=%+v", err, teamMateLike)
 	}
@@ -133,7 +138,7 @@ func TestPlayerControllerDatabaseFlows(t *testing.T) {
 	if len(GetPlayerPerByYear(sports.NBA, p1, nbaDate, nbaDate.AddDate(0, 0, 2))) == 0 {
 		t.Fatalf("GetPlayerPerByYear() should return at least one year")
 	}
-	if len(GetPlayerPerWithPlayerByYear(p1, p3, Opponent, nbaDate, nbaDate.AddDate(0, 0, 2))) == 0 {
+	if len(GetPlayerPerWithPlayerByYear(sports.NBA, p1, p3, Opponent, nbaDate, nbaDate.AddDate(0, 0, 2))) == 0 {
 		t.Fatalf("GetPlayerPerWithPlayerByYear() should return at least one year")
 	}
 
@@ -145,19 +150,55 @@ func TestPlayerControllerDatabaseFlows(t *testing.T) {
 		t.Fatalf("CalculatePIPFactor() returned nil")
 	}
 
-	AddPIPPrediction([]NBAPIPPrediction{{PlayerIndex: p1, Date: nbaDate, Version: CurrNBAPIPPredVersion(), NumGames: 5, Minutes: 31, Points: 23, Rebounds: 8, Assists: 6, Threes: 2, Usg: 22, Ortg: 111, Drtg: 107}})
-	preds, err := GetPIPPredictionsForDate(nbaDate)
+	AddPIPPrediction(sports.NBA, []NBAPIPPrediction{{PlayerIndex: p1, Date: nbaDate, Version: CurrNBAPIPPredVersion(), NumGames: 5, Minutes: 31, Points: 23, Rebounds: 8, Assists: 6, Threes: 2, Usg: 22, Ortg: 111, Drtg: 107}})
+	preds, err := GetPIPPredictionsForDate(sports.NBA, nbaDate)
 	if err != nil || len(preds) == 0 {
 		t.Fatalf("GetPIPPredictionsForDate() len=%d err=%v", len(preds), err)
 	}
-	pred, err := GetPlayerPIPPrediction(p1, nbaDate)
+	pred, err := GetPlayerPIPPrediction(sports.NBA, p1, nbaDate)
 	if err != nil || pred.PlayerIndex != p1 {
 		t.Fatalf("GetPlayerPIPPrediction() pred=%+v err=%v", pred, err)
 	}
-	if created := GetOrCreatePrediction(p1, nbaDate); created.GetStats()["points"] == 0 {
+	if created := GetOrCreatePrediction(sports.NBA, p1, nbaDate); created.GetStats()["points"] == 0 {
 		t.Fatalf("GetOrCreatePrediction() should return populated stats")
 	}
 
+	wnbaDate := time.Date(2099, 7, 1, 0, 0, 0, 0, time.UTC)
+	wg, err := games.AddGame(games.Game{Sport: "wnba", HomeIndex: home, AwayIndex: away, HomeScore: 85, AwayScore: 80, Date: wnbaDate})
+	if err != nil {
+		t.Fatalf("AddGame wnba error = %v", err)
+	}
+	w1 := "wnbaa" + suffix
+	w2 := "wnbab" + suffix
+	AddPlayers([]Player{{Index: w1, Sport: "wnba", Name: "WNBA A " + suffix}, {Index: w2, Sport: "wnba", Name: "WNBA B " + suffix}})
+	AddPlayerGames(sports.WNBA, []PlayerGame{
+		{PlayerIndex: w1, Game: wg, TeamIndex: home, Minutes: 32, Points: 21, Rebounds: 9, Assists: 4, Threes: 1, Usg: 26, Ortg: 112, Drtg: 101},
+		{PlayerIndex: w2, Game: wg, TeamIndex: away, Minutes: 30, Points: 17, Rebounds: 6, Assists: 5, Threes: 3, Usg: 23, Ortg: 108, Drtg: 104},
+	})
+	if nbaStats, _ := GetPlayerStats(sports.NBA, w1, wnbaDate, wnbaDate.AddDate(0, 0, 1)); nbaStats.IsValid() {
+		t.Fatalf("GetPlayerStats() nba should not read wnba games, got %+v", nbaStats)
+	}
+	wStats, err := GetPlayerStats(sports.WNBA, w1, wnbaDate, wnbaDate.AddDate(0, 0, 1))
+	if err != nil || !wStats.IsValid() || wStats.GetStats()["points"] != 21 {
+		t.Fatalf("GetPlayerStats() wnba stats=%+v err=%v", wStats, err)
+	}
+	wGames, err := GetStatsForGames(context.Background(), sports.WNBA, []string{fmt.Sprintf("%d", wg)})
+	if err != nil || len(wGames) != 2 {
+		t.Fatalf("GetStatsForGames() wnba len=%d err=%v", len(wGames), err)
+	}
+	wYears := GetPlayerPerWithPlayerByYear(sports.WNBA, w1, w2, Opponent, wnbaDate, wnbaDate.AddDate(0, 0, 1))
+	if _, ok := wYears[2099]; !ok {
+		t.Fatalf("GetPlayerPerWithPlayerByYear() wnba should key by calendar year, got %+v", wYears)
+	}
+	AddPIPPrediction(sports.WNBA, []NBAPIPPrediction{{PlayerIndex: w1, Date: wnbaDate, Version: CurrNBAPIPPredVersion(), NumGames: 1, Minutes: 32, Points: 22, Rebounds: 9, Assists: 4, Threes: 1, Usg: 26, Ortg: 112, Drtg: 101}})
+	if _, err := GetPlayerPIPPrediction(sports.NBA, w1, wnbaDate); err == nil {
+		t.Fatalf("GetPlayerPIPPrediction() nba should not read wnba predictions")
+	}
+	wPred, err := GetPlayerPIPPrediction(sports.WNBA, w1, wnbaDate)
+	if err != nil || wPred.Points != 22 {
+		t.Fatalf("GetPlayerPIPPrediction() wnba pred=%+v err=%v", wPred, err)
+	}
+
 	UpdatePlayerTables("new" + suffix)
 	if _, err := GetPlayer("new" + suffix); err != nil {
 		t.Fatalf("UpdatePlayerTables() should insert missing player: %v", err)
@@ -167,10 +208,13 @@ func TestPlayerControllerDatabaseFlows(t *testing.T) {
 	if err != nil {
 		t.Fatalf("UpdateRosters() error = %v", err)
 	}
-	rosters, err := GetActiveRosters()
+	rosters, err := GetActiveRosters(sports.NBA)
 	if err != nil || len(rosters[home]) == 0 {
 		t.Fatalf("GetActiveRosters() err=%v rosters=%+v", err, rosters)
 	}
+	if rosters, err := GetActiveRosters(sports.WNBA); err != nil || len(rosters[home]) != 0 {
+		t.Fatalf("GetActiveRosters(wnba) should not return nba players, err=%v rosters=%+v", err, rosters)
+	}
 
 	mlbDate := time.Date(2099, 5, 1, 0, 0, 0, 0, time.UTC)
 	mlbGame, err := games.AddGame(games.Game{Sport: "mlb", HomeIndex: mlbHome, AwayIndex: mlbAway, HomeScore: 6, AwayScore: 3, Date: mlbDate})
@@ -193,10 +237,25 @@ func TestPlayerControllerDatabaseFlows(t *testing.T) {
 	if err != nil || vsPitcher.PAs == 0 {
 		t.Fatalf("GetMLBPlayerStatsWithPlayer() stats=%+v err=%v", vsPitcher, err)
 	}
-	bMap, err := GetMLBBattingStatsForGames([]string{fmt.Sprintf("%d", mlbGame)})
+	bMap, err := GetMLBBattingStatsForGames(context.Background(), []string{fmt.Sprintf("%d", mlbGame)})
 	if err != nil || len(bMap) == 0 {
 		t.Fatalf("GetMLBBattingStatsForGames() len=%d err=%v", len(bMap), err)
 	}
+	if gameStats, err := GetStatsForGames(context.Background(), sports.MLB, []string{fmt.Sprintf("%d", mlbGame)}); err != nil || gameStats[batter].GetStats()["hits"] != 2 {
+		t.Fatalf("GetStatsForGames(mlb) stats=%+v err=%v", gameStats, err)
+	}
+	mlbMoments, err := GetMLBPlayerStatMoments(batter, mlbDate, mlbDate.AddDate(0, 0, 1))
+	if err != nil || mlbMoments["hits"].NumGames != 1 || mlbMoments["hits"].Mean != 2 {
+		t.Fatalf("GetMLBPlayerStatMoments() moments=%+v err=%v", mlbMoments, err)
+	}
+	err = AddMLBPIPPrediction([]MLBPIPPrediction{{PlayerIndex: batter, Date: mlbDate, Version: CurrMLBPIPPredVersion(), MLBBattingAvg: MLBBattingAvg{NumGames: 1, PAs: 4.5, Hits: 1.2, HomeRuns: 0.3, RBIs: 0.8}}})
+	if err != nil {
+		t.Fatalf("AddMLBPIPPrediction() err=%v", err)
+	}
+	mlbPred, err := GetMLBPlayerPIPPrediction(batter, mlbDate)
+	if err != nil || mlbPred.PlayerIndex != batter || mlbPred.Hits != 1.2 {
+		t.Fatalf("GetMLBPlayerPIPPrediction() pred=%+v err=%v", mlbPred, err)
+	}
 	if len(GetMLBPlayerPerWithPlayerByYear(batter, pitcher, mlbDate, mlbDate.AddDate(0, 0, 1))) == 0 {
 		t.Fatalf("GetMLBPlayerPerWithPlayerByYear() should return at least one year")
 	}
@@ -212,4 +271,103 @@ func TestPlayerControllerDatabaseFlows(t *testing.T) {
 	if err := AddMLBPlayerHandedness(batter, "R", "R"); err != nil {
 		t.Fatalf("AddMLBPlayerHandedness() err=%v", err)
 	}
+
+	nhlHome := "NHL_H" + suffix
+	nhlAway := "NHL_A" + suffix
+	teams.AddTeams([]teams.Team{{Index: nhlHome, Name: "NHL Home"}, {Index: nhlAway, Name: "NHL Away"}})
+	nhlDate := time.Date(2099, 11, 1, 0, 0, 0, 0, time.UTC)
+	nhlGame, err := games.AddGame(games.Game{Sport: "nhl", HomeIndex: nhlHome, AwayIndex: nhlAway, HomeScore: 4, AwayScore: 2, Date: nhlDate})
+	if err != nil {
+		t.Fatalf("AddGame nhl error = %v", err)
+	}
+	skater := "nhls" + suffix
+	goalie := "nhlg" + suffix
+	AddPlayers([]Player{{Index: skater, Sport: "nhl", Name: "NHL Skater " + suffix}, {Index: goalie, Sport: "nhl", Name: "NHL Goalie " + suffix}})
+	err = AddNHLPlayerGames([]NHLPlayerGame{
+		{PlayerIndex: skater, Game: nhlGame, TeamIndex: nhlHome, TimeOnIce: 19.5, Goals: 1, Assists: 1, Points: 2, Shots: 5, PlusMinus: 1},
+		{PlayerIndex: goalie, Game: nhlGame, TeamIndex: nhlAway, TimeOnIce: 60, ShotsAgainst: 32, Saves: 28, GoalsAgainst: 4},
+	})
+	if err != nil {
+		t.Fatalf("AddNHLPlayerGames() err=%v", err)
+	}
+
+	nhlStats, err := GetNHLStats(skater, nhlDate, nhlDate.AddDate(0, 0, 1))
+	if err != nil || nhlStats.Shots != 5 {
+		t.Fatalf("GetNHLStats() stats=%+v err=%v", nhlStats, err)
+	}
+	if years := GetPlayerPerByYear(sports.NHL, skater, nhlDate, nhlDate.AddDate(0, 0, 1)); years[2100] == nil {
+		t.Fatalf("GetPlayerPerByYear(nhl) should key by the season's end year, got %+v", years)
+	}
+	vsGoalie, err := GetNHLPlayerStatsWithPlayer(skater, goalie, nhlDate, nhlDate.AddDate(0, 0, 1))
+	if err != nil || vsGoalie.NumGames != 1 {
+		t.Fatalf("GetNHLPlayerStatsWithPlayer() stats=%+v err=%v", vsGoalie, err)
+	}
+	if len(GetNHLPlayerPerWithPlayerByYear(goalie, skater, nhlDate, nhlDate.AddDate(0, 0, 1))) == 0 {
+		t.Fatalf("GetNHLPlayerPerWithPlayerByYear() should return at least one year")
+	}
+	if gameStats, err := GetStatsForGames(context.Background(), sports.NHL, []string{fmt.Sprintf("%d", nhlGame)}); err != nil || gameStats[goalie].GetStats()["saves"] != 28 {
+		t.Fatalf("GetStatsForGames(nhl) stats=%+v err=%v", gameStats, err)
+	}
+	nhlMoments, err := GetNHLPlayerStatMoments(skater, nhlDate, nhlDate.AddDate(0, 0, 1))
+	if err != nil || nhlMoments["shots"].NumGames != 1 || nhlMoments["shots"].Mean != 5 {
+		t.Fatalf("GetNHLPlayerStatMoments() moments=%+v err=%v", nhlMoments, err)
+	}
+	err = AddNHLPIPPrediction([]NHLPIPPrediction{{PlayerIndex: goalie, Date: nhlDate, Version: CurrNHLPIPPredVersion(), NHLAvg: NHLAvg{NumGames: 1, TimeOnIce: 60, ShotsAgainst: 30, Saves: 27.5, GoalsAgainst: 2.5}}})
+	if err != nil {
+		t.Fatalf("AddNHLPIPPrediction() err=%v", err)
+	}
+	nhlPred, err := GetNHLPlayerPIPPrediction(goalie, nhlDate)
+	if err != nil || nhlPred.PlayerIndex != goalie || nhlPred.Saves != 27.5 {
+		t.Fatalf("GetNHLPlayerPIPPrediction() pred=%+v err=%v", nhlPred, err)
+	}
+
+	nflHome := "NFL_H" + suffix
+	nflAway := "NFL_A" + suffix
+	teams.AddTeams([]teams.Team{{Index: nflHome, Name: "NFL Home"}, {Index: nflAway, Name: "NFL Away"}})
+	nflDate := time.Date(2099, 9, 13, 0, 0, 0, 0, time.UTC)
+	nflGame, err := games.AddGame(games.Game{Sport: "nfl", HomeIndex: nflHome, AwayIndex: nflAway, HomeScore: 24, AwayScore: 17, Date: nflDate})
+	if err != nil {
+		t.Fatalf("AddGame nfl error = %v", err)
+	}
+	receiver := "nflr" + suffix
+	AddPlayers([]Player{{Index: receiver, Sport: "nfl", Name: "NFL Receiver " + suffix}})
+	err = AddNFLPlayerGames([]NFLPlayerGame{
+		{PlayerIndex: receiver, Game: nflGame, TeamIndex: nflHome, Snaps: 50, RushAttempts: 1, RushYards: 6, Targets: 10, Receptions: 7, RecYards: 84, RecTDs: 1},
+	})
+	if err != nil {
+		t.Fatalf("AddNFLPlayerGames() err=%v", err)
+	}
+
+	nflStats, err := GetNFLStats(receiver, nflDate, nflDate.AddDate(0, 0, 1))
+	if err != nil || nflStats.Receptions != 7 || nflStats.Touchdowns != 1 {
+		t.Fatalf("GetNFLStats() stats=%+v err=%v", nflStats, err)
+	}
+	if years := GetPlayerPerByYear(sports.NFL, receiver, nflDate, nflDate.AddDate(0, 0, 1)); years[2099] == nil {
+		t.Fatalf("GetPlayerPerByYear(nfl) should key by the season's start year, got %+v", years)
+	}
+	vsAway, err := GetNFLPlayerStatsAgainstTeam(receiver, nflAway, nflDate, nflDate.AddDate(0, 0, 1))
+	if err != nil || vsAway.NumGames != 1 {
+		t.Fatalf("GetNFLPlayerStatsAgainstTeam() stats=%+v err=%v", vsAway, err)
+	}
+	if vsOwn, _ := GetNFLPlayerStatsAgainstTeam(receiver, nflHome, nflDate, nflDate.AddDate(0, 0, 1)); vsOwn.NumGames != 0 {
+		t.Fatalf("GetNFLPlayerStatsAgainstTeam() should not count the player's own team, got %+v", vsOwn)
+	}
+	if len(GetNFLPlayerPerAgainstTeamByYear(receiver, nflAway, nflDate, nflDate.AddDate(0, 0, 1))) == 0 {
+		t.Fatalf("GetNFLPlayerPerAgainstTeamByYear() should return at least one year")
+	}
+	if gameStats, err := GetStatsForGames(context.Background(), sports.NFL, []string{fmt.Sprintf("%d", nflGame)}); err != nil || gameStats[receiver].GetStats()["rec_yards"] != 84 {
+		t.Fatalf("GetStatsForGames(nfl) stats=%+v err=%v", gameStats, err)
+	}
+	nflMoments, err := GetNFLPlayerStatMoments(receiver, nflDate, nflDate.AddDate(0, 0, 1))
+	if err != nil || nflMoments["receptions"].NumGames != 1 || nflMoments["receptions"].Mean != 7 {
+		t.Fatalf("GetNFLPlayerStatMoments() moments=%+v err=%v", nflMoments, err)
+	}
+	err = AddNFLPIPPrediction([]NFLPIPPrediction{{PlayerIndex: receiver, Date: nflDate, Version: CurrNFLPIPPredVersion(), NFLAvg: NFLAvg{NumGames: 1, Snaps: 52, Targets: 9, Receptions: 6.5, RecYards: 77}}})
+	if err != nil {
+		t.Fatalf("AddNFLPIPPrediction() err=%v", err)
+	}
+	nflPred, err := GetNFLPlayerPIPPrediction(receiver, nflDate)
+	if err != nil || nflPred.PlayerIndex != receiver || nflPred.Receptions != 6.5 {
+		t.Fatalf("GetNFLPlayerPIPPrediction() pred=%+v err=%v", nflPred, err)
+	}
 }
@@ -1,6 +1,12 @@
 package players
 
-import "testing"
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
 
 func TestPlayerNameToIndex_HardcodedMappings(t *testing.T) {
 	nameMap := map[string]string{}
@@ -48,3 +54,38 @@ func TestPlayerNameToIndex_UsesCacheBeforeDBLookup(t *testing.T) {
 		t.Fatalf("normalized cached result = %q, want jokicni01", got2)
 	}
 }
+
+func TestGetStatsForGamesRejectsUnsupportedSport(t *testing.T) {
+	if _, err := GetStatsForGames(context.Background(), sports.Sport("cricket"), []string{"1"}); err == nil {
+		t.Fatalf("GetStatsForGames() should reject an unsupported sport")
+	}
+}
+
+func TestSeasonYear(t *testing.T) {
+	date := time.Date(2024, 11, 5, 0, 0, 0, 0, time.UTC)
+	if got := SeasonYear(sports.NBA, date); got != 2025 {
+		t.Fatalf("SeasonYear(nba) = %d, want 2025", got)
+	}
+	if got := SeasonYear(sports.WNBA, date); got != 2024 {
+		t.Fatalf("SeasonYear(wnba) = %d, want 2024", got)
+	}
+	if got := SeasonYear(sports.NHL, date); got != 2025 {
+		t.Fatalf("SeasonYear(nhl) = %d, want 2025", got)
+	}
+	if got := SeasonYear(sports.NFL, date); got != 2024 {
+		t.Fatalf("SeasonYear(nfl) = %d, want 2024", got)
+	}
+	if got := SeasonYear(sports.NFL, time.Date(2025, 2, 9, 0, 0, 0, 0, time.UTC)); got != 2024 {
+		t.Fatalf("SeasonYear(nfl) for the Super Bowl = %d, want 2024", got)
+	}
+}
+
+func TestBasketballStatsRejectOtherSports(t *testing.T) {
+	date := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := GetPlayerStats(sports.MLB, "p1", date, date); err == nil {
+		t.Fatalf("GetPlayerStats() should reject a sport without basketball tables")
+	}
+	if _, err := GetPlayerPIPPrediction(sports.NHL, "p1", date); err == nil {
+		t.Fatalf("GetPlayerPIPPrediction() should reject a sport without basketball tables")
+	}
+}
@@ -1,6 +1,10 @@
 package players
 
-import "time"
+import (
+	"time"
+
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
 
 type Player struct {
 	Index   string            `json:"index"`
@@ -67,6 +71,45 @@ type MLBPlayerGamePitching struct {
 	WPA          float32 `json:"wpa"`
 }
 
+// NHLPlayerGame is a player's line from a game. Skaters and goalies share
+// the row, goalies filling in the shots they faced.
+type NHLPlayerGame struct {
+	PlayerIndex  string  `json:"player_index"`
+	Game         int     `json:"game"`
+	TeamIndex    string  `json:"team_index"`
+	TimeOnIce    float32 `json:"time_on_ice"`
+	Goals        int     `json:"goals"`
+	Assists      int     `json:"assists"`
+	Points       int     `json:"points"`
+	Shots        int     `json:"shots"`
+	PlusMinus    int     `json:"plus_minus"`
+	PenaltyMins  int     `json:"pen_min"`
+	ShotsAgainst int     `json:"shots_against"`
+	Saves        int     `json:"saves"`
+	GoalsAgainst int     `json:"goals_against"`
+}
+
+// NFLPlayerGame is an offensive player's line from a game. Snaps are the
+// offensive snaps they were on the field for.
+type NFLPlayerGame struct {
+	PlayerIndex     string `json:"player_index"`
+	Game            int    `json:"game"`
+	TeamIndex       string `json:"team_index"`
+	Snaps           int    `json:"snaps"`
+	PassCompletions int    `json:"pass_completions"`
+	PassAttempts    int    `json:"pass_attempts"`
+	PassYards       int    `json:"pass_yards"`
+	PassTDs         int    `json:"pass_tds"`
+	Interceptions   int    `json:"interceptions"`
+	RushAttempts    int    `json:"rush_attempts"`
+	RushYards       int    `json:"rush_yards"`
+	RushTDs         int    `json:"rush_tds"`
+	Targets         int    `json:"targets"`
+	Receptions      int    `json:"receptions"`
+	RecYards        int    `json:"rec_yards"`
+	RecTDs          int    `json:"rec_tds"`
+}
+
 type MLBPlayByPlay struct {
 	BatterIndex  string `json:"batter_index"`
 	PitcherIndex string `json:"pitcher_index"`
@@ -101,6 +144,38 @@ type NBAPIPPrediction struct {
 	Drtg        float32   `json:"drtg"`
 }
 
+// MLBPIPPrediction is a batter's predicted line for a date. Predictions are
+// kept apart from NBAPIPPrediction since the stats share nothing.
+type MLBPIPPrediction struct {
+	PlayerIndex string    `json:"player_index"`
+	Date        time.Time `json:"date"`
+	Version     int       `json:"version"`
+	MLBBattingAvg
+}
+
+// NHLPIPPrediction is a skater's or goalie's predicted line for a date
+type NHLPIPPrediction struct {
+	PlayerIndex string    `json:"player_index"`
+	Date        time.Time `json:"date"`
+	Version     int       `json:"version"`
+	NHLAvg
+}
+
+// NFLPIPPrediction is an offensive player's predicted line for a date
+type NFLPIPPrediction struct {
+	PlayerIndex string    `json:"player_index"`
+	Date        time.Time `json:"date"`
+	Version     int       `json:"version"`
+	NFLAvg
+}
+
+// StatMoments summarizes a stat's spread across a player's games
+type StatMoments struct {
+	NumGames int     `json:"num_games"`
+	Mean     float32 `json:"mean"`
+	Variance float32 `json:"variance"`
+}
+
 type PlayerRoster struct {
 	Id          int     `json:"id"`
 	Sport       string  `json:"sport"`
@@ -113,3 +188,28 @@ type PlayerRoster struct {
 func CurrNBAPIPPredVersion() int {
 	return 1
 }
+
+func CurrMLBPIPPredVersion() int {
+	return 1
+}
+
+func CurrNHLPIPPredVersion() int {
+	return 1
+}
+
+func CurrNFLPIPPredVersion() int {
+	return 1
+}
+
+// PIPPredVersion is the current prediction version for the sport
+func PIPPredVersion(sport sports.Sport) int {
+	switch sport {
+	case sports.MLB:
+		return CurrMLBPIPPredVersion()
+	case sports.NHL:
+		return CurrNHLPIPPredVersion()
+	case sports.NFL:
+		return CurrNFLPIPPredVersion()
+	}
+	return CurrNBAPIPPredVersion()
+}
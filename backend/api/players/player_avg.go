@@ -4,6 +4,24 @@ func getStatPchange(controlStat float32, newStat float32) float32 {
     return (newStat - controlStat) / controlStat
 }
 
+// getStatPchangeOrZero is getStatPchange for stats a player may never record,
+// like a skater's saves, where there's no change to measure
+func getStatPchangeOrZero(controlStat float32, newStat float32) float32 {
+    if controlStat == 0 {
+        return 0
+    }
+    return getStatPchange(controlStat, newStat)
+}
+
+// perOrZero is the stat per unit of opportunity, or zero when the player had
+// no opportunities
+func perOrZero(stat float32, opportunities float32) float32 {
+    if opportunities == 0 {
+        return 0
+    }
+    return stat / opportunities
+}
+
 type PlayerAvg interface {
     IsValid() bool
     AddAvg(PlayerAvg) PlayerAvg
@@ -12,6 +30,12 @@ type PlayerAvg interface {
     ConvertToPer() PlayerAvg
     ConvertToStats() PlayerAvg
     GetStats() map[string]float32
+    // GamesPlayed is the number of games behind the average
+    GamesPlayed() int
+    // PlayingTime is how much the player is on the field each game, minutes
+    // for basketball, plate appearances for baseball, time on ice for hockey
+    // and offensive snaps for football
+    PlayingTime() float32
 }
 
 type NBAAvg struct {
@@ -30,6 +54,14 @@ func (n NBAAvg) IsValid() bool {
     return n.NumGames > 0
 }
 
+func (n NBAAvg) GamesPlayed() int {
+    return n.NumGames
+}
+
+func (n NBAAvg) PlayingTime() float32 {
+    return n.Minutes
+}
+
 func (n NBAAvg) GetStats() map[string]float32 {
     return map[string]float32{
         "minutes": n.Minutes,
@@ -156,6 +188,14 @@ func (m MLBBattingAvg) IsValid() bool {
     return m.NumGames > 0
 }
 
+func (m MLBBattingAvg) GamesPlayed() int {
+    return m.NumGames
+}
+
+func (m MLBBattingAvg) PlayingTime() float32 {
+    return m.PAs
+}
+
 func (m MLBBattingAvg) GetStats() map[string]float32 {
     return map[string]float32{
         "at_bats": m.AtBats,
@@ -298,3 +338,288 @@ func (m MLBBattingAvg) PredictStats(pipFactor PlayerAvg) PlayerAvg {
         WPA: (m.WPA + m.WPA * mlbPip.WPA) * predictedPAs,
     }
 }
+
+// NHLAvg is a skater's or goalie's average line. Per stats are per minute on
+// the ice.
+type NHLAvg struct {
+    NumGames        int         `json:"num_games"`
+    TimeOnIce       float32     `json:"avg_time_on_ice"`
+    Goals           float32     `json:"avg_goals"`
+    Assists         float32     `json:"avg_assists"`
+    Points          float32     `json:"avg_points"`
+    Shots           float32     `json:"avg_shots"`
+    ShotsAgainst    float32     `json:"avg_shots_against"`
+    Saves           float32     `json:"avg_saves"`
+    GoalsAgainst    float32     `json:"avg_goals_against"`
+}
+
+func (h NHLAvg) IsValid() bool {
+    return h.NumGames > 0
+}
+
+func (h NHLAvg) GamesPlayed() int {
+    return h.NumGames
+}
+
+func (h NHLAvg) PlayingTime() float32 {
+    return h.TimeOnIce
+}
+
+func (h NHLAvg) GetStats() map[string]float32 {
+    return map[string]float32{
+        "time_on_ice": h.TimeOnIce,
+        "goals": h.Goals,
+        "assists": h.Assists,
+        "points": h.Points,
+        "shots": h.Shots,
+        "shots_against": h.ShotsAgainst,
+        "saves": h.Saves,
+        "goals_against": h.GoalsAgainst,
+    }
+}
+
+func (h NHLAvg) AddAvg(a PlayerAvg) PlayerAvg {
+    if !a.IsValid() {
+        return h
+    }
+    nhl := a.(NHLAvg)
+    total_games := float32(h.NumGames + nhl.NumGames)
+    return NHLAvg{
+        NumGames: h.NumGames + nhl.NumGames,
+        TimeOnIce: (h.TimeOnIce * float32(h.NumGames) + nhl.TimeOnIce * float32(nhl.NumGames)) / total_games,
+        Goals: (h.Goals * float32(h.NumGames) + nhl.Goals * float32(nhl.NumGames)) / total_games,
+        Assists: (h.Assists * float32(h.NumGames) + nhl.Assists * float32(nhl.NumGames)) / total_games,
+        Points: (h.Points * float32(h.NumGames) + nhl.Points * float32(nhl.NumGames)) / total_games,
+        Shots: (h.Shots * float32(h.NumGames) + nhl.Shots * float32(nhl.NumGames)) / total_games,
+        ShotsAgainst: (h.ShotsAgainst * float32(h.NumGames) + nhl.ShotsAgainst * float32(nhl.NumGames)) / total_games,
+        Saves: (h.Saves * float32(h.NumGames) + nhl.Saves * float32(nhl.NumGames)) / total_games,
+        GoalsAgainst: (h.GoalsAgainst * float32(h.NumGames) + nhl.GoalsAgainst * float32(nhl.NumGames)) / total_games,
+    }
+}
+
+func (h NHLAvg) CompareAvg(controlAvg PlayerAvg) PlayerAvg {
+    if !h.IsValid() {
+        return h
+    }
+    nhlControl := controlAvg.(NHLAvg)
+    // Skaters never make saves, goalies rarely score and scratched players
+    // never see the ice, so zeros are compared as no change
+    return NHLAvg{
+        NumGames: h.NumGames,
+        TimeOnIce: getStatPchangeOrZero(nhlControl.TimeOnIce, h.TimeOnIce),
+        Goals: getStatPchangeOrZero(nhlControl.Goals, h.Goals),
+        Assists: getStatPchangeOrZero(nhlControl.Assists, h.Assists),
+        Points: getStatPchangeOrZero(nhlControl.Points, h.Points),
+        Shots: getStatPchangeOrZero(nhlControl.Shots, h.Shots),
+        ShotsAgainst: getStatPchangeOrZero(nhlControl.ShotsAgainst, h.ShotsAgainst),
+        Saves: getStatPchangeOrZero(nhlControl.Saves, h.Saves),
+        GoalsAgainst: getStatPchangeOrZero(nhlControl.GoalsAgainst, h.GoalsAgainst),
+    }
+}
+
+func (h NHLAvg) ConvertToPer() PlayerAvg {
+    if h.IsValid() {
+        return NHLAvg{
+            NumGames: h.NumGames,
+            TimeOnIce: h.TimeOnIce,
+            Goals: perOrZero(h.Goals, h.TimeOnIce),
+            Assists: perOrZero(h.Assists, h.TimeOnIce),
+            Points: perOrZero(h.Points, h.TimeOnIce),
+            Shots: perOrZero(h.Shots, h.TimeOnIce),
+            ShotsAgainst: perOrZero(h.ShotsAgainst, h.TimeOnIce),
+            Saves: perOrZero(h.Saves, h.TimeOnIce),
+            GoalsAgainst: perOrZero(h.GoalsAgainst, h.TimeOnIce),
+        }
+    } else {
+        return h
+    }
+}
+
+func (h NHLAvg) ConvertToStats() PlayerAvg {
+    if h.IsValid() {
+        return NHLAvg{
+            NumGames: h.NumGames,
+            TimeOnIce: h.TimeOnIce,
+            Goals: h.Goals * h.TimeOnIce,
+            Assists: h.Assists * h.TimeOnIce,
+            Points: h.Points * h.TimeOnIce,
+            Shots: h.Shots * h.TimeOnIce,
+            ShotsAgainst: h.ShotsAgainst * h.TimeOnIce,
+            Saves: h.Saves * h.TimeOnIce,
+            GoalsAgainst: h.GoalsAgainst * h.TimeOnIce,
+        }
+    } else {
+        return h
+    }
+}
+
+func (h NHLAvg) PredictStats(pipFactor PlayerAvg) PlayerAvg {
+    nhlPip := pipFactor.(NHLAvg)
+    predictedTOI := h.TimeOnIce + h.TimeOnIce * nhlPip.TimeOnIce
+
+    return NHLAvg{
+        NumGames: nhlPip.NumGames,
+        TimeOnIce: predictedTOI,
+        Goals: (h.Goals + h.Goals * nhlPip.Goals) * predictedTOI,
+        Assists: (h.Assists + h.Assists * nhlPip.Assists) * predictedTOI,
+        Points: (h.Points + h.Points * nhlPip.Points) * predictedTOI,
+        Shots: (h.Shots + h.Shots * nhlPip.Shots) * predictedTOI,
+        ShotsAgainst: (h.ShotsAgainst + h.ShotsAgainst * nhlPip.ShotsAgainst) * predictedTOI,
+        Saves: (h.Saves + h.Saves * nhlPip.Saves) * predictedTOI,
+        GoalsAgainst: (h.GoalsAgainst + h.GoalsAgainst * nhlPip.GoalsAgainst) * predictedTOI,
+    }
+}
+
+// NFLAvg is an offensive player's average line. Per stats are per snap, except
+// receptions and receiving yards which are per target.
+type NFLAvg struct {
+    NumGames            int         `json:"num_games"`
+    Snaps               float32     `json:"avg_snaps"`
+    PassAttempts        float32     `json:"avg_pass_attempts"`
+    PassCompletions     float32     `json:"avg_pass_completions"`
+    PassYards           float32     `json:"avg_pass_yards"`
+    PassTDs             float32     `json:"avg_pass_tds"`
+    RushAttempts        float32     `json:"avg_rush_attempts"`
+    RushYards           float32     `json:"avg_rush_yards"`
+    Targets             float32     `json:"avg_targets"`
+    Receptions          float32     `json:"avg_receptions"`
+    RecYards            float32     `json:"avg_rec_yards"`
+    Touchdowns          float32     `json:"avg_touchdowns"`
+}
+
+func (f NFLAvg) IsValid() bool {
+    return f.NumGames > 0
+}
+
+func (f NFLAvg) GamesPlayed() int {
+    return f.NumGames
+}
+
+func (f NFLAvg) PlayingTime() float32 {
+    return f.Snaps
+}
+
+func (f NFLAvg) GetStats() map[string]float32 {
+    return map[string]float32{
+        "snaps": f.Snaps,
+        "pass_attempts": f.PassAttempts,
+        "pass_completions": f.PassCompletions,
+        "pass_yards": f.PassYards,
+        "pass_tds": f.PassTDs,
+        "rush_attempts": f.RushAttempts,
+        "rush_yards": f.RushYards,
+        "targets": f.Targets,
+        "receptions": f.Receptions,
+        "rec_yards": f.RecYards,
+        "touchdowns": f.Touchdowns,
+    }
+}
+
+func (f NFLAvg) AddAvg(a PlayerAvg) PlayerAvg {
+    if !a.IsValid() {
+        return f
+    }
+    nfl := a.(NFLAvg)
+    total_games := float32(f.NumGames + nfl.NumGames)
+    return NFLAvg{
+        NumGames: f.NumGames + nfl.NumGames,
+        Snaps: (f.Snaps * float32(f.NumGames) + nfl.Snaps * float32(nfl.NumGames)) / total_games,
+        PassAttempts: (f.PassAttempts * float32(f.NumGames) + nfl.PassAttempts * float32(nfl.NumGames)) / total_games,
+        PassCompletions: (f.PassCompletions * float32(f.NumGames) + nfl.PassCompletions * float32(nfl.NumGames)) / total_games,
+        PassYards: (f.PassYards * float32(f.NumGames) + nfl.PassYards * float32(nfl.NumGames)) / total_games,
+        PassTDs: (f.PassTDs * float32(f.NumGames) + nfl.PassTDs * float32(nfl.NumGames)) / total_games,
+        RushAttempts: (f.RushAttempts * float32(f.NumGames) + nfl.RushAttempts * float32(nfl.NumGames)) / total_games,
+        RushYards: (f.RushYards * float32(f.NumGames) + nfl.RushYards * float32(nfl.NumGames)) / total_games,
+        Targets: (f.Targets * float32(f.NumGames) + nfl.Targets * float32(nfl.NumGames)) / total_games,
+        Receptions: (f.Receptions * float32(f.NumGames) + nfl.Receptions * float32(nfl.NumGames)) / total_games,
+        RecYards: (f.RecYards * float32(f.NumGames) + nfl.RecYards * float32(nfl.NumGames)) / total_games,
+        Touchdowns: (f.Touchdowns * float32(f.NumGames) + nfl.Touchdowns * float32(nfl.NumGames)) / total_games,
+    }
+}
+
+func (f NFLAvg) CompareAvg(controlAvg PlayerAvg) PlayerAvg {
+    if !f.IsValid() {
+        return f
+    }
+    nflControl := controlAvg.(NFLAvg)
+    // Receivers rarely throw and quarterbacks rarely catch, so zeros are
+    // compared as no change
+    return NFLAvg{
+        NumGames: f.NumGames,
+        Snaps: getStatPchange(nflControl.Snaps, f.Snaps),
+        PassAttempts: getStatPchangeOrZero(nflControl.PassAttempts, f.PassAttempts),
+        PassCompletions: getStatPchangeOrZero(nflControl.PassCompletions, f.PassCompletions),
+        PassYards: getStatPchangeOrZero(nflControl.PassYards, f.PassYards),
+        PassTDs: getStatPchangeOrZero(nflControl.PassTDs, f.PassTDs),
+        RushAttempts: getStatPchangeOrZero(nflControl.RushAttempts, f.RushAttempts),
+        RushYards: getStatPchangeOrZero(nflControl.RushYards, f.RushYards),
+        Targets: getStatPchangeOrZero(nflControl.Targets, f.Targets),
+        Receptions: getStatPchangeOrZero(nflControl.Receptions, f.Receptions),
+        RecYards: getStatPchangeOrZero(nflControl.RecYards, f.RecYards),
+        Touchdowns: getStatPchangeOrZero(nflControl.Touchdowns, f.Touchdowns),
+    }
+}
+
+func (f NFLAvg) ConvertToPer() PlayerAvg {
+    if f.IsValid() {
+        return NFLAvg{
+            NumGames: f.NumGames,
+            Snaps: f.Snaps,
+            PassAttempts: perOrZero(f.PassAttempts, f.Snaps),
+            PassCompletions: perOrZero(f.PassCompletions, f.Snaps),
+            PassYards: perOrZero(f.PassYards, f.Snaps),
+            PassTDs: perOrZero(f.PassTDs, f.Snaps),
+            RushAttempts: perOrZero(f.RushAttempts, f.Snaps),
+            RushYards: perOrZero(f.RushYards, f.Snaps),
+            Targets: perOrZero(f.Targets, f.Snaps),
+            Receptions: perOrZero(f.Receptions, f.Targets),
+            RecYards: perOrZero(f.RecYards, f.Targets),
+            Touchdowns: perOrZero(f.Touchdowns, f.Snaps),
+        }
+    } else {
+        return f
+    }
+}
+
+func (f NFLAvg) ConvertToStats() PlayerAvg {
+    if f.IsValid() {
+        targets := f.Targets * f.Snaps
+        return NFLAvg{
+            NumGames: f.NumGames,
+            Snaps: f.Snaps,
+            PassAttempts: f.PassAttempts * f.Snaps,
+            PassCompletions: f.PassCompletions * f.Snaps,
+            PassYards: f.PassYards * f.Snaps,
+            PassTDs: f.PassTDs * f.Snaps,
+            RushAttempts: f.RushAttempts * f.Snaps,
+            RushYards: f.RushYards * f.Snaps,
+            Targets: targets,
+            Receptions: f.Receptions * targets,
+            RecYards: f.RecYards * targets,
+            Touchdowns: f.Touchdowns * f.Snaps,
+        }
+    } else {
+        return f
+    }
+}
+
+func (f NFLAvg) PredictStats(pipFactor PlayerAvg) PlayerAvg {
+    nflPip := pipFactor.(NFLAvg)
+    predictedSnaps := f.Snaps + f.Snaps * nflPip.Snaps
+    predictedTargets := (f.Targets + f.Targets * nflPip.Targets) * predictedSnaps
+
+    return NFLAvg{
+        NumGames: nflPip.NumGames,
+        Snaps: predictedSnaps,
+        PassAttempts: (f.PassAttempts + f.PassAttempts * nflPip.PassAttempts) * predictedSnaps,
+        PassCompletions: (f.PassCompletions + f.PassCompletions * nflPip.PassCompletions) * predictedSnaps,
+        PassYards: (f.PassYards + f.PassYards * nflPip.PassYards) * predictedSnaps,
+        PassTDs: (f.PassTDs + f.PassTDs * nflPip.PassTDs) * predictedSnaps,
+        RushAttempts: (f.RushAttempts + f.RushAttempts * nflPip.RushAttempts) * predictedSnaps,
+        RushYards: (f.RushYards + f.RushYards * nflPip.RushYards) * predictedSnaps,
+        Targets: predictedTargets,
+        Receptions: (f.Receptions + f.Receptions * nflPip.Receptions) * predictedTargets,
+        RecYards: (f.RecYards + f.RecYards * nflPip.RecYards) * predictedTargets,
+        Touchdowns: (f.Touchdowns + f.Touchdowns * nflPip.Touchdowns) * predictedSnaps,
+    }
+}
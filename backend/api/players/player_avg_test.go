@@ -96,3 +96,108 @@ func TestMLBAvgOperations(t *testing.T) {
 		t.Fatalf("PredictStats should increase PAs with positive factor")
 	}
 }
+
+func TestNHLAvgOperations(t *testing.T) {
+	skater := NHLAvg{NumGames: 2, TimeOnIce: 20, Goals: 1, Assists: 1, Points: 2, Shots: 4}
+	other := NHLAvg{NumGames: 2, TimeOnIce: 16, Goals: 0, Assists: 1, Points: 1, Shots: 2}
+
+	if !skater.IsValid() || (NHLAvg{}).IsValid() {
+		t.Fatalf("expected only averages with games to be valid")
+	}
+	if stats := skater.GetStats(); stats["shots"] != 4 || stats["saves"] != 0 {
+		t.Fatalf("GetStats() = %v", stats)
+	}
+
+	added := skater.AddAvg(other).(NHLAvg)
+	if added.NumGames != 4 || added.Shots != 3 {
+		t.Fatalf("AddAvg() = %+v", added)
+	}
+
+	cmp := other.CompareAvg(skater).(NHLAvg)
+	if cmp.Shots >= 0 {
+		t.Fatalf("expected shot comparison to be negative")
+	}
+	if cmp.Saves != 0 || cmp.GoalsAgainst != 0 {
+		t.Fatalf("expected stats a skater never records to compare as no change, got %+v", cmp)
+	}
+
+	per := skater.ConvertToPer().(NHLAvg)
+	if per.Shots != skater.Shots/skater.TimeOnIce {
+		t.Fatalf("ConvertToPer shots mismatch")
+	}
+	if raw := per.ConvertToStats().(NHLAvg); raw.Shots != per.Shots*per.TimeOnIce {
+		t.Fatalf("ConvertToStats shots mismatch")
+	}
+
+	scratched := NHLAvg{NumGames: 1, Shots: 1}
+	if per := scratched.ConvertToPer().(NHLAvg); per.Shots != 0 || per.Goals != 0 {
+		t.Fatalf("expected a player with no ice time to have no per-minute stats, got %+v", per)
+	}
+	if cmp := skater.CompareAvg(scratched).(NHLAvg); cmp.TimeOnIce != 0 {
+		t.Fatalf("expected no ice time in the control to compare as no change, got %+v", cmp)
+	}
+
+	goalie := NHLAvg{NumGames: 1, TimeOnIce: 60, ShotsAgainst: 0.5, Saves: 0.45, GoalsAgainst: 0.05}
+	pred := goalie.PredictStats(NHLAvg{NumGames: 3, Saves: 0.1}).(NHLAvg)
+	if pred.TimeOnIce != 60 || pred.Saves <= 27 || pred.NumGames != 3 {
+		t.Fatalf("PredictStats() = %+v", pred)
+	}
+}
+
+func TestNFLAvgOperations(t *testing.T) {
+	receiver := NFLAvg{NumGames: 2, Snaps: 50, Targets: 10, Receptions: 7, RecYards: 84, Touchdowns: 1}
+	other := NFLAvg{NumGames: 2, Snaps: 40, Targets: 6, Receptions: 3, RecYards: 30}
+
+	if !receiver.IsValid() || (NFLAvg{}).IsValid() {
+		t.Fatalf("expected only averages with games to be valid")
+	}
+	if stats := receiver.GetStats(); stats["rec_yards"] != 84 || stats["pass_yards"] != 0 {
+		t.Fatalf("GetStats() = %v", stats)
+	}
+
+	added := receiver.AddAvg(other).(NFLAvg)
+	if added.NumGames != 4 || added.Receptions != 5 || added.Snaps != 45 {
+		t.Fatalf("AddAvg() = %+v", added)
+	}
+
+	per := receiver.ConvertToPer().(NFLAvg)
+	if per.Targets != 0.2 || per.Receptions != 0.7 || per.RecYards != 8.4 || per.Touchdowns != 0.02 {
+		t.Fatalf("expected targets per snap and receptions per target, got %+v", per)
+	}
+	raw := per.ConvertToStats().(NFLAvg)
+	if raw.Targets != 10 || raw.Receptions != 7 || raw.Snaps != 50 {
+		t.Fatalf("ConvertToStats() = %+v", raw)
+	}
+	if noSnaps := (NFLAvg{NumGames: 1, RushYards: 12}).ConvertToPer().(NFLAvg); noSnaps.RushYards != 0 || noSnaps.Receptions != 0 {
+		t.Fatalf("expected a line without snaps to have no rates, got %+v", noSnaps)
+	}
+
+	cmp := other.ConvertToPer().CompareAvg(per).(NFLAvg)
+	if cmp.Receptions >= 0 || cmp.PassYards != 0 {
+		t.Fatalf("expected fewer catches per target and no change in stats never recorded, got %+v", cmp)
+	}
+
+	// Five more snaps and the same rates is one more target
+	pred := per.PredictStats(NFLAvg{NumGames: 3, Snaps: 0.1}).(NFLAvg)
+	if pred.NumGames != 3 || pred.Snaps != 55 || pred.Targets != 11 || pred.Receptions < 7.69 || pred.Receptions > 7.71 {
+		t.Fatalf("PredictStats() = %+v", pred)
+	}
+}
+
+func TestPlayerAvgSampleAndPlayingTime(t *testing.T) {
+	avgs := []struct {
+		avg         PlayerAvg
+		games       int
+		playingTime float32
+	}{
+		{avg: NBAAvg{NumGames: 12, Minutes: 31.5}, games: 12, playingTime: 31.5},
+		{avg: MLBBattingAvg{NumGames: 40, PAs: 4.2}, games: 40, playingTime: 4.2},
+		{avg: NHLAvg{NumGames: 20, TimeOnIce: 18.5}, games: 20, playingTime: 18.5},
+		{avg: NFLAvg{NumGames: 8, Snaps: 61}, games: 8, playingTime: 61},
+	}
+	for _, tt := range avgs {
+		if tt.avg.GamesPlayed() != tt.games || tt.avg.PlayingTime() != tt.playingTime {
+			t.Fatalf("%T GamesPlayed() = %d, PlayingTime() = %v", tt.avg, tt.avg.GamesPlayed(), tt.avg.PlayingTime())
+		}
+	}
+}
@@ -10,12 +10,23 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mgordon34/kornet-kover/api/picks"
 	"github.com/mgordon34/kornet-kover/internal/storage"
 )
 
 type StrategyServiceDeps struct {
-	GetStrategies func(userID int) ([]Strategy, error)
-	GetStrategy   func(strategyID int) (Strategy, error)
+	GetStrategies        func(userID int) ([]Strategy, error)
+	GetStrategy          func(strategyID int) (Strategy, error)
+	AddStrategy          func(strat Strategy) (int, error)
+	UpdateStrategy       func(strat Strategy) error
+	DeleteStrategy       func(strategyID int) error
+	GetStrategyFilters   func(strategyID int) ([]StrategyFilter, error)
+	GetStrategyFilter    func(strategyID int, filterID int) (StrategyFilter, error)
+	AddStrategyFilter    func(filter StrategyFilter) (int, error)
+	UpdateStrategyFilter func(filter StrategyFilter) error
+	DeleteStrategyFilter func(strategyID int, filterID int) error
+	GetGradedPicks       func(query picks.GradedPicksQuery) ([]picks.GradedPick, error)
 }
 
 type StrategyService struct {
@@ -29,21 +40,97 @@ func NewStrategyService(deps StrategyServiceDeps) *StrategyService {
 	if deps.GetStrategy == nil {
 		deps.GetStrategy = getStrategy
 	}
+	if deps.AddStrategy == nil {
+		deps.AddStrategy = addStrategy
+	}
+	if deps.UpdateStrategy == nil {
+		deps.UpdateStrategy = updateStrategy
+	}
+	if deps.DeleteStrategy == nil {
+		deps.DeleteStrategy = deleteStrategy
+	}
+	if deps.GetStrategyFilters == nil {
+		deps.GetStrategyFilters = GetStrategyFilters
+	}
+	if deps.GetStrategyFilter == nil {
+		deps.GetStrategyFilter = getStrategyFilter
+	}
+	if deps.AddStrategyFilter == nil {
+		deps.AddStrategyFilter = addStrategyFilter
+	}
+	if deps.UpdateStrategyFilter == nil {
+		deps.UpdateStrategyFilter = updateStrategyFilter
+	}
+	if deps.DeleteStrategyFilter == nil {
+		deps.DeleteStrategyFilter = deleteStrategyFilter
+	}
+	if deps.GetGradedPicks == nil {
+		deps.GetGradedPicks = picks.GetGradedPicks
+	}
 	return &StrategyService{deps: deps}
 }
 
+// ErrorResponse is the JSON body returned for every failed strategy request.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+func respondWithError(c *gin.Context, status int, err error) {
+	c.JSON(status, ErrorResponse{Error: err.Error()})
+}
+
+// statusForError maps storage errors to HTTP statuses: missing rows are 404s
+// and foreign key violations (unknown user, strategy still referenced by
+// prop_picks) are 409s.
+func statusForError(err error) int {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return http.StatusNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func parseIDParam(c *gin.Context, name string) (int, bool) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid %s id %q", name, c.Param(name)))
+		return 0, false
+	}
+	return id, true
+}
+
+func (s Strategy) Validate() error {
+	if s.UserId <= 0 {
+		return fmt.Errorf("user_id is required")
+	}
+	if s.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(s.Name) > 255 {
+		return fmt.Errorf("name must be at most 255 characters")
+	}
+	if s.Settings != nil {
+		if err := s.Settings.Validate(); err != nil {
+			return fmt.Errorf("invalid settings: %w", err)
+		}
+	}
+	return nil
+}
+
 func (s *StrategyService) GetStrategiesHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id, err := strconv.Atoi(c.Query("user_id"))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, err)
+			respondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid user_id %q", c.Query("user_id")))
 			return
 		}
-		log.Println(id)
 
 		strats, err := s.deps.GetStrategies(id)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, err)
+			respondWithError(c, statusForError(err), err)
 			return
 		}
 		c.JSON(http.StatusOK, strats)
@@ -52,32 +139,295 @@ func (s *StrategyService) GetStrategiesHandler() gin.HandlerFunc {
 
 func (s *StrategyService) GetStrategyHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		stratID, err := strconv.Atoi(c.Param("strat"))
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, err)
+		stratID, ok := parseIDParam(c, "strat")
+		if !ok {
 			return
 		}
-		log.Println(stratID)
 
 		strat, err := s.deps.GetStrategy(stratID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, err)
+			respondWithError(c, statusForError(err), err)
 			return
 		}
 		c.JSON(http.StatusOK, strat)
 	}
 }
 
+func (s *StrategyService) CreateStrategyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var strat Strategy
+		if err := c.ShouldBindJSON(&strat); err != nil {
+			respondWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		strat.Id = 0
+		if err := strat.Validate(); err != nil {
+			respondWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		id, err := s.deps.AddStrategy(strat)
+		if err != nil {
+			respondWithError(c, statusForError(err), err)
+			return
+		}
+		strat.Id = id
+		c.JSON(http.StatusCreated, strat)
+	}
+}
+
+// UpdateStrategyHandler serves both PUT and PATCH. PUT replaces the strategy
+// with the request body while PATCH only overwrites the fields present in it.
+func (s *StrategyService) UpdateStrategyHandler(partial bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stratID, ok := parseIDParam(c, "strat")
+		if !ok {
+			return
+		}
+
+		var strat Strategy
+		if partial {
+			existing, err := s.deps.GetStrategy(stratID)
+			if err != nil {
+				respondWithError(c, statusForError(err), err)
+				return
+			}
+			strat = existing
+		}
+		if err := c.ShouldBindJSON(&strat); err != nil {
+			respondWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		strat.Id = stratID
+		if err := strat.Validate(); err != nil {
+			respondWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.deps.UpdateStrategy(strat); err != nil {
+			respondWithError(c, statusForError(err), err)
+			return
+		}
+		c.JSON(http.StatusOK, strat)
+	}
+}
+
+func (s *StrategyService) DeleteStrategyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stratID, ok := parseIDParam(c, "strat")
+		if !ok {
+			return
+		}
+
+		if err := s.deps.DeleteStrategy(stratID); err != nil {
+			respondWithError(c, statusForError(err), err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (s *StrategyService) GetStrategyFiltersHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stratID, ok := parseIDParam(c, "strat")
+		if !ok {
+			return
+		}
+		if _, err := s.deps.GetStrategy(stratID); err != nil {
+			respondWithError(c, statusForError(err), err)
+			return
+		}
+
+		filters, err := s.deps.GetStrategyFilters(stratID)
+		if err != nil {
+			respondWithError(c, statusForError(err), err)
+			return
+		}
+		if filters == nil {
+			filters = []StrategyFilter{}
+		}
+		c.JSON(http.StatusOK, filters)
+	}
+}
+
+func (s *StrategyService) GetStrategyFilterHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stratID, ok := parseIDParam(c, "strat")
+		if !ok {
+			return
+		}
+		filterID, ok := parseIDParam(c, "filter")
+		if !ok {
+			return
+		}
+
+		filter, err := s.deps.GetStrategyFilter(stratID, filterID)
+		if err != nil {
+			respondWithError(c, statusForError(err), err)
+			return
+		}
+		c.JSON(http.StatusOK, filter)
+	}
+}
+
+func (s *StrategyService) CreateStrategyFilterHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stratID, ok := parseIDParam(c, "strat")
+		if !ok {
+			return
+		}
+
+		var filter StrategyFilter
+		if err := c.ShouldBindJSON(&filter); err != nil {
+			respondWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		filter.Id = 0
+		filter.StrategyId = stratID
+		if err := filter.Validate(); err != nil {
+			respondWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		if _, err := s.deps.GetStrategy(stratID); err != nil {
+			respondWithError(c, statusForError(err), err)
+			return
+		}
+
+		id, err := s.deps.AddStrategyFilter(filter)
+		if err != nil {
+			respondWithError(c, statusForError(err), err)
+			return
+		}
+		filter.Id = id
+		c.JSON(http.StatusCreated, filter)
+	}
+}
+
+// UpdateStrategyFilterHandler serves both PUT and PATCH for a single filter,
+// with the same replace/merge semantics as UpdateStrategyHandler.
+func (s *StrategyService) UpdateStrategyFilterHandler(partial bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stratID, ok := parseIDParam(c, "strat")
+		if !ok {
+			return
+		}
+		filterID, ok := parseIDParam(c, "filter")
+		if !ok {
+			return
+		}
+
+		var filter StrategyFilter
+		if partial {
+			existing, err := s.deps.GetStrategyFilter(stratID, filterID)
+			if err != nil {
+				respondWithError(c, statusForError(err), err)
+				return
+			}
+			filter = existing
+		}
+		if err := c.ShouldBindJSON(&filter); err != nil {
+			respondWithError(c, http.StatusBadRequest, err)
+			return
+		}
+		filter.Id = filterID
+		filter.StrategyId = stratID
+		if err := filter.Validate(); err != nil {
+			respondWithError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.deps.UpdateStrategyFilter(filter); err != nil {
+			respondWithError(c, statusForError(err), err)
+			return
+		}
+		c.JSON(http.StatusOK, filter)
+	}
+}
+
+func (s *StrategyService) DeleteStrategyFilterHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stratID, ok := parseIDParam(c, "strat")
+		if !ok {
+			return
+		}
+		filterID, ok := parseIDParam(c, "filter")
+		if !ok {
+			return
+		}
+
+		if err := s.deps.DeleteStrategyFilter(stratID, filterID); err != nil {
+			respondWithError(c, statusForError(err), err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// GetStrategyPerformanceHandler reports how a strategy's graded picks have
+// performed live, optionally between the start and end query dates. It only
+// reads picks that are already graded, which happens in the scraper's
+// UpdateGames run once a date's box scores are in, so picks for games it has
+// not scraped yet are left out.
+func (s *StrategyService) GetStrategyPerformanceHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stratID, ok := parseIDParam(c, "strat")
+		if !ok {
+			return
+		}
+		query := picks.GradedPicksQuery{StratId: stratID}
+		var err error
+		query.StartDate, err = picks.ParseOptionalDate(c.Query("start"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid start date %q", c.Query("start")))
+			return
+		}
+		query.EndDate, err = picks.ParseOptionalDate(c.Query("end"))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, fmt.Errorf("invalid end date %q", c.Query("end")))
+			return
+		}
+
+		if _, err := s.deps.GetStrategy(stratID); err != nil {
+			respondWithError(c, statusForError(err), err)
+			return
+		}
+		graded, err := s.deps.GetGradedPicks(query)
+		if err != nil {
+			respondWithError(c, statusForError(err), err)
+			return
+		}
+		c.JSON(http.StatusOK, picks.BuildPerformanceReport(graded))
+	}
+}
+
+// RegisterRoutes mounts the strategy and nested filter routes on r.
+func (s *StrategyService) RegisterRoutes(r gin.IRouter) {
+	r.GET("/strategies", s.GetStrategiesHandler())
+	r.POST("/strategies", s.CreateStrategyHandler())
+	r.GET("/strategies/:strat", s.GetStrategyHandler())
+	r.PUT("/strategies/:strat", s.UpdateStrategyHandler(false))
+	r.PATCH("/strategies/:strat", s.UpdateStrategyHandler(true))
+	r.DELETE("/strategies/:strat", s.DeleteStrategyHandler())
+	r.GET("/strategies/:strat/performance", s.GetStrategyPerformanceHandler())
+
+	r.GET("/strategies/:strat/filters", s.GetStrategyFiltersHandler())
+	r.POST("/strategies/:strat/filters", s.CreateStrategyFilterHandler())
+	r.GET("/strategies/:strat/filters/:filter", s.GetStrategyFilterHandler())
+	r.PUT("/strategies/:strat/filters/:filter", s.UpdateStrategyFilterHandler(false))
+	r.PATCH("/strategies/:strat/filters/:filter", s.UpdateStrategyFilterHandler(true))
+	r.DELETE("/strategies/:strat/filters/:filter", s.DeleteStrategyFilterHandler())
+}
+
 func addStrategy(strat Strategy) (int, error) {
 	db := storage.GetDB()
 
 	sqlStmt := `
-	INSERT INTO strategies (user_id, name)
-	VALUES ($1, $2)
+	INSERT INTO strategies (user_id, name, settings)
+	VALUES ($1, $2, $3)
 	ON CONFLICT DO NOTHING
     RETURNING ID`
 	var resId int
-	err := db.QueryRow(context.Background(), sqlStmt, strat.UserId, strat.Name).Scan(&resId)
+	err := db.QueryRow(context.Background(), sqlStmt, strat.UserId, strat.Name, strat.Settings).Scan(&resId)
 	if err != nil {
 		return 0, err
 	}
@@ -121,7 +471,7 @@ func getStrategy(stratId int) (Strategy, error) {
 	}
 	strat, err := pgx.CollectExactlyOneRow(row, pgx.RowToStructByName[Strategy])
 	if err != nil {
-		return strat, errors.New(fmt.Sprintf("Error getting strategy %d: %v", stratId, err))
+		return strat, fmt.Errorf("error getting strategy %d: %w", stratId, err)
 	}
 
 	return strat, nil
@@ -130,3 +480,216 @@ func getStrategy(stratId int) (Strategy, error) {
 func GetStrategy(c *gin.Context) {
 	NewStrategyService(StrategyServiceDeps{}).GetStrategyHandler()(c)
 }
+
+func updateStrategy(strat Strategy) error {
+	db := storage.GetDB()
+
+	sql := `
+    UPDATE strategies
+    SET user_id = ($2), name = ($3), settings = ($4)
+    WHERE id = ($1)`
+
+	tag, err := db.Exec(context.Background(), sql, strat.Id, strat.UserId, strat.Name, strat.Settings)
+	if err != nil {
+		return fmt.Errorf("error updating strategy %d: %w", strat.Id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("error updating strategy %d: %w", strat.Id, pgx.ErrNoRows)
+	}
+	log.Printf("Updated strategy: %v", strat)
+	return nil
+}
+
+func deleteStrategy(stratId int) error {
+	db := storage.GetDB()
+
+	txn, err := db.Begin(context.Background())
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback(context.Background())
+
+	_, err = txn.Exec(context.Background(), `DELETE FROM strategy_filters WHERE strategy_id = ($1)`, stratId)
+	if err != nil {
+		return fmt.Errorf("error deleting filters for strategy %d: %w", stratId, err)
+	}
+	tag, err := txn.Exec(context.Background(), `DELETE FROM strategies WHERE id = ($1)`, stratId)
+	if err != nil {
+		return fmt.Errorf("error deleting strategy %d: %w", stratId, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("error deleting strategy %d: %w", stratId, pgx.ErrNoRows)
+	}
+
+	if err := txn.Commit(context.Background()); err != nil {
+		return err
+	}
+	log.Printf("Deleted strategy: %d", stratId)
+	return nil
+}
+
+func getStrategyFilter(stratId int, filterId int) (StrategyFilter, error) {
+	db := storage.GetDB()
+
+	sql := `
+    SELECT id, strategy_id, function, stat, operator, comparison_type, compare_value::float8,
+    compare_function, compare_stat, modifier_operator from strategy_filters
+    WHERE strategy_id = ($1) AND id = ($2)`
+
+	row, err := db.Query(context.Background(), sql, stratId, filterId)
+	if err != nil {
+		return StrategyFilter{}, fmt.Errorf("error querying filter %d for strategy %d: %w", filterId, stratId, err)
+	}
+	filter, err := pgx.CollectExactlyOneRow(row, pgx.RowToStructByPos[StrategyFilter])
+	if err != nil {
+		return filter, fmt.Errorf("error getting filter %d for strategy %d: %w", filterId, stratId, err)
+	}
+
+	return filter, nil
+}
+
+func addStrategyFilter(filter StrategyFilter) (int, error) {
+	db := storage.GetDB()
+
+	sqlStmt := `
+    INSERT INTO strategy_filters (strategy_id, function, stat, operator, comparison_type, compare_value,
+    compare_function, compare_stat, modifier_operator)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    RETURNING ID`
+	var resId int
+	err := db.QueryRow(
+		context.Background(),
+		sqlStmt,
+		filter.StrategyId,
+		filter.Function,
+		filter.Stat,
+		filter.Operator,
+		filter.ComparisonType,
+		filter.CompareValue,
+		filter.CompareFunction,
+		filter.CompareStat,
+		filter.ModifierOperator,
+	).Scan(&resId)
+	if err != nil {
+		return 0, fmt.Errorf("error adding filter for strategy %d: %w", filter.StrategyId, err)
+	}
+	log.Printf("Added strategy filter: %v", filter)
+	return resId, nil
+}
+
+func updateStrategyFilter(filter StrategyFilter) error {
+	db := storage.GetDB()
+
+	sql := `
+    UPDATE strategy_filters
+    SET function = ($3), stat = ($4), operator = ($5), comparison_type = ($6), compare_value = ($7),
+    compare_function = ($8), compare_stat = ($9), modifier_operator = ($10)
+    WHERE strategy_id = ($1) AND id = ($2)`
+
+	tag, err := db.Exec(
+		context.Background(),
+		sql,
+		filter.StrategyId,
+		filter.Id,
+		filter.Function,
+		filter.Stat,
+		filter.Operator,
+		filter.ComparisonType,
+		filter.CompareValue,
+		filter.CompareFunction,
+		filter.CompareStat,
+		filter.ModifierOperator,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating filter %d: %w", filter.Id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("error updating filter %d: %w", filter.Id, pgx.ErrNoRows)
+	}
+	log.Printf("Updated strategy filter: %v", filter)
+	return nil
+}
+
+func deleteStrategyFilter(stratId int, filterId int) error {
+	db := storage.GetDB()
+
+	sql := `
+    DELETE FROM strategy_filters
+    WHERE strategy_id = ($1) AND id = ($2)`
+
+	tag, err := db.Exec(context.Background(), sql, stratId, filterId)
+	if err != nil {
+		return fmt.Errorf("error deleting filter %d: %w", filterId, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("error deleting filter %d: %w", filterId, pgx.ErrNoRows)
+	}
+	log.Printf("Deleted strategy filter: %d", filterId)
+	return nil
+}
+
+func GetStrategyByID(stratId int) (Strategy, error) {
+	return getStrategy(stratId)
+}
+
+func GetStrategyFilters(stratId int) ([]StrategyFilter, error) {
+	db := storage.GetDB()
+
+	sql := `
+    SELECT id, strategy_id, function, stat, operator, comparison_type, compare_value::float8,
+    compare_function, compare_stat, modifier_operator from strategy_filters
+    WHERE strategy_id = ($1)
+    ORDER BY id`
+
+	row, err := db.Query(context.Background(), sql, stratId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying filters for strategy %d: %w", stratId, err)
+	}
+	filters, err := pgx.CollectRows(row, pgx.RowToStructByPos[StrategyFilter])
+	if err != nil {
+		return filters, fmt.Errorf("error getting filters for strategy %d: %w", stratId, err)
+	}
+
+	return filters, nil
+}
+
+// GetConfiguredStrategies returns every user's strategies that can be run as
+// a PropSelector, i.e. those with stored settings or at least one filter.
+func GetConfiguredStrategies() ([]Strategy, error) {
+	db := storage.GetDB()
+
+	sql := `
+    SELECT s.id, s.user_id, s.name, s.settings from strategies s
+    WHERE s.settings IS NOT NULL
+    OR EXISTS (SELECT 1 FROM strategy_filters sf WHERE sf.strategy_id = s.id)
+    ORDER BY s.id`
+
+	row, err := db.Query(context.Background(), sql)
+	if err != nil {
+		return nil, fmt.Errorf("error querying configured strategies: %w", err)
+	}
+	strats, err := pgx.CollectRows(row, pgx.RowToStructByName[Strategy])
+	if err != nil {
+		return strats, fmt.Errorf("error getting configured strategies: %w", err)
+	}
+
+	return strats, nil
+}
+
+func SetStrategySettings(stratId int, settings StrategySettings) error {
+	db := storage.GetDB()
+
+	sql := `
+    UPDATE strategies
+    SET settings = ($2)
+    WHERE id = ($1)`
+
+	tag, err := db.Exec(context.Background(), sql, stratId, settings)
+	if err != nil {
+		return fmt.Errorf("error setting settings for strategy %d: %w", stratId, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("error setting settings for strategy %d: %w", stratId, pgx.ErrNoRows)
+	}
+	return nil
+}
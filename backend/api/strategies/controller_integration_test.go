@@ -6,12 +6,14 @@ package strategies
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/mgordon34/kornet-kover/internal/storage"
 )
 
@@ -83,3 +85,181 @@ func TestGetStrategiesHandlerSuccess(t *testing.T) {
 		t.Fatalf("failed decoding response: %v", err)
 	}
 }
+
+func TestGetStrategyFiltersAndConfiguredStrategies(t *testing.T) {
+	storage.UseLocalDBForIntegrationTests(t)
+	storage.InitTables()
+	db := storage.GetDB()
+
+	userID := createTestUser(t)
+	stratID, err := addStrategy(Strategy{UserId: userID, Name: "Filtered Strategy"})
+	if err != nil {
+		t.Fatalf("addStrategy() error = %v", err)
+	}
+
+	_, err = db.Exec(context.Background(), `INSERT INTO strategy_filters (strategy_id, function, stat, operator, comparison_type, compare_value, compare_function, modifier_operator)
+        VALUES ($1, 'prediction', 'points', '>', 'modified', 1.2, 'line', '*')`, stratID)
+	if err != nil {
+		t.Fatalf("insert strategy filter error = %v", err)
+	}
+
+	filters, err := GetStrategyFilters(stratID)
+	if err != nil {
+		t.Fatalf("GetStrategyFilters() error = %v", err)
+	}
+	if len(filters) != 1 || filters[0].Validate() != nil || *filters[0].ModifierOperator != Multiply {
+		t.Fatalf("unexpected filters: %+v", filters)
+	}
+
+	strats, err := GetConfiguredStrategies()
+	if err != nil {
+		t.Fatalf("GetConfiguredStrategies() error = %v", err)
+	}
+	found := false
+	for _, strat := range strats {
+		if strat.Id == stratID {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("GetConfiguredStrategies() missing strategy %d", stratID)
+	}
+}
+
+func TestStrategyAndFilterCRUD(t *testing.T) {
+	storage.UseLocalDBForIntegrationTests(t)
+	storage.InitTables()
+
+	userID := createTestUser(t)
+	stratID, err := addStrategy(Strategy{UserId: userID, Name: "CRUD Strategy"})
+	if err != nil {
+		t.Fatalf("addStrategy() error = %v", err)
+	}
+
+	if err := updateStrategy(Strategy{Id: stratID, UserId: userID, Name: "CRUD Renamed"}); err != nil {
+		t.Fatalf("updateStrategy() error = %v", err)
+	}
+
+	value := 25.0
+	filterID, err := addStrategyFilter(StrategyFilter{StrategyId: stratID, Function: PredictionFunction, Stat: "points", Operator: GreaterThan, ComparisonType: ValueComparison, CompareValue: &value})
+	if err != nil {
+		t.Fatalf("addStrategyFilter() error = %v", err)
+	}
+
+	filter, err := getStrategyFilter(stratID, filterID)
+	if err != nil {
+		t.Fatalf("getStrategyFilter() error = %v", err)
+	}
+	filter.Operator = LessThan
+	if err := updateStrategyFilter(filter); err != nil {
+		t.Fatalf("updateStrategyFilter() error = %v", err)
+	}
+	if err := deleteStrategyFilter(stratID, filterID); err != nil {
+		t.Fatalf("deleteStrategyFilter() error = %v", err)
+	}
+	if err := deleteStrategyFilter(stratID, filterID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("deleteStrategyFilter() twice error = %v, want ErrNoRows", err)
+	}
+
+	if err := deleteStrategy(stratID); err != nil {
+		t.Fatalf("deleteStrategy() error = %v", err)
+	}
+	if _, err := getStrategy(stratID); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("getStrategy() after delete error = %v, want ErrNoRows", err)
+	}
+}
+
+func TestStrategySettingsRoundTrip(t *testing.T) {
+	storage.UseLocalDBForIntegrationTests(t)
+	storage.InitTables()
+
+	userID := createTestUser(t)
+	stratID, err := addStrategy(Strategy{UserId: userID, Name: "Settings Strategy"})
+	if err != nil {
+		t.Fatalf("addStrategy() error = %v", err)
+	}
+
+	strat, err := GetStrategyByID(stratID)
+	if err != nil {
+		t.Fatalf("GetStrategyByID() error = %v", err)
+	}
+	if strat.Settings != nil {
+		t.Fatalf("new strategy should have no settings, got %+v", strat.Settings)
+	}
+
+	minOdds, maxOver, maxUnder := 200, 100, 0
+	settings := StrategySettings{
+		LineType:      AlternateLines,
+		Thresholds:    map[string]float32{"points": -.3},
+		ThresholdType: PercentThreshold,
+		MinOdds:       &minOdds,
+		MaxOdds:       600,
+		MaxOver:       &maxOver,
+		MaxUnder:      &maxUnder,
+	}
+	if err := SetStrategySettings(stratID, settings); err != nil {
+		t.Fatalf("SetStrategySettings() error = %v", err)
+	}
+
+	strat, err = GetStrategyByID(stratID)
+	if err != nil {
+		t.Fatalf("GetStrategyByID() error = %v", err)
+	}
+	if strat.Settings == nil || strat.Settings.LineType != AlternateLines || strat.Settings.Thresholds["points"] != -.3 || strat.Settings.MaxOdds != 600 || strat.Settings.MaxUnder == nil || *strat.Settings.MaxUnder != 0 {
+		t.Fatalf("unexpected settings: %+v", strat.Settings)
+	}
+
+	strats, err := GetConfiguredStrategies()
+	if err != nil {
+		t.Fatalf("GetConfiguredStrategies() error = %v", err)
+	}
+	found := false
+	for _, s := range strats {
+		if s.Id == stratID {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("GetConfiguredStrategies() missing strategy %d", stratID)
+	}
+
+	if err := SetStrategySettings(-1, settings); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("SetStrategySettings() missing strategy error = %v", err)
+	}
+}
+
+func TestSeedStrategySettingsOnlyFillsUnconfiguredStrategies(t *testing.T) {
+	storage.UseLocalDBForIntegrationTests(t)
+	storage.InitTables()
+
+	userID := createTestUser(t)
+	emptyID, err := addStrategy(Strategy{UserId: userID, Name: "Unconfigured"})
+	if err != nil {
+		t.Fatalf("addStrategy() error = %v", err)
+	}
+	filteredID, err := addStrategy(Strategy{UserId: userID, Name: "Filtered"})
+	if err != nil {
+		t.Fatalf("addStrategy() error = %v", err)
+	}
+	value := 25.0
+	if _, err := addStrategyFilter(StrategyFilter{StrategyId: filteredID, Function: PredictionFunction, Stat: "points", Operator: GreaterThan, ComparisonType: ValueComparison, CompareValue: &value}); err != nil {
+		t.Fatalf("addStrategyFilter() error = %v", err)
+	}
+
+	legacy := legacyStrategySettings()
+	seed := map[int]StrategySettings{emptyID: legacy[1], filteredID: legacy[5]}
+	for i := 0; i < 2; i++ {
+		if err := seedStrategySettings(seed); err != nil {
+			t.Fatalf("seedStrategySettings() run %d error = %v", i, err)
+		}
+	}
+
+	strat, err := GetStrategyByID(emptyID)
+	if err != nil || strat.Settings == nil || strat.Settings.LineType != MainlineLines || strat.Settings.MaxUnder == nil || *strat.Settings.MaxUnder != 0 {
+		t.Fatalf("unconfigured strategy should be seeded, got %+v err=%v", strat.Settings, err)
+	}
+	strat, err = GetStrategyByID(filteredID)
+	if err != nil || strat.Settings != nil {
+		t.Fatalf("filtered strategy should keep no settings, got %+v err=%v", strat.Settings, err)
+	}
+}
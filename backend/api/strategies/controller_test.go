@@ -1,13 +1,18 @@
 package strategies
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mgordon34/kornet-kover/api/picks"
 )
 
 func TestGetStrategiesHandlerBadUserID(t *testing.T) {
@@ -19,8 +24,8 @@ func TestGetStrategiesHandlerBadUserID(t *testing.T) {
 	rec := httptest.NewRecorder()
 	r.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusInternalServerError {
-		t.Fatalf("status = %d, want 500", rec.Code)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
 	}
 }
 
@@ -33,8 +38,8 @@ func TestGetStrategyHandlerBadParam(t *testing.T) {
 	rec := httptest.NewRecorder()
 	r.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusInternalServerError {
-		t.Fatalf("status = %d, want 500", rec.Code)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
 	}
 }
 
@@ -89,3 +94,218 @@ func TestGetStrategyHandlerSuccessAndError(t *testing.T) {
 		t.Fatalf("status = %d, want 500", rec2.Code)
 	}
 }
+
+func newTestStrategyRouter(deps StrategyServiceDeps) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewStrategyService(deps).RegisterRoutes(r)
+	return r
+}
+
+func serveStrategyRequest(r *gin.Engine, method string, path string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStrategyCRUDHandlers(t *testing.T) {
+	stored := Strategy{Id: 4, UserId: 1, Name: "Original"}
+	deleted := false
+	r := newTestStrategyRouter(StrategyServiceDeps{
+		GetStrategy: func(strategyID int) (Strategy, error) {
+			if strategyID != stored.Id {
+				return Strategy{}, fmt.Errorf("error getting strategy %d: %w", strategyID, pgx.ErrNoRows)
+			}
+			return stored, nil
+		},
+		AddStrategy: func(strat Strategy) (int, error) {
+			if strat.UserId == 99 {
+				return 0, &pgconn.PgError{Code: "23503"}
+			}
+			return 7, nil
+		},
+		UpdateStrategy: func(strat Strategy) error {
+			stored = strat
+			return nil
+		},
+		DeleteStrategy: func(strategyID int) error {
+			deleted = true
+			return nil
+		},
+	})
+
+	rec := serveStrategyRequest(r, http.MethodPost, "/strategies", `{"user_id":1,"name":"New"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d, want 201; body=%s", rec.Code, rec.Body.String())
+	}
+	var created Strategy
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || created.Id != 7 {
+		t.Fatalf("POST response = %s (%v)", rec.Body.String(), err)
+	}
+
+	rec = serveStrategyRequest(r, http.MethodPost, "/strategies", `{"user_id":1}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("POST without name status = %d, want 400", rec.Code)
+	}
+	var errResp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &errResp); err != nil || errResp.Error == "" {
+		t.Fatalf("expected structured error, got %s", rec.Body.String())
+	}
+
+	rec = serveStrategyRequest(r, http.MethodPost, "/strategies", `{"user_id":99,"name":"Orphan"}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("POST unknown user status = %d, want 409", rec.Code)
+	}
+
+	rec = serveStrategyRequest(r, http.MethodPatch, "/strategies/4", `{"name":"Renamed"}`)
+	if rec.Code != http.StatusOK || stored.Name != "Renamed" || stored.UserId != 1 {
+		t.Fatalf("PATCH status = %d, stored = %+v", rec.Code, stored)
+	}
+
+	rec = serveStrategyRequest(r, http.MethodPut, "/strategies/4", `{"name":"Replaced"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("PUT without user_id status = %d, want 400", rec.Code)
+	}
+
+	rec = serveStrategyRequest(r, http.MethodPatch, "/strategies/5", `{"name":"Missing"}`)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("PATCH missing status = %d, want 404", rec.Code)
+	}
+
+	rec = serveStrategyRequest(r, http.MethodDelete, "/strategies/4", "")
+	if rec.Code != http.StatusNoContent || !deleted {
+		t.Fatalf("DELETE status = %d, deleted = %v", rec.Code, deleted)
+	}
+}
+
+func TestStrategyFilterHandlers(t *testing.T) {
+	value := 1.2
+	line := LineFunction
+	mult := Multiply
+	stored := StrategyFilter{Id: 2, StrategyId: 4, Function: PredictionFunction, Stat: "points", Operator: GreaterThan, ComparisonType: ModifiedComparison, CompareValue: &value, CompareFunction: &line, ModifierOperator: &mult}
+	added := false
+	r := newTestStrategyRouter(StrategyServiceDeps{
+		GetStrategy: func(strategyID int) (Strategy, error) {
+			if strategyID != 4 {
+				return Strategy{}, pgx.ErrNoRows
+			}
+			return Strategy{Id: 4, UserId: 1, Name: "S"}, nil
+		},
+		GetStrategyFilters: func(strategyID int) ([]StrategyFilter, error) {
+			return []StrategyFilter{stored}, nil
+		},
+		GetStrategyFilter: func(strategyID int, filterID int) (StrategyFilter, error) {
+			if filterID != stored.Id {
+				return StrategyFilter{}, pgx.ErrNoRows
+			}
+			return stored, nil
+		},
+		AddStrategyFilter: func(filter StrategyFilter) (int, error) {
+			added = true
+			return 3, nil
+		},
+		UpdateStrategyFilter: func(filter StrategyFilter) error {
+			stored = filter
+			return nil
+		},
+		DeleteStrategyFilter: func(strategyID int, filterID int) error {
+			return pgx.ErrNoRows
+		},
+	})
+
+	rec := serveStrategyRequest(r, http.MethodGet, "/strategies/4/filters", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET filters status = %d", rec.Code)
+	}
+	rec = serveStrategyRequest(r, http.MethodGet, "/strategies/5/filters", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("GET filters for missing strategy status = %d, want 404", rec.Code)
+	}
+
+	rec = serveStrategyRequest(r, http.MethodPost, "/strategies/4/filters", `{"function":"prediction","stat":"points","operator":">","comparison_type":"value","compare_value":20}`)
+	if rec.Code != http.StatusCreated || !added {
+		t.Fatalf("POST filter status = %d; body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveStrategyRequest(r, http.MethodPost, "/strategies/4/filters", `{"function":"prediction","stat":"points","operator":"!=","comparison_type":"value","compare_value":20}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("POST filter with bad operator status = %d, want 400", rec.Code)
+	}
+
+	rec = serveStrategyRequest(r, http.MethodPatch, "/strategies/4/filters/2", `{"operator":">="}`)
+	if rec.Code != http.StatusOK || stored.Operator != GreaterOrEqual || stored.CompareFunction == nil {
+		t.Fatalf("PATCH filter status = %d, stored = %+v", rec.Code, stored)
+	}
+
+	rec = serveStrategyRequest(r, http.MethodPut, "/strategies/4/filters/2", `{"function":"prediction","stat":"points","operator":">","comparison_type":"modified","compare_function":"line"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("PUT filter missing modifier status = %d, want 400", rec.Code)
+	}
+
+	rec = serveStrategyRequest(r, http.MethodGet, "/strategies/4/filters/bad", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("GET filter bad id status = %d, want 400", rec.Code)
+	}
+
+	rec = serveStrategyRequest(r, http.MethodDelete, "/strategies/4/filters/9", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("DELETE missing filter status = %d, want 404", rec.Code)
+	}
+}
+
+func TestStrategyPerformanceHandler(t *testing.T) {
+	var got picks.GradedPicksQuery
+	r := newTestStrategyRouter(StrategyServiceDeps{
+		GetStrategy: func(strategyID int) (Strategy, error) {
+			if strategyID != 4 {
+				return Strategy{}, fmt.Errorf("error getting strategy %d: %w", strategyID, pgx.ErrNoRows)
+			}
+			return Strategy{Id: 4, UserId: 1, Name: "Live"}, nil
+		},
+		GetGradedPicks: func(query picks.GradedPicksQuery) ([]picks.GradedPick, error) {
+			got = query
+			return []picks.GradedPick{
+				{StratId: 4, Stat: "points", Side: "Over", Odds: -110, Result: picks.ResultWin, Profit: .91},
+				{StratId: 4, Stat: "points", Side: "Over", Odds: 300, Result: picks.ResultLoss, Profit: -1},
+			}, nil
+		},
+	})
+
+	rec := serveStrategyRequest(r, http.MethodGet, "/strategies/4/performance?start=2026-01-01&end=2026-02-01", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var report picks.PerformanceReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("unmarshal error = %v", err)
+	}
+	if report.Summary.Bets != 2 || report.Summary.Wins != 1 || len(report.ByOdds) != 2 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if got.StratId != 4 || got.UserId != 0 || got.StartDate == nil || got.EndDate == nil {
+		t.Fatalf("unexpected query: %+v", got)
+	}
+
+	for path, want := range map[string]int{
+		"/strategies/bad/performance":             http.StatusBadRequest,
+		"/strategies/4/performance?start=bad":     http.StatusBadRequest,
+		"/strategies/4/performance?end=2026-13-1": http.StatusBadRequest,
+		"/strategies/9/performance":               http.StatusNotFound,
+	} {
+		if rec := serveStrategyRequest(r, http.MethodGet, path, ""); rec.Code != want {
+			t.Fatalf("%s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+
+	failing := newTestStrategyRouter(StrategyServiceDeps{
+		GetStrategy: func(strategyID int) (Strategy, error) { return Strategy{Id: strategyID}, nil },
+		GetGradedPicks: func(query picks.GradedPicksQuery) ([]picks.GradedPick, error) {
+			return nil, errors.New("db down")
+		},
+	})
+	if rec := serveStrategyRequest(failing, http.MethodGet, "/strategies/4/performance", ""); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+}
@@ -1,9 +1,118 @@
 package strategies
 
+import (
+    "fmt"
+
+    "github.com/mgordon34/kornet-kover/internal/sports"
+)
+
 type Strategy struct {
-    Id              int         `json:"id"`
-    UserId          int         `json:"user_id"`
-    Name            string      `json:"name"`
+    Id              int                 `json:"id"`
+    UserId          int                 `json:"user_id"`
+    Name            string              `json:"name"`
+    Settings        *StrategySettings   `json:"settings,omitempty"`
+}
+
+const (
+    RawThreshold        = "raw"
+    PercentThreshold    = "percent"
+    EVThreshold         = "ev"
+)
+
+const (
+    StatRankSort        = "stat_rank"
+    DiffSort            = "diff"
+    PDiffSort           = "pdiff"
+    ProbEdgeSort        = "prob_edge"
+    EVSort              = "ev"
+)
+
+const (
+    SortDesc            = "desc"
+    SortAsc             = "asc"
+)
+
+const (
+    MainlineLines   = "mainline"
+    AlternateLines  = "alternate"
+)
+
+// StrategySettings holds the PropSelector knobs for a strategy. It is stored
+// as JSONB on the strategies row. MinOdds, MaxOver and MaxUnder are pointers
+// so an explicit 0 (e.g. "no unders") can be told apart from an unset limit,
+// which keeps the PropSelector default.
+type StrategySettings struct {
+    // Sport is the sport the strategy is picked for, defaulting to NBA
+    Sport           sports.Sport        `json:"sport"`
+    LineType        string              `json:"line_type"`
+    Thresholds      map[string]float32  `json:"thresholds"`
+    ThresholdType   string              `json:"threshold_type"`
+    SortType        string              `json:"sort_type"`
+    SortDir         string              `json:"sort_dir"`
+    RequireOutlier  bool                `json:"require_outlier"`
+    MinMinutes      float32             `json:"min_minutes"`
+    MinGames        int                 `json:"min_games"`
+    MinOdds         *int                `json:"min_odds"`
+    MaxOdds         int                 `json:"max_odds"`
+    MinLine         float32             `json:"min_line"`
+    MaxLine         float32             `json:"max_line"`
+    MinDiff         float32             `json:"min_diff"`
+    BetSize         float32             `json:"bet_size"`
+    MaxOver         *int                `json:"max_over"`
+    MaxUnder        *int                `json:"max_under"`
+    TotalMax        int                 `json:"total_max"`
+    MaxPerPlayer    int                 `json:"max_per_player"`
+    MaxPerGame      int                 `json:"max_per_game"`
+    MaxPerTeam      int                 `json:"max_per_team"`
+    MaxPerStat      int                 `json:"max_per_stat"`
+    // OneAlternatePerSide keeps only the best alternate line for each
+    // player, stat and side
+    OneAlternatePerSide bool            `json:"one_alternate_per_side"`
+}
+
+func (s StrategySettings) Validate() error {
+    if s.Sport != "" {
+        if _, err := sports.GetConfig(s.Sport); err != nil {
+            return err
+        }
+    }
+    switch s.LineType {
+    case "", MainlineLines, AlternateLines:
+    default:
+        return fmt.Errorf("unknown line_type %q", s.LineType)
+    }
+    switch s.ThresholdType {
+    case "", RawThreshold, PercentThreshold, EVThreshold:
+    default:
+        return fmt.Errorf("unknown threshold_type %q", s.ThresholdType)
+    }
+    switch s.SortType {
+    case "", StatRankSort, DiffSort, PDiffSort, ProbEdgeSort, EVSort:
+    default:
+        return fmt.Errorf("unknown sort_type %q", s.SortType)
+    }
+    switch s.SortDir {
+    case "", SortDesc, SortAsc:
+    default:
+        return fmt.Errorf("unknown sort_dir %q", s.SortDir)
+    }
+    if s.MaxOdds != 0 && s.MinOdds != nil && s.MaxOdds < *s.MinOdds {
+        return fmt.Errorf("max_odds must be greater than min_odds")
+    }
+    if s.MaxLine != 0 && s.MaxLine < s.MinLine {
+        return fmt.Errorf("max_line must be greater than min_line")
+    }
+    if s.BetSize < 0 || isNegative(s.MaxOver) || isNegative(s.MaxUnder) || s.TotalMax < 0 || s.MinGames < 0 {
+        return fmt.Errorf("bet_size, max_over, max_under, total_max and min_games must not be negative")
+    }
+    if s.MaxPerPlayer < 0 || s.MaxPerGame < 0 || s.MaxPerTeam < 0 || s.MaxPerStat < 0 {
+        return fmt.Errorf("max_per_player, max_per_game, max_per_team and max_per_stat must not be negative")
+    }
+    return nil
+}
+
+func isNegative(v *int) bool {
+    return v != nil && *v < 0
 }
 
 type ComparisonType string
@@ -45,3 +154,149 @@ type StrategyFilter struct {
     CompareStat       *string            `json:"compare_stat,omitempty"`
     ModifierOperator  *ModifierOperator  `json:"modifier_operator,omitempty"`
 }
+
+const (
+    PredictionFunction  = "prediction"
+    BaseFunction        = "base"
+    OutlierFunction     = "outlier"
+    LineFunction        = "line"
+    OddsFunction        = "odds"
+    DiffFunction        = "diff"
+    PDiffFunction       = "pdiff"
+    // ImpliedProbFunction is the break-even probability of the pick's price
+    ImpliedProbFunction = "implied_prob"
+    // FairProbFunction is the no-vig probability of the pick's side
+    FairProbFunction    = "fair_prob"
+    // FairEVFunction is the profit per dollar expected at the pick's price
+    // if the side hits at its fair probability
+    FairEVFunction      = "fair_ev"
+    // ModelProbFunction is the chance of the pick's side hitting under the
+    // stat's fitted distribution
+    ModelProbFunction   = "model_prob"
+    // ModelEVFunction is the profit per dollar expected at the pick's price
+    // under the stat's fitted distribution
+    ModelEVFunction     = "model_ev"
+)
+
+var FilterFunctions = []string{
+    PredictionFunction,
+    BaseFunction,
+    OutlierFunction,
+    LineFunction,
+    OddsFunction,
+    DiffFunction,
+    PDiffFunction,
+    ImpliedProbFunction,
+    FairProbFunction,
+    FairEVFunction,
+    ModelProbFunction,
+    ModelEVFunction,
+}
+
+func IsFilterFunction(function string) bool {
+    for _, f := range FilterFunctions {
+        if f == function {
+            return true
+        }
+    }
+    return false
+}
+
+func (c ComparisonType) IsValid() bool {
+    switch c {
+    case ValueComparison, FunctionComparison, ModifiedComparison:
+        return true
+    }
+    return false
+}
+
+func (m ModifierOperator) IsValid() bool {
+    switch m {
+    case Multiply, Divide, Add, Subtract:
+        return true
+    }
+    return false
+}
+
+func (m ModifierOperator) Apply(value float64, modifier float64) (float64, error) {
+    switch m {
+    case Multiply:
+        return value * modifier, nil
+    case Divide:
+        if modifier == 0 {
+            return 0, fmt.Errorf("cannot divide by zero")
+        }
+        return value / modifier, nil
+    case Add:
+        return value + modifier, nil
+    case Subtract:
+        return value - modifier, nil
+    }
+    return 0, fmt.Errorf("unknown modifier operator %q", m)
+}
+
+func (o ComparisonOperator) IsValid() bool {
+    switch o {
+    case GreaterThan, LessThan, GreaterOrEqual, LessOrEqual, Equal:
+        return true
+    }
+    return false
+}
+
+func (o ComparisonOperator) Compare(left float64, right float64) bool {
+    switch o {
+    case GreaterThan:
+        return left > right
+    case LessThan:
+        return left < right
+    case GreaterOrEqual:
+        return left >= right
+    case LessOrEqual:
+        return left <= right
+    case Equal:
+        return left == right
+    }
+    return false
+}
+
+func (f StrategyFilter) Validate() error {
+    if !IsFilterFunction(f.Function) {
+        return fmt.Errorf("unknown function %q", f.Function)
+    }
+    if f.Stat == "" {
+        return fmt.Errorf("stat is required")
+    }
+    if !f.Operator.IsValid() {
+        return fmt.Errorf("unknown operator %q", f.Operator)
+    }
+
+    switch f.ComparisonType {
+    case ValueComparison:
+        if f.CompareValue == nil {
+            return fmt.Errorf("compare_value is required for %s comparisons", f.ComparisonType)
+        }
+    case FunctionComparison, ModifiedComparison:
+        if f.CompareFunction == nil || !IsFilterFunction(*f.CompareFunction) {
+            return fmt.Errorf("a valid compare_function is required for %s comparisons", f.ComparisonType)
+        }
+        if f.ComparisonType == ModifiedComparison {
+            if f.ModifierOperator == nil || !f.ModifierOperator.IsValid() {
+                return fmt.Errorf("a valid modifier_operator is required for %s comparisons", f.ComparisonType)
+            }
+            if f.CompareValue == nil {
+                return fmt.Errorf("compare_value is required for %s comparisons", f.ComparisonType)
+            }
+        }
+    default:
+        return fmt.Errorf("unknown comparison_type %q", f.ComparisonType)
+    }
+
+    return nil
+}
+
+func (f StrategyFilter) GetCompareStat() string {
+    if f.CompareStat == nil || *f.CompareStat == "" {
+        return f.Stat
+    }
+    return *f.CompareStat
+}
@@ -1,6 +1,10 @@
 package strategies
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
 
 func TestStrategyConstants(t *testing.T) {
 	if ValueComparison == "" || FunctionComparison == "" || ModifiedComparison == "" {
@@ -13,3 +17,110 @@ func TestStrategyConstants(t *testing.T) {
 		t.Fatalf("comparison operator constants should be non-empty")
 	}
 }
+
+func TestOperatorsCompareAndApply(t *testing.T) {
+	if !GreaterThan.Compare(2, 1) || GreaterThan.Compare(1, 1) {
+		t.Fatalf("GreaterThan.Compare() unexpected result")
+	}
+	if !LessOrEqual.Compare(1, 1) || !Equal.Compare(3, 3) || ComparisonOperator("!=").Compare(1, 2) {
+		t.Fatalf("ComparisonOperator.Compare() unexpected result")
+	}
+
+	got, err := Multiply.Apply(20, 1.2)
+	if err != nil || got != 24 {
+		t.Fatalf("Multiply.Apply() = (%v, %v), want 24", got, err)
+	}
+	if _, err := Divide.Apply(20, 0); err == nil {
+		t.Fatalf("Divide.Apply() by zero should error")
+	}
+	if _, err := ModifierOperator("%").Apply(1, 1); err == nil {
+		t.Fatalf("unknown modifier should error")
+	}
+}
+
+func TestStrategyFilterValidate(t *testing.T) {
+	value := 1.2
+	line := LineFunction
+	bogus := "bogus"
+	mult := Multiply
+
+	valid := []StrategyFilter{
+		{Function: PredictionFunction, Stat: "points", Operator: GreaterThan, ComparisonType: ValueComparison, CompareValue: &value},
+		{Function: PredictionFunction, Stat: "points", Operator: GreaterThan, ComparisonType: FunctionComparison, CompareFunction: &line},
+		{Function: PredictionFunction, Stat: "points", Operator: GreaterThan, ComparisonType: ModifiedComparison, CompareFunction: &line, ModifierOperator: &mult, CompareValue: &value},
+	}
+	for _, f := range valid {
+		if err := f.Validate(); err != nil {
+			t.Fatalf("Validate(%+v) error = %v", f, err)
+		}
+	}
+
+	invalid := []StrategyFilter{
+		{Function: bogus, Stat: "points", Operator: GreaterThan, ComparisonType: ValueComparison, CompareValue: &value},
+		{Function: PredictionFunction, Operator: GreaterThan, ComparisonType: ValueComparison, CompareValue: &value},
+		{Function: PredictionFunction, Stat: "points", Operator: "!=", ComparisonType: ValueComparison, CompareValue: &value},
+		{Function: PredictionFunction, Stat: "points", Operator: GreaterThan, ComparisonType: ValueComparison},
+		{Function: PredictionFunction, Stat: "points", Operator: GreaterThan, ComparisonType: FunctionComparison, CompareFunction: &bogus},
+		{Function: PredictionFunction, Stat: "points", Operator: GreaterThan, ComparisonType: ModifiedComparison, CompareFunction: &line, CompareValue: &value},
+		{Function: PredictionFunction, Stat: "points", Operator: GreaterThan, ComparisonType: ModifiedComparison, CompareFunction: &line, ModifierOperator: &mult},
+		{Function: PredictionFunction, Stat: "points", Operator: GreaterThan, ComparisonType: "other"},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("Validate(%+v) expected error", f)
+		}
+	}
+
+	stat := "rebounds"
+	if got := (StrategyFilter{Stat: "points"}).GetCompareStat(); got != "points" {
+		t.Fatalf("GetCompareStat() default = %q", got)
+	}
+	if got := (StrategyFilter{Stat: "points", CompareStat: &stat}).GetCompareStat(); got != "rebounds" {
+		t.Fatalf("GetCompareStat() = %q", got)
+	}
+}
+
+func TestStrategySettingsValidate(t *testing.T) {
+	minOdds, noUnders, negative := 200, 0, -1
+	valid := StrategySettings{LineType: AlternateLines, ThresholdType: EVThreshold, SortType: ProbEdgeSort, SortDir: SortAsc, MinOdds: &minOdds, MaxOdds: 600, MaxLine: 20, MaxUnder: &noUnders, Sport: sports.MLB}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if err := (StrategySettings{}).Validate(); err != nil {
+		t.Fatalf("empty settings should be valid, got %v", err)
+	}
+
+	invalid := []StrategySettings{
+		{LineType: "exotic"},
+		{ThresholdType: "ratio"},
+		{SortType: "random"},
+		{SortDir: "sideways"},
+		{MaxPerGame: -1},
+		{MinOdds: &minOdds, MaxOdds: 100},
+		{MinLine: 10, MaxLine: 5},
+		{MaxOver: &negative},
+		{MaxUnder: &negative},
+		{Sport: "cricket"},
+	}
+	for _, settings := range invalid {
+		if err := settings.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", settings)
+		}
+	}
+
+	strat := Strategy{UserId: 1, Name: "Alt", Settings: &StrategySettings{LineType: "exotic"}}
+	if err := strat.Validate(); err == nil {
+		t.Fatalf("expected strategy with invalid settings to fail validation")
+	}
+}
+
+func TestLegacyStrategySettingsAreValid(t *testing.T) {
+	for stratId, settings := range legacyStrategySettings() {
+		if err := settings.Validate(); err != nil {
+			t.Fatalf("strategy %d settings invalid: %v", stratId, err)
+		}
+		if settings.MaxUnder == nil || *settings.MaxUnder != 0 {
+			t.Fatalf("strategy %d should not bet unders, got %v", stratId, settings.MaxUnder)
+		}
+	}
+}
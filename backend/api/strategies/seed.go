@@ -0,0 +1,96 @@
+package strategies
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/mgordon34/kornet-kover/internal/storage"
+)
+
+// legacyStrategySettings are the selectors that used to be compiled into the
+// daily pick run, keyed by the strategy they were picked for.
+func legacyStrategySettings() map[int]StrategySettings {
+	mainline := func(thresholds map[string]float32, thresholdType string) StrategySettings {
+		minOdds, maxOver, maxUnder := -135, 100, 0
+		return StrategySettings{
+			LineType:       MainlineLines,
+			Thresholds:     thresholds,
+			ThresholdType:  thresholdType,
+			RequireOutlier: true,
+			MinGames:       10,
+			MinOdds:        &minOdds,
+			BetSize:        100,
+			MaxOver:        &maxOver,
+			MaxUnder:       &maxUnder,
+			TotalMax:       100,
+		}
+	}
+	alternate := func(thresholds map[string]float32, thresholdType string, minOdds int, maxOdds int, maxLine float32) StrategySettings {
+		maxOver, maxUnder := 100, 0
+		return StrategySettings{
+			LineType:       AlternateLines,
+			Thresholds:     thresholds,
+			ThresholdType:  thresholdType,
+			RequireOutlier: true,
+			MinGames:       10,
+			MinOdds:        &minOdds,
+			MaxOdds:        maxOdds,
+			MaxLine:        maxLine,
+			BetSize:        100,
+			MaxOver:        &maxOver,
+			MaxUnder:       &maxUnder,
+			TotalMax:       100,
+		}
+	}
+
+	return map[int]StrategySettings{
+		1:  mainline(map[string]float32{"points": .3, "rebounds": 10, "assists": 10}, PercentThreshold),
+		2:  mainline(map[string]float32{"points": 2.5, "rebounds": 1000, "assists": 1000}, RawThreshold),
+		3:  mainline(map[string]float32{"points": 1000, "rebounds": 1, "assists": 1000}, RawThreshold),
+		4:  mainline(map[string]float32{"points": 1000, "rebounds": 1000, "assists": 1000, "threes": .6}, PercentThreshold),
+		5:  alternate(map[string]float32{"points": -.3, "rebounds": 1000, "assists": 1000, "threes": 1000}, PercentThreshold, 200, 600, 20),
+		6:  alternate(map[string]float32{"points": -100, "rebounds": 1000, "assists": 1000, "threes": 1000}, RawThreshold, 600, 0, 20),
+		8:  alternate(map[string]float32{"points": 1000, "rebounds": 1000, "assists": -100, "threes": 1000}, RawThreshold, 600, 0, 9),
+		9:  alternate(map[string]float32{"points": 1000, "rebounds": 1000, "assists": 1000, "threes": -100}, RawThreshold, 600, 0, 4),
+		10: alternate(map[string]float32{"points": 1000, "rebounds": -100, "assists": 1000, "threes": 1000}, RawThreshold, 300, 600, 10),
+		11: alternate(map[string]float32{"points": 1000, "rebounds": 1000, "assists": -100, "threes": 1000}, RawThreshold, 300, 600, 9),
+		12: alternate(map[string]float32{"points": 1000, "rebounds": 1000, "assists": 1000, "threes": -100}, RawThreshold, 200, 600, 3),
+	}
+}
+
+// SeedStrategySettings stores the legacy selectors on their strategies so an
+// existing deployment keeps picking them after the move to stored settings.
+// It runs on every start and is safe to repeat.
+func SeedStrategySettings() error {
+	return seedStrategySettings(legacyStrategySettings())
+}
+
+// seedStrategySettings only fills strategies that have neither settings nor
+// filters, so it never overwrites a strategy a user has configured since.
+func seedStrategySettings(settings map[int]StrategySettings) error {
+	db := storage.GetDB()
+
+	sql := `
+    UPDATE strategies s
+    SET settings = ($2)
+    WHERE s.id = ($1) AND s.settings IS NULL
+    AND NOT EXISTS (SELECT 1 FROM strategy_filters sf WHERE sf.strategy_id = s.id)`
+
+	stratIds := make([]int, 0, len(settings))
+	for stratId := range settings {
+		stratIds = append(stratIds, stratId)
+	}
+	sort.Ints(stratIds)
+	for _, stratId := range stratIds {
+		tag, err := db.Exec(context.Background(), sql, stratId, settings[stratId])
+		if err != nil {
+			return fmt.Errorf("error seeding settings for strategy %d: %w", stratId, err)
+		}
+		if tag.RowsAffected() > 0 {
+			log.Printf("Seeded settings for strategy %d", stratId)
+		}
+	}
+	return nil
+}
@@ -19,6 +19,17 @@ func TestPrunePlayers(t *testing.T) {
 	}
 }
 
+func TestAnalysisGameKey(t *testing.T) {
+	home := Analysis{TeamIndex: "NYK", OpponentIndex: "BOS"}
+	away := Analysis{TeamIndex: "BOS", OpponentIndex: "NYK"}
+	if home.GameKey() != "BOS-NYK" || away.GameKey() != home.GameKey() {
+		t.Fatalf("expected both sides to share a key, got %q %q", home.GameKey(), away.GameKey())
+	}
+	if (Analysis{TeamIndex: "BOS"}).GameKey() != "" {
+		t.Fatalf("expected no key without an opponent")
+	}
+}
+
 func TestOutliersAndHasOutlier(t *testing.T) {
 	base := players.NBAAvg{NumGames: 1, Minutes: 20, Points: 10, Rebounds: 5, Assists: 3, Threes: 1, Usg: 10, Ortg: 100, Drtg: 100}
 	pred := players.NBAAvg{NumGames: 1, Minutes: 20, Points: 13, Rebounds: 4, Assists: 2, Threes: 1.2, Usg: 10, Ortg: 100, Drtg: 100}
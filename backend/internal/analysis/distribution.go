@@ -0,0 +1,133 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mgordon34/kornet-kover/api/players"
+)
+
+// minDistributionGames is the fewest games a player's variance is trusted
+// from. Below it a stat is assumed to be Poisson.
+const minDistributionGames = 5
+
+// distributionLookbackYears is how far back game logs are read to fit a
+// stat's spread
+const distributionLookbackYears = 1
+
+// StatDistribution is the chance of each whole-number outcome of a counting
+// stat
+type StatDistribution interface {
+	Name() string
+	Mean() float64
+	// CDF is the chance the stat finishes at or below k
+	CDF(k int) float64
+}
+
+// Poisson fits stats whose variance is about their mean
+type Poisson struct {
+	Lambda float64 `json:"lambda"`
+}
+
+func (p Poisson) Name() string {
+	return fmt.Sprintf("poisson(%.2f)", p.Lambda)
+}
+
+func (p Poisson) Mean() float64 {
+	return p.Lambda
+}
+
+func (p Poisson) CDF(k int) float64 {
+	if k < 0 {
+		return 0
+	}
+	if p.Lambda <= 0 {
+		return 1
+	}
+	total := 0.0
+	for i := 0; i <= k; i++ {
+		lgamma, _ := math.Lgamma(float64(i + 1))
+		total += math.Exp(float64(i)*math.Log(p.Lambda) - p.Lambda - lgamma)
+	}
+	return math.Min(total, 1)
+}
+
+// NegativeBinomial fits stats that swing more than a Poisson allows, like
+// points for a streaky shooter. Variance must be above the mean.
+type NegativeBinomial struct {
+	Mu       float64 `json:"mean"`
+	Variance float64 `json:"variance"`
+}
+
+func (n NegativeBinomial) Name() string {
+	return fmt.Sprintf("negative binomial(%.2f, %.2f)", n.Mu, n.Variance)
+}
+
+func (n NegativeBinomial) Mean() float64 {
+	return n.Mu
+}
+
+func (n NegativeBinomial) CDF(k int) float64 {
+	if n.Variance <= n.Mu {
+		return Poisson{Lambda: n.Mu}.CDF(k)
+	}
+	if k < 0 {
+		return 0
+	}
+	r := n.Mu * n.Mu / (n.Variance - n.Mu)
+	p := r / (r + n.Mu)
+	lgammaR, _ := math.Lgamma(r)
+	total := 0.0
+	for i := 0; i <= k; i++ {
+		lgammaIR, _ := math.Lgamma(float64(i) + r)
+		lgammaI, _ := math.Lgamma(float64(i + 1))
+		total += math.Exp(lgammaIR - lgammaR - lgammaI + r*math.Log(p) + float64(i)*math.Log(1-p))
+	}
+	return math.Min(total, 1)
+}
+
+// FitStatDistribution centers a distribution on the predicted mean with the
+// spread the player has shown. The variance to mean ratio from their games
+// carries over to the prediction, and it falls back to Poisson when the
+// games show no extra spread or are too few to trust. It is nil when the
+// prediction isn't positive.
+func FitStatDistribution(mean float64, moments players.StatMoments) StatDistribution {
+	if mean <= 0 || math.IsNaN(mean) {
+		return nil
+	}
+	if moments.NumGames < minDistributionGames || moments.Mean <= 0 {
+		return Poisson{Lambda: mean}
+	}
+	dispersion := float64(moments.Variance / moments.Mean)
+	if dispersion <= 1 {
+		return Poisson{Lambda: mean}
+	}
+	return NegativeBinomial{Mu: mean, Variance: mean * dispersion}
+}
+
+// FitStatDistributions fits a distribution for every predicted stat that has
+// moments
+func FitStatDistributions(prediction players.PlayerAvg, moments map[string]players.StatMoments) map[string]StatDistribution {
+	distributions := make(map[string]StatDistribution)
+	predicted := prediction.GetStats()
+	for stat, m := range moments {
+		mean, ok := predicted[stat]
+		if !ok {
+			continue
+		}
+		if dist := FitStatDistribution(float64(mean), m); dist != nil {
+			distributions[stat] = dist
+		}
+	}
+	return distributions
+}
+
+// ProbOver is the chance the stat finishes above the line
+func ProbOver(dist StatDistribution, line float32) float64 {
+	return 1 - dist.CDF(int(math.Floor(float64(line))))
+}
+
+// ProbUnder is the chance the stat finishes below the line
+func ProbUnder(dist StatDistribution, line float32) float64 {
+	return dist.CDF(int(math.Ceil(float64(line))) - 1)
+}
@@ -0,0 +1,79 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mgordon34/kornet-kover/api/players"
+)
+
+func TestPoissonCDF(t *testing.T) {
+	p := Poisson{Lambda: 2}
+	want := math.Exp(-2) * (1 + 2 + 2)
+	if got := p.CDF(2); math.Abs(got-want) > 1e-12 {
+		t.Fatalf("CDF(2) = %v, want %v", got, want)
+	}
+	if p.CDF(-1) != 0 || p.CDF(200) < 1-1e-12 || p.Mean() != 2 || p.Name() != "poisson(2.00)" {
+		t.Fatalf("unexpected poisson tails: %v %v", p.CDF(-1), p.CDF(200))
+	}
+	if (Poisson{}).CDF(0) != 1 {
+		t.Fatalf("a zero mean should always finish at zero")
+	}
+}
+
+func TestNegativeBinomialCDF(t *testing.T) {
+	// r = 4, p = .5
+	nb := NegativeBinomial{Mu: 4, Variance: 8}
+	want := math.Pow(.5, 4) * (1 + 4*.5 + 10*.25)
+	if got := nb.CDF(2); math.Abs(got-want) > 1e-12 {
+		t.Fatalf("CDF(2) = %v, want %v", got, want)
+	}
+	if nb.CDF(-1) != 0 || nb.CDF(500) < 1-1e-9 || nb.Mean() != 4 || nb.Name() != "negative binomial(4.00, 8.00)" {
+		t.Fatalf("unexpected negative binomial tails")
+	}
+	if got := (NegativeBinomial{Mu: 4, Variance: 4}).CDF(3); got != (Poisson{Lambda: 4}).CDF(3) {
+		t.Fatalf("expected no extra spread to fall back to poisson, got %v", got)
+	}
+
+	// The wider distribution puts more weight on a far alternate line
+	line := float32(29.5)
+	if ProbOver(NegativeBinomial{Mu: 20, Variance: 60}, line) <= ProbOver(Poisson{Lambda: 20}, line) {
+		t.Fatalf("expected the negative binomial to have a fatter tail")
+	}
+}
+
+func TestProbOverAndUnder(t *testing.T) {
+	p := Poisson{Lambda: 3}
+	if got := ProbOver(p, 2.5) + ProbUnder(p, 2.5); math.Abs(got-1) > 1e-12 {
+		t.Fatalf("half point line should have no push, got %v", got)
+	}
+	push := 1 - ProbOver(p, 3) - ProbUnder(p, 3)
+	if want := math.Exp(-3) * 27 / 6; math.Abs(push-want) > 1e-12 {
+		t.Fatalf("push on 3 = %v, want %v", push, want)
+	}
+	if ProbOver(p, 4.5) >= ProbOver(p, 2.5) {
+		t.Fatalf("a higher line should be harder to clear")
+	}
+}
+
+func TestFitStatDistributions(t *testing.T) {
+	if FitStatDistribution(0, players.StatMoments{}) != nil || FitStatDistribution(math.NaN(), players.StatMoments{}) != nil {
+		t.Fatalf("expected no distribution for a prediction without a mean")
+	}
+	if got := FitStatDistribution(10, players.StatMoments{NumGames: 2, Mean: 10, Variance: 40}); got != (Poisson{Lambda: 10}) {
+		t.Fatalf("expected too few games to be Poisson, got %+v", got)
+	}
+
+	prediction := players.NBAAvg{NumGames: 30, Minutes: 34, Points: 30, Rebounds: 10}
+	got := FitStatDistributions(prediction, map[string]players.StatMoments{
+		"points":   {NumGames: 30, Mean: 25, Variance: 75},
+		"rebounds": {NumGames: 30, Mean: 10, Variance: 9},
+		"steals":   {NumGames: 30, Mean: 1, Variance: 2},
+	})
+	if len(got) != 2 {
+		t.Fatalf("expected points and rebounds, got %+v", got)
+	}
+	if got["points"] != (NegativeBinomial{Mu: 30, Variance: 90}) || got["rebounds"] != (Poisson{Lambda: 10}) {
+		t.Fatalf("unexpected fits %+v", got)
+	}
+}
@@ -1,6 +1,8 @@
 package analysis
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math"
@@ -12,13 +14,17 @@ import (
 	"github.com/mgordon34/kornet-kover/api/odds"
 	"github.com/mgordon34/kornet-kover/api/picks"
 	"github.com/mgordon34/kornet-kover/api/players"
+	"github.com/mgordon34/kornet-kover/api/strategies"
 	"github.com/mgordon34/kornet-kover/internal/scraper"
 	"github.com/mgordon34/kornet-kover/internal/sports"
 )
 
 type PropSelector struct {
-	StratId        int
-	StratName      string
+	StratId   int
+	StratName string
+	// Sport decides how stats are ranked, defaulting to NBA
+	Sport          sports.Sport
+	LineType       string
 	Thresholds     map[string]float32
 	TresholdType   ThresholdType
 	SortType       SortType
@@ -34,7 +40,17 @@ type PropSelector struct {
 	BetSize        float32
 	MaxOver        int
 	MaxUnder       int
-	TotalMax       int
+	// TotalMax and the MaxPer caps limit how many picks are kept. Zero means
+	// no limit. Games and teams are only capped for analyses that know them.
+	TotalMax     int
+	MaxPerPlayer int
+	MaxPerGame   int
+	MaxPerTeam   int
+	MaxPerStat   int
+	// OneAlternatePerSide keeps only the best ranked alternate line for each
+	// player, stat and side, since they all hit together
+	OneAlternatePerSide bool
+	Filters             FilterSet
 }
 
 type ThresholdType int
@@ -42,13 +58,34 @@ type ThresholdType int
 const (
 	Raw ThresholdType = iota
 	Percent
+	// EV compares the pick's expected value per dollar under its stat's
+	// fitted distribution to the threshold
+	EV
 )
 
+// SortType is the order picks are considered in, which decides which picks
+// MaxOver and MaxUnder keep
 type SortType int
 
 const (
-	Diff ThresholdType = iota
-	PDiff
+	// DefaultSort ranks mainlines by stat and alternates by expected value
+	DefaultSort SortType = iota
+	// StatRankSort ranks by the sport's stat weights, then by the size of the
+	// percent diff
+	StatRankSort
+	DiffSort
+	PDiffSort
+	// ProbEdgeSort ranks by how far the model probability is above the
+	// price's implied probability
+	ProbEdgeSort
+	// EVSort ranks by expected value per dollar under the stat's fitted
+	// distribution
+	EVSort
+)
+
+const (
+	SortDesc = "desc"
+	SortAsc  = "asc"
 )
 
 type PropPick struct {
@@ -59,8 +96,13 @@ type PropPick struct {
 	Diff    float32
 	PDiff   float32
 	BetSize float32
+	Date    time.Time
 	Actual  float32
 	Result  string
+	Profit  float32
+	// Closing and CLV compare the pick to the last line posted for its market
+	Closing *odds.PlayerLine
+	CLV     *odds.LineCLV
 	odds.PlayerLine
 	odds.PlayerOdds
 	Analysis
@@ -77,6 +119,61 @@ func (p PropPick) GetLine() odds.PlayerLine {
 	return p.Under
 }
 
+// FairProbability is the no-vig chance of the pick's side hitting. It is only
+// known for mainline picks whose market had both sides posted.
+func (p PropPick) FairProbability() (float64, bool) {
+	if p.Fair == nil {
+		return 0, false
+	}
+	if p.Side == "Over" {
+		return p.Fair.Over, true
+	}
+	return p.Fair.Under, true
+}
+
+// ModelProbability is the chance the pick's side hits under the fitted
+// distribution of its stat
+func (p PropPick) ModelProbability() (float64, bool) {
+	dist, ok := p.Distributions[p.Stat]
+	if !ok {
+		return 0, false
+	}
+	if p.Side == "Over" {
+		return ProbOver(dist, p.GetLine().Line), true
+	}
+	return ProbUnder(dist, p.GetLine().Line), true
+}
+
+// ModelExpectedValue is the profit per dollar the pick is expected to return
+// at its price under the fitted distribution of its stat. A push on a whole
+// number line returns the stake.
+func (p PropPick) ModelExpectedValue() (float64, bool) {
+	dist, ok := p.Distributions[p.Stat]
+	if !ok {
+		return 0, false
+	}
+	line := p.GetLine()
+	if line.Odds == 0 {
+		return 0, false
+	}
+	over, under := ProbOver(dist, line.Line), ProbUnder(dist, line.Line)
+	win, lose := over, under
+	if p.Side != "Over" {
+		win, lose = under, over
+	}
+	return win*(odds.DecimalOdds(line.Odds)-1) - lose, true
+}
+
+// ModelProbabilityEdge is how far the model probability of the pick's side
+// is above the break-even probability of its price
+func (p PropPick) ModelProbabilityEdge() (float64, bool) {
+	prob, ok := p.ModelProbability()
+	if !ok || p.GetLine().Odds == 0 {
+		return 0, false
+	}
+	return prob - odds.ImpliedProbability(p.GetLine().Odds), true
+}
+
 func (p PropSelector) PickProps(props map[string]map[string]odds.PlayerOdds, analyses []Analysis, date time.Time, savePicks bool) ([]PropPick, error) {
 	var pPicks, selectedPicks []PropPick
 	for _, analysis := range analyses {
@@ -103,6 +200,7 @@ func (p PropSelector) PickProps(props map[string]map[string]odds.PlayerOdds, ana
 				Diff:       diff,
 				PDiff:      pDiff,
 				BetSize:    p.BetSize,
+				Date:       date,
 				PlayerOdds: line,
 				Analysis:   analysis,
 			}
@@ -110,22 +208,9 @@ func (p PropSelector) PickProps(props map[string]map[string]odds.PlayerOdds, ana
 		}
 	}
 
-	p.sortPicks(pPicks)
+	p.sortPicks(pPicks, StatRankSort)
 
-	var overCount, underCount int
-	for _, pick := range pPicks {
-		if (pick.Side == "Over" && overCount >= p.MaxOver) || (pick.Side == "Under" && underCount >= p.MaxUnder) {
-			continue
-		}
-		if p.isPickElligible(pick) {
-			selectedPicks = append(selectedPicks, pick)
-			if pick.Side == "Over" {
-				overCount++
-			} else {
-				underCount++
-			}
-		}
-	}
+	selectedPicks = p.selectPicks(pPicks)
 
 	if savePicks {
 		models := p.convertToPicksModel(selectedPicks, date)
@@ -158,6 +243,7 @@ func (p PropSelector) PickAlternateProps(props map[string]map[string][]odds.Play
 					Diff:       diff,
 					PDiff:      pDiff,
 					BetSize:    p.BetSize,
+					Date:       date,
 					PlayerLine: line,
 					Analysis:   analysis,
 				}
@@ -166,22 +252,11 @@ func (p PropSelector) PickAlternateProps(props map[string]map[string][]odds.Play
 		}
 	}
 
-	p.sortPicks(pPicks)
+	// Every alternate line of a stat shares a mean, so by default they are
+	// ranked by expected value under the fitted distributions
+	p.sortPicks(pPicks, EVSort)
 
-	var overCount, underCount int
-	for _, pick := range pPicks {
-		if (pick.Side == "Over" && overCount >= p.MaxOver) || (pick.Side == "Under" && underCount >= p.MaxUnder) {
-			continue
-		}
-		if p.isPickElligible(pick) {
-			selectedPicks = append(selectedPicks, pick)
-			if pick.Side == "Over" {
-				overCount++
-			} else {
-				underCount++
-			}
-		}
-	}
+	selectedPicks = p.selectPicks(pPicks)
 
 	if savePicks {
 		models := p.convertToPicksModel(selectedPicks, date)
@@ -195,6 +270,62 @@ func (p PropSelector) PickAlternateProps(props map[string]map[string][]odds.Play
 	return selectedPicks, nil
 }
 
+// selectPicks walks the ranked picks and keeps every eligible one that fits
+// under the selector's caps
+func (p PropSelector) selectPicks(pPicks []PropPick) []PropPick {
+	type market struct {
+		playerIndex string
+		stat        string
+		side        string
+	}
+	var selectedPicks []PropPick
+	var overCount, underCount int
+	playerCounts := make(map[string]int)
+	gameCounts := make(map[string]int)
+	teamCounts := make(map[string]int)
+	statCounts := make(map[string]int)
+	markets := make(map[market]bool)
+	underCap := func(counts map[string]int, key string, limit int) bool {
+		return limit == 0 || key == "" || counts[key] < limit
+	}
+
+	for _, pick := range pPicks {
+		if p.TotalMax != 0 && len(selectedPicks) >= p.TotalMax {
+			break
+		}
+		if (pick.Side == "Over" && overCount >= p.MaxOver) || (pick.Side == "Under" && underCount >= p.MaxUnder) {
+			continue
+		}
+		key := market{playerIndex: pick.Analysis.PlayerIndex, stat: pick.Stat, side: pick.Side}
+		if p.OneAlternatePerSide && markets[key] {
+			continue
+		}
+		if !underCap(playerCounts, pick.Analysis.PlayerIndex, p.MaxPerPlayer) ||
+			!underCap(gameCounts, pick.GameKey(), p.MaxPerGame) ||
+			!underCap(teamCounts, pick.TeamIndex, p.MaxPerTeam) ||
+			!underCap(statCounts, pick.Stat, p.MaxPerStat) {
+			continue
+		}
+		if !p.isPickElligible(pick) {
+			continue
+		}
+
+		selectedPicks = append(selectedPicks, pick)
+		if pick.Side == "Over" {
+			overCount++
+		} else {
+			underCount++
+		}
+		playerCounts[pick.Analysis.PlayerIndex]++
+		gameCounts[pick.GameKey()]++
+		teamCounts[pick.TeamIndex]++
+		statCounts[pick.Stat]++
+		markets[key] = true
+	}
+
+	return selectedPicks
+}
+
 func (p PropSelector) convertToPicksModel(pPicks []PropPick, date time.Time) []picks.PropPick {
 	var models []picks.PropPick
 	for _, pick := range pPicks {
@@ -209,19 +340,83 @@ func (p PropSelector) convertToPicksModel(pPicks []PropPick, date time.Time) []p
 	return models
 }
 
-func (p PropSelector) sortPicks(picks []PropPick) {
+// sortPicks orders picks by the selector's SortType, or by fallback when it is
+// DefaultSort. Picks are ranked by stat first, so picks that tie, or that have
+// no model probability under a model sort, stay in stat order after the rest.
+// SortDir flips the stat ranking itself only when sorting by stat rank.
+func (p PropSelector) sortPicks(picks []PropPick, fallback SortType) {
+	sortType := p.SortType
+	if sortType == DefaultSort {
+		sortType = fallback
+	}
+	desc := p.SortDir != SortAsc
+
+	// Only the stat ranking follows the sort direction, the strongest edge
+	// within a stat always comes first
+	rankings := p.statWeights()
+	statRankDesc := sortType != StatRankSort || desc
 	sort.Slice(picks, func(i, j int) bool {
-		rankings := map[string]int{
-			"points":   3,
-			"rebounds": 2,
-			"assists":  1,
+		a, b := picks[i], picks[j]
+		if a.Stat == b.Stat {
+			return math.Abs(float64(a.PDiff)) > math.Abs(float64(b.PDiff))
 		}
-		if picks[i].Stat == picks[j].Stat {
-			return math.Abs(float64(picks[i].PDiff)) > math.Abs(float64(picks[j].PDiff))
-		} else {
-			return rankings[picks[i].Stat] > rankings[picks[j].Stat]
+		if statRankDesc {
+			return rankings[a.Stat] > rankings[b.Stat]
 		}
+		return rankings[a.Stat] < rankings[b.Stat]
 	})
+	if sortType == StatRankSort {
+		return
+	}
+
+	type keyed struct {
+		pick  PropPick
+		value float64
+		ok    bool
+	}
+	keyedPicks := make([]keyed, len(picks))
+	for i, pick := range picks {
+		value, ok := sortValue(pick, sortType)
+		keyedPicks[i] = keyed{pick: pick, value: value, ok: ok}
+	}
+	sort.SliceStable(keyedPicks, func(i, j int) bool {
+		a, b := keyedPicks[i], keyedPicks[j]
+		if a.ok != b.ok {
+			return a.ok
+		}
+		if !a.ok || a.value == b.value {
+			return false
+		}
+		return (a.value > b.value) == desc
+	})
+	for i, k := range keyedPicks {
+		picks[i] = k.pick
+	}
+}
+
+// statWeights ranks the selector's sport's stats
+func (p PropSelector) statWeights() map[string]float64 {
+	sport := p.Sport
+	if sport == "" {
+		sport = sports.NBA
+	}
+	return sports.Configs[sport].Analysis.StatWeights
+}
+
+// sortValue is the pick's key under the sort type. It is false when the pick
+// has no model probability to sort by.
+func sortValue(pick PropPick, sortType SortType) (float64, bool) {
+	switch sortType {
+	case DiffSort:
+		return float64(pick.Diff), true
+	case PDiffSort:
+		return float64(pick.PDiff), true
+	case ProbEdgeSort:
+		return pick.ModelProbabilityEdge()
+	case EVSort:
+		return pick.ModelExpectedValue()
+	}
+	return 0, false
 }
 
 func (p PropSelector) isPickElligible(pick PropPick) bool {
@@ -248,19 +443,31 @@ func (p PropSelector) isPickElligible(pick PropPick) bool {
 		return false
 	}
 
-	nbaPred := pick.Prediction.(players.NBAAvg)
-	if p.MinMinutes != 0 && nbaPred.Minutes < p.MinMinutes {
+	if p.MinMinutes != 0 && pick.Prediction.PlayingTime() < p.MinMinutes {
+		return false
+	}
+	if p.MinGames != 0 && pick.Prediction.GamesPlayed() < p.MinGames {
+		return false
+	}
+	if p.RequireOutlier && !pick.HasOutlier(pick.Stat, pick.Side) {
 		return false
 	}
-	if p.MinGames != 0 && nbaPred.NumGames < p.MinGames {
+	if len(p.Filters) > 0 && !p.Filters.Matches(pick) {
 		return false
 	}
+	if p.Thresholds == nil {
+		return len(p.Filters) > 0
+	}
 	threshold, ok := p.Thresholds[pick.Stat]
 	if !ok {
 		return false
 	}
-	if p.RequireOutlier && !pick.HasOutlier(pick.Stat, pick.Side) {
-		return false
+	if p.TresholdType == EV {
+		ev, ok := pick.ModelExpectedValue()
+		if !ok {
+			return false
+		}
+		diff = ev
 	}
 	return diff > float64(threshold)
 }
@@ -321,329 +528,98 @@ func runPickProps() ([]PropPick, error) {
 	t := time.Now()
 	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
 
-	// Gather player Odds map for upcoming games
-	oddsMap, err := odds.GetPlayerOddsForDate(sports.NBA, today)
+	pickers, err := LoadStrategySelectors()
 	if err != nil {
 		return picks, err
 	}
-
-	var results []Analysis
-	analysisService := NewAnalysisService(AnalysisServiceDeps{})
-	rosterMap, err := players.GetActiveRosters()
-	if err != nil {
-		return picks, err
+	sportPickers, sportOrder := selectorsBySport(pickers)
+	for _, sport := range sportOrder {
+		sportPicks, err := runPickPropsForSport(sport, sportPickers[sport], today)
+		if errors.Is(err, sports.ErrUnsupportedSport) {
+			log.Printf("Skipping %d %s strategies: %v", len(sportPickers[sport]), sport, err)
+			continue
+		}
+		if err != nil {
+			return picks, err
+		}
+		picks = append(picks, sportPicks...)
 	}
-	matchups := scraper.ScrapeTodaysGames()
 
-	for _, matchup := range matchups {
-		results = append(results, analysisService.RunAnalysisOnGame(rosterMap[matchup[0]], rosterMap[matchup[1]], today, true, true)...)
-		results = append(results, analysisService.RunAnalysisOnGame(rosterMap[matchup[1]], rosterMap[matchup[0]], today, true, true)...)
-	}
-
-	picker := PropSelector{
-		StratId:   1,
-		StratName: "Percent",
-		Thresholds: map[string]float32{
-			"points":   .3,
-			"rebounds": 10,
-			"assists":  10,
-		},
-		TresholdType:   Percent,
-		RequireOutlier: true,
-		MinGames:       10,
-		MinOdds:        -135,
-		BetSize:        100,
-		MaxOver:        100,
-		MaxUnder:       0,
-		TotalMax:       100,
-	}
-	picks, err = picker.PickProps(oddsMap, results, today, true)
-	if err != nil {
-		return picks, err
-	}
-	log.Printf("================================%v=========================================", picker.StratName)
-	for _, pick := range picks {
-		log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f, PDiff: %.2f, ID: %v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.Over.Line, pick.Diff, pick.PDiff, pick.LineId)
-	}
-
-	prpicker := PropSelector{
-		StratId:   2,
-		StratName: "Raw",
-		Thresholds: map[string]float32{
-			"points":   2.5,
-			"rebounds": 1000,
-			"assists":  1000,
-		},
-		TresholdType:   Raw,
-		RequireOutlier: true,
-		MinGames:       10,
-		MinOdds:        -135,
-		BetSize:        100,
-		MaxOver:        100,
-		MaxUnder:       0,
-		TotalMax:       100,
-	}
-	apicks, err := prpicker.PickProps(oddsMap, results, today, true)
-	if err != nil {
-		return picks, err
-	}
-	log.Printf("================================%v=========================================", prpicker.StratName)
-	for _, pick := range apicks {
-		log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f, ID: %v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.Over.Line, pick.Diff, pick.LineId)
-	}
-
-	rpicker := PropSelector{
-		StratId:   3,
-		StratName: "Raw",
-		Thresholds: map[string]float32{
-			"points":   1000,
-			"rebounds": 1,
-			"assists":  1000,
-		},
-		TresholdType:   Raw,
-		RequireOutlier: true,
-		MinGames:       10,
-		MinOdds:        -135,
-		BetSize:        100,
-		MaxOver:        100,
-		MaxUnder:       0,
-		TotalMax:       100,
-	}
-	prpicks, err := rpicker.PickProps(oddsMap, results, today, true)
-	if err != nil {
-		return picks, err
-	}
-	log.Printf("================================%v=========================================", rpicker.StratName)
-	for _, pick := range prpicks {
-		log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f, ID: %v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.Over.Line, pick.Diff, pick.LineId)
-	}
-
-	tpicker := PropSelector{
-		StratId:   4,
-		StratName: "Threes",
-		Thresholds: map[string]float32{
-			"points":   1000,
-			"rebounds": 1000,
-			"assists":  1000,
-			"threes":   .6,
-		},
-		TresholdType:   Percent,
-		RequireOutlier: true,
-		MinGames:       10,
-		MinOdds:        -135,
-		BetSize:        100,
-		MaxOver:        100,
-		MaxUnder:       0,
-		TotalMax:       100,
-	}
-	tpicks, err := tpicker.PickProps(oddsMap, results, today, true)
-	if err != nil {
-		return picks, err
-	}
-	log.Printf("================================%v=========================================", rpicker.StratName)
-	for _, pick := range tpicks {
-		log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f, ID: %v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.Over.Line, pick.Diff, pick.LineId)
-	}
+	return picks, nil
+}
 
-	altOddsMap, err := odds.GetAlternatePlayerOddsForDate(sports.NBA, today)
-	if err != nil {
-		return picks, err
-	}
-	altPicker := PropSelector{
-		StratId:   5,
-		StratName: "Alt Points",
-		Thresholds: map[string]float32{
-			"points":   -.3,
-			"rebounds": 1000,
-			"assists":  1000,
-			"threes":   1000,
-		},
-		TresholdType:   Percent,
-		RequireOutlier: true,
-		MinGames:       10,
-		MinOdds:        200,
-		MaxOdds:        600,
-		MaxLine:        20,
-		BetSize:        100,
-		MaxOver:        100,
-		MaxUnder:       0,
-		TotalMax:       100,
-	}
-	altPicks, err := altPicker.PickAlternateProps(altOddsMap, results, today, true)
-	if err != nil {
-		return picks, err
-	}
-	log.Printf("================================%v=========================================", altPicker.StratName)
-	for _, pick := range altPicks {
-		log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f, Odds: %v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.GetLine().Line, pick.Diff, pick.GetLine().Odds)
-	}
-	altRPicker := PropSelector{
-		StratId:   10,
-		StratName: "Alt Rebounds",
-		Thresholds: map[string]float32{
-			"points":   1000,
-			"rebounds": -100,
-			"assists":  1000,
-			"threes":   1000,
-		},
-		TresholdType:   Raw,
-		RequireOutlier: true,
-		MinGames:       10,
-		MinOdds:        300,
-		MaxOdds:        600,
-		MaxLine:        10,
-		BetSize:        100,
-		MaxOver:        100,
-		MaxUnder:       0,
-		TotalMax:       100,
-	}
-	altRPicks, err := altRPicker.PickAlternateProps(altOddsMap, results, today, true)
-	if err != nil {
-		return picks, err
+// selectorsBySport groups the selectors by the sport they pick, with
+// strategies that name no sport picked for the NBA
+func selectorsBySport(selectors []PropSelector) (map[sports.Sport][]PropSelector, []sports.Sport) {
+	bySport := make(map[sports.Sport][]PropSelector)
+	var order []sports.Sport
+	for _, selector := range selectors {
+		sport := selector.Sport
+		if sport == "" {
+			sport = sports.NBA
+		}
+		if _, ok := bySport[sport]; !ok {
+			order = append(order, sport)
+		}
+		bySport[sport] = append(bySport[sport], selector)
 	}
-	log.Printf("================================%v=========================================", altRPicker.StratName)
-	for _, pick := range altRPicks {
-		log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f, Odds: %v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.GetLine().Line, pick.Diff, pick.GetLine().Odds)
-	}
-
-	altAPicker := PropSelector{
-		StratId:   11,
-		StratName: "Alt Assists",
-		Thresholds: map[string]float32{
-			"points":   1000,
-			"rebounds": 1000,
-			"assists":  -100,
-			"threes":   1000,
-		},
-		TresholdType:   Raw,
-		RequireOutlier: true,
-		MinGames:       10,
-		MinOdds:        300,
-		MaxOdds:        600,
-		MaxLine:        9,
-		BetSize:        100,
-		MaxOver:        100,
-		MaxUnder:       0,
-		TotalMax:       100,
-	}
-	altAPicks, err := altAPicker.PickAlternateProps(altOddsMap, results, today, true)
+
+	return bySport, order
+}
+
+func runPickPropsForSport(sport sports.Sport, pickers []PropSelector, today time.Time) ([]PropPick, error) {
+	var picks []PropPick
+
+	// Gather today's matchups before any odds so sports without a schedule
+	// are skipped cheaply
+	matchups, err := scraper.ScrapeTodaysGames(sport)
 	if err != nil {
 		return picks, err
 	}
-	log.Printf("================================%v=========================================", altAPicker.StratName)
-	for _, pick := range altAPicks {
-		log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f, Odds: %v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.GetLine().Line, pick.Diff, pick.GetLine().Odds)
-	}
-
-	altTPicker := PropSelector{
-		StratId:   12,
-		StratName: "Alt Threes",
-		Thresholds: map[string]float32{
-			"points":   1000,
-			"rebounds": 1000,
-			"assists":  1000,
-			"threes":   -100,
-		},
-		TresholdType:   Raw,
-		RequireOutlier: true,
-		MinGames:       10,
-		MinOdds:        200,
-		MaxOdds:        600,
-		MaxLine:        3,
-		BetSize:        100,
-		MaxOver:        100,
-		MaxUnder:       0,
-		TotalMax:       100,
-	}
-	altTPicks, err := altTPicker.PickAlternateProps(altOddsMap, results, today, true)
+
+	// Gather player Odds map for upcoming games
+	oddsMap, err := odds.GetPlayerOddsForDate(context.Background(), sport, today, odds.BestPrice{})
 	if err != nil {
 		return picks, err
 	}
-	log.Printf("================================%v=========================================", altTPicker.StratName)
-	for _, pick := range altTPicks {
-		log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f, Odds: %v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.GetLine().Line, pick.Diff, pick.GetLine().Odds)
-	}
-
-	altPPicker := PropSelector{
-		StratId:   6,
-		StratName: "Alt Points(Longshot)",
-		Thresholds: map[string]float32{
-			"points":   -100,
-			"rebounds": 1000,
-			"assists":  1000,
-			"threes":   1000,
-		},
-		TresholdType:   Raw,
-		RequireOutlier: true,
-		MinGames:       10,
-		MinOdds:        600,
-		MaxLine:        20,
-		BetSize:        100,
-		MaxOver:        100,
-		MaxUnder:       0,
-		TotalMax:       100,
-	}
-	altPPicks, err := altPPicker.PickAlternateProps(altOddsMap, results, today, true)
+
+	var results []Analysis
+	analysisService := NewAnalysisService(AnalysisServiceDeps{})
+	rosterMap, err := players.GetActiveRosters(sport)
 	if err != nil {
 		return picks, err
 	}
-	log.Printf("================================%v=========================================", altPPicker.StratName)
-	for _, pick := range altPPicks {
-		log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f, Odds: %v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.GetLine().Line, pick.Diff, pick.GetLine().Odds)
-	}
-	altALPicker := PropSelector{
-		StratId:   8,
-		StratName: "Alt Assists(Longshot)",
-		Thresholds: map[string]float32{
-			"points":   1000,
-			"rebounds": 1000,
-			"assists":  -100,
-			"threes":   1000,
-		},
-		TresholdType:   Raw,
-		RequireOutlier: true,
-		MinGames:       10,
-		MinOdds:        600,
-		MaxLine:        9,
-		BetSize:        100,
-		MaxOver:        100,
-		MaxUnder:       0,
-		TotalMax:       100,
-	}
-	altALPicks, err := altALPicker.PickAlternateProps(altOddsMap, results, today, true)
-	if err != nil {
-		return picks, err
+
+	for _, matchup := range matchups {
+		for _, sides := range [][2]string{{matchup[0], matchup[1]}, {matchup[1], matchup[0]}} {
+			analyses, err := analysisService.GetGameAnalysis(context.Background(), sport, rosterMap[sides[0]], rosterMap[sides[1]], today, false, true)
+			if err != nil {
+				return picks, err
+			}
+			results = append(results, analyses...)
+		}
 	}
-	log.Printf("================================%v=========================================", altALPicker.StratName)
-	for _, pick := range altALPicks {
-		log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f, Odds: %v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.GetLine().Line, pick.Diff, pick.GetLine().Odds)
-	}
-	altTLPicker := PropSelector{
-		StratId:   9,
-		StratName: "Alt Threes(Longshot)",
-		Thresholds: map[string]float32{
-			"points":   1000,
-			"rebounds": 1000,
-			"assists":  1000,
-			"threes":   -100,
-		},
-		TresholdType:   Raw,
-		RequireOutlier: true,
-		MinGames:       10,
-		MinOdds:        600,
-		MaxLine:        4,
-		BetSize:        100,
-		MaxOver:        100,
-		MaxUnder:       0,
-		TotalMax:       100,
-	}
-	altTLPicks, err := altTLPicker.PickAlternateProps(altOddsMap, results, today, true)
+
+	altOddsMap, err := odds.GetAlternatePlayerOddsForDate(context.Background(), sport, today, odds.BestPrice{})
 	if err != nil {
 		return picks, err
 	}
-	log.Printf("================================%v=========================================", altTLPicker.StratName)
-	for _, pick := range altTLPicks {
-		log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f, Odds: %v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.GetLine().Line, pick.Diff, pick.GetLine().Odds)
+
+	for _, picker := range pickers {
+		var stratPicks []PropPick
+		if picker.LineType == strategies.AlternateLines {
+			stratPicks, err = picker.PickAlternateProps(altOddsMap, results, today, true)
+		} else {
+			stratPicks, err = picker.PickProps(oddsMap, results, today, true)
+		}
+		if err != nil {
+			return picks, err
+		}
+		log.Printf("================================%v=========================================", picker.StratName)
+		for _, pick := range stratPicks {
+			log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f, Odds: %v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.GetLine().Line, pick.Diff, pick.GetLine().Odds)
+		}
+		picks = append(picks, stratPicks...)
 	}
 
 	return picks, nil
@@ -1,11 +1,14 @@
 package analysis
 
 import (
+	"math"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/mgordon34/kornet-kover/api/odds"
 	"github.com/mgordon34/kornet-kover/api/players"
+	"github.com/mgordon34/kornet-kover/internal/sports"
 )
 
 func TestPropPickGetLine(t *testing.T) {
@@ -45,7 +48,7 @@ func TestSortPicksRanksByStatThenPDiff(t *testing.T) {
 		{Stat: "points", PDiff: 0.8},
 		{Stat: "rebounds", PDiff: 0.7},
 	}
-	selector.sortPicks(items)
+	selector.sortPicks(items, StatRankSort)
 
 	if items[0].Stat != "points" || items[0].PDiff != 0.8 {
 		t.Fatalf("unexpected first pick after sort: %+v", items[0])
@@ -108,3 +111,322 @@ func TestPickPropsAndPickAlternateProps(t *testing.T) {
 		t.Fatalf("expected alternate picks")
 	}
 }
+
+func TestPropPickModelProbabilityAndExpectedValue(t *testing.T) {
+	pick := PropPick{
+		Stat:       "points",
+		Side:       "Over",
+		PlayerLine: odds.PlayerLine{Line: 20, Odds: 100, Side: "Over"},
+		Analysis:   Analysis{Distributions: map[string]StatDistribution{"points": Poisson{Lambda: 20}}},
+	}
+	over, ok := pick.ModelProbability()
+	if !ok || math.Abs(over-ProbOver(Poisson{Lambda: 20}, 20)) > 1e-12 {
+		t.Fatalf("ModelProbability() = %v, %v", over, ok)
+	}
+	under := ProbUnder(Poisson{Lambda: 20}, 20)
+	if ev, _ := pick.ModelExpectedValue(); math.Abs(ev-(over-under)) > 1e-12 {
+		t.Fatalf("expected the push to return the stake, got ev %v", ev)
+	}
+
+	pick.Side = "Under"
+	if prob, _ := pick.ModelProbability(); prob != under {
+		t.Fatalf("under probability = %v, want %v", prob, under)
+	}
+
+	pick.PlayerLine.Odds = 0
+	if _, ok := pick.ModelExpectedValue(); ok {
+		t.Fatalf("expected no EV without a price")
+	}
+	pick.Stat = "rebounds"
+	if _, ok := pick.ModelProbability(); ok {
+		t.Fatalf("expected no probability without a distribution")
+	}
+	if _, ok := pick.ModelExpectedValue(); ok {
+		t.Fatalf("expected no EV without a distribution")
+	}
+}
+
+func TestPickAlternatePropsRanksByExpectedValue(t *testing.T) {
+	analysis := Analysis{
+		PlayerIndex: "p1",
+		Prediction:  players.NBAAvg{NumGames: 20, Minutes: 35, Points: 26, Rebounds: 9},
+		Distributions: map[string]StatDistribution{
+			"points": NegativeBinomial{Mu: 26, Variance: 60},
+		},
+	}
+	altProps := map[string]map[string][]odds.PlayerLine{
+		"p1": {
+			"points": {
+				{Id: 1, Side: "Over", Line: 19.5, Odds: -600},
+				{Id: 2, Side: "Over", Line: 24.5, Odds: -110},
+				{Id: 3, Side: "Over", Line: 29.5, Odds: 250},
+			},
+			"rebounds": {
+				{Id: 4, Side: "Over", Line: 4.5, Odds: -400},
+			},
+		},
+	}
+	selector := PropSelector{
+		Thresholds: map[string]float32{"points": -10, "rebounds": -10},
+		MinOdds:    -1000,
+		MaxOver:    10,
+		MaxUnder:   10,
+	}
+
+	picks, err := selector.PickAlternateProps(altProps, []Analysis{analysis}, time.Now(), false)
+	if err != nil || len(picks) != 4 {
+		t.Fatalf("PickAlternateProps() = %+v, %v", picks, err)
+	}
+	var prev float64 = math.Inf(1)
+	for _, pick := range picks[:3] {
+		ev, ok := pick.ModelExpectedValue()
+		if !ok || ev > prev {
+			t.Fatalf("expected points picks by descending EV, got %+v", picks)
+		}
+		prev = ev
+	}
+	if picks[0].LineId != 3 || picks[3].LineId != 4 {
+		t.Fatalf("expected the +250 over first and the pick without a distribution last, got %v %v", picks[0].LineId, picks[3].LineId)
+	}
+}
+
+func TestSortPicksModes(t *testing.T) {
+	dist := map[string]StatDistribution{"points": Poisson{Lambda: 25}}
+	items := []PropPick{
+		{LineId: 1, Stat: "points", Side: "Over", Diff: 1, PDiff: .05, PlayerLine: odds.PlayerLine{Line: 24.5, Odds: -110}, Analysis: Analysis{Distributions: dist}},
+		{LineId: 2, Stat: "points", Side: "Over", Diff: 5, PDiff: .25, PlayerLine: odds.PlayerLine{Line: 19.5, Odds: -500}, Analysis: Analysis{Distributions: dist}},
+		{LineId: 3, Stat: "rebounds", Side: "Over", Diff: 3, PDiff: .6, PlayerLine: odds.PlayerLine{Line: 4.5, Odds: 100}},
+		{LineId: 4, Stat: "points", Side: "Over", Diff: -3, PDiff: -.1, PlayerLine: odds.PlayerLine{Line: 27.5, Odds: 300}, Analysis: Analysis{Distributions: dist}},
+	}
+	order := func(selector PropSelector, fallback SortType) []int {
+		sorted := append([]PropPick(nil), items...)
+		selector.sortPicks(sorted, fallback)
+		var ids []int
+		for _, pick := range sorted {
+			ids = append(ids, pick.LineId)
+		}
+		return ids
+	}
+	equal := func(got []int, want ...int) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	if got := order(PropSelector{SortType: DiffSort}, StatRankSort); !equal(got, 2, 3, 1, 4) {
+		t.Fatalf("diff desc = %v", got)
+	}
+	if got := order(PropSelector{SortType: DiffSort, SortDir: SortAsc}, StatRankSort); !equal(got, 4, 1, 3, 2) {
+		t.Fatalf("diff asc = %v", got)
+	}
+	if got := order(PropSelector{SortType: PDiffSort}, EVSort); !equal(got, 3, 2, 1, 4) {
+		t.Fatalf("pdiff desc = %v", got)
+	}
+	if got := order(PropSelector{SortType: StatRankSort, SortDir: SortAsc}, EVSort); !equal(got, 3, 2, 4, 1) {
+		t.Fatalf("stat rank asc should keep the strongest edge first within a stat, got %v", got)
+	}
+	if got := order(PropSelector{}, StatRankSort); !equal(got, 2, 4, 1, 3) {
+		t.Fatalf("default mainline sort = %v", got)
+	}
+
+	// The +300 on 27.5 is the best value against a mean of 25, and rebounds
+	// have no distribution so sort last whichever way
+	ev := order(PropSelector{}, EVSort)
+	if !equal(ev, 4, 2, 1, 3) {
+		t.Fatalf("default alternate sort = %v", ev)
+	}
+	if got := order(PropSelector{SortType: EVSort, SortDir: SortAsc}, StatRankSort); !equal(got, 1, 2, 4, 3) {
+		t.Fatalf("ev asc = %v", got)
+	}
+	edge := order(PropSelector{SortType: ProbEdgeSort}, StatRankSort)
+	if edge[3] != 3 {
+		t.Fatalf("expected the pick without a distribution last, got %v", edge)
+	}
+	for i := 0; i < 2; i++ {
+		a, _ := items[edge[i]-1].ModelProbabilityEdge()
+		b, _ := items[edge[i+1]-1].ModelProbabilityEdge()
+		if a < b {
+			t.Fatalf("prob edge should descend, got %v", edge)
+		}
+	}
+	if _, ok := (PropPick{Stat: "points", Analysis: Analysis{Distributions: dist}}).ModelProbabilityEdge(); ok {
+		t.Fatalf("expected no edge without a price")
+	}
+}
+
+func TestEVThreshold(t *testing.T) {
+	selector := PropSelector{TresholdType: EV, Thresholds: map[string]float32{"points": .05}, MinOdds: -1000}
+	pick := PropPick{
+		Stat:       "points",
+		Side:       "Over",
+		PlayerLine: odds.PlayerLine{Line: 27.5, Odds: 300},
+		Analysis: Analysis{
+			Prediction:    players.NBAAvg{NumGames: 10, Minutes: 30, Points: 25},
+			Distributions: map[string]StatDistribution{"points": Poisson{Lambda: 25}},
+		},
+	}
+	if ev, _ := pick.ModelExpectedValue(); ev <= .05 || !selector.isPickElligible(pick) {
+		t.Fatalf("expected a %v EV pick to clear the threshold", ev)
+	}
+	pick.PlayerLine.Odds = 120
+	if selector.isPickElligible(pick) {
+		t.Fatalf("expected a -EV pick to miss the threshold")
+	}
+	pick.Distributions = nil
+	if selector.isPickElligible(pick) {
+		t.Fatalf("expected a pick without a distribution to miss an EV threshold")
+	}
+}
+
+func TestSelectPicksEnforcesCaps(t *testing.T) {
+	pick := func(id int, player string, team string, opponent string, stat string, side string) PropPick {
+		return PropPick{
+			LineId:     id,
+			Stat:       stat,
+			Side:       side,
+			PlayerLine: odds.PlayerLine{Id: id, Line: 10, Odds: 100, Side: side},
+			Analysis: Analysis{
+				PlayerIndex:   player,
+				TeamIndex:     team,
+				OpponentIndex: opponent,
+				Prediction:    players.NBAAvg{NumGames: 10, Minutes: 30},
+			},
+		}
+	}
+	ranked := []PropPick{
+		pick(1, "p1", "BOS", "NYK", "points", "Over"),
+		pick(2, "p1", "BOS", "NYK", "points", "Over"),
+		pick(3, "p1", "BOS", "NYK", "rebounds", "Over"),
+		pick(4, "p2", "BOS", "NYK", "points", "Under"),
+		pick(5, "p3", "NYK", "BOS", "assists", "Over"),
+		pick(6, "p4", "LAL", "", "points", "Over"),
+	}
+	base := PropSelector{Thresholds: map[string]float32{"points": -100, "rebounds": -100, "assists": -100}, MaxOver: 10, MaxUnder: 10}
+	ids := func(selector PropSelector) []int {
+		var got []int
+		for _, pick := range selector.selectPicks(ranked) {
+			got = append(got, pick.LineId)
+		}
+		return got
+	}
+	equal := func(got []int, want ...int) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	if got := ids(base); !equal(got, 1, 2, 3, 4, 5, 6) {
+		t.Fatalf("uncapped = %v", got)
+	}
+	capped := base
+	capped.TotalMax = 2
+	if got := ids(capped); !equal(got, 1, 2) {
+		t.Fatalf("total max = %v", got)
+	}
+	capped = base
+	capped.MaxPerPlayer = 1
+	if got := ids(capped); !equal(got, 1, 4, 5, 6) {
+		t.Fatalf("max per player = %v", got)
+	}
+	capped = base
+	capped.MaxPerGame = 3
+	if got := ids(capped); !equal(got, 1, 2, 3, 6) {
+		t.Fatalf("max per game should skip the rest of BOS-NYK but not a game it can't place, got %v", got)
+	}
+	capped = base
+	capped.MaxPerTeam = 2
+	if got := ids(capped); !equal(got, 1, 2, 5, 6) {
+		t.Fatalf("max per team = %v", got)
+	}
+	capped = base
+	capped.MaxPerStat = 2
+	if got := ids(capped); !equal(got, 1, 2, 3, 5) {
+		t.Fatalf("max per stat = %v", got)
+	}
+	capped = base
+	capped.OneAlternatePerSide = true
+	if got := ids(capped); !equal(got, 1, 3, 4, 5, 6) {
+		t.Fatalf("one alternate per side = %v", got)
+	}
+	capped = base
+	capped.MaxUnder = 0
+	capped.TotalMax = 4
+	if got := ids(capped); !equal(got, 1, 2, 3, 5) {
+		t.Fatalf("a skipped under should not count toward the total, got %v", got)
+	}
+}
+
+func TestPickAlternatePropsForMLBBatters(t *testing.T) {
+	analyses := []Analysis{
+		{PlayerIndex: "b1", Prediction: players.MLBBattingAvg{NumGames: 12, PAs: 4.2, Hits: 1.6, HomeRuns: .3, RBIs: .9}},
+		{PlayerIndex: "b2", Prediction: players.MLBBattingAvg{NumGames: 2, PAs: 4, Hits: 1.8}},
+	}
+	props := map[string]map[string][]odds.PlayerLine{
+		"b1": {
+			"hits":      {{Id: 1, Side: "Over", Line: 1.5, Odds: 150}},
+			"home_runs": {{Id: 2, Side: "Over", Line: 0.5, Odds: 300}},
+		},
+		"b2": {"hits": {{Id: 3, Side: "Over", Line: 1.5, Odds: 150}}},
+	}
+	selector := PropSelector{
+		Thresholds: map[string]float32{"hits": 0, "home_runs": 0},
+		Sport:      sports.MLB,
+		MinGames:   5,
+		MinMinutes: 4.1,
+		MaxOver:    10,
+	}
+
+	picked, err := selector.PickAlternateProps(props, analyses, time.Now(), false)
+	if err != nil {
+		t.Fatalf("PickAlternateProps() error = %v", err)
+	}
+	if len(picked) != 1 || picked[0].LineId != 1 {
+		t.Fatalf("expected only b1's hits over, got %+v", picked)
+	}
+}
+
+func TestSortPicksRanksBySportStatWeights(t *testing.T) {
+	items := []PropPick{
+		{Stat: "home_runs", PDiff: 0.9},
+		{Stat: "hits", PDiff: 0.1},
+		{Stat: "rbis", PDiff: 0.5},
+		{Stat: "walks", PDiff: 2},
+	}
+	PropSelector{Sport: sports.MLB}.sortPicks(items, StatRankSort)
+
+	var got []string
+	for _, item := range items {
+		got = append(got, item.Stat)
+	}
+	if strings.Join(got, ",") != "hits,rbis,home_runs,walks" {
+		t.Fatalf("expected MLB stats in weight order, got %v", got)
+	}
+}
+
+func TestSelectorsBySport(t *testing.T) {
+	selectors := []PropSelector{
+		{StratId: 1},
+		{StratId: 2, Sport: sports.MLB},
+		{StratId: 3, Sport: sports.NBA},
+	}
+
+	bySport, order := selectorsBySport(selectors)
+	if len(order) != 2 || order[0] != sports.NBA || order[1] != sports.MLB {
+		t.Fatalf("unexpected sport order %v", order)
+	}
+	if len(bySport[sports.NBA]) != 2 || bySport[sports.NBA][1].StratId != 3 || len(bySport[sports.MLB]) != 1 {
+		t.Fatalf("unexpected grouping %+v", bySport)
+	}
+}
@@ -1,60 +1,138 @@
 package analysis
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/mgordon34/kornet-kover/api/players"
+	"github.com/mgordon34/kornet-kover/internal/snapshots"
 	"github.com/mgordon34/kornet-kover/internal/sports"
-	"github.com/mgordon34/kornet-kover/internal/utils"
 )
 
 type Analysis struct {
 	PlayerIndex string
-	BaseStats   players.PlayerAvg
-	Prediction  players.PlayerAvg
-	Outliers    map[string]float32
+	// TeamIndex and OpponentIndex are empty when the rosters didn't say
+	TeamIndex     string
+	OpponentIndex string
+	BaseStats     players.PlayerAvg
+	Prediction    players.PlayerAvg
+	Outliers      map[string]float32
+	// Distributions spread each counting stat's prediction into the chance of
+	// every outcome
+	Distributions map[string]StatDistribution
 }
 
 type AnalysisStore interface {
-	GetPlayerPIPPrediction(playerIndex string, date time.Time) (players.NBAPIPPrediction, error)
-	AddPIPPrediction(predictions []players.NBAPIPPrediction)
+	GetPlayerPIPPrediction(sport sports.Sport, playerIndex string, date time.Time) (players.NBAPIPPrediction, error)
+	AddPIPPrediction(sport sports.Sport, predictions []players.NBAPIPPrediction)
+	GetMLBPlayerPIPPrediction(playerIndex string, date time.Time) (players.MLBPIPPrediction, error)
+	AddMLBPIPPrediction(predictions []players.MLBPIPPrediction) error
+	GetNHLPlayerPIPPrediction(playerIndex string, date time.Time) (players.NHLPIPPrediction, error)
+	AddNHLPIPPrediction(predictions []players.NHLPIPPrediction) error
+	GetNFLPlayerPIPPrediction(playerIndex string, date time.Time) (players.NFLPIPPrediction, error)
+	AddNFLPIPPrediction(predictions []players.NFLPIPPrediction) error
 	GetPlayerPerByYear(sport sports.Sport, player string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg
-	GetPlayerPerWithPlayerByYear(player string, defender string, relationship players.Relationship, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg
+	GetPlayerPerWithPlayerByYear(sport sports.Sport, player string, defender string, relationship players.Relationship, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg
 	GetMLBPlayerPerWithPlayerByYear(player string, defender string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg
+	GetNHLPlayerPerWithPlayerByYear(player string, defender string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg
+	GetNFLPlayerPerAgainstTeamByYear(player string, team string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg
+	GetPlayerStatMoments(sport sports.Sport, player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error)
+	GetMLBPlayerStatMoments(player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error)
+	GetNHLPlayerStatMoments(player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error)
+	GetNFLPlayerStatMoments(player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error)
 	CalculatePIPFactor(controlMap map[int]players.PlayerAvg, relatedMap map[int]players.PlayerAvg) players.PlayerAvg
 }
 
 type AnalysisServiceDeps struct {
-	Store AnalysisStore
+	Store     AnalysisStore
+	Snapshots *snapshots.Store[[]Analysis]
 }
 
+// analysisSnapshotLimit bounds the shared store at a few seasons' worth of
+// game analyses, one entry per team and game
+const analysisSnapshotLimit = 10000
+
+// analysisSnapshots is shared by every service that doesn't bring its own
+// store, so backtests, sweeps and pick-props reuse each other's analyses
+var analysisSnapshots = snapshots.NewStore[[]Analysis](analysisSnapshotLimit)
+
 type AnalysisService struct {
 	deps AnalysisServiceDeps
 }
 
 type defaultAnalysisStore struct{}
 
-func (d defaultAnalysisStore) GetPlayerPIPPrediction(playerIndex string, date time.Time) (players.NBAPIPPrediction, error) {
-	return players.GetPlayerPIPPrediction(playerIndex, date)
+func (d defaultAnalysisStore) GetPlayerPIPPrediction(sport sports.Sport, playerIndex string, date time.Time) (players.NBAPIPPrediction, error) {
+	return players.GetPlayerPIPPrediction(sport, playerIndex, date)
+}
+
+func (d defaultAnalysisStore) AddPIPPrediction(sport sports.Sport, predictions []players.NBAPIPPrediction) {
+	players.AddPIPPrediction(sport, predictions)
+}
+
+func (d defaultAnalysisStore) GetMLBPlayerPIPPrediction(playerIndex string, date time.Time) (players.MLBPIPPrediction, error) {
+	return players.GetMLBPlayerPIPPrediction(playerIndex, date)
+}
+
+func (d defaultAnalysisStore) AddMLBPIPPrediction(predictions []players.MLBPIPPrediction) error {
+	return players.AddMLBPIPPrediction(predictions)
+}
+
+func (d defaultAnalysisStore) GetNHLPlayerPIPPrediction(playerIndex string, date time.Time) (players.NHLPIPPrediction, error) {
+	return players.GetNHLPlayerPIPPrediction(playerIndex, date)
+}
+
+func (d defaultAnalysisStore) AddNHLPIPPrediction(predictions []players.NHLPIPPrediction) error {
+	return players.AddNHLPIPPrediction(predictions)
 }
 
-func (d defaultAnalysisStore) AddPIPPrediction(predictions []players.NBAPIPPrediction) {
-	players.AddPIPPrediction(predictions)
+func (d defaultAnalysisStore) GetNFLPlayerPIPPrediction(playerIndex string, date time.Time) (players.NFLPIPPrediction, error) {
+	return players.GetNFLPlayerPIPPrediction(playerIndex, date)
+}
+
+func (d defaultAnalysisStore) AddNFLPIPPrediction(predictions []players.NFLPIPPrediction) error {
+	return players.AddNFLPIPPrediction(predictions)
 }
 
 func (d defaultAnalysisStore) GetPlayerPerByYear(sport sports.Sport, player string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg {
 	return players.GetPlayerPerByYear(sport, player, startDate, endDate)
 }
 
-func (d defaultAnalysisStore) GetPlayerPerWithPlayerByYear(player string, defender string, relationship players.Relationship, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg {
-	return players.GetPlayerPerWithPlayerByYear(player, defender, relationship, startDate, endDate)
+func (d defaultAnalysisStore) GetPlayerPerWithPlayerByYear(sport sports.Sport, player string, defender string, relationship players.Relationship, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg {
+	return players.GetPlayerPerWithPlayerByYear(sport, player, defender, relationship, startDate, endDate)
 }
 
 func (d defaultAnalysisStore) GetMLBPlayerPerWithPlayerByYear(player string, defender string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg {
 	return players.GetMLBPlayerPerWithPlayerByYear(player, defender, startDate, endDate)
 }
 
+func (d defaultAnalysisStore) GetNHLPlayerPerWithPlayerByYear(player string, defender string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg {
+	return players.GetNHLPlayerPerWithPlayerByYear(player, defender, startDate, endDate)
+}
+
+func (d defaultAnalysisStore) GetNFLPlayerPerAgainstTeamByYear(player string, team string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg {
+	return players.GetNFLPlayerPerAgainstTeamByYear(player, team, startDate, endDate)
+}
+
+func (d defaultAnalysisStore) GetPlayerStatMoments(sport sports.Sport, player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error) {
+	return players.GetPlayerStatMoments(sport, player, startDate, endDate)
+}
+
+func (d defaultAnalysisStore) GetMLBPlayerStatMoments(player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error) {
+	return players.GetMLBPlayerStatMoments(player, startDate, endDate)
+}
+
+func (d defaultAnalysisStore) GetNHLPlayerStatMoments(player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error) {
+	return players.GetNHLPlayerStatMoments(player, startDate, endDate)
+}
+
+func (d defaultAnalysisStore) GetNFLPlayerStatMoments(player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error) {
+	return players.GetNFLPlayerStatMoments(player, startDate, endDate)
+}
+
 func (d defaultAnalysisStore) CalculatePIPFactor(controlMap map[int]players.PlayerAvg, relatedMap map[int]players.PlayerAvg) players.PlayerAvg {
 	return players.CalculatePIPFactor(controlMap, relatedMap)
 }
@@ -63,27 +141,37 @@ func NewAnalysisService(deps AnalysisServiceDeps) *AnalysisService {
 	if deps.Store == nil {
 		deps.Store = defaultAnalysisStore{}
 	}
+	if deps.Snapshots == nil {
+		deps.Snapshots = analysisSnapshots
+	}
 	return &AnalysisService{deps: deps}
 }
 
-func (s *AnalysisService) RunAnalysisOnGame(roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) []Analysis {
-	startDate, _ := time.Parse("2006-01-02", "2018-10-01")
+// RunAnalysisOnGame predicts the roster's basketball lines against its
+// opponents. NBA and WNBA share the model, each reading its own league's games
+func (s *AnalysisService) RunAnalysisOnGame(ctx context.Context, sport sports.Sport, roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) []Analysis {
+	startDate := basketballHistoryStart(sport)
 	var predictedStats []Analysis
 
 	prunedPlayers := prunePlayers(roster)
 	prunedOpponents := prunePlayers(opponents)
+	team, opponent := rosterTeam(roster), rosterTeam(opponents)
 
 	for _, player := range prunedPlayers[:min(len(prunedPlayers), 5)] {
-		controlMap := s.deps.Store.GetPlayerPerByYear(sports.NBA, player, startDate, endDate)
+		// Stop once the caller gives up, without storing a partial run
+		if ctx.Err() != nil {
+			return predictedStats
+		}
+		controlMap := s.deps.Store.GetPlayerPerByYear(sport, player, startDate, endDate)
 
-		currYear := utils.DateToNBAYear(endDate)
+		currYear := players.SeasonYear(sport, endDate)
 		_, ok := controlMap[currYear]
 		if !ok {
 			log.Printf("Player %v has no stats for current year. Skipping...", player)
 			continue
 		}
 
-		pipPred := s.GetOrCreatePrediction(player, prunedOpponents[:min(len(prunedOpponents), 8)], players.Opponent, controlMap, startDate, endDate, forceUpdate)
+		pipPred := s.GetOrCreatePrediction(sport, player, prunedOpponents[:min(len(prunedOpponents), 8)], players.Opponent, controlMap, startDate, endDate, forceUpdate)
 		prediction := players.NBAAvg{
 			NumGames: pipPred.NumGames,
 			Minutes:  pipPred.Minutes,
@@ -98,32 +186,106 @@ func (s *AnalysisService) RunAnalysisOnGame(roster []players.PlayerRoster, oppon
 
 		baseStats := controlMap[currYear].ConvertToStats()
 		outliers := GetOutliers(baseStats, prediction)
+		moments, err := s.deps.Store.GetPlayerStatMoments(sport, player, endDate.AddDate(-distributionLookbackYears, 0, 0), endDate)
+		if err != nil {
+			log.Printf("Could not get stat moments for %v: %v", player, err)
+		}
 		predictedStats = append(
 			predictedStats,
 			Analysis{
-				PlayerIndex: player,
-				BaseStats:   baseStats,
-				Prediction:  prediction,
-				Outliers:    outliers,
+				PlayerIndex:   player,
+				TeamIndex:     team,
+				OpponentIndex: opponent,
+				BaseStats:     baseStats,
+				Prediction:    prediction,
+				Outliers:      outliers,
+				Distributions: FitStatDistributions(prediction, moments),
 			},
 		)
 	}
 
 	if storePIP {
-		s.CreateAndStorePIPPrediction(predictedStats, endDate)
+		s.CreateAndStorePIPPrediction(sport, predictedStats, endDate)
 	}
 
 	return predictedStats
 }
 
-func (s *AnalysisService) RunMLBAnalysisOnGame(roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) []Analysis {
+// basketballHistoryStart is the first day of the seasons predictions are
+// drawn from. Yearly windows start at the season's opening month so one
+// season never spans two of them
+func basketballHistoryStart(sport sports.Sport) time.Time {
+	if sport == sports.WNBA {
+		return time.Date(2018, time.May, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(2018, time.October, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// GetGameAnalysis reads the roster's analysis against its opponents through the
+// snapshot store, only running the analysis when it isn't cached or
+// forceUpdate is set, which refreshes the cached snapshot. Snapshots are
+// dropped once new games for the sport are scraped. For MLB the roster is
+// the batters and the opponents are the pitchers they face. For NHL both sides
+// are skaters and goalies. For NFL the opponents only name the defense faced.
+// A run cut short by ctx returns its error and is not cached.
+func (s *AnalysisService) GetGameAnalysis(ctx context.Context, sport sports.Sport, roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) ([]Analysis, error) {
+	key := snapshots.NewKey(sport, endDate, players.PIPPredVersion(sport), rosterKey(roster, opponents))
+	if !forceUpdate {
+		if analyses, ok := s.deps.Snapshots.Get(key); ok {
+			return analyses, nil
+		}
+	}
+
+	var analyses []Analysis
+	switch sport {
+	case sports.NBA, sports.WNBA:
+		analyses = s.RunAnalysisOnGame(ctx, sport, roster, opponents, endDate, forceUpdate, storePIP)
+	case sports.MLB:
+		analyses = s.RunMLBAnalysisOnGame(ctx, roster, opponents, endDate, forceUpdate, storePIP)
+	case sports.NHL:
+		analyses = s.RunNHLAnalysisOnGame(ctx, roster, opponents, endDate, forceUpdate, storePIP)
+	case sports.NFL:
+		analyses = s.RunNFLAnalysisOnGame(ctx, roster, opponents, endDate, forceUpdate, storePIP)
+	default:
+		log.Printf("Analysis is not supported for sport %v", sport)
+		return nil, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.deps.Snapshots.Put(key, analyses)
+	return analyses, nil
+}
+
+// rosterKey describes both rosters in order, since the order decides which
+// players get analyzed
+func rosterKey(roster []players.PlayerRoster, opponents []players.PlayerRoster) string {
+	var b strings.Builder
+	for _, side := range [][]players.PlayerRoster{roster, opponents} {
+		for _, player := range side {
+			fmt.Fprintf(&b, "%s:%s:%s:%.1f,", player.PlayerIndex, player.TeamIndex, player.Status, player.AvgMins)
+		}
+		b.WriteString("|")
+	}
+	return b.String()
+}
+
+func (s *AnalysisService) RunMLBAnalysisOnGame(ctx context.Context, roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) []Analysis {
 	startDate, _ := time.Parse("2006-01-02", "2019-03-01")
 	var predictedStats []Analysis
 
 	prunedPlayers := prunePlayers(roster)
 	prunedOpponents := prunePlayers(opponents)
+	if len(prunedOpponents) == 0 {
+		log.Printf("No pitchers to face %v. Skipping...", rosterTeam(roster))
+		return predictedStats
+	}
+	team, opponent := rosterTeam(roster), rosterTeam(opponents)
 
 	for _, player := range prunedPlayers[:min(len(prunedPlayers), 9)] {
+		if ctx.Err() != nil {
+			return predictedStats
+		}
 		controlMap := s.deps.Store.GetPlayerPerByYear(sports.MLB, player, startDate, endDate)
 
 		_, ok := controlMap[endDate.Year()]
@@ -132,19 +294,162 @@ func (s *AnalysisService) RunMLBAnalysisOnGame(roster []players.PlayerRoster, op
 			continue
 		}
 
-		pipPred := s.CreateMLBPrediction(player, prunedOpponents[:1], players.Opponent, controlMap, startDate, endDate)
-		log.Printf("PIPPred: %v", pipPred)
+		// Batters are only matched against the starting pitcher
+		prediction := s.GetOrCreateMLBPrediction(player, prunedOpponents[:1], controlMap, startDate, endDate, forceUpdate)
+
+		baseStats := controlMap[endDate.Year()].ConvertToStats()
+		moments, err := s.deps.Store.GetMLBPlayerStatMoments(player, endDate.AddDate(-distributionLookbackYears, 0, 0), endDate)
+		if err != nil {
+			log.Printf("Could not get stat moments for %v: %v", player, err)
+		}
+		predictedStats = append(
+			predictedStats,
+			Analysis{
+				PlayerIndex:   player,
+				TeamIndex:     team,
+				OpponentIndex: opponent,
+				BaseStats:     baseStats,
+				Prediction:    prediction,
+				Outliers:      GetOutliers(baseStats, prediction),
+				Distributions: FitStatDistributions(prediction, moments),
+			},
+		)
+	}
+
+	if storePIP {
+		s.CreateAndStoreMLBPIPPrediction(predictedStats, endDate)
+	}
+
+	return predictedStats
+}
+
+// RunNHLAnalysisOnGame predicts the roster's skater and goalie lines against
+// the opposing skaters and goalie
+func (s *AnalysisService) RunNHLAnalysisOnGame(ctx context.Context, roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) []Analysis {
+	startDate := time.Date(2018, time.October, 1, 0, 0, 0, 0, time.UTC)
+	var predictedStats []Analysis
+
+	prunedPlayers := prunePlayers(roster)
+	prunedOpponents := prunePlayers(opponents)
+	team, opponent := rosterTeam(roster), rosterTeam(opponents)
+	currYear := players.SeasonYear(sports.NHL, endDate)
+
+	for _, player := range prunedPlayers[:min(len(prunedPlayers), 10)] {
+		if ctx.Err() != nil {
+			return predictedStats
+		}
+		controlMap := s.deps.Store.GetPlayerPerByYear(sports.NHL, player, startDate, endDate)
+
+		_, ok := controlMap[currYear]
+		if !ok {
+			log.Printf("Player %v has no stats for current year. Skipping...", player)
+			continue
+		}
+
+		prediction := s.GetOrCreateNHLPrediction(player, prunedOpponents[:min(len(prunedOpponents), 10)], controlMap, startDate, endDate, forceUpdate)
+
+		baseStats := controlMap[currYear].ConvertToStats()
+		moments, err := s.deps.Store.GetNHLPlayerStatMoments(player, endDate.AddDate(-distributionLookbackYears, 0, 0), endDate)
+		if err != nil {
+			log.Printf("Could not get stat moments for %v: %v", player, err)
+		}
+		predictedStats = append(
+			predictedStats,
+			Analysis{
+				PlayerIndex:   player,
+				TeamIndex:     team,
+				OpponentIndex: opponent,
+				BaseStats:     baseStats,
+				Prediction:    prediction,
+				Outliers:      GetOutliers(baseStats, prediction),
+				Distributions: FitStatDistributions(prediction, moments),
+			},
+		)
+	}
+
+	if storePIP {
+		s.CreateAndStoreNHLPIPPrediction(predictedStats, endDate)
+	}
+
+	return predictedStats
+}
+
+// RunNFLAnalysisOnGame predicts the roster's offensive lines against the
+// opposing team's defense
+func (s *AnalysisService) RunNFLAnalysisOnGame(ctx context.Context, roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) []Analysis {
+	startDate := time.Date(2018, time.August, 1, 0, 0, 0, 0, time.UTC)
+	var predictedStats []Analysis
+
+	prunedPlayers := prunePlayers(roster)
+	team, opponent := rosterTeam(roster), rosterTeam(opponents)
+	if opponent == "" {
+		log.Printf("No opponent for %v. Skipping...", team)
+		return predictedStats
+	}
+	currYear := players.SeasonYear(sports.NFL, endDate)
+
+	for _, player := range prunedPlayers[:min(len(prunedPlayers), 12)] {
+		if ctx.Err() != nil {
+			return predictedStats
+		}
+		controlMap := s.deps.Store.GetPlayerPerByYear(sports.NFL, player, startDate, endDate)
+
+		_, ok := controlMap[currYear]
+		if !ok {
+			log.Printf("Player %v has no stats for current year. Skipping...", player)
+			continue
+		}
+
+		prediction := s.GetOrCreateNFLPrediction(player, opponent, controlMap, startDate, endDate, forceUpdate)
 
-		// yearlyStats := controlMap[endDate.Year()].(players.MLBBattingAvg)
+		baseStats := controlMap[currYear].ConvertToStats()
+		moments, err := s.deps.Store.GetNFLPlayerStatMoments(player, endDate.AddDate(-distributionLookbackYears, 0, 0), endDate)
+		if err != nil {
+			log.Printf("Could not get stat moments for %v: %v", player, err)
+		}
+		predictedStats = append(
+			predictedStats,
+			Analysis{
+				PlayerIndex:   player,
+				TeamIndex:     team,
+				OpponentIndex: opponent,
+				BaseStats:     baseStats,
+				Prediction:    prediction,
+				Outliers:      GetOutliers(baseStats, prediction),
+				Distributions: FitStatDistributions(prediction, moments),
+			},
+		)
 	}
 
 	if storePIP {
-		s.CreateAndStorePIPPrediction(predictedStats, endDate)
+		s.CreateAndStoreNFLPIPPrediction(predictedStats, endDate)
 	}
 
 	return predictedStats
 }
 
+// rosterTeam is the team the roster's players are listed on
+func rosterTeam(roster []players.PlayerRoster) string {
+	for _, player := range roster {
+		if player.TeamIndex != "" {
+			return player.TeamIndex
+		}
+	}
+	return ""
+}
+
+// GameKey names the game the analysis is for the same way from either side,
+// or is empty when the teams aren't known
+func (a Analysis) GameKey() string {
+	if a.TeamIndex == "" || a.OpponentIndex == "" {
+		return ""
+	}
+	if a.TeamIndex < a.OpponentIndex {
+		return a.TeamIndex + "-" + a.OpponentIndex
+	}
+	return a.OpponentIndex + "-" + a.TeamIndex
+}
+
 func prunePlayers(roster []players.PlayerRoster) []string {
 	var activePlayers []string
 
@@ -157,27 +462,27 @@ func prunePlayers(roster []players.PlayerRoster) []string {
 	return activePlayers
 }
 
-func (s *AnalysisService) GetOrCreatePrediction(playerIndex string, opponents []string, relationship players.Relationship, controlMap map[int]players.PlayerAvg, startDate time.Time, endDate time.Time, forceUpdate bool) players.NBAPIPPrediction {
+func (s *AnalysisService) GetOrCreatePrediction(sport sports.Sport, playerIndex string, opponents []string, relationship players.Relationship, controlMap map[int]players.PlayerAvg, startDate time.Time, endDate time.Time, forceUpdate bool) players.NBAPIPPrediction {
 	if forceUpdate {
 		log.Printf("Force creating new PIPPrediction on %v players...", len(opponents))
-		return s.CreatePIPPrediction(playerIndex, opponents, relationship, controlMap, startDate, endDate)
+		return s.CreatePIPPrediction(sport, playerIndex, opponents, relationship, controlMap, startDate, endDate)
 	}
 
-	pipPred, err := s.deps.Store.GetPlayerPIPPrediction(playerIndex, endDate)
+	pipPred, err := s.deps.Store.GetPlayerPIPPrediction(sport, playerIndex, endDate)
 	if err != nil {
 		log.Println("Could not find PIPPrediction, creating new:", err)
-		pipPred = s.CreatePIPPrediction(playerIndex, opponents, relationship, controlMap, startDate, endDate)
+		pipPred = s.CreatePIPPrediction(sport, playerIndex, opponents, relationship, controlMap, startDate, endDate)
 	}
 
 	return pipPred
 }
 
-func (s *AnalysisService) CreatePIPPrediction(playerIndex string, opponents []string, relationship players.Relationship, controlMap map[int]players.PlayerAvg, startDate time.Time, endDate time.Time) players.NBAPIPPrediction {
+func (s *AnalysisService) CreatePIPPrediction(sport sports.Sport, playerIndex string, opponents []string, relationship players.Relationship, controlMap map[int]players.PlayerAvg, startDate time.Time, endDate time.Time) players.NBAPIPPrediction {
 	var totalPip players.PlayerAvg
-	currYear := utils.DateToNBAYear(endDate)
+	currYear := players.SeasonYear(sport, endDate)
 
 	for _, defender := range opponents {
-		affectedMap := s.deps.Store.GetPlayerPerWithPlayerByYear(playerIndex, defender, players.Opponent, startDate, endDate)
+		affectedMap := s.deps.Store.GetPlayerPerWithPlayerByYear(sport, playerIndex, defender, players.Opponent, startDate, endDate)
 		pipFactor := s.deps.Store.CalculatePIPFactor(controlMap, affectedMap)
 
 		if totalPip == nil {
@@ -206,7 +511,7 @@ func (s *AnalysisService) CreatePIPPrediction(playerIndex string, opponents []st
 	return prediction
 }
 
-func (s *AnalysisService) CreateAndStorePIPPrediction(analyses []Analysis, date time.Time) {
+func (s *AnalysisService) CreateAndStorePIPPrediction(sport sports.Sport, analyses []Analysis, date time.Time) {
 	log.Printf("Adding %v PIPPredictions to DB", len(analyses))
 	var pPreds []players.NBAPIPPrediction
 	for _, analysis := range analyses {
@@ -228,18 +533,36 @@ func (s *AnalysisService) CreateAndStorePIPPrediction(analyses []Analysis, date
 		pPreds = append(pPreds, pPred)
 	}
 
-	s.deps.Store.AddPIPPrediction(pPreds)
+	s.deps.Store.AddPIPPrediction(sport, pPreds)
 }
 
+func (s *AnalysisService) GetOrCreateMLBPrediction(playerIndex string, opponents []string, controlMap map[int]players.PlayerAvg, startDate time.Time, endDate time.Time, forceUpdate bool) players.MLBBattingAvg {
+	if forceUpdate {
+		log.Printf("Force creating new MLBPIPPrediction on %v players...", len(opponents))
+		return s.CreateMLBPrediction(playerIndex, opponents, players.Opponent, controlMap, startDate, endDate)
+	}
+
+	pipPred, err := s.deps.Store.GetMLBPlayerPIPPrediction(playerIndex, endDate)
+	if err != nil {
+		log.Println("Could not find MLBPIPPrediction, creating new:", err)
+		return s.CreateMLBPrediction(playerIndex, opponents, players.Opponent, controlMap, startDate, endDate)
+	}
+
+	return pipPred.MLBBattingAvg
+}
+
+// CreateMLBPrediction adjusts the batter's per plate appearance rates by how
+// they've done against the pitchers. With no history against any of them the
+// prediction is their current year's line.
 func (s *AnalysisService) CreateMLBPrediction(playerIndex string, opponents []string, relationship players.Relationship, controlMap map[int]players.PlayerAvg, startDate time.Time, endDate time.Time) players.MLBBattingAvg {
 	var totalPip players.PlayerAvg
 
 	for _, defender := range opponents {
-		log.Printf("Batter: %v, Defender: %v", playerIndex, defender)
 		affectedMap := s.deps.Store.GetMLBPlayerPerWithPlayerByYear(playerIndex, defender, startDate, endDate)
-		log.Printf("AffectedMap: %v", affectedMap)
 		pipFactor := s.deps.Store.CalculatePIPFactor(controlMap, affectedMap)
-		log.Printf("PipFactor: %v", pipFactor)
+		if pipFactor == nil {
+			continue
+		}
 
 		if totalPip == nil {
 			totalPip = pipFactor
@@ -247,12 +570,135 @@ func (s *AnalysisService) CreateMLBPrediction(playerIndex string, opponents []st
 			totalPip = totalPip.AddAvg(pipFactor)
 		}
 	}
+	if totalPip == nil {
+		totalPip = players.MLBBattingAvg{}
+	}
 
 	pred := controlMap[endDate.Year()].PredictStats(totalPip).(players.MLBBattingAvg)
 
 	return pred
 }
 
+func (s *AnalysisService) CreateAndStoreMLBPIPPrediction(analyses []Analysis, date time.Time) {
+	log.Printf("Adding %v MLBPIPPredictions to DB", len(analyses))
+	var pPreds []players.MLBPIPPrediction
+	for _, analysis := range analyses {
+		pPreds = append(pPreds, players.MLBPIPPrediction{
+			PlayerIndex:   analysis.PlayerIndex,
+			Date:          date,
+			Version:       players.CurrMLBPIPPredVersion(),
+			MLBBattingAvg: analysis.Prediction.(players.MLBBattingAvg),
+		})
+	}
+
+	if err := s.deps.Store.AddMLBPIPPrediction(pPreds); err != nil {
+		log.Printf("Error storing MLBPIPPredictions: %v", err)
+	}
+}
+
+func (s *AnalysisService) GetOrCreateNHLPrediction(playerIndex string, opponents []string, controlMap map[int]players.PlayerAvg, startDate time.Time, endDate time.Time, forceUpdate bool) players.NHLAvg {
+	if forceUpdate {
+		log.Printf("Force creating new NHLPIPPrediction on %v players...", len(opponents))
+		return s.CreateNHLPrediction(playerIndex, opponents, controlMap, startDate, endDate)
+	}
+
+	pipPred, err := s.deps.Store.GetNHLPlayerPIPPrediction(playerIndex, endDate)
+	if err != nil {
+		log.Println("Could not find NHLPIPPrediction, creating new:", err)
+		return s.CreateNHLPrediction(playerIndex, opponents, controlMap, startDate, endDate)
+	}
+
+	return pipPred.NHLAvg
+}
+
+// CreateNHLPrediction adjusts the player's per minute of ice time rates by how
+// they've done against the opposing players. With no history against any of
+// them the prediction is their current season's line.
+func (s *AnalysisService) CreateNHLPrediction(playerIndex string, opponents []string, controlMap map[int]players.PlayerAvg, startDate time.Time, endDate time.Time) players.NHLAvg {
+	var totalPip players.PlayerAvg
+
+	for _, defender := range opponents {
+		affectedMap := s.deps.Store.GetNHLPlayerPerWithPlayerByYear(playerIndex, defender, startDate, endDate)
+		pipFactor := s.deps.Store.CalculatePIPFactor(controlMap, affectedMap)
+		if pipFactor == nil {
+			continue
+		}
+
+		if totalPip == nil {
+			totalPip = pipFactor
+		} else {
+			totalPip = totalPip.AddAvg(pipFactor)
+		}
+	}
+	if totalPip == nil {
+		totalPip = players.NHLAvg{}
+	}
+
+	return controlMap[players.SeasonYear(sports.NHL, endDate)].PredictStats(totalPip).(players.NHLAvg)
+}
+
+func (s *AnalysisService) CreateAndStoreNHLPIPPrediction(analyses []Analysis, date time.Time) {
+	log.Printf("Adding %v NHLPIPPredictions to DB", len(analyses))
+	var pPreds []players.NHLPIPPrediction
+	for _, analysis := range analyses {
+		pPreds = append(pPreds, players.NHLPIPPrediction{
+			PlayerIndex: analysis.PlayerIndex,
+			Date:        date,
+			Version:     players.CurrNHLPIPPredVersion(),
+			NHLAvg:      analysis.Prediction.(players.NHLAvg),
+		})
+	}
+
+	if err := s.deps.Store.AddNHLPIPPrediction(pPreds); err != nil {
+		log.Printf("Error storing NHLPIPPredictions: %v", err)
+	}
+}
+
+func (s *AnalysisService) GetOrCreateNFLPrediction(playerIndex string, opponent string, controlMap map[int]players.PlayerAvg, startDate time.Time, endDate time.Time, forceUpdate bool) players.NFLAvg {
+	if forceUpdate {
+		log.Printf("Force creating new NFLPIPPrediction against %v...", opponent)
+		return s.CreateNFLPrediction(playerIndex, opponent, controlMap, startDate, endDate)
+	}
+
+	pipPred, err := s.deps.Store.GetNFLPlayerPIPPrediction(playerIndex, endDate)
+	if err != nil {
+		log.Println("Could not find NFLPIPPrediction, creating new:", err)
+		return s.CreateNFLPrediction(playerIndex, opponent, controlMap, startDate, endDate)
+	}
+
+	return pipPred.NFLAvg
+}
+
+// CreateNFLPrediction adjusts the player's per snap and per target rates by
+// how they've done against the opposing team. With no history against them
+// the prediction is their current season's line.
+func (s *AnalysisService) CreateNFLPrediction(playerIndex string, opponent string, controlMap map[int]players.PlayerAvg, startDate time.Time, endDate time.Time) players.NFLAvg {
+	affectedMap := s.deps.Store.GetNFLPlayerPerAgainstTeamByYear(playerIndex, opponent, startDate, endDate)
+	pipFactor := s.deps.Store.CalculatePIPFactor(controlMap, affectedMap)
+	if pipFactor == nil {
+		pipFactor = players.NFLAvg{}
+	}
+
+	return controlMap[players.SeasonYear(sports.NFL, endDate)].PredictStats(pipFactor).(players.NFLAvg)
+}
+
+func (s *AnalysisService) CreateAndStoreNFLPIPPrediction(analyses []Analysis, date time.Time) {
+	log.Printf("Adding %v NFLPIPPredictions to DB", len(analyses))
+	var pPreds []players.NFLPIPPrediction
+	for _, analysis := range analyses {
+		pPreds = append(pPreds, players.NFLPIPPrediction{
+			PlayerIndex: analysis.PlayerIndex,
+			Date:        date,
+			Version:     players.CurrNFLPIPPredVersion(),
+			NFLAvg:      analysis.Prediction.(players.NFLAvg),
+		})
+	}
+
+	if err := s.deps.Store.AddNFLPIPPrediction(pPreds); err != nil {
+		log.Printf("Error storing NFLPIPPredictions: %v", err)
+	}
+}
+
 func GetOutliers(baseStats players.PlayerAvg, predictedStats players.PlayerAvg) map[string]float32 {
 	outliers := make(map[string]float32)
 
@@ -1,37 +1,65 @@
 package analysis
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/mgordon34/kornet-kover/api/players"
+	"github.com/mgordon34/kornet-kover/internal/snapshots"
 	"github.com/mgordon34/kornet-kover/internal/sports"
 	"github.com/mgordon34/kornet-kover/internal/utils"
 )
 
 type fakeAnalysisStore struct {
-	getPlayerPIPPredictionFn       func(playerIndex string, date time.Time) (players.NBAPIPPrediction, error)
-	addPIPPredictionFn             func(predictions []players.NBAPIPPrediction)
+	getPlayerPIPPredictionFn       func(sport sports.Sport, playerIndex string, date time.Time) (players.NBAPIPPrediction, error)
+	addPIPPredictionFn             func(sport sports.Sport, predictions []players.NBAPIPPrediction)
+	getMLBPlayerPIPPredictionFn    func(playerIndex string, date time.Time) (players.MLBPIPPrediction, error)
+	addMLBPIPPredictionFn          func(predictions []players.MLBPIPPrediction) error
 	getPlayerPerByYearFn           func(sport sports.Sport, player string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg
-	getPlayerPerWithPlayerByYearFn func(player string, defender string, relationship players.Relationship, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg
+	getPlayerPerWithPlayerByYearFn func(sport sports.Sport, player string, defender string, relationship players.Relationship, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg
 	getMLBPerWithPlayerByYearFn    func(player string, defender string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg
+	getPlayerStatMomentsFn         func(sport sports.Sport, player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error)
+	getMLBPlayerStatMomentsFn      func(player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error)
+	getNHLPlayerPIPPredictionFn    func(playerIndex string, date time.Time) (players.NHLPIPPrediction, error)
+	addNHLPIPPredictionFn          func(predictions []players.NHLPIPPrediction) error
+	getNHLPerWithPlayerByYearFn    func(player string, defender string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg
+	getNFLPlayerPIPPredictionFn    func(playerIndex string, date time.Time) (players.NFLPIPPrediction, error)
+	addNFLPIPPredictionFn          func(predictions []players.NFLPIPPrediction) error
+	getNFLPerAgainstTeamByYearFn   func(player string, team string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg
+	getNFLPlayerStatMomentsFn      func(player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error)
+	getNHLPlayerStatMomentsFn      func(player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error)
 	calculatePIPFactorFn           func(controlMap map[int]players.PlayerAvg, relatedMap map[int]players.PlayerAvg) players.PlayerAvg
 }
 
-func (f fakeAnalysisStore) GetPlayerPIPPrediction(playerIndex string, date time.Time) (players.NBAPIPPrediction, error) {
+func (f fakeAnalysisStore) GetPlayerPIPPrediction(sport sports.Sport, playerIndex string, date time.Time) (players.NBAPIPPrediction, error) {
 	if f.getPlayerPIPPredictionFn == nil {
 		return players.NBAPIPPrediction{}, errors.New("not configured")
 	}
-	return f.getPlayerPIPPredictionFn(playerIndex, date)
+	return f.getPlayerPIPPredictionFn(sport, playerIndex, date)
 }
 
-func (f fakeAnalysisStore) AddPIPPrediction(predictions []players.NBAPIPPrediction) {
+func (f fakeAnalysisStore) AddPIPPrediction(sport sports.Sport, predictions []players.NBAPIPPrediction) {
 	if f.addPIPPredictionFn != nil {
-		f.addPIPPredictionFn(predictions)
+		f.addPIPPredictionFn(sport, predictions)
 	}
 }
 
+func (f fakeAnalysisStore) GetMLBPlayerPIPPrediction(playerIndex string, date time.Time) (players.MLBPIPPrediction, error) {
+	if f.getMLBPlayerPIPPredictionFn == nil {
+		return players.MLBPIPPrediction{}, errors.New("not configured")
+	}
+	return f.getMLBPlayerPIPPredictionFn(playerIndex, date)
+}
+
+func (f fakeAnalysisStore) AddMLBPIPPrediction(predictions []players.MLBPIPPrediction) error {
+	if f.addMLBPIPPredictionFn == nil {
+		return nil
+	}
+	return f.addMLBPIPPredictionFn(predictions)
+}
+
 func (f fakeAnalysisStore) GetPlayerPerByYear(sport sports.Sport, player string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg {
 	if f.getPlayerPerByYearFn == nil {
 		return nil
@@ -39,11 +67,11 @@ func (f fakeAnalysisStore) GetPlayerPerByYear(sport sports.Sport, player string,
 	return f.getPlayerPerByYearFn(sport, player, startDate, endDate)
 }
 
-func (f fakeAnalysisStore) GetPlayerPerWithPlayerByYear(player string, defender string, relationship players.Relationship, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg {
+func (f fakeAnalysisStore) GetPlayerPerWithPlayerByYear(sport sports.Sport, player string, defender string, relationship players.Relationship, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg {
 	if f.getPlayerPerWithPlayerByYearFn == nil {
 		return nil
 	}
-	return f.getPlayerPerWithPlayerByYearFn(player, defender, relationship, startDate, endDate)
+	return f.getPlayerPerWithPlayerByYearFn(sport, player, defender, relationship, startDate, endDate)
 }
 
 func (f fakeAnalysisStore) GetMLBPlayerPerWithPlayerByYear(player string, defender string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg {
@@ -53,6 +81,76 @@ func (f fakeAnalysisStore) GetMLBPlayerPerWithPlayerByYear(player string, defend
 	return f.getMLBPerWithPlayerByYearFn(player, defender, startDate, endDate)
 }
 
+func (f fakeAnalysisStore) GetPlayerStatMoments(sport sports.Sport, player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error) {
+	if f.getPlayerStatMomentsFn == nil {
+		return nil, errors.New("not configured")
+	}
+	return f.getPlayerStatMomentsFn(sport, player, startDate, endDate)
+}
+
+func (f fakeAnalysisStore) GetMLBPlayerStatMoments(player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error) {
+	if f.getMLBPlayerStatMomentsFn == nil {
+		return nil, errors.New("not configured")
+	}
+	return f.getMLBPlayerStatMomentsFn(player, startDate, endDate)
+}
+
+func (f fakeAnalysisStore) GetNHLPlayerPIPPrediction(playerIndex string, date time.Time) (players.NHLPIPPrediction, error) {
+	if f.getNHLPlayerPIPPredictionFn == nil {
+		return players.NHLPIPPrediction{}, errors.New("not configured")
+	}
+	return f.getNHLPlayerPIPPredictionFn(playerIndex, date)
+}
+
+func (f fakeAnalysisStore) AddNHLPIPPrediction(predictions []players.NHLPIPPrediction) error {
+	if f.addNHLPIPPredictionFn == nil {
+		return nil
+	}
+	return f.addNHLPIPPredictionFn(predictions)
+}
+
+func (f fakeAnalysisStore) GetNHLPlayerPerWithPlayerByYear(player string, defender string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg {
+	if f.getNHLPerWithPlayerByYearFn == nil {
+		return nil
+	}
+	return f.getNHLPerWithPlayerByYearFn(player, defender, startDate, endDate)
+}
+
+func (f fakeAnalysisStore) GetNHLPlayerStatMoments(player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error) {
+	if f.getNHLPlayerStatMomentsFn == nil {
+		return nil, errors.New("not configured")
+	}
+	return f.getNHLPlayerStatMomentsFn(player, startDate, endDate)
+}
+
+func (f fakeAnalysisStore) GetNFLPlayerPIPPrediction(playerIndex string, date time.Time) (players.NFLPIPPrediction, error) {
+	if f.getNFLPlayerPIPPredictionFn == nil {
+		return players.NFLPIPPrediction{}, errors.New("not configured")
+	}
+	return f.getNFLPlayerPIPPredictionFn(playerIndex, date)
+}
+
+func (f fakeAnalysisStore) AddNFLPIPPrediction(predictions []players.NFLPIPPrediction) error {
+	if f.addNFLPIPPredictionFn == nil {
+		return nil
+	}
+	return f.addNFLPIPPredictionFn(predictions)
+}
+
+func (f fakeAnalysisStore) GetNFLPlayerPerAgainstTeamByYear(player string, team string, startDate time.Time, endDate time.Time) map[int]players.PlayerAvg {
+	if f.getNFLPerAgainstTeamByYearFn == nil {
+		return nil
+	}
+	return f.getNFLPerAgainstTeamByYearFn(player, team, startDate, endDate)
+}
+
+func (f fakeAnalysisStore) GetNFLPlayerStatMoments(player string, startDate time.Time, endDate time.Time) (map[string]players.StatMoments, error) {
+	if f.getNFLPlayerStatMomentsFn == nil {
+		return nil, errors.New("not configured")
+	}
+	return f.getNFLPlayerStatMomentsFn(player, startDate, endDate)
+}
+
 func (f fakeAnalysisStore) CalculatePIPFactor(controlMap map[int]players.PlayerAvg, relatedMap map[int]players.PlayerAvg) players.PlayerAvg {
 	if f.calculatePIPFactorFn == nil {
 		return nil
@@ -64,10 +162,10 @@ func TestGetOrCreatePredictionBranches(t *testing.T) {
 	endDate := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
 	controlMap := map[int]players.PlayerAvg{2026: players.NBAAvg{NumGames: 1, Minutes: 30, Points: 20}}
 	svc := NewAnalysisService(AnalysisServiceDeps{Store: fakeAnalysisStore{
-		getPlayerPIPPredictionFn: func(playerIndex string, date time.Time) (players.NBAPIPPrediction, error) {
+		getPlayerPIPPredictionFn: func(sport sports.Sport, playerIndex string, date time.Time) (players.NBAPIPPrediction, error) {
 			return players.NBAPIPPrediction{}, errors.New("not found")
 		},
-		getPlayerPerWithPlayerByYearFn: func(player, defender string, relationship players.Relationship, startDate, endDate time.Time) map[int]players.PlayerAvg {
+		getPlayerPerWithPlayerByYearFn: func(sport sports.Sport, player, defender string, relationship players.Relationship, startDate, endDate time.Time) map[int]players.PlayerAvg {
 			return map[int]players.PlayerAvg{utils.DateToNBAYear(endDate): players.NBAAvg{NumGames: 1, Minutes: 1, Points: 1}}
 		},
 		calculatePIPFactorFn: func(controlMap, relatedMap map[int]players.PlayerAvg) players.PlayerAvg {
@@ -75,18 +173,18 @@ func TestGetOrCreatePredictionBranches(t *testing.T) {
 		},
 	}})
 
-	got := svc.GetOrCreatePrediction("p1", []string{"d1"}, players.Opponent, controlMap, endDate.AddDate(-1, 0, 0), endDate, false)
+	got := svc.GetOrCreatePrediction(sports.NBA, "p1", []string{"d1"}, players.Opponent, controlMap, endDate.AddDate(-1, 0, 0), endDate, false)
 	if got.PlayerIndex != "p1" || got.Points == 0 {
 		t.Fatalf("GetOrCreatePrediction() fallback create = %+v", got)
 	}
 
 	svc2 := NewAnalysisService(AnalysisServiceDeps{Store: fakeAnalysisStore{
-		getPlayerPIPPredictionFn: func(playerIndex string, date time.Time) (players.NBAPIPPrediction, error) {
+		getPlayerPIPPredictionFn: func(sport sports.Sport, playerIndex string, date time.Time) (players.NBAPIPPrediction, error) {
 			return players.NBAPIPPrediction{PlayerIndex: "p2", Points: 9}, nil
 		},
 	}})
 
-	got2 := svc2.GetOrCreatePrediction("p2", []string{"d1"}, players.Opponent, controlMap, endDate.AddDate(-1, 0, 0), endDate, false)
+	got2 := svc2.GetOrCreatePrediction(sports.NBA, "p2", []string{"d1"}, players.Opponent, controlMap, endDate.AddDate(-1, 0, 0), endDate, false)
 	if got2.PlayerIndex != "p2" || got2.Points != 9 {
 		t.Fatalf("GetOrCreatePrediction() existing = %+v", got2)
 	}
@@ -95,7 +193,7 @@ func TestGetOrCreatePredictionBranches(t *testing.T) {
 func TestCreateAndStorePIPPrediction_UsesInjectedStore(t *testing.T) {
 	called := false
 	svc := NewAnalysisService(AnalysisServiceDeps{Store: fakeAnalysisStore{
-		addPIPPredictionFn: func(preds []players.NBAPIPPrediction) {
+		addPIPPredictionFn: func(sport sports.Sport, preds []players.NBAPIPPrediction) {
 			called = true
 			if len(preds) != 1 || preds[0].PlayerIndex != "p1" {
 				t.Fatalf("unexpected preds: %+v", preds)
@@ -103,7 +201,7 @@ func TestCreateAndStorePIPPrediction_UsesInjectedStore(t *testing.T) {
 		},
 	}})
 
-	svc.CreateAndStorePIPPrediction([]Analysis{{
+	svc.CreateAndStorePIPPrediction(sports.NBA, []Analysis{{
 		PlayerIndex: "p1",
 		Prediction:  players.NBAAvg{NumGames: 3, Minutes: 32, Points: 22, Rebounds: 8, Assists: 6, Threes: 2, Usg: 24, Ortg: 112, Drtg: 107},
 	}}, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
@@ -116,20 +214,31 @@ func TestCreateAndStorePIPPrediction_UsesInjectedStore(t *testing.T) {
 func TestRunAnalysisOnGame_UsesServiceDeps(t *testing.T) {
 	stored := false
 	svc := NewAnalysisService(AnalysisServiceDeps{Store: fakeAnalysisStore{
-		getPlayerPIPPredictionFn: func(playerIndex string, date time.Time) (players.NBAPIPPrediction, error) {
+		getPlayerPIPPredictionFn: func(sport sports.Sport, playerIndex string, date time.Time) (players.NBAPIPPrediction, error) {
 			return players.NBAPIPPrediction{PlayerIndex: playerIndex, NumGames: 3, Minutes: 31, Points: 22, Rebounds: 9, Assists: 7, Threes: 3, Usg: 23, Ortg: 111, Drtg: 106}, nil
 		},
 		getPlayerPerByYearFn: func(sport sports.Sport, player string, startDate, endDate time.Time) map[int]players.PlayerAvg {
 			return map[int]players.PlayerAvg{utils.DateToNBAYear(endDate): players.NBAAvg{NumGames: 2, Minutes: 30, Points: 20, Rebounds: 8, Assists: 6, Threes: 2, Usg: 22, Ortg: 110, Drtg: 107}}
 		},
-		addPIPPredictionFn: func(predictions []players.NBAPIPPrediction) {
+		getPlayerStatMomentsFn: func(sport sports.Sport, player string, startDate, endDate time.Time) (map[string]players.StatMoments, error) {
+			if !startDate.Equal(endDate.AddDate(-1, 0, 0)) {
+				t.Fatalf("expected a year of games, got %v to %v", startDate, endDate)
+			}
+			return map[string]players.StatMoments{
+				"points":   {NumGames: 40, Mean: 20, Variance: 50},
+				"rebounds": {NumGames: 40, Mean: 8, Variance: 6},
+			}, nil
+		},
+		addPIPPredictionFn: func(sport sports.Sport, predictions []players.NBAPIPPrediction) {
 			stored = true
 		},
 	}})
 
 	out := svc.RunAnalysisOnGame(
-		[]players.PlayerRoster{{PlayerIndex: "p1", Status: "Available", AvgMins: 30}, {PlayerIndex: "p2", Status: "Out", AvgMins: 30}},
-		[]players.PlayerRoster{{PlayerIndex: "d1", Status: "Available", AvgMins: 25}},
+		context.Background(),
+		sports.NBA,
+		[]players.PlayerRoster{{PlayerIndex: "p1", Status: "Available", AvgMins: 30}, {PlayerIndex: "p2", TeamIndex: "NYK", Status: "Out", AvgMins: 30}},
+		[]players.PlayerRoster{{PlayerIndex: "d1", TeamIndex: "BOS", Status: "Available", AvgMins: 25}},
 		time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
 		false,
 		true,
@@ -138,14 +247,78 @@ func TestRunAnalysisOnGame_UsesServiceDeps(t *testing.T) {
 	if len(out) != 1 || out[0].PlayerIndex != "p1" {
 		t.Fatalf("RunAnalysisOnGame output = %+v", out)
 	}
+	if out[0].TeamIndex != "NYK" || out[0].OpponentIndex != "BOS" || out[0].GameKey() != "BOS-NYK" {
+		t.Fatalf("expected the teams from the rosters, got %+v", out[0])
+	}
+	if points, ok := out[0].Distributions["points"].(NegativeBinomial); !ok || points.Mu != 22 || points.Variance != 22*2.5 {
+		t.Fatalf("expected points spread like the player's games, got %+v", out[0].Distributions)
+	}
+	if rebounds, ok := out[0].Distributions["rebounds"].(Poisson); !ok || rebounds.Lambda != 9 {
+		t.Fatalf("expected underdispersed rebounds to be Poisson, got %+v", out[0].Distributions)
+	}
+	if _, ok := out[0].Distributions["assists"]; ok {
+		t.Fatalf("expected no distribution without moments")
+	}
 	if !stored {
 		t.Fatalf("expected predictions to be stored")
 	}
 }
 
+func TestRunAnalysisOnGameForWNBA(t *testing.T) {
+	endDate := time.Date(2026, 7, 15, 0, 0, 0, 0, time.UTC)
+	var storedSport sports.Sport
+	svc := NewAnalysisService(AnalysisServiceDeps{Snapshots: snapshots.NewStore[[]Analysis](analysisSnapshotLimit), Store: fakeAnalysisStore{
+		getPlayerPerByYearFn: func(sport sports.Sport, player string, startDate, endDate time.Time) map[int]players.PlayerAvg {
+			if sport != sports.WNBA || startDate.Month() != time.May {
+				t.Fatalf("expected WNBA seasons starting in May, got %v from %v", sport, startDate)
+			}
+			// WNBA seasons are keyed by calendar year
+			return map[int]players.PlayerAvg{2026: players.NBAAvg{NumGames: 10, Minutes: 32, Points: 0.5, Rebounds: 0.25}}
+		},
+		getPlayerPerWithPlayerByYearFn: func(sport sports.Sport, player, defender string, relationship players.Relationship, startDate, endDate time.Time) map[int]players.PlayerAvg {
+			if sport != sports.WNBA {
+				t.Fatalf("expected WNBA matchups, got %v", sport)
+			}
+			return nil
+		},
+		calculatePIPFactorFn: func(controlMap, relatedMap map[int]players.PlayerAvg) players.PlayerAvg {
+			return players.NBAAvg{NumGames: 1}
+		},
+		getPlayerStatMomentsFn: func(sport sports.Sport, player string, startDate, endDate time.Time) (map[string]players.StatMoments, error) {
+			if sport != sports.WNBA {
+				t.Fatalf("expected WNBA moments, got %v", sport)
+			}
+			return map[string]players.StatMoments{"points": {NumGames: 20, Mean: 16, Variance: 40}}, nil
+		},
+		addPIPPredictionFn: func(sport sports.Sport, predictions []players.NBAPIPPrediction) {
+			storedSport = sport
+		},
+	}})
+
+	out, _ := svc.GetGameAnalysis(
+		context.Background(),
+		sports.WNBA,
+		[]players.PlayerRoster{{PlayerIndex: "w1", TeamIndex: "NYL", Status: "Available", AvgMins: 32}},
+		[]players.PlayerRoster{{PlayerIndex: "w2", TeamIndex: "LVA", Status: "Available", AvgMins: 30}},
+		endDate,
+		true,
+		true,
+	)
+
+	if len(out) != 1 || out[0].PlayerIndex != "w1" || out[0].Prediction.GetStats()["points"] != 16 {
+		t.Fatalf("GetGameAnalysis(wnba) = %+v", out)
+	}
+	if _, ok := out[0].Distributions["points"]; !ok {
+		t.Fatalf("expected a points distribution, got %+v", out[0].Distributions)
+	}
+	if storedSport != sports.WNBA {
+		t.Fatalf("expected predictions stored for WNBA, got %q", storedSport)
+	}
+}
+
 func TestCreatePredictions_WithInjectedStore(t *testing.T) {
 	svc := NewAnalysisService(AnalysisServiceDeps{Store: fakeAnalysisStore{
-		getPlayerPerWithPlayerByYearFn: func(player, defender string, relationship players.Relationship, startDate, endDate time.Time) map[int]players.PlayerAvg {
+		getPlayerPerWithPlayerByYearFn: func(sport sports.Sport, player, defender string, relationship players.Relationship, startDate, endDate time.Time) map[int]players.PlayerAvg {
 			return map[int]players.PlayerAvg{utils.DateToNBAYear(endDate): players.NBAAvg{NumGames: 1, Minutes: 1, Points: 1}}
 		},
 		getMLBPerWithPlayerByYearFn: func(player, defender string, startDate, endDate time.Time) map[int]players.PlayerAvg {
@@ -166,7 +339,7 @@ func TestCreatePredictions_WithInjectedStore(t *testing.T) {
 
 	nbaDate := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
 	nbaControl := map[int]players.PlayerAvg{utils.DateToNBAYear(nbaDate): players.NBAAvg{NumGames: 10, Minutes: 30, Points: 20, Rebounds: 8, Assists: 6, Threes: 2, Usg: 20, Ortg: 110, Drtg: 107}}
-	nbaPred := svc.CreatePIPPrediction("p1", []string{"d1", "d2"}, players.Opponent, nbaControl, nbaDate.AddDate(-1, 0, 0), nbaDate)
+	nbaPred := svc.CreatePIPPrediction(sports.NBA, "p1", []string{"d1", "d2"}, players.Opponent, nbaControl, nbaDate.AddDate(-1, 0, 0), nbaDate)
 	if nbaPred.PlayerIndex != "p1" || nbaPred.Points <= 20 {
 		t.Fatalf("CreatePIPPrediction() = %+v", nbaPred)
 	}
@@ -178,3 +351,241 @@ func TestCreatePredictions_WithInjectedStore(t *testing.T) {
 		t.Fatalf("CreateMLBPrediction() = %+v", mlbPred)
 	}
 }
+
+func TestRunMLBAnalysisOnGameReturnsBatterAnalyses(t *testing.T) {
+	endDate := time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)
+	var stored []players.MLBPIPPrediction
+	var faced []string
+	svc := NewAnalysisService(AnalysisServiceDeps{Store: fakeAnalysisStore{
+		getPlayerPerByYearFn: func(sport sports.Sport, player string, startDate, endDate time.Time) map[int]players.PlayerAvg {
+			if sport != sports.MLB {
+				t.Fatalf("expected MLB stats, got %v", sport)
+			}
+			if player == "b2" {
+				return map[int]players.PlayerAvg{endDate.Year() - 1: players.MLBBattingAvg{NumGames: 10, PAs: 4, Hits: .25}}
+			}
+			return map[int]players.PlayerAvg{endDate.Year(): players.MLBBattingAvg{NumGames: 10, PAs: 4, Hits: .25, HomeRuns: .05, RBIs: .1}}
+		},
+		getMLBPerWithPlayerByYearFn: func(player, defender string, startDate, endDate time.Time) map[int]players.PlayerAvg {
+			faced = append(faced, defender)
+			return nil
+		},
+		getMLBPlayerStatMomentsFn: func(player string, startDate, endDate time.Time) (map[string]players.StatMoments, error) {
+			return map[string]players.StatMoments{"hits": {NumGames: 20, Mean: 1, Variance: 1.5}}, nil
+		},
+		addMLBPIPPredictionFn: func(predictions []players.MLBPIPPrediction) error {
+			stored = predictions
+			return nil
+		},
+	}})
+	batters := []players.PlayerRoster{
+		{PlayerIndex: "b1", TeamIndex: "NYY", Status: "Available", AvgMins: 21},
+		{PlayerIndex: "b2", TeamIndex: "NYY", Status: "Available", AvgMins: 21},
+	}
+	pitchers := []players.PlayerRoster{
+		{PlayerIndex: "p1", TeamIndex: "BOS", Status: "Available", AvgMins: 21},
+		{PlayerIndex: "p2", TeamIndex: "BOS", Status: "Available", AvgMins: 21},
+	}
+
+	out, _ := svc.GetGameAnalysis(context.Background(), sports.MLB, batters, pitchers, endDate, false, true)
+	if len(out) != 1 || out[0].PlayerIndex != "b1" || out[0].TeamIndex != "NYY" || out[0].OpponentIndex != "BOS" {
+		t.Fatalf("expected one analysis for the batter with current stats, got %+v", out)
+	}
+	pred, ok := out[0].Prediction.(players.MLBBattingAvg)
+	if !ok || pred.Hits != 1 || pred.PAs != 4 {
+		t.Fatalf("expected the batter's line with no history against the pitcher, got %+v", out[0].Prediction)
+	}
+	if len(faced) != 1 || faced[0] != "p1" {
+		t.Fatalf("expected batters to only face the starter, got %v", faced)
+	}
+	if hits, ok := out[0].Distributions["hits"].(NegativeBinomial); !ok || hits.Mu != 1 {
+		t.Fatalf("expected hits spread like the batter's games, got %+v", out[0].Distributions)
+	}
+	if len(stored) != 1 || stored[0].Version != players.CurrMLBPIPPredVersion() || stored[0].Hits != 1 {
+		t.Fatalf("expected the MLB prediction to be stored, got %+v", stored)
+	}
+
+	if got := svc.RunMLBAnalysisOnGame(context.Background(), batters, nil, endDate, false, false); len(got) != 0 {
+		t.Fatalf("expected no analyses without a pitcher, got %+v", got)
+	}
+}
+
+func TestGetOrCreateMLBPredictionUsesStoredPrediction(t *testing.T) {
+	endDate := time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)
+	svc := NewAnalysisService(AnalysisServiceDeps{Store: fakeAnalysisStore{
+		getMLBPlayerPIPPredictionFn: func(playerIndex string, date time.Time) (players.MLBPIPPrediction, error) {
+			return players.MLBPIPPrediction{PlayerIndex: playerIndex, MLBBattingAvg: players.MLBBattingAvg{NumGames: 3, Hits: 1.4}}, nil
+		},
+	}})
+	controlMap := map[int]players.PlayerAvg{endDate.Year(): players.MLBBattingAvg{NumGames: 10, PAs: 4, Hits: .25}}
+
+	if got := svc.GetOrCreateMLBPrediction("b1", []string{"p1"}, controlMap, endDate.AddDate(-1, 0, 0), endDate, false); got.Hits != 1.4 {
+		t.Fatalf("expected the stored prediction, got %+v", got)
+	}
+	if got := svc.GetOrCreateMLBPrediction("b1", []string{"p1"}, controlMap, endDate.AddDate(-1, 0, 0), endDate, true); got.Hits != 1 {
+		t.Fatalf("expected a forced update to create a new prediction, got %+v", got)
+	}
+}
+
+func TestRunNHLAnalysisOnGamePredictsSkatersAndGoalies(t *testing.T) {
+	endDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	var stored []players.NHLPIPPrediction
+	var faced []string
+	svc := NewAnalysisService(AnalysisServiceDeps{Store: fakeAnalysisStore{
+		getPlayerPerByYearFn: func(sport sports.Sport, player string, startDate, endDate time.Time) map[int]players.PlayerAvg {
+			if sport != sports.NHL {
+				t.Fatalf("expected NHL stats, got %v", sport)
+			}
+			// The 2024-25 season is keyed by the year it ends in
+			if player == "g1" {
+				return map[int]players.PlayerAvg{2025: players.NHLAvg{NumGames: 20, TimeOnIce: 60, ShotsAgainst: .5, Saves: .45}}
+			}
+			return map[int]players.PlayerAvg{2025: players.NHLAvg{NumGames: 40, TimeOnIce: 20, Shots: .15, Points: .05}}
+		},
+		getNHLPerWithPlayerByYearFn: func(player, defender string, startDate, endDate time.Time) map[int]players.PlayerAvg {
+			faced = append(faced, defender)
+			return nil
+		},
+		getNHLPlayerStatMomentsFn: func(player string, startDate, endDate time.Time) (map[string]players.StatMoments, error) {
+			return map[string]players.StatMoments{"saves": {NumGames: 30, Mean: 27, Variance: 30}}, nil
+		},
+		addNHLPIPPredictionFn: func(predictions []players.NHLPIPPrediction) error {
+			stored = predictions
+			return nil
+		},
+	}})
+	roster := []players.PlayerRoster{
+		{PlayerIndex: "s1", TeamIndex: "NHL_FLA", Status: "Available", AvgMins: 21},
+		{PlayerIndex: "g1", TeamIndex: "NHL_FLA", Status: "Available", AvgMins: 21},
+	}
+	opponents := []players.PlayerRoster{
+		{PlayerIndex: "o1", TeamIndex: "NHL_BOS", Status: "Available", AvgMins: 21},
+	}
+
+	out, _ := svc.GetGameAnalysis(context.Background(), sports.NHL, roster, opponents, endDate, false, true)
+	if len(out) != 2 || out[0].OpponentIndex != "NHL_BOS" {
+		t.Fatalf("expected a skater and goalie analysis, got %+v", out)
+	}
+	skater, ok := out[0].Prediction.(players.NHLAvg)
+	if !ok || skater.Shots != 3 || skater.Points != 1 {
+		t.Fatalf("expected the skater's line with no history against the opponents, got %+v", out[0].Prediction)
+	}
+	goalie := out[1].Prediction.(players.NHLAvg)
+	if goalie.Saves != 27 || goalie.ShotsAgainst != 30 {
+		t.Fatalf("expected the goalie's line, got %+v", goalie)
+	}
+	if _, ok := out[1].Distributions["saves"]; !ok {
+		t.Fatalf("expected saves spread like the goalie's games, got %+v", out[1].Distributions)
+	}
+	if len(faced) != 2 {
+		t.Fatalf("expected both players to face the opponent, got %v", faced)
+	}
+	if len(stored) != 2 || stored[1].Version != players.CurrNHLPIPPredVersion() || stored[1].Saves != 27 {
+		t.Fatalf("expected the NHL predictions to be stored, got %+v", stored)
+	}
+}
+
+func TestRunNFLAnalysisOnGamePredictsAgainstTheDefense(t *testing.T) {
+	endDate := time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)
+	var stored []players.NFLPIPPrediction
+	var faced []string
+	svc := NewAnalysisService(AnalysisServiceDeps{Store: fakeAnalysisStore{
+		getPlayerPerByYearFn: func(sport sports.Sport, player string, startDate, endDate time.Time) map[int]players.PlayerAvg {
+			if sport != sports.NFL {
+				t.Fatalf("expected NFL stats, got %v", sport)
+			}
+			// A January game is still the season that started the year before
+			return map[int]players.PlayerAvg{2024: players.NFLAvg{NumGames: 16, Snaps: 50, Targets: .2, Receptions: .5, RecYards: 10}}
+		},
+		getNFLPerAgainstTeamByYearFn: func(player, team string, startDate, endDate time.Time) map[int]players.PlayerAvg {
+			faced = append(faced, team)
+			return map[int]players.PlayerAvg{2023: players.NFLAvg{NumGames: 1, Snaps: 50, Targets: .2, Receptions: .6, RecYards: 10}}
+		},
+		calculatePIPFactorFn: func(controlMap, relatedMap map[int]players.PlayerAvg) players.PlayerAvg {
+			return players.NFLAvg{NumGames: 1, Receptions: .2}
+		},
+		getNFLPlayerStatMomentsFn: func(player string, startDate, endDate time.Time) (map[string]players.StatMoments, error) {
+			return map[string]players.StatMoments{"receptions": {NumGames: 16, Mean: 5, Variance: 4}}, nil
+		},
+		addNFLPIPPredictionFn: func(predictions []players.NFLPIPPrediction) error {
+			stored = predictions
+			return nil
+		},
+	}})
+	roster := []players.PlayerRoster{{PlayerIndex: "FlowZa00", TeamIndex: "NFL_BAL", Status: "Available", AvgMins: 21}}
+	opponents := []players.PlayerRoster{{PlayerIndex: "MahoPa00", TeamIndex: "NFL_KAN", Status: "Available", AvgMins: 21}}
+
+	out, _ := svc.GetGameAnalysis(context.Background(), sports.NFL, roster, opponents, endDate, false, true)
+	if len(out) != 1 || out[0].OpponentIndex != "NFL_KAN" {
+		t.Fatalf("expected one analysis against the Chiefs, got %+v", out)
+	}
+	pred := out[0].Prediction.(players.NFLAvg)
+	if pred.Targets != 10 || pred.Receptions != 6 || pred.RecYards != 100 {
+		t.Fatalf("expected ten targets with a better catch rate, got %+v", pred)
+	}
+	if len(faced) != 1 || faced[0] != "NFL_KAN" {
+		t.Fatalf("expected the prediction to read the player's games against the defense, got %v", faced)
+	}
+	if _, ok := out[0].Distributions["receptions"]; !ok {
+		t.Fatalf("expected receptions spread like the player's games, got %+v", out[0].Distributions)
+	}
+	if len(stored) != 1 || stored[0].Version != players.CurrNFLPIPPredVersion() || stored[0].Receptions != 6 {
+		t.Fatalf("expected the NFL prediction to be stored, got %+v", stored)
+	}
+
+	if got := svc.RunNFLAnalysisOnGame(context.Background(), roster, nil, endDate, false, false); len(got) != 0 {
+		t.Fatalf("expected no analyses without an opponent, got %+v", got)
+	}
+}
+
+func TestGetGameAnalysisReadsThroughSnapshots(t *testing.T) {
+	endDate := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	perCalls := 0
+	store := snapshots.NewStore[[]Analysis](analysisSnapshotLimit)
+	svc := NewAnalysisService(AnalysisServiceDeps{Snapshots: store, Store: fakeAnalysisStore{
+		getPlayerPIPPredictionFn: func(sport sports.Sport, playerIndex string, date time.Time) (players.NBAPIPPrediction, error) {
+			return players.NBAPIPPrediction{PlayerIndex: playerIndex, NumGames: 5, Minutes: 30, Points: 25}, nil
+		},
+		getPlayerPerByYearFn: func(sport sports.Sport, player string, startDate, endDate time.Time) map[int]players.PlayerAvg {
+			perCalls++
+			return map[int]players.PlayerAvg{utils.DateToNBAYear(endDate): players.NBAAvg{NumGames: 5, Minutes: 30, Points: 20}}
+		},
+		calculatePIPFactorFn: func(controlMap map[int]players.PlayerAvg, relatedMap map[int]players.PlayerAvg) players.PlayerAvg {
+			return players.NBAAvg{}
+		},
+	}})
+	roster := []players.PlayerRoster{{PlayerIndex: "p1", Status: "Available", AvgMins: 30}}
+	opponents := []players.PlayerRoster{{PlayerIndex: "d1", Status: "Available", AvgMins: 30}}
+
+	first, _ := svc.GetGameAnalysis(context.Background(), sports.NBA, roster, opponents, endDate, false, false)
+	second, _ := svc.GetGameAnalysis(context.Background(), sports.NBA, roster, opponents, endDate.Add(20*time.Hour), false, false)
+	if len(first) != 1 || len(second) != 1 || perCalls != 1 || store.Len() != 1 {
+		t.Fatalf("expected one cached analysis, got %d/%d with %d calls", len(first), len(second), perCalls)
+	}
+
+	injured := []players.PlayerRoster{{PlayerIndex: "p1", Status: "Out", AvgMins: 30}}
+	if got, _ := svc.GetGameAnalysis(context.Background(), sports.NBA, injured, opponents, endDate, false, false); len(got) != 0 || perCalls != 1 || store.Len() != 2 {
+		t.Fatalf("expected a roster change to miss the cache, got %v with %d calls", got, perCalls)
+	}
+
+	store.InvalidateFrom(sports.NBA, endDate)
+	svc.GetGameAnalysis(context.Background(), sports.NBA, roster, opponents, endDate, false, false)
+	if perCalls != 2 {
+		t.Fatalf("expected analysis to rerun after invalidation, got %d calls", perCalls)
+	}
+
+	svc.GetGameAnalysis(context.Background(), sports.NBA, roster, opponents, endDate, true, false)
+	if perCalls != 3 || store.Len() != 1 {
+		t.Fatalf("expected forceUpdate to rerun and refresh the cached analysis, got %d calls", perCalls)
+	}
+	svc.GetGameAnalysis(context.Background(), sports.NBA, roster, opponents, endDate, false, false)
+	if perCalls != 3 {
+		t.Fatalf("expected the refreshed analysis to be cached, got %d calls", perCalls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got, err := svc.GetGameAnalysis(ctx, sports.NBA, roster, opponents, endDate.AddDate(0, 0, 1), false, false); !errors.Is(err, context.Canceled) || got != nil || perCalls != 3 || store.Len() != 1 {
+		t.Fatalf("expected a cancelled run to stop before analyzing and cache nothing, got %v, %v with %d calls", got, err, perCalls)
+	}
+}
@@ -0,0 +1,262 @@
+package analysis
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/mgordon34/kornet-kover/api/odds"
+	"github.com/mgordon34/kornet-kover/api/strategies"
+)
+
+// FilterSet is the list of persisted strategy_filters rows for a strategy. A
+// pick passes the set only when every filter evaluates to true.
+type FilterSet []strategies.StrategyFilter
+
+func (f FilterSet) Matches(pick PropPick) bool {
+	for _, filter := range f {
+		ok, err := EvaluateFilter(filter, pick)
+		if err != nil || !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// EvaluateFilter applies a single filter to a pick, e.g.
+// prediction(points) > line(points) * 1.2.
+func EvaluateFilter(filter strategies.StrategyFilter, pick PropPick) (bool, error) {
+	if err := filter.Validate(); err != nil {
+		return false, fmt.Errorf("invalid filter %d: %w", filter.Id, err)
+	}
+
+	left, err := getFilterValue(filter.Function, filter.Stat, pick)
+	if err != nil {
+		return false, err
+	}
+
+	var right float64
+	switch filter.ComparisonType {
+	case strategies.ValueComparison:
+		right = *filter.CompareValue
+	case strategies.FunctionComparison:
+		right, err = getFilterValue(*filter.CompareFunction, filter.GetCompareStat(), pick)
+		if err != nil {
+			return false, err
+		}
+	case strategies.ModifiedComparison:
+		right, err = getFilterValue(*filter.CompareFunction, filter.GetCompareStat(), pick)
+		if err != nil {
+			return false, err
+		}
+		right, err = filter.ModifierOperator.Apply(right, *filter.CompareValue)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return filter.Operator.Compare(left, right), nil
+}
+
+func getFilterValue(function string, stat string, pick PropPick) (float64, error) {
+	switch function {
+	case strategies.PredictionFunction:
+		if pick.Prediction == nil {
+			return 0, fmt.Errorf("pick has no prediction")
+		}
+		value, ok := pick.Prediction.GetStats()[stat]
+		if !ok {
+			return 0, fmt.Errorf("prediction has no stat %s", stat)
+		}
+		return checkFilterValue(value)
+	case strategies.BaseFunction:
+		if pick.BaseStats == nil {
+			return 0, fmt.Errorf("pick has no base stats")
+		}
+		value, ok := pick.BaseStats.GetStats()[stat]
+		if !ok {
+			return 0, fmt.Errorf("base stats have no stat %s", stat)
+		}
+		return checkFilterValue(value)
+	case strategies.OutlierFunction:
+		return checkFilterValue(pick.Outliers[stat])
+	}
+
+	// The remaining functions describe the line being bet, so they only
+	// exist for picks on the requested stat.
+	if stat != pick.Stat {
+		return 0, fmt.Errorf("%s(%s) is not available for a %s pick", function, stat, pick.Stat)
+	}
+	switch function {
+	case strategies.LineFunction:
+		return checkFilterValue(pick.GetLine().Line)
+	case strategies.OddsFunction:
+		return float64(pick.GetLine().Odds), nil
+	case strategies.DiffFunction:
+		return checkFilterValue(pick.Diff)
+	case strategies.PDiffFunction:
+		return checkFilterValue(pick.PDiff)
+	case strategies.ImpliedProbFunction:
+		return odds.ImpliedProbability(pick.GetLine().Odds), nil
+	case strategies.FairProbFunction, strategies.FairEVFunction:
+		fair, ok := pick.FairProbability()
+		if !ok {
+			return 0, fmt.Errorf("pick has no fair probability")
+		}
+		if function == strategies.FairProbFunction {
+			return fair, nil
+		}
+		return odds.ExpectedValue(fair, pick.GetLine().Odds), nil
+	case strategies.ModelProbFunction:
+		prob, ok := pick.ModelProbability()
+		if !ok {
+			return 0, fmt.Errorf("pick has no %s distribution", stat)
+		}
+		return prob, nil
+	case strategies.ModelEVFunction:
+		ev, ok := pick.ModelExpectedValue()
+		if !ok {
+			return 0, fmt.Errorf("pick has no %s distribution", stat)
+		}
+		return ev, nil
+	}
+
+	return 0, fmt.Errorf("unknown function %q", function)
+}
+
+func checkFilterValue(value float32) (float64, error) {
+	v := float64(value)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("value is not a number")
+	}
+	return v, nil
+}
+
+// NewStrategySelector builds a PropSelector from a strategy's persisted
+// settings and filters. Strategies without settings are driven entirely by
+// their filters, and limits a strategy leaves unset keep the defaults below.
+func NewStrategySelector(strat strategies.Strategy, filters []strategies.StrategyFilter) PropSelector {
+	selector := PropSelector{
+		StratId:   strat.Id,
+		StratName: strat.Name,
+		LineType:  strategies.MainlineLines,
+		Filters:   filters,
+		BetSize:   100,
+		MinOdds:   math.MinInt32,
+		MaxOver:   math.MaxInt32,
+		MaxUnder:  math.MaxInt32,
+	}
+	if strat.Settings == nil {
+		return selector
+	}
+
+	settings := strat.Settings
+	selector.Sport = settings.Sport
+	if settings.LineType != "" {
+		selector.LineType = settings.LineType
+	}
+	selector.Thresholds = settings.Thresholds
+	switch settings.ThresholdType {
+	case strategies.PercentThreshold:
+		selector.TresholdType = Percent
+	case strategies.EVThreshold:
+		selector.TresholdType = EV
+	}
+	switch settings.SortType {
+	case strategies.StatRankSort:
+		selector.SortType = StatRankSort
+	case strategies.DiffSort:
+		selector.SortType = DiffSort
+	case strategies.PDiffSort:
+		selector.SortType = PDiffSort
+	case strategies.ProbEdgeSort:
+		selector.SortType = ProbEdgeSort
+	case strategies.EVSort:
+		selector.SortType = EVSort
+	}
+	selector.SortDir = settings.SortDir
+	selector.RequireOutlier = settings.RequireOutlier
+	selector.MinMinutes = settings.MinMinutes
+	selector.MinGames = settings.MinGames
+	if settings.MinOdds != nil {
+		selector.MinOdds = *settings.MinOdds
+	}
+	selector.MaxOdds = settings.MaxOdds
+	selector.MinLine = settings.MinLine
+	selector.MaxLine = settings.MaxLine
+	selector.MinDiff = settings.MinDiff
+	if settings.BetSize != 0 {
+		selector.BetSize = settings.BetSize
+	}
+	if settings.MaxOver != nil {
+		selector.MaxOver = *settings.MaxOver
+	}
+	if settings.MaxUnder != nil {
+		selector.MaxUnder = *settings.MaxUnder
+	}
+	selector.TotalMax = settings.TotalMax
+	selector.MaxPerPlayer = settings.MaxPerPlayer
+	selector.MaxPerGame = settings.MaxPerGame
+	selector.MaxPerTeam = settings.MaxPerTeam
+	selector.MaxPerStat = settings.MaxPerStat
+	selector.OneAlternatePerSide = settings.OneAlternatePerSide
+
+	return selector
+}
+
+func LoadStrategySelector(stratId int) (PropSelector, error) {
+	strat, err := strategies.GetStrategyByID(stratId)
+	if err != nil {
+		return PropSelector{}, err
+	}
+	filters, err := strategies.GetStrategyFilters(stratId)
+	if err != nil {
+		return PropSelector{}, err
+	}
+	if err := validateFilters(strat, filters); err != nil {
+		return PropSelector{}, err
+	}
+
+	return NewStrategySelector(strat, filters), nil
+}
+
+// validateFilters rejects a strategy whose stored filters no longer parse, so
+// it is never run with a filter that silently drops every pick.
+func validateFilters(strat strategies.Strategy, filters []strategies.StrategyFilter) error {
+	for _, filter := range filters {
+		if err := filter.Validate(); err != nil {
+			return fmt.Errorf("strategy %d has invalid filter %d: %w", strat.Id, filter.Id, err)
+		}
+	}
+	return nil
+}
+
+// LoadStrategySelectors returns a PropSelector for every user's strategy that
+// has settings or filters stored. A strategy with a filter that no longer
+// parses is logged and skipped so it can't hold up everyone else's picks.
+func LoadStrategySelectors() ([]PropSelector, error) {
+	strats, err := strategies.GetConfiguredStrategies()
+	if err != nil {
+		return nil, err
+	}
+
+	return buildStrategySelectors(strats, strategies.GetStrategyFilters)
+}
+
+func buildStrategySelectors(strats []strategies.Strategy, getFilters func(stratId int) ([]strategies.StrategyFilter, error)) ([]PropSelector, error) {
+	var selectors []PropSelector
+	for _, strat := range strats {
+		filters, err := getFilters(strat.Id)
+		if err != nil {
+			return selectors, err
+		}
+		if err := validateFilters(strat, filters); err != nil {
+			log.Printf("Skipping strategy: %v", err)
+			continue
+		}
+		selectors = append(selectors, NewStrategySelector(strat, filters))
+	}
+
+	return selectors, nil
+}
@@ -0,0 +1,299 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/mgordon34/kornet-kover/api/odds"
+	"github.com/mgordon34/kornet-kover/api/players"
+	"github.com/mgordon34/kornet-kover/api/strategies"
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
+
+func filterTestPick() PropPick {
+	return PropPick{
+		Stat:       "points",
+		Side:       "Over",
+		Diff:       5,
+		PDiff:      0.25,
+		PlayerLine: odds.PlayerLine{Line: 20, Odds: 150, Side: "Over"},
+		Analysis: Analysis{
+			PlayerIndex: "p1",
+			BaseStats:   players.NBAAvg{NumGames: 10, Points: 21, Rebounds: 6},
+			Prediction:  players.NBAAvg{NumGames: 10, Minutes: 30, Points: 25, Rebounds: 7},
+			Outliers:    map[string]float32{"points": 0.19},
+		},
+	}
+}
+
+func TestEvaluateFilterComparisonTypes(t *testing.T) {
+	pick := filterTestPick()
+	line := strategies.LineFunction
+	base := strategies.BaseFunction
+	mult := strategies.Multiply
+	value := 1.2
+	oddsValue := 100.0
+
+	tests := []struct {
+		name   string
+		filter strategies.StrategyFilter
+		want   bool
+	}{
+		{
+			name:   "prediction above line times modifier",
+			filter: strategies.StrategyFilter{Function: strategies.PredictionFunction, Stat: "points", Operator: strategies.GreaterThan, ComparisonType: strategies.ModifiedComparison, CompareFunction: &line, ModifierOperator: &mult, CompareValue: &value},
+			want:   true,
+		},
+		{
+			name:   "prediction above base",
+			filter: strategies.StrategyFilter{Function: strategies.PredictionFunction, Stat: "rebounds", Operator: strategies.GreaterThan, ComparisonType: strategies.FunctionComparison, CompareFunction: &base},
+			want:   true,
+		},
+		{
+			name:   "odds at least value",
+			filter: strategies.StrategyFilter{Function: strategies.OddsFunction, Stat: "points", Operator: strategies.GreaterOrEqual, ComparisonType: strategies.ValueComparison, CompareValue: &oddsValue},
+			want:   true,
+		},
+		{
+			name:   "pdiff below value",
+			filter: strategies.StrategyFilter{Function: strategies.PDiffFunction, Stat: "points", Operator: strategies.LessThan, ComparisonType: strategies.ValueComparison, CompareValue: &value},
+			want:   true,
+		},
+		{
+			name:   "outlier above value",
+			filter: strategies.StrategyFilter{Function: strategies.OutlierFunction, Stat: "points", Operator: strategies.GreaterThan, ComparisonType: strategies.ValueComparison, CompareValue: &value},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvaluateFilter(tt.filter, pick)
+			if err != nil {
+				t.Fatalf("EvaluateFilter() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("EvaluateFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateFilterErrors(t *testing.T) {
+	pick := filterTestPick()
+	value := 1.0
+	diff := strategies.DiffFunction
+
+	if _, err := EvaluateFilter(strategies.StrategyFilter{Function: "bogus", Stat: "points", Operator: strategies.GreaterThan, ComparisonType: strategies.ValueComparison, CompareValue: &value}, pick); err == nil {
+		t.Fatalf("expected invalid filter error")
+	}
+	if err := validateFilters(strategies.Strategy{Id: 1}, []strategies.StrategyFilter{{Id: 2, Function: "bogus", Stat: "points", Operator: strategies.GreaterThan, ComparisonType: strategies.ValueComparison, CompareValue: &value}}); err == nil {
+		t.Fatalf("expected strategy with an invalid filter to be rejected")
+	}
+	if _, err := EvaluateFilter(strategies.StrategyFilter{Function: strategies.LineFunction, Stat: "rebounds", Operator: strategies.GreaterThan, ComparisonType: strategies.ValueComparison, CompareValue: &value}, pick); err == nil {
+		t.Fatalf("expected stat mismatch error for line(rebounds) on points pick")
+	}
+	if _, err := EvaluateFilter(strategies.StrategyFilter{Function: strategies.PredictionFunction, Stat: "steals", Operator: strategies.GreaterThan, ComparisonType: strategies.FunctionComparison, CompareFunction: &diff}, pick); err == nil {
+		t.Fatalf("expected missing prediction stat error")
+	}
+
+	pick.BaseStats = nil
+	if _, err := EvaluateFilter(strategies.StrategyFilter{Function: strategies.BaseFunction, Stat: "points", Operator: strategies.GreaterThan, ComparisonType: strategies.ValueComparison, CompareValue: &value}, pick); err == nil {
+		t.Fatalf("expected missing base stats error")
+	}
+}
+
+func TestBuildStrategySelectorsSkipsInvalidFilters(t *testing.T) {
+	value := 1.0
+	filters := map[int][]strategies.StrategyFilter{
+		1: {{Id: 2, Function: "bogus", Stat: "points", Operator: strategies.GreaterThan, ComparisonType: strategies.ValueComparison, CompareValue: &value}},
+		3: {{Id: 4, Function: strategies.PredictionFunction, Stat: "points", Operator: strategies.GreaterThan, ComparisonType: strategies.ValueComparison, CompareValue: &value}},
+	}
+	getFilters := func(stratId int) ([]strategies.StrategyFilter, error) {
+		return filters[stratId], nil
+	}
+
+	selectors, err := buildStrategySelectors([]strategies.Strategy{{Id: 1}, {Id: 3}}, getFilters)
+	if err != nil || len(selectors) != 1 || selectors[0].StratId != 3 {
+		t.Fatalf("buildStrategySelectors() = %+v, err=%v", selectors, err)
+	}
+
+	failing := func(stratId int) ([]strategies.StrategyFilter, error) {
+		return nil, fmt.Errorf("db down")
+	}
+	if _, err := buildStrategySelectors([]strategies.Strategy{{Id: 3}}, failing); err == nil {
+		t.Fatalf("expected filter lookup error")
+	}
+}
+
+func TestEvaluateFilterProbabilityFunctions(t *testing.T) {
+	pick := filterTestPick()
+	pick.PlayerLine = odds.PlayerLine{}
+	pick.PlayerOdds = odds.PlayerOdds{
+		Over:  odds.PlayerLine{Line: 20.5, Odds: 150, Side: "Over"},
+		Under: odds.PlayerLine{Line: 20.5, Odds: -190, Side: "Under"},
+		Fair:  &odds.FairProbability{Over: .45, Under: .55},
+	}
+	value := 0.0
+
+	tests := []struct {
+		function string
+		want     float64
+	}{
+		{strategies.ImpliedProbFunction, .4},
+		{strategies.FairProbFunction, .45},
+		{strategies.FairEVFunction, .45*1.5 - .55},
+	}
+	for _, tt := range tests {
+		got, err := getFilterValue(tt.function, "points", pick)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Fatalf("%s = %v, %v, want %v", tt.function, got, err, tt.want)
+		}
+	}
+
+	positiveEV := strategies.StrategyFilter{Function: strategies.FairEVFunction, Stat: "points", Operator: strategies.GreaterThan, ComparisonType: strategies.ValueComparison, CompareValue: &value}
+	if ok, err := EvaluateFilter(positiveEV, pick); err != nil || !ok {
+		t.Fatalf("expected the +150 over to be +EV at a .45 fair probability, got %v %v", ok, err)
+	}
+	pick.Side = "Under"
+	if ok, _ := EvaluateFilter(positiveEV, pick); ok {
+		t.Fatalf("expected the -190 under to be -EV")
+	}
+	pick.Fair = nil
+	if _, err := EvaluateFilter(positiveEV, pick); err == nil {
+		t.Fatalf("expected error without a fair probability")
+	}
+}
+
+func TestEvaluateFilterModelFunctions(t *testing.T) {
+	pick := filterTestPick()
+	pick.Distributions = map[string]StatDistribution{"points": Poisson{Lambda: 25}}
+	wantProb, _ := pick.ModelProbability()
+	wantEV, _ := pick.ModelExpectedValue()
+
+	if got, err := getFilterValue(strategies.ModelProbFunction, "points", pick); err != nil || got != wantProb {
+		t.Fatalf("model_prob = %v, %v, want %v", got, err, wantProb)
+	}
+	if got, err := getFilterValue(strategies.ModelEVFunction, "points", pick); err != nil || got != wantEV {
+		t.Fatalf("model_ev = %v, %v, want %v", got, err, wantEV)
+	}
+
+	pick.Distributions = nil
+	for _, function := range []string{strategies.ModelProbFunction, strategies.ModelEVFunction} {
+		if _, err := getFilterValue(function, "points", pick); err == nil {
+			t.Fatalf("expected %s to error without a distribution", function)
+		}
+	}
+}
+
+func TestPropSelectorUsesFilters(t *testing.T) {
+	line := strategies.LineFunction
+	mult := strategies.Multiply
+	value := 1.2
+	filters := []strategies.StrategyFilter{
+		{Function: strategies.PredictionFunction, Stat: "points", Operator: strategies.GreaterThan, ComparisonType: strategies.ModifiedComparison, CompareFunction: &line, ModifierOperator: &mult, CompareValue: &value},
+	}
+
+	selector := NewStrategySelector(strategies.Strategy{Id: 3, Name: "Data"}, filters)
+	if selector.StratId != 3 || selector.StratName != "Data" || selector.BetSize == 0 {
+		t.Fatalf("unexpected filter selector: %+v", selector)
+	}
+	if !selector.isPickElligible(filterTestPick()) {
+		t.Fatalf("pick matching filters should be eligible")
+	}
+
+	favourite := filterTestPick()
+	favourite.PlayerLine.Odds = -110
+	if !selector.isPickElligible(favourite) {
+		t.Fatalf("selector without min_odds should accept a -110 pick")
+	}
+
+	pick := filterTestPick()
+	pick.PlayerLine.Line = 22
+	if selector.isPickElligible(pick) {
+		t.Fatalf("pick failing filters should be ineligible")
+	}
+
+	selector.Thresholds = map[string]float32{"rebounds": 0}
+	if selector.isPickElligible(filterTestPick()) {
+		t.Fatalf("thresholds should still apply alongside filters")
+	}
+
+	if (PropSelector{MaxOver: 1}).isPickElligible(filterTestPick()) {
+		t.Fatalf("selector without thresholds or filters should reject picks")
+	}
+}
+
+func TestNewStrategySelectorAppliesSettings(t *testing.T) {
+	minOdds, maxOver, maxUnder := 200, 100, 0
+	strat := strategies.Strategy{
+		Id:   5,
+		Name: "Alt Points",
+		Settings: &strategies.StrategySettings{
+			Sport:               sports.MLB,
+			LineType:            strategies.AlternateLines,
+			Thresholds:          map[string]float32{"points": -.3},
+			ThresholdType:       strategies.PercentThreshold,
+			RequireOutlier:      true,
+			MinGames:            10,
+			MinOdds:             &minOdds,
+			MaxOdds:             600,
+			MaxLine:             20,
+			MaxOver:             &maxOver,
+			MaxUnder:            &maxUnder,
+			TotalMax:            100,
+			MaxPerPlayer:        2,
+			MaxPerGame:          4,
+			MaxPerTeam:          3,
+			MaxPerStat:          5,
+			OneAlternatePerSide: true,
+		},
+	}
+
+	selector := NewStrategySelector(strat, nil)
+	if selector.Sport != sports.MLB || selector.LineType != strategies.AlternateLines || selector.TresholdType != Percent {
+		t.Fatalf("unexpected line or threshold type: %+v", selector)
+	}
+	if selector.Thresholds["points"] != -.3 || !selector.RequireOutlier || selector.MinGames != 10 {
+		t.Fatalf("thresholds not applied: %+v", selector)
+	}
+	if selector.MinOdds != 200 || selector.MaxOdds != 600 || selector.MaxLine != 20 {
+		t.Fatalf("odds or line limits not applied: %+v", selector)
+	}
+	if selector.BetSize != 100 || selector.MaxOver != 100 || selector.MaxUnder != 0 || selector.TotalMax != 100 {
+		t.Fatalf("bet limits not applied: %+v", selector)
+	}
+	if selector.MaxPerPlayer != 2 || selector.MaxPerGame != 4 || selector.MaxPerTeam != 3 || selector.MaxPerStat != 5 || !selector.OneAlternatePerSide {
+		t.Fatalf("exposure caps not applied: %+v", selector)
+	}
+
+	noSettings := NewStrategySelector(strategies.Strategy{Id: 6}, nil)
+	if noSettings.LineType != strategies.MainlineLines || noSettings.Thresholds != nil || noSettings.MaxUnder != math.MaxInt32 || noSettings.MinOdds != math.MinInt32 || noSettings.SortType != DefaultSort {
+		t.Fatalf("unexpected defaults: %+v", noSettings)
+	}
+	unset := NewStrategySelector(strategies.Strategy{Id: 7, Settings: &strategies.StrategySettings{Thresholds: map[string]float32{"points": 1}}}, nil)
+	if unset.MinOdds != math.MinInt32 || unset.MaxOver != math.MaxInt32 || unset.MaxUnder != math.MaxInt32 {
+		t.Fatalf("unset settings should keep the default limits: %+v", unset)
+	}
+
+	sorts := map[string]SortType{
+		"":                      DefaultSort,
+		strategies.StatRankSort: StatRankSort,
+		strategies.DiffSort:     DiffSort,
+		strategies.PDiffSort:    PDiffSort,
+		strategies.ProbEdgeSort: ProbEdgeSort,
+		strategies.EVSort:       EVSort,
+	}
+	for setting, want := range sorts {
+		strat.Settings.SortType = setting
+		if got := NewStrategySelector(strat, nil).SortType; got != want {
+			t.Fatalf("sort_type %q = %v, want %v", setting, got, want)
+		}
+	}
+	strat.Settings.ThresholdType = strategies.EVThreshold
+	strat.Settings.SortDir = strategies.SortAsc
+	if selector := NewStrategySelector(strat, nil); selector.TresholdType != EV || selector.SortDir != SortAsc {
+		t.Fatalf("unexpected ev threshold or sort direction: %+v", selector)
+	}
+}
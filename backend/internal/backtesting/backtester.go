@@ -1,14 +1,23 @@
 package backtesting
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"math"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/mgordon34/kornet-kover/api/backtests"
 	"github.com/mgordon34/kornet-kover/api/games"
 	"github.com/mgordon34/kornet-kover/api/odds"
+	"github.com/mgordon34/kornet-kover/api/picks"
 	"github.com/mgordon34/kornet-kover/api/players"
+	"github.com/mgordon34/kornet-kover/api/strategies"
 	"github.com/mgordon34/kornet-kover/internal/analysis"
 	"github.com/mgordon34/kornet-kover/internal/sports"
 )
@@ -32,20 +41,128 @@ func (b *BacktestResult) addResult(pick analysis.PropPick, result players.Player
 		log.Printf("Skipping result for %s, no stats found", pick.Analysis.PlayerIndex)
 		return
 	}
+	settled := b.settle(pick, result)
+	if settled.Result == "Win" {
+		log.Printf("Bet is win. line %v vs actual %v. Profits $%.2f", settled.GetLine().Line, settled.Actual, settled.Profit)
+	} else {
+		log.Printf("Bet is loss. line %v vs actual %v", settled.GetLine().Line, settled.Actual)
+	}
+}
+
+// settle grades the pick against the player's result and records it
+func (b *BacktestResult) settle(pick analysis.PropPick, result players.PlayerAvg) *analysis.PropPick {
 	b.Bets = append(b.Bets, &pick)
 	actualValue := result.GetStats()[pick.Stat]
+	pick.Actual = actualValue
 
 	if pick.Side == "Over" && actualValue > pick.GetLine().Line || pick.Side == "Under" && actualValue < pick.GetLine().Line {
 		pick.Result = "Win"
+		pick.Profit = calculateProfit(pick.BetSize, pick.GetLine().Odds)
 		b.Wins++
-		b.Profit += calculateProfit(pick.BetSize, pick.GetLine().Odds)
-		log.Printf("Bet is win. line %v vs actual %v. Profits $%.2f", pick.GetLine().Line, actualValue, calculateProfit(pick.BetSize, pick.GetLine().Odds))
+		b.Profit += pick.Profit
 	} else {
 		pick.Result = "Loss"
+		pick.Profit = -pick.BetSize
 		b.Losses++
 		b.Profit -= pick.BetSize
-		log.Printf("Bet is loss. line %v vs actual %v", pick.GetLine().Line, actualValue)
 	}
+
+	return &pick
+}
+
+// maxDrawdown is the largest drop in running profit from its previous peak,
+// measured over bets in the order they were placed
+func (b BacktestResult) maxDrawdown() float32 {
+	var running, peak, drawdown float32
+	for _, bet := range b.Bets {
+		running += bet.Profit
+		if running > peak {
+			peak = running
+		}
+		if peak-running > drawdown {
+			drawdown = peak - running
+		}
+	}
+
+	return drawdown
+}
+
+// toBacktestRun snapshots the strategy config and its results for storage
+func (s Strategy) toBacktestRun(sport sports.Sport, startDate time.Time, endDate time.Time) (backtests.BacktestRun, []backtests.BacktestBet, error) {
+	config, err := json.Marshal(s.PropSelector)
+	if err != nil {
+		return backtests.BacktestRun{}, nil, fmt.Errorf("error snapshotting config for %s: %w", s.StratName, err)
+	}
+
+	run := backtests.BacktestRun{
+		StratName:    s.StratName,
+		Sport:        string(sport),
+		Config:       config,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		ModelVersion: players.PIPPredVersion(sport),
+		Bets:         len(s.Bets),
+		Wins:         s.Wins,
+		Losses:       s.Losses,
+		Profit:       s.Profit,
+		MaxDrawdown:  s.maxDrawdown(),
+	}
+	if s.StratId != 0 {
+		stratId := s.StratId
+		run.StratId = &stratId
+	}
+
+	var bets []backtests.BacktestBet
+	var beatClose int
+	for _, pick := range s.Bets {
+		run.Staked += pick.BetSize
+		var prediction float32
+		if pick.Prediction != nil {
+			prediction = pick.Prediction.GetStats()[pick.Stat]
+		}
+		bet := backtests.BacktestBet{
+			Date:        pick.Date,
+			PlayerIndex: pick.Analysis.PlayerIndex,
+			LineId:      pick.LineId,
+			Stat:        pick.Stat,
+			Side:        pick.Side,
+			Line:        pick.GetLine().Line,
+			Odds:        pick.GetLine().Odds,
+			Prediction:  prediction,
+			Diff:        pick.Diff,
+			PDiff:       pick.PDiff,
+			BetSize:     pick.BetSize,
+			Actual:      pick.Actual,
+			Result:      strings.ToLower(pick.Result),
+			Profit:      pick.Profit,
+		}
+		if pick.Closing != nil && pick.CLV != nil {
+			bet.ClosingLine = &pick.Closing.Line
+			bet.ClosingOdds = &pick.Closing.Odds
+			bet.CLVLine = &pick.CLV.LineMove
+			bet.CLVProb = &pick.CLV.ProbDelta
+			run.CLVBets++
+			run.AvgCLVLine += pick.CLV.LineMove
+			run.AvgCLVProb += pick.CLV.ProbDelta
+			if pick.CLV.ProbDelta > 0 {
+				beatClose++
+			}
+		}
+		bets = append(bets, bet)
+	}
+	if run.CLVBets > 0 {
+		run.AvgCLVLine /= float32(run.CLVBets)
+		run.AvgCLVProb /= float32(run.CLVBets)
+		run.BeatCloseRate = float32(beatClose) / float32(run.CLVBets)
+	}
+	if run.Staked > 0 {
+		run.ROI = run.Profit / run.Staked
+	}
+	if run.Wins+run.Losses > 0 {
+		run.WinRate = float32(run.Wins) / float32(run.Wins+run.Losses)
+	}
+
+	return run, bets, nil
 }
 
 func (b BacktestResult) printResults(name string) {
@@ -147,17 +264,8 @@ func (b BacktestResult) resultBreakdown() {
 				rBrackets[key] = append(rBrackets[key], *pick)
 			}
 		}
-		for key := range oBrackets {
-			if pick.GetLine().Odds >= key && pick.GetLine().Odds < (key+100) {
-				oBrackets[key] = append(oBrackets[key], *pick)
-			}
-			if key == 1000 && pick.GetLine().Odds >= 1100 {
-				oBrackets[key] = append(oBrackets[key], *pick)
-			}
-			if key == 0 && pick.GetLine().Odds < 0 {
-				oBrackets[key] = append(oBrackets[key], *pick)
-			}
-		}
+		oKey := picks.OddsBucketKey(pick.GetLine().Odds)
+		oBrackets[oKey] = append(oBrackets[oKey], *pick)
 		for key := range lBrackets {
 			if pick.GetLine().Line < key {
 				lBrackets[key] = append(lBrackets[key], *pick)
@@ -227,142 +335,625 @@ func calculateProfit(betSize float32, odds int) float32 {
 	}
 }
 
+// defaultBacktestWorkers keeps concurrent dates well under the pgx pool size
+const defaultBacktestWorkers = 4
+
 type Backtester struct {
+	// Sport is the league whose games are backtested, defaulting to NBA
+	Sport      sports.Sport
 	StartDate  time.Time
 	EndDate    time.Time
 	Strategies []Strategy
-	deps       BacktesterDeps
+	// JobId links stored runs to the backtest job that launched them
+	JobId int
+	// OnProgress is called after each date with the number of dates processed.
+	// Dates load concurrently but calls never overlap.
+	OnProgress func(completed int, total int)
+	// Workers bounds how many dates load at once, defaulting to defaultBacktestWorkers
+	Workers int
+	// Staking lists the staking policies each strategy's bets are replayed under
+	Staking []StakingConfig
+	// LineSelector picks which posting of each line a bet is placed at,
+	// defaulting to odds.DefaultLineSelector
+	LineSelector odds.LineSelector
+	deps         BacktesterDeps
 }
 
 type BacktesterDataSource interface {
-	GetGamesForDate(sport sports.Sport, date time.Time) ([]games.Game, error)
-	GetPlayerStatsForGames(gameIDs []string) (map[string]players.PlayerAvg, error)
-	GetAlternatePlayerOddsForDate(sport sports.Sport, date time.Time) (map[string]map[string][]odds.PlayerLine, error)
-	GetPlayersForGame(gameID int, homeIndex string, playerGameTable string, sortString string) (map[string][]players.Player, error)
-	RunAnalysisOnGame(roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) []analysis.Analysis
+	GetGamesForDate(ctx context.Context, sport sports.Sport, date time.Time) ([]games.Game, error)
+	GetPlayerStatsForGames(ctx context.Context, sport sports.Sport, gameIDs []string) (map[string]players.PlayerAvg, error)
+	GetPlayerOddsForDate(ctx context.Context, sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string]odds.PlayerOdds, error)
+	GetAlternatePlayerOddsForDate(ctx context.Context, sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string][]odds.PlayerLine, error)
+	GetClosingLinesForDate(ctx context.Context, sport sports.Sport, date time.Time, lineType string) (map[odds.MarketKey]odds.PlayerLine, error)
+	GetPlayersForGame(ctx context.Context, gameID int, homeIndex string, playerGameTable string, sortString string) (map[string][]players.Player, error)
+	RunAnalysisOnGame(ctx context.Context, sport sports.Sport, roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) ([]analysis.Analysis, error)
+}
+
+type BacktestStore interface {
+	SaveBacktestRun(run backtests.BacktestRun, bets []backtests.BacktestBet) (int, error)
 }
 
 type BacktesterDeps struct {
 	DataSource BacktesterDataSource
+	Store      BacktestStore
+}
+
+type defaultBacktestStore struct{}
+
+func (d defaultBacktestStore) SaveBacktestRun(run backtests.BacktestRun, bets []backtests.BacktestBet) (int, error) {
+	return backtests.AddBacktestRun(run, bets)
 }
 
 type defaultBacktesterDataSource struct{}
 
-func (d defaultBacktesterDataSource) GetGamesForDate(sport sports.Sport, date time.Time) ([]games.Game, error) {
-	return games.GetGamesForDate(sport, date)
+func (d defaultBacktesterDataSource) GetGamesForDate(ctx context.Context, sport sports.Sport, date time.Time) ([]games.Game, error) {
+	return games.GetGamesForDate(ctx, sport, date)
+}
+
+func (d defaultBacktesterDataSource) GetPlayerStatsForGames(ctx context.Context, sport sports.Sport, gameIDs []string) (map[string]players.PlayerAvg, error) {
+	return players.GetStatsForGames(ctx, sport, gameIDs)
+}
+
+func (d defaultBacktesterDataSource) GetPlayerOddsForDate(ctx context.Context, sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string]odds.PlayerOdds, error) {
+	return odds.GetPlayerOddsForDate(ctx, sport, date, selector)
 }
 
-func (d defaultBacktesterDataSource) GetPlayerStatsForGames(gameIDs []string) (map[string]players.PlayerAvg, error) {
-	return players.GetPlayerStatsForGames(gameIDs)
+func (d defaultBacktesterDataSource) GetAlternatePlayerOddsForDate(ctx context.Context, sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string][]odds.PlayerLine, error) {
+	return odds.GetAlternatePlayerOddsForDate(ctx, sport, date, selector)
 }
 
-func (d defaultBacktesterDataSource) GetAlternatePlayerOddsForDate(sport sports.Sport, date time.Time) (map[string]map[string][]odds.PlayerLine, error) {
-	return odds.GetAlternatePlayerOddsForDate(sport, date)
+func (d defaultBacktesterDataSource) GetClosingLinesForDate(ctx context.Context, sport sports.Sport, date time.Time, lineType string) (map[odds.MarketKey]odds.PlayerLine, error) {
+	return odds.GetClosingLinesForDate(ctx, sport, date, lineType)
 }
 
-func (d defaultBacktesterDataSource) GetPlayersForGame(gameID int, homeIndex string, playerGameTable string, sortString string) (map[string][]players.Player, error) {
-	return players.GetPlayersForGame(gameID, homeIndex, playerGameTable, sortString)
+func (d defaultBacktesterDataSource) GetPlayersForGame(ctx context.Context, gameID int, homeIndex string, playerGameTable string, sortString string) (map[string][]players.Player, error) {
+	return players.GetPlayersForGame(ctx, gameID, homeIndex, playerGameTable, sortString)
 }
 
-func (d defaultBacktesterDataSource) RunAnalysisOnGame(roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) []analysis.Analysis {
-	return analysis.NewAnalysisService(analysis.AnalysisServiceDeps{}).RunAnalysisOnGame(roster, opponents, endDate, forceUpdate, storePIP)
+func (d defaultBacktesterDataSource) RunAnalysisOnGame(ctx context.Context, sport sports.Sport, roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) ([]analysis.Analysis, error) {
+	return analysis.NewAnalysisService(analysis.AnalysisServiceDeps{}).GetGameAnalysis(ctx, sport, roster, opponents, endDate, forceUpdate, storePIP)
 }
 
 func NewBacktester(startDate time.Time, endDate time.Time, strategies []Strategy, deps BacktesterDeps) Backtester {
 	if deps.DataSource == nil {
 		deps.DataSource = defaultBacktesterDataSource{}
 	}
+	if deps.Store == nil {
+		deps.Store = defaultBacktestStore{}
+	}
 
 	return Backtester{
-		StartDate:  startDate,
-		EndDate:    endDate,
-		Strategies: strategies,
-		deps:       deps,
+		Sport:        sports.NBA,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Strategies:   strategies,
+		LineSelector: odds.DefaultLineSelector,
+		deps:         deps,
+	}
+}
+
+func LoadStrategies(stratIds []int) ([]Strategy, error) {
+	var strategies []Strategy
+	for _, stratId := range stratIds {
+		selector, err := analysis.LoadStrategySelector(stratId)
+		if err != nil {
+			return strategies, err
+		}
+		strategies = append(strategies, Strategy{PropSelector: selector, BacktestResult: &BacktestResult{}})
 	}
+
+	return strategies, nil
 }
 
 func (b *Backtester) ensureDataSource() {
 	if b.deps.DataSource == nil {
 		b.deps.DataSource = defaultBacktesterDataSource{}
 	}
+	if b.deps.Store == nil {
+		b.deps.Store = defaultBacktestStore{}
+	}
+}
+
+func (b Backtester) sport() sports.Sport {
+	if b.Sport == "" {
+		return sports.NBA
+	}
+	return b.Sport
+}
+
+// TotalDays is the number of dates the backtest iterates over
+func (b Backtester) TotalDays() int {
+	total := 0
+	for d := b.StartDate; !d.After(b.EndDate); d = d.AddDate(0, 0, b.dateStep()) {
+		total++
+	}
+	return total
+}
+
+// DateError records a date that could not be loaded, or that a strategy could
+// not pick. The rest of the backtest carries on without it.
+type DateError struct {
+	Date time.Time
+	Err  error
+}
+
+func (e DateError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Date.Format("2006-01-02"), e.Err)
+}
+
+func (e DateError) Unwrap() error {
+	return e.Err
+}
+
+type BacktestReport struct {
+	// RunIds are the stored runs in strategy order
+	RunIds []int
+	// DateErrors are the dates that were skipped, in full or by a strategy, in
+	// date order
+	DateErrors []DateError
+	// Bankrolls holds a report per staking config for each strategy, in strategy order
+	Bankrolls [][]BankrollReport
+}
+
+func (b Backtester) workers() int {
+	if b.Workers > 0 {
+		return b.Workers
+	}
+	return defaultBacktestWorkers
+}
+
+// dateStep is the number of days between the backtest's dates
+func (b Backtester) dateStep() int {
+	return gameLineups[b.sport()].step()
 }
 
-func (b Backtester) RunBacktest() {
+func (b Backtester) dates() []time.Time {
+	var dates []time.Time
+	for d := b.StartDate; !d.After(b.EndDate); d = d.AddDate(0, 0, b.dateStep()) {
+		dates = append(dates, d)
+	}
+	return dates
+}
+
+// RunBacktest runs every strategy over the date range and stores each as a
+// backtest run. Dates that fail to load are skipped and reported, the run only
+// fails when it is cancelled, nothing could be loaded or a run can't be stored.
+func (b Backtester) RunBacktest(ctx context.Context) (BacktestReport, error) {
 	b.ensureDataSource()
-	for d := b.StartDate; !d.After(b.EndDate); d = d.AddDate(0, 0, 1) {
-		b.backtestDate(d)
+	report := BacktestReport{}
+	snapshots, dateErrs, err := b.loadSnapshots(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, dateErr := range dateErrs {
+		log.Printf("Skipping date in backtest: %v", dateErr)
+	}
+	if len(dateErrs) > 0 && len(dateErrs) == b.TotalDays() {
+		report.DateErrors = dateErrs
+		return report, fmt.Errorf("every date in the backtest failed, first error: %w", dateErrs[0])
+	}
+
+	for _, snapshot := range snapshots {
+		for _, dateErr := range b.applySnapshot(snapshot) {
+			log.Printf("Skipping strategy on date in backtest: %v", dateErr)
+			dateErrs = append(dateErrs, dateErr)
+		}
 	}
+	sort.SliceStable(dateErrs, func(i, j int) bool { return dateErrs[i].Date.Before(dateErrs[j].Date) })
+	report.DateErrors = dateErrs
 
 	for _, strategy := range b.Strategies {
 		strategy.printResults(strategy.StratName)
 		strategy.resultBreakdown()
+
+		var bankrolls []BankrollReport
+		for _, staking := range b.Staking {
+			bankroll := strategy.SimulateBankroll(staking)
+			bankroll.print(strategy.StratName)
+			bankrolls = append(bankrolls, bankroll)
+		}
+		report.Bankrolls = append(report.Bankrolls, bankrolls)
+
+		run, bets, err := strategy.toBacktestRun(b.sport(), b.StartDate, b.EndDate)
+		if err != nil {
+			return report, err
+		}
+		if run.CLVBets > 0 {
+			log.Printf("CLV over %v bets: %.2f points, %.2f%% implied probability, beat the close %.2f%%", run.CLVBets, run.AvgCLVLine, run.AvgCLVProb*100, run.BeatCloseRate*100)
+		}
+		if b.JobId != 0 {
+			jobId := b.JobId
+			run.JobId = &jobId
+		}
+		runId, err := b.deps.Store.SaveBacktestRun(run, bets)
+		if err != nil {
+			return report, err
+		}
+		report.RunIds = append(report.RunIds, runId)
+	}
+
+	return report, nil
+}
+
+// dateSnapshot holds everything a selector needs to pick and settle bets for
+// one date, so analyses only have to be run once per date
+type dateSnapshot struct {
+	date     time.Time
+	mainline map[string]map[string]odds.PlayerOdds
+	odds     map[string]map[string][]odds.PlayerLine
+	// closing holds the closing lines posted on each game date
+	closing  map[time.Time]map[odds.MarketKey]odds.PlayerLine
+	analyses []analysis.Analysis
+	stats    map[string]players.PlayerAvg
+	// gameDates is the date of each analyzed player's game, which differs
+	// from the snapshot's date when it covers several days
+	gameDates map[string]time.Time
+}
+
+// merge adds a later day's games to the snapshot. Players only play once in
+// the days a snapshot covers, so their lines never overlap.
+func (s *dateSnapshot) merge(day *dateSnapshot) {
+	if s.mainline == nil {
+		s.mainline = make(map[string]map[string]odds.PlayerOdds)
+	}
+	for player, lines := range day.mainline {
+		s.mainline[player] = lines
 	}
+	if s.odds == nil {
+		s.odds = make(map[string]map[string][]odds.PlayerLine)
+	}
+	for player, lines := range day.odds {
+		s.odds[player] = lines
+	}
+	if s.closing == nil {
+		s.closing = make(map[time.Time]map[odds.MarketKey]odds.PlayerLine)
+	}
+	for date, lines := range day.closing {
+		s.closing[date] = lines
+	}
+	s.analyses = append(s.analyses, day.analyses...)
+	if s.stats == nil {
+		s.stats = make(map[string]players.PlayerAvg)
+	}
+	for player, stats := range day.stats {
+		s.stats[player] = stats
+	}
+	if s.gameDates == nil {
+		s.gameDates = make(map[string]time.Time)
+	}
+	for player, date := range day.gameDates {
+		s.gameDates[player] = date
+	}
+}
+
+// gameDate is the date of the player's game, the snapshot's date when unknown
+func (s *dateSnapshot) gameDate(player string) time.Time {
+	if date, ok := s.gameDates[player]; ok {
+		return date
+	}
+	return s.date
+}
+
+// withCLV attaches the closing line for the pick's market when one was posted
+// on the pick's date
+func (s *dateSnapshot) withCLV(pick analysis.PropPick) analysis.PropPick {
+	line := pick.GetLine()
+	closing, ok := s.closing[pick.Date][odds.NewMarketKey(line)]
+	if !ok {
+		return pick
+	}
+	clv := odds.CalculateCLV(pick.Side, line.Line, line.Odds, closing.Line, closing.Odds)
+	pick.Closing = &closing
+	pick.CLV = &clv
+	return pick
+}
+
+// pick runs the selector against the snapshot's odds for its line type.
+// Selectors without one pick alternate lines, which backtests always used.
+// Picks are dated to their game with its closing line attached, in date order
+// so a week's picks settle as separate betting days.
+func (s *dateSnapshot) pick(selector analysis.PropSelector) ([]analysis.PropPick, error) {
+	var picks []analysis.PropPick
+	var err error
+	switch selector.LineType {
+	case strategies.MainlineLines:
+		picks, err = selector.PickProps(s.mainline, s.analyses, s.date, false)
+	case "", strategies.AlternateLines:
+		picks, err = selector.PickAlternateProps(s.odds, s.analyses, s.date, false)
+	default:
+		return nil, fmt.Errorf("unknown line type %q", selector.LineType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range picks {
+		picks[i].Date = s.gameDate(picks[i].Analysis.PlayerIndex)
+		picks[i] = s.withCLV(picks[i])
+	}
+	sort.SliceStable(picks, func(i, j int) bool { return picks[i].Date.Before(picks[j].Date) })
+
+	return picks, nil
+}
+
+// applySnapshot runs every strategy against the date and records the results.
+// A strategy that fails to pick the date is skipped for it and returned as a
+// DateError.
+func (b Backtester) applySnapshot(snapshot *dateSnapshot) []DateError {
+	var dateErrs []DateError
+	for _, strategy := range b.Strategies {
+		if strategy.Sport == "" {
+			strategy.Sport = b.sport()
+		}
+		picks, err := snapshot.pick(strategy.PropSelector)
+		if err != nil {
+			dateErrs = append(dateErrs, DateError{Date: snapshot.date, Err: fmt.Errorf("strategy %q: %w", strategy.StratName, err)})
+			continue
+		}
+
+		for _, pick := range picks {
+			log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f Odds: %v/%v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.GetLine().Line, pick.Diff, pick.Over.Odds, pick.Under.Odds)
+			strategy.addResult(pick, snapshot.stats[pick.Analysis.PlayerIndex])
+		}
+	}
+	return dateErrs
+}
+
+// loadSnapshots loads every date in the backtest range on a bounded pool of
+// workers. Snapshots and date errors come back in date order regardless of
+// which worker finished first. Only cancellation is returned as an error.
+func (b Backtester) loadSnapshots(ctx context.Context) ([]*dateSnapshot, []DateError, error) {
+	b.ensureDataSource()
+	dates := b.dates()
+	loaded := make([]*dateSnapshot, len(dates))
+	errs := make([]error, len(dates))
+
+	var mu sync.Mutex
+	completed := 0
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(b.workers(), len(dates)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				loaded[i], errs[i] = b.safeLoadSnapshot(ctx, dates[i])
+
+				mu.Lock()
+				completed++
+				if b.OnProgress != nil {
+					b.OnProgress(completed, len(dates))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range dates {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var snapshots []*dateSnapshot
+	var dateErrs []DateError
+	for i, date := range dates {
+		if errs[i] != nil {
+			dateErrs = append(dateErrs, DateError{Date: date, Err: errs[i]})
+			continue
+		}
+		if loaded[i] != nil {
+			snapshots = append(snapshots, loaded[i])
+		}
+	}
+
+	return snapshots, dateErrs, nil
 }
 
-func (b Backtester) backtestDate(date time.Time) {
+// safeLoadSnapshot turns a panic while loading a date into an error, since it
+// runs on a worker where nothing upstream can recover it
+func (b Backtester) safeLoadSnapshot(ctx context.Context, date time.Time) (snapshot *dateSnapshot, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			snapshot = nil
+			err = fmt.Errorf("panic loading date: %v", r)
+		}
+	}()
+	return b.loadSnapshot(ctx, date)
+}
+
+// gameLineup is where a sport's players for a past game are read from. Each
+// team's roster is analyzed against the other team's opponents, its batters
+// against their starting pitcher for MLB.
+type gameLineup struct {
+	rosterTable   string
+	rosterSort    string
+	rosterSize    int
+	opponentTable string
+	opponentSort  string
+	opponentSize  int
+	// days is how many days each backtest date covers, one unless set. Sports
+	// that play a round of games a week are backtested a week at a time.
+	days int
+}
+
+func (l gameLineup) step() int {
+	if l.days > 0 {
+		return l.days
+	}
+	return 1
+}
+
+var gameLineups = map[sports.Sport]gameLineup{
+	// TODO: make this more intelligent by getting player's avg minutes for this point in the season
+	sports.NBA:  {rosterTable: "nba_player_games", rosterSort: "minutes", rosterSize: 8, opponentTable: "nba_player_games", opponentSort: "minutes", opponentSize: 8},
+	sports.WNBA: {rosterTable: "wnba_player_games", rosterSort: "minutes", rosterSize: 8, opponentTable: "wnba_player_games", opponentSort: "minutes", opponentSize: 8},
+	sports.MLB:  {rosterTable: "mlb_player_games_batting", rosterSort: "pas", rosterSize: 9, opponentTable: "mlb_player_games_pitching", opponentSort: "innings", opponentSize: 1},
+	sports.NHL:  {rosterTable: "nhl_player_games", rosterSort: "time_on_ice", rosterSize: 10, opponentTable: "nhl_player_games", opponentSort: "time_on_ice", opponentSize: 10},
+	sports.NFL:  {rosterTable: "nfl_player_games", rosterSort: "snaps", rosterSize: 12, opponentTable: "nfl_player_games", opponentSort: "snaps", opponentSize: 1, days: 7},
+}
+
+// loadSnapshot gathers games, odds, analyses and results for a date, and the
+// days after it up to the sport's step without going past the end of the
+// backtest. Returns nil when there is nothing to bet on in those days.
+func (b Backtester) loadSnapshot(ctx context.Context, date time.Time) (*dateSnapshot, error) {
 	b.ensureDataSource()
+	sport := b.sport()
+	lineup, ok := gameLineups[sport]
+	if !ok {
+		return nil, fmt.Errorf("backtesting is not supported for sport %s", sport)
+	}
 	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	var snapshot *dateSnapshot
+	for i := 0; i < lineup.step(); i++ {
+		day := date.AddDate(0, 0, i)
+		if i > 0 && day.After(b.EndDate) {
+			break
+		}
+		daySnapshot, err := b.loadDay(ctx, sport, lineup, day)
+		if err != nil {
+			return nil, err
+		}
+		if daySnapshot == nil {
+			continue
+		}
+		if snapshot == nil {
+			snapshot = daySnapshot
+			snapshot.date = date
+			continue
+		}
+		snapshot.merge(daySnapshot)
+	}
+
+	return snapshot, nil
+}
+
+// loadDay gathers games, odds, analyses and results for a single day
+func (b Backtester) loadDay(ctx context.Context, sport sports.Sport, lineup gameLineup, date time.Time) (*dateSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	log.Printf("Running for date %v", date)
 
-	todayGames, err := b.deps.DataSource.GetGamesForDate(sports.NBA, date)
+	todayGames, err := b.deps.DataSource.GetGamesForDate(ctx, sport, date)
 	if err != nil {
-		log.Fatal("Error getting games for date: ", err)
+		return nil, fmt.Errorf("error getting games for %v: %w", date, err)
 	}
 	if len(todayGames) == 0 {
 		log.Printf("No games for %v", date)
-		return
+		return nil, nil
 	}
 
 	var strs []string
 	for _, game := range todayGames {
 		strs = append(strs, strconv.FormatInt(int64(game.Id), 10))
 	}
-	statMap, err := b.deps.DataSource.GetPlayerStatsForGames(strs)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	statMap, err := b.deps.DataSource.GetPlayerStatsForGames(ctx, sport, strs)
 	if err != nil {
-		log.Fatal("Error getting historical stats: ", err)
+		return nil, fmt.Errorf("error getting historical stats for %v: %w", date, err)
 	}
 
-	// todaysOdds, err := odds.GetPlayerOddsForDate(date, []string{"points", "rebounds", "assists", "threes"})
-	todaysOdds, err := b.deps.DataSource.GetAlternatePlayerOddsForDate(sports.NBA, date)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	mainlineOdds, err := b.deps.DataSource.GetPlayerOddsForDate(ctx, sport, date, b.LineSelector)
+	if err != nil {
+		return nil, fmt.Errorf("error getting historical mainline odds for %v: %w", date, err)
+	}
+	todaysOdds, err := b.deps.DataSource.GetAlternatePlayerOddsForDate(ctx, sport, date, b.LineSelector)
 	if err != nil {
-		log.Fatal("Error getting historical odds: ", err)
+		return nil, fmt.Errorf("error getting historical odds for %v: %w", date, err)
 	}
-	if len(todaysOdds) == 0 {
+	if len(mainlineOdds) == 0 && len(todaysOdds) == 0 {
 		log.Printf("No player odds for %v", date)
-		return
+		return nil, nil
+	}
+	// Mainline and alternate markets key differently, so both fit in one map
+	closing := make(map[odds.MarketKey]odds.PlayerLine)
+	for _, lineType := range []string{"mainline", "alternate"} {
+		lines, err := b.deps.DataSource.GetClosingLinesForDate(ctx, sport, date, lineType)
+		if err != nil {
+			return nil, fmt.Errorf("error getting closing lines for %v: %w", date, err)
+		}
+		for key, line := range lines {
+			closing[key] = line
+		}
 	}
 
 	var results []analysis.Analysis
 	for _, game := range todayGames {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		log.Printf("Analyzing %v vs. %v", game.HomeIndex, game.AwayIndex)
-		playerMap, err := b.deps.DataSource.GetPlayersForGame(game.Id, game.HomeIndex, "nba_player_games", "minutes")
+		playerMap, err := b.deps.DataSource.GetPlayersForGame(ctx, game.Id, game.HomeIndex, lineup.rosterTable, lineup.rosterSort)
 		if err != nil {
-			log.Fatal("Error getting players for game: ", err)
+			return nil, fmt.Errorf("error getting players for game %d: %w", game.Id, err)
 		}
-		// TODO: make this more intelligent by getting player's avg minutes for this point in the season
-		homeRoster := convertPlayerMaptoPlayerRosters(playerMap["home"][:8])
-		awayRoster := convertPlayerMaptoPlayerRosters(playerMap["away"][:8])
+		opponentMap := playerMap
+		if lineup.opponentTable != lineup.rosterTable {
+			opponentMap, err = b.deps.DataSource.GetPlayersForGame(ctx, game.Id, game.HomeIndex, lineup.opponentTable, lineup.opponentSort)
+			if err != nil {
+				return nil, fmt.Errorf("error getting opponents for game %d: %w", game.Id, err)
+			}
+		}
+		homeRoster := convertPlayerMaptoPlayerRosters(topPlayers(playerMap["home"], lineup.rosterSize), game.HomeIndex)
+		awayRoster := convertPlayerMaptoPlayerRosters(topPlayers(playerMap["away"], lineup.rosterSize), game.AwayIndex)
+		homeOpponents := convertPlayerMaptoPlayerRosters(topPlayers(opponentMap["home"], lineup.opponentSize), game.HomeIndex)
+		awayOpponents := convertPlayerMaptoPlayerRosters(topPlayers(opponentMap["away"], lineup.opponentSize), game.AwayIndex)
 
-		results = append(results, b.deps.DataSource.RunAnalysisOnGame(homeRoster, awayRoster, date, false, true)...)
-		results = append(results, b.deps.DataSource.RunAnalysisOnGame(awayRoster, homeRoster, date, false, true)...)
+		homeResults, err := b.deps.DataSource.RunAnalysisOnGame(ctx, sport, homeRoster, awayOpponents, date, false, true)
+		if err != nil {
+			return nil, fmt.Errorf("error analyzing %v in game %d: %w", game.HomeIndex, game.Id, err)
+		}
+		awayResults, err := b.deps.DataSource.RunAnalysisOnGame(ctx, sport, awayRoster, homeOpponents, date, false, true)
+		if err != nil {
+			return nil, fmt.Errorf("error analyzing %v in game %d: %w", game.AwayIndex, game.Id, err)
+		}
+		results = append(results, homeResults...)
+		results = append(results, awayResults...)
 	}
 
-	var picks []analysis.PropPick
-	for _, strategy := range b.Strategies {
-		picks, _ = strategy.PickAlternateProps(todaysOdds, results, date, false)
+	gameDates := make(map[string]time.Time, len(results))
+	for _, result := range results {
+		gameDates[result.PlayerIndex] = date
+	}
 
-		for _, pick := range picks {
-			log.Printf("%v: Selected %v %v Predicted %.2f vs. Line %.2f. Diff: %.2f Odds: %v/%v", pick.Analysis.PlayerIndex, pick.Side, pick.Stat, pick.Prediction.GetStats()[pick.Stat], pick.GetLine().Line, pick.Diff, pick.Over.Odds, pick.Under.Odds)
-			strategy.addResult(pick, statMap[pick.Analysis.PlayerIndex])
-		}
+	return &dateSnapshot{
+		date:      date,
+		mainline:  mainlineOdds,
+		odds:      todaysOdds,
+		closing:   map[time.Time]map[odds.MarketKey]odds.PlayerLine{date: closing},
+		analyses:  results,
+		stats:     statMap,
+		gameDates: gameDates,
+	}, nil
+}
+
+func topPlayers(p []players.Player, n int) []players.Player {
+	if len(p) < n {
+		return p
 	}
+	return p[:n]
 }
 
-func convertPlayerMaptoPlayerRosters(p []players.Player) []players.PlayerRoster {
+func convertPlayerMaptoPlayerRosters(p []players.Player, teamIndex string) []players.PlayerRoster {
 	var playerRosters []players.PlayerRoster
 	for _, player := range p {
 		playerRosters = append(playerRosters, players.PlayerRoster{
 			PlayerIndex: player.Index,
+			TeamIndex:   teamIndex,
 			Status:      "Available",
 			AvgMins:     21,
 		})
@@ -1,42 +1,64 @@
 package backtesting
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/mgordon34/kornet-kover/api/backtests"
 	"github.com/mgordon34/kornet-kover/api/games"
 	"github.com/mgordon34/kornet-kover/api/odds"
 	"github.com/mgordon34/kornet-kover/api/players"
+	"github.com/mgordon34/kornet-kover/api/strategies"
 	"github.com/mgordon34/kornet-kover/internal/analysis"
 	"github.com/mgordon34/kornet-kover/internal/sports"
 )
 
 type fakeBacktesterDataSource struct {
 	getGamesForDateFn         func(sport sports.Sport, date time.Time) ([]games.Game, error)
-	getPlayerStatsForGamesFn  func(gameIDs []string) (map[string]players.PlayerAvg, error)
-	getAlternateOddsForDateFn func(sport sports.Sport, date time.Time) (map[string]map[string][]odds.PlayerLine, error)
+	getPlayerStatsForGamesFn  func(sport sports.Sport, gameIDs []string) (map[string]players.PlayerAvg, error)
+	getPlayerOddsForDateFn    func(sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string]odds.PlayerOdds, error)
+	getAlternateOddsForDateFn func(sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string][]odds.PlayerLine, error)
+	getClosingLinesForDateFn  func(sport sports.Sport, date time.Time, lineType string) (map[odds.MarketKey]odds.PlayerLine, error)
 	getPlayersForGameFn       func(gameID int, homeIndex string, playerGameTable string, sortString string) (map[string][]players.Player, error)
-	runAnalysisOnGameFn       func(roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) []analysis.Analysis
+	runAnalysisOnGameFn       func(sport sports.Sport, roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) []analysis.Analysis
 }
 
-func (f fakeBacktesterDataSource) GetGamesForDate(sport sports.Sport, date time.Time) ([]games.Game, error) {
+func (f fakeBacktesterDataSource) GetGamesForDate(ctx context.Context, sport sports.Sport, date time.Time) ([]games.Game, error) {
 	return f.getGamesForDateFn(sport, date)
 }
 
-func (f fakeBacktesterDataSource) GetPlayerStatsForGames(gameIDs []string) (map[string]players.PlayerAvg, error) {
-	return f.getPlayerStatsForGamesFn(gameIDs)
+func (f fakeBacktesterDataSource) GetPlayerStatsForGames(ctx context.Context, sport sports.Sport, gameIDs []string) (map[string]players.PlayerAvg, error) {
+	return f.getPlayerStatsForGamesFn(sport, gameIDs)
 }
 
-func (f fakeBacktesterDataSource) GetAlternatePlayerOddsForDate(sport sports.Sport, date time.Time) (map[string]map[string][]odds.PlayerLine, error) {
-	return f.getAlternateOddsForDateFn(sport, date)
+func (f fakeBacktesterDataSource) GetPlayerOddsForDate(ctx context.Context, sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string]odds.PlayerOdds, error) {
+	if f.getPlayerOddsForDateFn == nil {
+		return nil, nil
+	}
+	return f.getPlayerOddsForDateFn(sport, date, selector)
+}
+
+func (f fakeBacktesterDataSource) GetAlternatePlayerOddsForDate(ctx context.Context, sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string][]odds.PlayerLine, error) {
+	return f.getAlternateOddsForDateFn(sport, date, selector)
 }
 
-func (f fakeBacktesterDataSource) GetPlayersForGame(gameID int, homeIndex string, playerGameTable string, sortString string) (map[string][]players.Player, error) {
+func (f fakeBacktesterDataSource) GetClosingLinesForDate(ctx context.Context, sport sports.Sport, date time.Time, lineType string) (map[odds.MarketKey]odds.PlayerLine, error) {
+	if f.getClosingLinesForDateFn == nil {
+		return nil, nil
+	}
+	return f.getClosingLinesForDateFn(sport, date, lineType)
+}
+
+func (f fakeBacktesterDataSource) GetPlayersForGame(ctx context.Context, gameID int, homeIndex string, playerGameTable string, sortString string) (map[string][]players.Player, error) {
 	return f.getPlayersForGameFn(gameID, homeIndex, playerGameTable, sortString)
 }
 
-func (f fakeBacktesterDataSource) RunAnalysisOnGame(roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) []analysis.Analysis {
-	return f.runAnalysisOnGameFn(roster, opponents, endDate, forceUpdate, storePIP)
+func (f fakeBacktesterDataSource) RunAnalysisOnGame(ctx context.Context, sport sports.Sport, roster []players.PlayerRoster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate bool, storePIP bool) ([]analysis.Analysis, error) {
+	return f.runAnalysisOnGameFn(sport, roster, opponents, endDate, forceUpdate, storePIP), nil
 }
 
 func TestCalculateProfit(t *testing.T) {
@@ -70,8 +92,8 @@ func TestAddResultWinAndLossAndNil(t *testing.T) {
 
 func TestConvertHelpers(t *testing.T) {
 	playersIn := []players.Player{{Index: "a"}, {Index: "b"}}
-	rosters := convertPlayerMaptoPlayerRosters(playersIn)
-	if len(rosters) != 2 || rosters[0].Status != "Available" {
+	rosters := convertPlayerMaptoPlayerRosters(playersIn, "BOS")
+	if len(rosters) != 2 || rosters[0].Status != "Available" || rosters[1].TeamIndex != "BOS" {
 		t.Fatalf("unexpected rosters conversion: %+v", rosters)
 	}
 
@@ -90,17 +112,17 @@ func TestBacktestDateBranchesWithInjectedDeps(t *testing.T) {
 			getGamesForDateFn: func(sport sports.Sport, date time.Time) ([]games.Game, error) {
 				return []games.Game{{Id: 1, HomeIndex: "H", AwayIndex: "A"}}, nil
 			},
-			getPlayerStatsForGamesFn: func(gameIDs []string) (map[string]players.PlayerAvg, error) {
+			getPlayerStatsForGamesFn: func(sport sports.Sport, gameIDs []string) (map[string]players.PlayerAvg, error) {
 				return map[string]players.PlayerAvg{"p1": players.NBAAvg{NumGames: 1, Points: 22}}, nil
 			},
-			getAlternateOddsForDateFn: func(sport sports.Sport, date time.Time) (map[string]map[string][]odds.PlayerLine, error) {
+			getAlternateOddsForDateFn: func(sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string][]odds.PlayerLine, error) {
 				return map[string]map[string][]odds.PlayerLine{"p1": {"points": {{Id: 1, Side: "Over", Line: 20.5, Odds: 200}}}}, nil
 			},
 			getPlayersForGameFn: func(gameID int, homeIndex, table, sort string) (map[string][]players.Player, error) {
 				arr := []players.Player{{Index: "p1"}, {Index: "p2"}, {Index: "p3"}, {Index: "p4"}, {Index: "p5"}, {Index: "p6"}, {Index: "p7"}, {Index: "p8"}}
 				return map[string][]players.Player{"home": arr, "away": arr}, nil
 			},
-			runAnalysisOnGameFn: func(roster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate, storePIP bool) []analysis.Analysis {
+			runAnalysisOnGameFn: func(sport sports.Sport, roster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate, storePIP bool) []analysis.Analysis {
 				return []analysis.Analysis{{PlayerIndex: "p1", Prediction: players.NBAAvg{NumGames: 2, Minutes: 30, Points: 25}, Outliers: map[string]float32{"points": 0.2}}}
 			},
 		}},
@@ -108,9 +130,438 @@ func TestBacktestDateBranchesWithInjectedDeps(t *testing.T) {
 
 	strat := Strategy{PropSelector: analysis.PropSelector{Thresholds: map[string]float32{"points": 0.1}, TresholdType: analysis.Percent, MinOdds: -200, MaxOdds: 500, MaxOver: 10, MaxUnder: 10, BetSize: 100, MinGames: 1, MinMinutes: 1}, BacktestResult: &BacktestResult{}}
 	b.Strategies = []Strategy{strat}
-	b.backtestDate(b.StartDate)
+	snapshot, err := b.loadSnapshot(context.Background(), b.StartDate)
+	if err != nil || snapshot == nil {
+		t.Fatalf("loadSnapshot() = %v, %v", snapshot, err)
+	}
+	b.applySnapshot(snapshot)
 
 	if b.Strategies[0].Wins+b.Strategies[0].Losses == 0 {
 		t.Fatalf("expected at least one evaluated bet in strategy result")
 	}
 }
+
+func TestLoadSnapshotForMLB(t *testing.T) {
+	date := time.Date(2099, 5, 1, 0, 0, 0, 0, time.UTC)
+	var tables []string
+	var matchups []string
+	b := NewBacktester(date, date, nil, BacktesterDeps{DataSource: fakeBacktesterDataSource{
+		getGamesForDateFn: func(sport sports.Sport, date time.Time) ([]games.Game, error) {
+			if sport != sports.MLB {
+				t.Fatalf("expected MLB games, got %v", sport)
+			}
+			return []games.Game{{Id: 1, HomeIndex: "NYY", AwayIndex: "BOS"}}, nil
+		},
+		getPlayerStatsForGamesFn: func(sport sports.Sport, gameIDs []string) (map[string]players.PlayerAvg, error) {
+			return map[string]players.PlayerAvg{"b1": players.MLBBattingAvg{NumGames: 1, Hits: 2}}, nil
+		},
+		getAlternateOddsForDateFn: func(sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string][]odds.PlayerLine, error) {
+			return map[string]map[string][]odds.PlayerLine{"b1": {
+				"hits":      {{Id: 1, Side: "Over", Line: 1.5, Odds: 150}},
+				"home_runs": {{Id: 2, Side: "Over", Line: 0.5, Odds: 300}},
+			}}, nil
+		},
+		getPlayersForGameFn: func(gameID int, homeIndex, table, sort string) (map[string][]players.Player, error) {
+			tables = append(tables, table+":"+sort)
+			if table == "mlb_player_games_pitching" {
+				return map[string][]players.Player{"home": {{Index: "hp1"}, {Index: "hp2"}}, "away": {{Index: "ap1"}, {Index: "ap2"}}}, nil
+			}
+			return map[string][]players.Player{"home": {{Index: "b1"}}, "away": {{Index: "b2"}}}, nil
+		},
+		runAnalysisOnGameFn: func(sport sports.Sport, roster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate, storePIP bool) []analysis.Analysis {
+			if sport != sports.MLB {
+				t.Fatalf("expected MLB analysis, got %v", sport)
+			}
+			var faced []string
+			for _, opponent := range opponents {
+				faced = append(faced, opponent.PlayerIndex)
+			}
+			matchups = append(matchups, roster[0].PlayerIndex+" vs "+strings.Join(faced, ","))
+			if roster[0].PlayerIndex != "b1" {
+				return nil
+			}
+			return []analysis.Analysis{{PlayerIndex: "b1", Prediction: players.MLBBattingAvg{NumGames: 10, Hits: 1.8, HomeRuns: .9}}}
+		},
+	}})
+	b.Sport = sports.MLB
+	// home runs have the bigger edge but MLB ranks hits first
+	b.Strategies = []Strategy{{PropSelector: analysis.PropSelector{Thresholds: map[string]float32{"hits": 0, "home_runs": 0}, SortType: analysis.StatRankSort, MaxOver: 1, BetSize: 100, MinGames: 5}, BacktestResult: &BacktestResult{}}}
+
+	snapshot, err := b.loadSnapshot(context.Background(), date)
+	if err != nil || snapshot == nil {
+		t.Fatalf("loadSnapshot() = %v, %v", snapshot, err)
+	}
+	if strings.Join(tables, "|") != "mlb_player_games_batting:pas|mlb_player_games_pitching:innings" {
+		t.Fatalf("unexpected player tables %v", tables)
+	}
+	if strings.Join(matchups, "|") != "b1 vs ap1|b2 vs hp1" {
+		t.Fatalf("expected batters to face the other team's starter, got %v", matchups)
+	}
+	b.applySnapshot(snapshot)
+	if b.Strategies[0].Wins != 1 || b.Strategies[0].Bets[0].Stat != "hits" {
+		t.Fatalf("expected the hits over to win, got %+v", b.Strategies[0].BacktestResult)
+	}
+
+	run, _, err := b.Strategies[0].toBacktestRun(b.Sport, date, date)
+	if err != nil || run.Sport != "mlb" || run.ModelVersion != players.CurrMLBPIPPredVersion() {
+		t.Fatalf("toBacktestRun() = %+v, %v", run, err)
+	}
+
+	b.Sport = sports.Sport("cricket")
+	if _, err := b.loadSnapshot(context.Background(), date); err == nil {
+		t.Fatalf("expected an unsupported sport to fail")
+	}
+}
+
+func TestNFLBacktestsAWeekAtATime(t *testing.T) {
+	start := time.Date(2099, 9, 3, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2099, 9, 12, 0, 0, 0, 0, time.UTC)
+	var requested []string
+	var analyzed []string
+	b := NewBacktester(start, end, nil, BacktesterDeps{DataSource: fakeBacktesterDataSource{
+		getGamesForDateFn: func(sport sports.Sport, date time.Time) ([]games.Game, error) {
+			requested = append(requested, date.Format("01-02"))
+			switch date.Day() {
+			case 3:
+				return []games.Game{{Id: 1, HomeIndex: "NFL_KAN", AwayIndex: "NFL_BAL"}}, nil
+			case 6:
+				return []games.Game{{Id: 2, HomeIndex: "NFL_PHI", AwayIndex: "NFL_GNB"}}, nil
+			}
+			return nil, nil
+		},
+		getPlayerStatsForGamesFn: func(sport sports.Sport, gameIDs []string) (map[string]players.PlayerAvg, error) {
+			if gameIDs[0] == "1" {
+				return map[string]players.PlayerAvg{"FlowZa00": players.NFLAvg{NumGames: 1, Receptions: 7}}, nil
+			}
+			return map[string]players.PlayerAvg{"BrowAJ00": players.NFLAvg{NumGames: 1, Receptions: 3}}, nil
+		},
+		getAlternateOddsForDateFn: func(sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string][]odds.PlayerLine, error) {
+			if date.Day() == 3 {
+				return map[string]map[string][]odds.PlayerLine{"FlowZa00": {"receptions": {{Id: 1, PlayerIndex: "FlowZa00", Stat: "receptions", Side: "Over", Type: "alternate", Line: 5.5, Odds: 110}}}}, nil
+			}
+			return map[string]map[string][]odds.PlayerLine{"BrowAJ00": {"receptions": {{Id: 2, PlayerIndex: "BrowAJ00", Stat: "receptions", Side: "Over", Type: "alternate", Line: 5.5, Odds: 110}}}}, nil
+		},
+		getClosingLinesForDateFn: func(sport sports.Sport, date time.Time, lineType string) (map[odds.MarketKey]odds.PlayerLine, error) {
+			// the same market closes at a different price on each day, only
+			// the close on the day of the game counts
+			closing := odds.PlayerLine{PlayerIndex: "BrowAJ00", Stat: "receptions", Side: "Over", Type: lineType, Line: 5.5, Odds: 300}
+			if date.Day() == 6 {
+				closing.Odds = -120
+			}
+			return map[odds.MarketKey]odds.PlayerLine{odds.NewMarketKey(closing): closing}, nil
+		},
+		getPlayersForGameFn: func(gameID int, homeIndex, table, sort string) (map[string][]players.Player, error) {
+			if table != "nfl_player_games" || sort != "snaps" {
+				t.Fatalf("unexpected player table %v:%v", table, sort)
+			}
+			return map[string][]players.Player{"home": {{Index: "h1"}}, "away": {{Index: "a1"}}}, nil
+		},
+		runAnalysisOnGameFn: func(sport sports.Sport, roster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate, storePIP bool) []analysis.Analysis {
+			analyzed = append(analyzed, endDate.Format("01-02")+" "+roster[0].TeamIndex)
+			receiver := map[string]string{"NFL_KAN": "FlowZa00", "NFL_PHI": "BrowAJ00"}[roster[0].TeamIndex]
+			if receiver == "" {
+				return nil
+			}
+			return []analysis.Analysis{{PlayerIndex: receiver, Prediction: players.NFLAvg{NumGames: 2, Receptions: 8}}}
+		},
+	}})
+	b.Sport = sports.NFL
+
+	dates := b.dates()
+	if len(dates) != 2 || b.TotalDays() != 2 || !dates[1].Equal(start.AddDate(0, 0, 7)) {
+		t.Fatalf("expected a date a week, got %v", dates)
+	}
+
+	snapshot, err := b.loadSnapshot(context.Background(), start)
+	if err != nil || snapshot == nil {
+		t.Fatalf("loadSnapshot() = %v, %v", snapshot, err)
+	}
+	if strings.Join(requested, ",") != "09-03,09-04,09-05,09-06,09-07,09-08,09-09" {
+		t.Fatalf("expected every day of the week to be loaded, got %v", requested)
+	}
+	if !snapshot.date.Equal(start) || len(snapshot.odds) != 2 || len(snapshot.stats) != 2 {
+		t.Fatalf("expected the week's games in one snapshot, got %+v", snapshot)
+	}
+	if strings.Join(analyzed, ",") != "09-03 NFL_KAN,09-03 NFL_BAL,09-06 NFL_PHI,09-06 NFL_GNB" {
+		t.Fatalf("expected each game analyzed as of its own day, got %v", analyzed)
+	}
+
+	selector := analysis.PropSelector{LineType: strategies.AlternateLines, Thresholds: map[string]float32{"receptions": 0.1}, TresholdType: analysis.Percent, MinOdds: -200, MaxOver: 2, BetSize: 100}
+	picks, err := snapshot.pick(selector)
+	if err != nil || len(picks) != 2 {
+		t.Fatalf("pick() = %+v, %v", picks, err)
+	}
+	if picks[0].Analysis.PlayerIndex != "FlowZa00" || !picks[0].Date.Equal(start) || picks[0].Closing != nil {
+		t.Fatalf("expected the first pick dated to its own game, got %+v", picks[0])
+	}
+	if picks[1].Analysis.PlayerIndex != "BrowAJ00" || !picks[1].Date.Equal(start.AddDate(0, 0, 3)) || picks[1].Closing == nil || picks[1].Closing.Odds != -120 {
+		t.Fatalf("expected the second pick dated and closed on its own game, got %+v", picks[1])
+	}
+	if days := bettingDays([]*analysis.PropPick{&picks[0], &picks[1]}); len(days) != 2 {
+		t.Fatalf("expected the week's picks on separate betting days, got %d", len(days))
+	}
+
+	requested = nil
+	if _, err := b.loadSnapshot(context.Background(), dates[1]); err != nil {
+		t.Fatalf("loadSnapshot() err = %v", err)
+	}
+	if strings.Join(requested, ",") != "09-10,09-11,09-12" {
+		t.Fatalf("expected the last week to stop at the end of the backtest, got %v", requested)
+	}
+}
+
+type fakeBacktestStore struct {
+	saveBacktestRunFn func(run backtests.BacktestRun, bets []backtests.BacktestBet) (int, error)
+}
+
+func (f fakeBacktestStore) SaveBacktestRun(run backtests.BacktestRun, bets []backtests.BacktestBet) (int, error) {
+	return f.saveBacktestRunFn(run, bets)
+}
+
+// singlePickDataSource serves one game where p1 scores 22 against a 20.5
+// points alternate line at +200
+func singlePickDataSource() fakeBacktesterDataSource {
+	return fakeBacktesterDataSource{
+		getGamesForDateFn: func(sport sports.Sport, date time.Time) ([]games.Game, error) {
+			return []games.Game{{Id: 1, HomeIndex: "H", AwayIndex: "A"}}, nil
+		},
+		getPlayerStatsForGamesFn: func(sport sports.Sport, gameIDs []string) (map[string]players.PlayerAvg, error) {
+			return map[string]players.PlayerAvg{"p1": players.NBAAvg{NumGames: 1, Points: 22}}, nil
+		},
+		getPlayerOddsForDateFn: func(sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string]odds.PlayerOdds, error) {
+			return map[string]map[string]odds.PlayerOdds{"p1": {"points": {
+				Over:  odds.PlayerLine{Id: 8, PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "mainline", Line: 20.5, Odds: 200},
+				Under: odds.PlayerLine{Id: 9, PlayerIndex: "p1", Stat: "points", Side: "Under", Type: "mainline", Line: 20.5, Odds: -250},
+			}}}, nil
+		},
+		getAlternateOddsForDateFn: func(sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string][]odds.PlayerLine, error) {
+			return map[string]map[string][]odds.PlayerLine{"p1": {"points": {{Id: 7, PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "alternate", Line: 20.5, Odds: 200}}}}, nil
+		},
+		getPlayersForGameFn: func(gameID int, homeIndex, table, sort string) (map[string][]players.Player, error) {
+			arr := []players.Player{{Index: "p1"}, {Index: "p2"}, {Index: "p3"}}
+			return map[string][]players.Player{"home": arr, "away": arr}, nil
+		},
+		runAnalysisOnGameFn: func(sport sports.Sport, roster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate, storePIP bool) []analysis.Analysis {
+			return []analysis.Analysis{{PlayerIndex: "p1", Prediction: players.NBAAvg{NumGames: 2, Minutes: 30, Points: 25}, Outliers: map[string]float32{"points": 0.2}}}
+		},
+	}
+}
+
+func TestResultMaxDrawdown(t *testing.T) {
+	res := BacktestResult{Bets: []*analysis.PropPick{{Profit: 100}, {Profit: -50}, {Profit: -100}, {Profit: 300}, {Profit: -20}}}
+	if got := res.maxDrawdown(); got != 150 {
+		t.Fatalf("maxDrawdown() = %v, want 150", got)
+	}
+	if got := (BacktestResult{}).maxDrawdown(); got != 0 {
+		t.Fatalf("empty maxDrawdown() = %v", got)
+	}
+}
+
+func TestSnapshotPicksByLineType(t *testing.T) {
+	date := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewBacktester(date, date, nil, BacktesterDeps{DataSource: singlePickDataSource()})
+	snapshot, err := b.loadSnapshot(context.Background(), date)
+	if err != nil || snapshot == nil {
+		t.Fatalf("loadSnapshot() = %v, %v", snapshot, err)
+	}
+
+	selector := analysis.PropSelector{Thresholds: map[string]float32{"points": 0.1}, TresholdType: analysis.Percent, MinOdds: -200, MaxOver: 1, BetSize: 100}
+	for lineType, wantType := range map[string]string{strategies.MainlineLines: "mainline", strategies.AlternateLines: "alternate", "": "alternate"} {
+		selector.LineType = lineType
+		picks, err := snapshot.pick(selector)
+		if err != nil || len(picks) != 1 || picks[0].GetLine().Type != wantType {
+			t.Fatalf("%q selector picked %+v, %v, want one %s line", lineType, picks, err, wantType)
+		}
+	}
+}
+
+func TestRunBacktestStoresRuns(t *testing.T) {
+	date := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	var saved []backtests.BacktestRun
+	var savedBets [][]backtests.BacktestBet
+	data := singlePickDataSource()
+	data.getClosingLinesForDateFn = func(sport sports.Sport, date time.Time, lineType string) (map[odds.MarketKey]odds.PlayerLine, error) {
+		closing := odds.PlayerLine{PlayerIndex: "p1", Stat: "points", Side: "Over", Type: "alternate", Line: 20.5, Odds: 150}
+		return map[odds.MarketKey]odds.PlayerLine{odds.NewMarketKey(closing): closing}, nil
+	}
+	var selectors []odds.LineSelector
+	alternates := data.getAlternateOddsForDateFn
+	data.getAlternateOddsForDateFn = func(sport sports.Sport, date time.Time, selector odds.LineSelector) (map[string]map[string][]odds.PlayerLine, error) {
+		selectors = append(selectors, selector)
+		return alternates(sport, date, selector)
+	}
+	b := NewBacktester(date, date, nil, BacktesterDeps{
+		DataSource: data,
+		Store: fakeBacktestStore{saveBacktestRunFn: func(run backtests.BacktestRun, bets []backtests.BacktestBet) (int, error) {
+			saved = append(saved, run)
+			savedBets = append(savedBets, bets)
+			return len(saved), nil
+		}},
+	})
+	b.Strategies = []Strategy{
+		{PropSelector: analysis.PropSelector{StratId: 4, StratName: "Alt", Thresholds: map[string]float32{"points": 0.1}, TresholdType: analysis.Percent, MinOdds: -200, MaxOdds: 500, MaxOver: 1, BetSize: 100, MinGames: 1, MinMinutes: 1}, BacktestResult: &BacktestResult{}},
+		{PropSelector: analysis.PropSelector{StratName: "Ad hoc"}, BacktestResult: &BacktestResult{}},
+	}
+
+	b.LineSelector = odds.EarliestLine{}
+
+	report, err := b.RunBacktest(context.Background())
+	if err != nil {
+		t.Fatalf("RunBacktest() error = %v", err)
+	}
+	if len(selectors) != 1 || selectors[0] != (odds.EarliestLine{}) {
+		t.Fatalf("expected the backtester's line selector to load odds, got %v", selectors)
+	}
+	if len(report.RunIds) != 2 || report.RunIds[0] != 1 || report.RunIds[1] != 2 || len(report.DateErrors) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	run := saved[0]
+	if run.StratId == nil || *run.StratId != 4 || run.Bets != 1 || run.Wins != 1 || run.Staked != 100 || run.Profit != 200 || run.ROI != 2 || run.WinRate != 1 {
+		t.Fatalf("unexpected stored run: %+v", run)
+	}
+	var config analysis.PropSelector
+	if err := json.Unmarshal(run.Config, &config); err != nil || config.MinOdds != -200 || config.Thresholds["points"] != 0.1 {
+		t.Fatalf("unexpected config snapshot %s err=%v", run.Config, err)
+	}
+	bet := savedBets[0][0]
+	if !bet.Date.Equal(date) || bet.PlayerIndex != "p1" || bet.LineId != 7 || bet.Actual != 22 || bet.Prediction != 25 || bet.Result != "win" || bet.Profit != 200 {
+		t.Fatalf("unexpected stored bet: %+v", bet)
+	}
+	if bet.ClosingOdds == nil || *bet.ClosingOdds != 150 || bet.CLVLine == nil || *bet.CLVLine != 0 || bet.CLVProb == nil || *bet.CLVProb <= 0 {
+		t.Fatalf("unexpected stored bet clv: %+v", bet)
+	}
+	if run.CLVBets != 1 || run.AvgCLVProb <= 0 || run.BeatCloseRate != 1 {
+		t.Fatalf("unexpected stored run clv: %+v", run)
+	}
+	if saved[1].StratId != nil || saved[1].Bets != 0 || len(savedBets[1]) != 0 {
+		t.Fatalf("unexpected ad hoc run: %+v", saved[1])
+	}
+
+	b.deps.Store = fakeBacktestStore{saveBacktestRunFn: func(run backtests.BacktestRun, bets []backtests.BacktestBet) (int, error) {
+		return 0, errors.New("db down")
+	}}
+	if _, err := b.RunBacktest(context.Background()); err == nil {
+		t.Fatalf("expected store error")
+	}
+}
+
+func TestRunBacktestCollectsDateErrorsInOrder(t *testing.T) {
+	start := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := singlePickDataSource()
+	data.getGamesForDateFn = func(sport sports.Sport, date time.Time) ([]games.Game, error) {
+		switch date.Day() {
+		case 2:
+			return nil, errors.New("db down")
+		case 4:
+			panic("bad row")
+		}
+		// later dates finish first so results only line up if ordering is restored
+		time.Sleep(time.Duration(10-date.Day()) * time.Millisecond)
+		return []games.Game{{Id: date.Day(), HomeIndex: "H", AwayIndex: "A"}}, nil
+	}
+	var saved []backtests.BacktestBet
+	b := NewBacktester(start, start.AddDate(0, 0, 5), nil, BacktesterDeps{
+		DataSource: data,
+		Store: fakeBacktestStore{saveBacktestRunFn: func(run backtests.BacktestRun, bets []backtests.BacktestBet) (int, error) {
+			saved = bets
+			return 1, nil
+		}},
+	})
+	b.Workers = 3
+	b.Strategies = []Strategy{{PropSelector: analysis.PropSelector{StratName: "Alt", Thresholds: map[string]float32{"points": 0.1}, TresholdType: analysis.Percent, MaxOdds: 500, MaxOver: 1, BetSize: 100, MinGames: 1, MinMinutes: 1}, BacktestResult: &BacktestResult{}}}
+	var progress []int
+	b.OnProgress = func(completed int, total int) { progress = append(progress, completed) }
+
+	report, err := b.RunBacktest(context.Background())
+	if err != nil {
+		t.Fatalf("RunBacktest() error = %v", err)
+	}
+	if len(report.DateErrors) != 2 || report.DateErrors[0].Date.Day() != 2 || report.DateErrors[1].Date.Day() != 4 {
+		t.Fatalf("unexpected date errors: %+v", report.DateErrors)
+	}
+	if !strings.Contains(report.DateErrors[0].Error(), "db down") || !strings.Contains(report.DateErrors[1].Error(), "bad row") {
+		t.Fatalf("unexpected date error messages: %v, %v", report.DateErrors[0], report.DateErrors[1])
+	}
+	if len(progress) != 6 || progress[5] != 6 {
+		t.Fatalf("unexpected progress %v", progress)
+	}
+	if len(saved) != 4 {
+		t.Fatalf("expected a bet on each loaded date, got %d", len(saved))
+	}
+	for i, day := range []int{1, 3, 5, 6} {
+		if saved[i].Date.Day() != day {
+			t.Fatalf("bets out of date order: %+v", saved)
+		}
+	}
+}
+
+func TestRunBacktestRecordsStrategyPickErrors(t *testing.T) {
+	start := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	var saved [][]backtests.BacktestBet
+	b := NewBacktester(start, start.AddDate(0, 0, 1), nil, BacktesterDeps{
+		DataSource: singlePickDataSource(),
+		Store: fakeBacktestStore{saveBacktestRunFn: func(run backtests.BacktestRun, bets []backtests.BacktestBet) (int, error) {
+			saved = append(saved, bets)
+			return len(saved), nil
+		}},
+	})
+	good := analysis.PropSelector{StratName: "Alt", Thresholds: map[string]float32{"points": 0.1}, TresholdType: analysis.Percent, MaxOdds: 500, MaxOver: 1, BetSize: 100, MinGames: 1, MinMinutes: 1}
+	broken := good
+	broken.StratName = "Broken"
+	broken.LineType = "exotic"
+	b.Strategies = []Strategy{{PropSelector: broken, BacktestResult: &BacktestResult{}}, {PropSelector: good, BacktestResult: &BacktestResult{}}}
+
+	report, err := b.RunBacktest(context.Background())
+	if err != nil {
+		t.Fatalf("RunBacktest() error = %v", err)
+	}
+	if len(report.DateErrors) != 2 || report.DateErrors[0].Date.Day() != 1 || report.DateErrors[1].Date.Day() != 2 {
+		t.Fatalf("expected the broken strategy to be reported on each date, got %+v", report.DateErrors)
+	}
+	if !strings.Contains(report.DateErrors[0].Error(), `strategy "Broken": unknown line type "exotic"`) {
+		t.Fatalf("unexpected date error message: %v", report.DateErrors[0])
+	}
+	if len(saved) != 2 || len(saved[0]) != 0 || len(saved[1]) != 2 {
+		t.Fatalf("expected only the working strategy to bet, got %+v", saved)
+	}
+}
+
+func TestRunBacktestFailsWhenEveryDateFails(t *testing.T) {
+	start := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := singlePickDataSource()
+	data.getGamesForDateFn = func(sport sports.Sport, date time.Time) ([]games.Game, error) {
+		return nil, errors.New("db down")
+	}
+	b := NewBacktester(start, start.AddDate(0, 0, 1), nil, BacktesterDeps{DataSource: data})
+
+	report, err := b.RunBacktest(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "db down") || len(report.DateErrors) != 2 {
+		t.Fatalf("expected every date to fail, got %+v, %v", report, err)
+	}
+}
+
+func TestRunBacktestCancelled(t *testing.T) {
+	start := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	data := singlePickDataSource()
+	data.getGamesForDateFn = func(sport sports.Sport, date time.Time) ([]games.Game, error) {
+		cancel()
+		return []games.Game{{Id: 1, HomeIndex: "H", AwayIndex: "A"}}, nil
+	}
+	b := NewBacktester(start, start.AddDate(0, 0, 30), nil, BacktesterDeps{
+		DataSource: data,
+		Store: fakeBacktestStore{saveBacktestRunFn: func(run backtests.BacktestRun, bets []backtests.BacktestBet) (int, error) {
+			t.Fatalf("cancelled backtest should not store runs")
+			return 0, nil
+		}},
+	})
+	b.Strategies = []Strategy{{PropSelector: analysis.PropSelector{StratName: "Alt"}, BacktestResult: &BacktestResult{}}}
+
+	if _, err := b.RunBacktest(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := b.RunSweep(ctx, SweepConfig{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected sweep to be cancelled, got %v", err)
+	}
+}
@@ -0,0 +1,336 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/mgordon34/kornet-kover/api/backtests"
+	"github.com/mgordon34/kornet-kover/api/strategies"
+	"github.com/mgordon34/kornet-kover/internal/analysis"
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
+
+const (
+	defaultBetsPageSize = 100
+	maxBetsPageSize     = 500
+)
+
+// StrategyConfig selects a stored strategy by id or describes one inline
+type StrategyConfig struct {
+	StrategyId int                          `json:"strategy_id"`
+	Name       string                       `json:"name"`
+	Settings   *strategies.StrategySettings `json:"settings"`
+	Filters    []strategies.StrategyFilter  `json:"filters"`
+}
+
+type BacktestRequest struct {
+	Sport      sports.Sport     `json:"sport"`
+	StartDate  string           `json:"start_date"`
+	EndDate    string           `json:"end_date"`
+	Strategies []StrategyConfig `json:"strategies"`
+}
+
+type BacktestJobResponse struct {
+	backtests.BacktestJob
+	Runs []backtests.BacktestRun `json:"runs"`
+}
+
+type BacktestBetsResponse struct {
+	Bets     []backtests.BacktestBet `json:"bets"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+	Total    int                     `json:"total"`
+}
+
+type BacktestJobStore interface {
+	AddBacktestJob(job backtests.BacktestJob) (int, error)
+	GetBacktestJob(jobId int) (backtests.BacktestJob, error)
+	UpdateBacktestJobProgress(jobId int, completedDays int) error
+	FinishBacktestJob(jobId int, status string, errMsg *string) error
+	GetBacktestRunsForJob(jobId int) ([]backtests.BacktestRun, error)
+	GetBacktestBetsForJob(jobId int, limit int, offset int) ([]backtests.BacktestBet, int, error)
+}
+
+type defaultBacktestJobStore struct{}
+
+func (d defaultBacktestJobStore) AddBacktestJob(job backtests.BacktestJob) (int, error) {
+	return backtests.AddBacktestJob(job)
+}
+
+func (d defaultBacktestJobStore) GetBacktestJob(jobId int) (backtests.BacktestJob, error) {
+	return backtests.GetBacktestJob(jobId)
+}
+
+func (d defaultBacktestJobStore) UpdateBacktestJobProgress(jobId int, completedDays int) error {
+	return backtests.UpdateBacktestJobProgress(jobId, completedDays)
+}
+
+func (d defaultBacktestJobStore) FinishBacktestJob(jobId int, status string, errMsg *string) error {
+	return backtests.FinishBacktestJob(jobId, status, errMsg)
+}
+
+func (d defaultBacktestJobStore) GetBacktestRunsForJob(jobId int) ([]backtests.BacktestRun, error) {
+	return backtests.GetBacktestRunsForJob(jobId)
+}
+
+func (d defaultBacktestJobStore) GetBacktestBetsForJob(jobId int, limit int, offset int) ([]backtests.BacktestBet, int, error) {
+	return backtests.GetBacktestBetsForJob(jobId, limit, offset)
+}
+
+type BacktestServiceDeps struct {
+	Jobs           BacktestJobStore
+	BacktesterDeps BacktesterDeps
+	LoadSelector   func(stratId int) (analysis.PropSelector, error)
+	// Launch starts a job in the background. Tests replace it to run jobs inline.
+	Launch func(job func())
+}
+
+type BacktestService struct {
+	deps BacktestServiceDeps
+}
+
+func NewBacktestService(deps BacktestServiceDeps) *BacktestService {
+	if deps.Jobs == nil {
+		deps.Jobs = defaultBacktestJobStore{}
+	}
+	if deps.LoadSelector == nil {
+		deps.LoadSelector = analysis.LoadStrategySelector
+	}
+	if deps.Launch == nil {
+		deps.Launch = func(job func()) { go job() }
+	}
+	return &BacktestService{deps: deps}
+}
+
+func (s *BacktestService) RegisterRoutes(r gin.IRouter) {
+	r.POST("/backtests", s.CreateBacktestHandler())
+	r.GET("/backtests/:id", s.GetBacktestHandler())
+	r.GET("/backtests/:id/bets", s.GetBacktestBetsHandler())
+}
+
+func (r BacktestRequest) parseDates() (time.Time, time.Time, error) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	startDate, err := time.ParseInLocation("2006-01-02", r.StartDate, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start_date %q", r.StartDate)
+	}
+	endDate, err := time.ParseInLocation("2006-01-02", r.EndDate, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end_date %q", r.EndDate)
+	}
+	if endDate.Before(startDate) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end_date must not be before start_date")
+	}
+	return startDate, endDate, nil
+}
+
+// buildStrategies resolves every config into a backtest Strategy, loading
+// stored strategies by id and building inline ones from their settings
+func (s *BacktestService) buildStrategies(configs []StrategyConfig) ([]Strategy, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("at least one strategy is required")
+	}
+
+	var strats []Strategy
+	for i, config := range configs {
+		var selector analysis.PropSelector
+		if config.StrategyId != 0 {
+			var err error
+			selector, err = s.deps.LoadSelector(config.StrategyId)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			if config.Settings == nil && len(config.Filters) == 0 {
+				return nil, fmt.Errorf("strategy %d needs a strategy_id, settings or filters", i)
+			}
+			if config.Settings != nil {
+				if err := config.Settings.Validate(); err != nil {
+					return nil, fmt.Errorf("strategy %d has invalid settings: %w", i, err)
+				}
+			}
+			for _, filter := range config.Filters {
+				if err := filter.Validate(); err != nil {
+					return nil, fmt.Errorf("strategy %d has an invalid filter: %w", i, err)
+				}
+			}
+			name := config.Name
+			if name == "" {
+				name = fmt.Sprintf("Strategy %d", i+1)
+			}
+			selector = analysis.NewStrategySelector(strategies.Strategy{Name: name, Settings: config.Settings}, config.Filters)
+		}
+		strats = append(strats, Strategy{PropSelector: selector, BacktestResult: &BacktestResult{}})
+	}
+
+	return strats, nil
+}
+
+func (s *BacktestService) CreateBacktestHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BacktestRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Sport == "" {
+			req.Sport = sports.NBA
+		}
+		if _, ok := gameLineups[req.Sport]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("backtesting is not supported for sport %s", req.Sport)})
+			return
+		}
+		startDate, endDate, err := req.parseDates()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		strats, err := s.buildStrategies(req.Strategies)
+		if err != nil {
+			c.JSON(statusForError(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+			return
+		}
+
+		b := NewBacktester(startDate, endDate, strats, s.deps.BacktesterDeps)
+		b.Sport = req.Sport
+		job := backtests.BacktestJob{
+			Sport:     string(req.Sport),
+			StartDate: startDate,
+			EndDate:   endDate,
+			Status:    backtests.JobQueued,
+			TotalDays: b.TotalDays(),
+		}
+		job.Id, err = s.deps.Jobs.AddBacktestJob(job)
+		if err != nil {
+			log.Println("Error creating backtest job:", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		b.JobId = job.Id
+		b.OnProgress = func(completed int, total int) {
+			if err := s.deps.Jobs.UpdateBacktestJobProgress(job.Id, completed); err != nil {
+				log.Println("Error updating backtest progress:", err)
+			}
+		}
+		s.deps.Launch(func() { s.runJob(job.Id, b) })
+
+		c.JSON(http.StatusAccepted, job)
+	}
+}
+
+// runJob runs the backtest and records how it finished. Panics are recovered
+// so a bad date fails the job instead of the server. A job that completes with
+// skipped dates keeps them in its error.
+func (s *BacktestService) runJob(jobId int, b Backtester) {
+	status := backtests.JobCompleted
+	var errMsg *string
+	defer func() {
+		if r := recover(); r != nil {
+			status = backtests.JobFailed
+			msg := fmt.Sprint("backtest panicked: ", r)
+			errMsg = &msg
+		}
+		if errMsg != nil {
+			log.Printf("Backtest job %d failed: %s", jobId, *errMsg)
+		}
+		if err := s.deps.Jobs.FinishBacktestJob(jobId, status, errMsg); err != nil {
+			log.Println("Error finishing backtest job:", err)
+		}
+	}()
+
+	report, err := b.RunBacktest(context.Background())
+	if err != nil {
+		status = backtests.JobFailed
+		msg := err.Error()
+		errMsg = &msg
+		return
+	}
+	if len(report.DateErrors) > 0 {
+		skipped := make([]string, len(report.DateErrors))
+		for i, dateErr := range report.DateErrors {
+			skipped[i] = dateErr.Error()
+		}
+		msg := fmt.Sprintf("skipped %d dates: %s", len(skipped), strings.Join(skipped, "; "))
+		errMsg = &msg
+	}
+}
+
+func (s *BacktestService) GetBacktestHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid backtest id"})
+			return
+		}
+
+		job, err := s.deps.Jobs.GetBacktestJob(jobId)
+		if err != nil {
+			c.JSON(statusForError(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+			return
+		}
+		runs, err := s.deps.Jobs.GetBacktestRunsForJob(jobId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if runs == nil {
+			runs = []backtests.BacktestRun{}
+		}
+
+		c.JSON(http.StatusOK, BacktestJobResponse{BacktestJob: job, Runs: runs})
+	}
+}
+
+func (s *BacktestService) GetBacktestBetsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid backtest id"})
+			return
+		}
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+			return
+		}
+		pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultBetsPageSize)))
+		if err != nil || pageSize < 1 || pageSize > maxBetsPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("page_size must be between 1 and %d", maxBetsPageSize)})
+			return
+		}
+
+		if _, err := s.deps.Jobs.GetBacktestJob(jobId); err != nil {
+			c.JSON(statusForError(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+			return
+		}
+		bets, total, err := s.deps.Jobs.GetBacktestBetsForJob(jobId, pageSize, (page-1)*pageSize)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if bets == nil {
+			bets = []backtests.BacktestBet{}
+		}
+
+		c.JSON(http.StatusOK, BacktestBetsResponse{Bets: bets, Page: page, PageSize: pageSize, Total: total})
+	}
+}
+
+func statusForError(err error, fallback int) int {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return http.StatusNotFound
+	}
+	return fallback
+}
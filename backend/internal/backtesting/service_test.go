@@ -0,0 +1,257 @@
+package backtesting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/mgordon34/kornet-kover/api/backtests"
+	"github.com/mgordon34/kornet-kover/api/games"
+	"github.com/mgordon34/kornet-kover/internal/analysis"
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
+
+type memoryJobStore struct {
+	jobs     map[int]*backtests.BacktestJob
+	runs     map[int][]backtests.BacktestRun
+	bets     map[int][]backtests.BacktestBet
+	progress []int
+}
+
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{
+		jobs: map[int]*backtests.BacktestJob{},
+		runs: map[int][]backtests.BacktestRun{},
+		bets: map[int][]backtests.BacktestBet{},
+	}
+}
+
+func (m *memoryJobStore) AddBacktestJob(job backtests.BacktestJob) (int, error) {
+	job.Id = len(m.jobs) + 1
+	m.jobs[job.Id] = &job
+	return job.Id, nil
+}
+
+func (m *memoryJobStore) GetBacktestJob(jobId int) (backtests.BacktestJob, error) {
+	job, ok := m.jobs[jobId]
+	if !ok {
+		return backtests.BacktestJob{}, fmt.Errorf("error getting backtest job %d: %w", jobId, pgx.ErrNoRows)
+	}
+	return *job, nil
+}
+
+func (m *memoryJobStore) UpdateBacktestJobProgress(jobId int, completedDays int) error {
+	m.jobs[jobId].Status = backtests.JobRunning
+	m.jobs[jobId].CompletedDays = completedDays
+	m.progress = append(m.progress, completedDays)
+	return nil
+}
+
+func (m *memoryJobStore) FinishBacktestJob(jobId int, status string, errMsg *string) error {
+	m.jobs[jobId].Status = status
+	m.jobs[jobId].Error = errMsg
+	return nil
+}
+
+func (m *memoryJobStore) GetBacktestRunsForJob(jobId int) ([]backtests.BacktestRun, error) {
+	return m.runs[jobId], nil
+}
+
+func (m *memoryJobStore) GetBacktestBetsForJob(jobId int, limit int, offset int) ([]backtests.BacktestBet, int, error) {
+	bets := m.bets[jobId]
+	if offset >= len(bets) {
+		return nil, len(bets), nil
+	}
+	end := offset + limit
+	if end > len(bets) {
+		end = len(bets)
+	}
+	return bets[offset:end], len(bets), nil
+}
+
+func (m *memoryJobStore) SaveBacktestRun(run backtests.BacktestRun, bets []backtests.BacktestBet) (int, error) {
+	run.Id = len(m.runs[*run.JobId]) + 1
+	m.runs[*run.JobId] = append(m.runs[*run.JobId], run)
+	m.bets[*run.JobId] = append(m.bets[*run.JobId], bets...)
+	return run.Id, nil
+}
+
+func newTestBacktestService(store *memoryJobStore, dataSource BacktesterDataSource) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewBacktestService(BacktestServiceDeps{
+		Jobs:           store,
+		BacktesterDeps: BacktesterDeps{DataSource: dataSource, Store: store},
+		LoadSelector: func(stratId int) (analysis.PropSelector, error) {
+			if stratId != 4 {
+				return analysis.PropSelector{}, fmt.Errorf("error getting strategy %d: %w", stratId, pgx.ErrNoRows)
+			}
+			return analysis.PropSelector{StratId: 4, StratName: "Stored", Thresholds: map[string]float32{"points": 0.1}, TresholdType: analysis.Percent, MinOdds: -200, MaxOdds: 500, MaxOver: 1, BetSize: 100, MinGames: 1, MinMinutes: 1}, nil
+		},
+		Launch: func(job func()) { job() },
+	}).RegisterRoutes(r)
+	return r
+}
+
+func serveBacktestRequest(r *gin.Engine, method string, path string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBacktestJobLifecycle(t *testing.T) {
+	store := newMemoryJobStore()
+	r := newTestBacktestService(store, singlePickDataSource())
+
+	body := `{"sport": "nba", "start_date": "2099-01-01", "end_date": "2099-01-02", "strategies": [
+		{"strategy_id": 4},
+		{"name": "Inline", "settings": {"thresholds": {"points": 0.1}, "threshold_type": "percent", "min_odds": -200, "max_odds": 500, "max_over": 1, "min_games": 1, "min_minutes": 1}}
+	]}`
+	rec := serveBacktestRequest(r, http.MethodPost, "/backtests", body)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("create status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var created backtests.BacktestJob
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || created.Id != 1 || created.Status != backtests.JobQueued || created.TotalDays != 2 {
+		t.Fatalf("unexpected created job %+v err=%v", created, err)
+	}
+	if len(store.progress) != 2 || store.progress[1] != 2 {
+		t.Fatalf("unexpected progress updates: %v", store.progress)
+	}
+
+	rec = serveBacktestRequest(r, http.MethodGet, "/backtests/1", "")
+	var job BacktestJobResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("get status = %d err=%v", rec.Code, err)
+	}
+	if job.Status != backtests.JobCompleted || job.CompletedDays != 2 || len(job.Runs) != 2 {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+	if job.Runs[0].StratName != "Stored" || job.Runs[1].StratName != "Inline" || job.Runs[1].Bets != 2 {
+		t.Fatalf("unexpected runs: %+v", job.Runs)
+	}
+
+	rec = serveBacktestRequest(r, http.MethodGet, "/backtests/1/bets?page=2&page_size=3", "")
+	var page BacktestBetsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("bets status = %d err=%v", rec.Code, err)
+	}
+	if page.Total != 4 || page.Page != 2 || page.PageSize != 3 || len(page.Bets) != 1 {
+		t.Fatalf("unexpected bets page: %+v", page)
+	}
+}
+
+func TestBacktestJobFailureIsRecorded(t *testing.T) {
+	store := newMemoryJobStore()
+	dataSource := singlePickDataSource()
+	dataSource.getGamesForDateFn = func(sport sports.Sport, date time.Time) ([]games.Game, error) {
+		return nil, errors.New("db down")
+	}
+	r := newTestBacktestService(store, dataSource)
+
+	rec := serveBacktestRequest(r, http.MethodPost, "/backtests", `{"start_date": "2099-01-01", "end_date": "2099-01-01", "strategies": [{"strategy_id": 4}]}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("create status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if job := store.jobs[1]; job.Status != backtests.JobFailed || job.Error == nil || !strings.Contains(*job.Error, "db down") {
+		t.Fatalf("unexpected failed job: %+v", job)
+	}
+
+	dataSource.getGamesForDateFn = func(sport sports.Sport, date time.Time) ([]games.Game, error) {
+		panic("bad data")
+	}
+	r = newTestBacktestService(store, dataSource)
+	serveBacktestRequest(r, http.MethodPost, "/backtests", `{"start_date": "2099-01-01", "end_date": "2099-01-01", "strategies": [{"strategy_id": 4}]}`)
+	if job := store.jobs[2]; job.Status != backtests.JobFailed || job.Error == nil || !strings.Contains(*job.Error, "bad data") {
+		t.Fatalf("unexpected panicked job: %+v", job)
+	}
+}
+
+func TestBacktestJobRunsForRequestedSport(t *testing.T) {
+	store := newMemoryJobStore()
+	dataSource := singlePickDataSource()
+	var gotSport sports.Sport
+	dataSource.getGamesForDateFn = func(sport sports.Sport, date time.Time) ([]games.Game, error) {
+		gotSport = sport
+		return nil, nil
+	}
+	r := newTestBacktestService(store, dataSource)
+
+	rec := serveBacktestRequest(r, http.MethodPost, "/backtests", `{"sport": "mlb", "start_date": "2099-05-01", "end_date": "2099-05-01", "strategies": [{"strategy_id": 4}]}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("create status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if job := store.jobs[1]; job.Sport != "mlb" || gotSport != sports.MLB {
+		t.Fatalf("expected an MLB backtest, got job %+v for %v games", job, gotSport)
+	}
+
+	rec = serveBacktestRequest(r, http.MethodPost, "/backtests", `{"sport": "wnba", "start_date": "2099-07-01", "end_date": "2099-07-01", "strategies": [{"strategy_id": 4}]}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("create wnba status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if job := store.jobs[2]; job.Sport != "wnba" || gotSport != sports.WNBA {
+		t.Fatalf("expected a WNBA backtest, got job %+v for %v games", job, gotSport)
+	}
+}
+
+func TestBacktestHandlersRejectBadInput(t *testing.T) {
+	r := newTestBacktestService(newMemoryJobStore(), singlePickDataSource())
+
+	cases := map[string]int{
+		`not json`: http.StatusBadRequest,
+		`{"sport": "cricket", "start_date": "2099-01-01", "end_date": "2099-01-01", "strategies": [{"strategy_id": 4}]}`: http.StatusBadRequest,
+		`{"start_date": "bad", "end_date": "2099-01-01", "strategies": [{"strategy_id": 4}]}`:                            http.StatusBadRequest,
+		`{"start_date": "2099-01-02", "end_date": "2099-01-01", "strategies": [{"strategy_id": 4}]}`:                     http.StatusBadRequest,
+		`{"start_date": "2099-01-01", "end_date": "2099-01-01", "strategies": []}`:                                       http.StatusBadRequest,
+		`{"start_date": "2099-01-01", "end_date": "2099-01-01", "strategies": [{"name": "Empty"}]}`:                      http.StatusBadRequest,
+		`{"start_date": "2099-01-01", "end_date": "2099-01-01", "strategies": [{"settings": {"line_type": "x"}}]}`:       http.StatusBadRequest,
+		`{"start_date": "2099-01-01", "end_date": "2099-01-01", "strategies": [{"strategy_id": 9}]}`:                     http.StatusNotFound,
+	}
+	for body, want := range cases {
+		if rec := serveBacktestRequest(r, http.MethodPost, "/backtests", body); rec.Code != want {
+			t.Fatalf("%s status = %d, want %d", body, rec.Code, want)
+		}
+	}
+
+	paths := map[string]int{
+		"/backtests/bad":                   http.StatusBadRequest,
+		"/backtests/7":                     http.StatusNotFound,
+		"/backtests/bad/bets":              http.StatusBadRequest,
+		"/backtests/7/bets":                http.StatusNotFound,
+		"/backtests/1/bets?page=0":         http.StatusBadRequest,
+		"/backtests/1/bets?page_size=1000": http.StatusBadRequest,
+	}
+	for path, want := range paths {
+		if rec := serveBacktestRequest(r, http.MethodGet, path, ""); rec.Code != want {
+			t.Fatalf("%s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestBacktestJobKeepsSkippedDates(t *testing.T) {
+	store := newMemoryJobStore()
+	dataSource := singlePickDataSource()
+	getGames := dataSource.getGamesForDateFn
+	dataSource.getGamesForDateFn = func(sport sports.Sport, date time.Time) ([]games.Game, error) {
+		if date.Day() == 2 {
+			return nil, errors.New("db down")
+		}
+		return getGames(sport, date)
+	}
+	r := newTestBacktestService(store, dataSource)
+
+	serveBacktestRequest(r, http.MethodPost, "/backtests", `{"start_date": "2099-01-01", "end_date": "2099-01-02", "strategies": [{"strategy_id": 4}]}`)
+	job := store.jobs[1]
+	if job.Status != backtests.JobCompleted || job.Error == nil || !strings.Contains(*job.Error, "skipped 1 dates: 2099-01-02") {
+		t.Fatalf("unexpected job with skipped dates: %+v", job)
+	}
+}
@@ -0,0 +1,283 @@
+package backtesting
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/mgordon34/kornet-kover/internal/analysis"
+)
+
+const (
+	defaultStartingBankroll = 10000
+	defaultRuinSimulations  = 1000
+	// defaultRuinThreshold is the share of the starting bankroll at which a
+	// simulated bankroll counts as ruined
+	defaultRuinThreshold = 0.1
+)
+
+// StakingPolicy sizes a bet given the bankroll it is placed from
+type StakingPolicy interface {
+	Name() string
+	Stake(bankroll float32, pick analysis.PropPick) float32
+}
+
+// FlatStake bets a fixed amount, falling back to the selector's BetSize
+type FlatStake struct {
+	Amount float32
+}
+
+func (f FlatStake) Name() string {
+	return "flat"
+}
+
+func (f FlatStake) Stake(bankroll float32, pick analysis.PropPick) float32 {
+	if f.Amount > 0 {
+		return f.Amount
+	}
+	return pick.BetSize
+}
+
+// PercentStake bets a fixed share of the current bankroll
+type PercentStake struct {
+	Percent float32
+}
+
+func (p PercentStake) Name() string {
+	return fmt.Sprintf("%.1f%% of bankroll", p.Percent*100)
+}
+
+func (p PercentStake) Stake(bankroll float32, pick analysis.PropPick) float32 {
+	return bankroll * p.Percent
+}
+
+// KellyStake bets Fraction of the Kelly criterion for the model's edge over
+// the price. Cap, when set, limits a single bet to that share of the bankroll.
+// WinProbability defaults to ModelWinProbability.
+type KellyStake struct {
+	Fraction       float32
+	Cap            float32
+	WinProbability func(pick analysis.PropPick) float64
+}
+
+func (k KellyStake) Name() string {
+	if k.Cap > 0 {
+		return fmt.Sprintf("%.2fx kelly capped at %.1f%%", k.Fraction, k.Cap*100)
+	}
+	return fmt.Sprintf("%.2fx kelly", k.Fraction)
+}
+
+func (k KellyStake) Stake(bankroll float32, pick analysis.PropPick) float32 {
+	winProbability := k.WinProbability
+	if winProbability == nil {
+		winProbability = ModelWinProbability
+	}
+
+	fraction := float32(KellyFraction(winProbability(pick), pick.GetLine().Odds)) * k.Fraction
+	if k.Cap > 0 && fraction > k.Cap {
+		fraction = k.Cap
+	}
+	return bankroll * fraction
+}
+
+// KellyFraction is the share of bankroll the Kelly criterion stakes at the
+// given win probability and american odds. Bets without an edge get 0.
+func KellyFraction(winProbability float64, odds int) float64 {
+	payout := float64(calculateProfit(1, odds))
+	if payout <= 0 {
+		return 0
+	}
+	fraction := (payout*winProbability - (1 - winProbability)) / payout
+	return math.Max(fraction, 0)
+}
+
+// ModelWinProbability is the chance the pick hits under its stat's fitted
+// distribution. Picks without one are approximated by treating the stat as
+// normally distributed around the prediction with a Poisson-like variance
+// equal to the prediction.
+func ModelWinProbability(pick analysis.PropPick) float64 {
+	if prob, ok := pick.ModelProbability(); ok {
+		return prob
+	}
+	if pick.Prediction == nil {
+		return 0
+	}
+	prediction := float64(pick.Prediction.GetStats()[pick.Stat])
+	if prediction <= 0 {
+		return 0
+	}
+	z := (prediction - float64(pick.GetLine().Line)) / math.Sqrt(prediction)
+	over := 0.5 * math.Erfc(-z/math.Sqrt2)
+	if pick.Side == "Under" {
+		return 1 - over
+	}
+	return over
+}
+
+type StakingConfig struct {
+	Policy           StakingPolicy
+	StartingBankroll float32
+	// MaxDailyExposure limits the total staked on a date to this share of the
+	// bankroll at the start of the date. Zero means no limit.
+	MaxDailyExposure float32
+	// RuinThreshold is the share of the starting bankroll that counts as ruin
+	RuinThreshold float32
+	// RuinSimulations is how many resampled seasons estimate risk of ruin
+	RuinSimulations int
+	// Seed makes the risk of ruin simulation reproducible
+	Seed int64
+}
+
+func (c StakingConfig) withDefaults() StakingConfig {
+	if c.Policy == nil {
+		c.Policy = FlatStake{}
+	}
+	if c.StartingBankroll <= 0 {
+		c.StartingBankroll = defaultStartingBankroll
+	}
+	if c.RuinThreshold <= 0 {
+		c.RuinThreshold = defaultRuinThreshold
+	}
+	if c.RuinSimulations <= 0 {
+		c.RuinSimulations = defaultRuinSimulations
+	}
+	return c
+}
+
+type BankrollPoint struct {
+	Date     time.Time
+	Bankroll float32
+	Exposure float32
+}
+
+type BankrollReport struct {
+	Policy           string
+	StartingBankroll float32
+	EndingBankroll   float32
+	Curve            []BankrollPoint
+	Bets             int
+	// Skipped counts bets staked at nothing, either for lack of edge or
+	// because the daily exposure limit was already used up
+	Skipped        int
+	Staked         float32
+	Profit         float32
+	ROI            float32
+	MaxDrawdown    float32
+	MaxDrawdownPct float32
+	Ruined         bool
+	RiskOfRuin     float32
+}
+
+// bettingDays groups settled bets by date, keeping the order they were placed
+func bettingDays(bets []*analysis.PropPick) [][]*analysis.PropPick {
+	var days [][]*analysis.PropPick
+	for _, bet := range bets {
+		if len(days) == 0 || !days[len(days)-1][0].Date.Equal(bet.Date) {
+			days = append(days, nil)
+		}
+		days[len(days)-1] = append(days[len(days)-1], bet)
+	}
+	return days
+}
+
+// simulateBankroll replays the days under the staking config. Every bet on a
+// date is sized from the bankroll at the start of the date, since they all
+// settle together.
+func simulateBankroll(config StakingConfig, days [][]*analysis.PropPick) BankrollReport {
+	report := BankrollReport{
+		Policy:           config.Policy.Name(),
+		StartingBankroll: config.StartingBankroll,
+	}
+	bankroll := config.StartingBankroll
+	peak := bankroll
+	ruinLevel := config.StartingBankroll * config.RuinThreshold
+
+	for _, day := range days {
+		if bankroll <= ruinLevel {
+			report.Ruined = true
+			break
+		}
+		limit := float32(math.MaxFloat32)
+		if config.MaxDailyExposure > 0 {
+			limit = bankroll * config.MaxDailyExposure
+		}
+
+		var exposure, dayProfit float32
+		for _, bet := range day {
+			stake := min(config.Policy.Stake(bankroll, *bet), limit-exposure, bankroll-exposure)
+			if stake <= 0 {
+				report.Skipped++
+				continue
+			}
+			exposure += stake
+			report.Bets++
+			if bet.Result == "Win" {
+				dayProfit += calculateProfit(stake, bet.GetLine().Odds)
+			} else {
+				dayProfit -= stake
+			}
+		}
+
+		bankroll += dayProfit
+		report.Staked += exposure
+		report.Curve = append(report.Curve, BankrollPoint{Date: day[0].Date, Bankroll: bankroll, Exposure: exposure})
+		if bankroll > peak {
+			peak = bankroll
+		}
+		if peak-bankroll > report.MaxDrawdown {
+			report.MaxDrawdown = peak - bankroll
+			report.MaxDrawdownPct = report.MaxDrawdown / peak
+		}
+	}
+	if bankroll <= ruinLevel {
+		report.Ruined = true
+	}
+
+	report.EndingBankroll = bankroll
+	report.Profit = bankroll - config.StartingBankroll
+	if report.Staked > 0 {
+		report.ROI = report.Profit / report.Staked
+	}
+
+	return report
+}
+
+// riskOfRuin resamples betting days with replacement into seasons of the same
+// length and returns the share of them that end up ruined
+func riskOfRuin(config StakingConfig, days [][]*analysis.PropPick) float32 {
+	if len(days) == 0 {
+		return 0
+	}
+	rng := rand.New(rand.NewSource(config.Seed))
+	ruined := 0
+	season := make([][]*analysis.PropPick, len(days))
+	for sim := 0; sim < config.RuinSimulations; sim++ {
+		for i := range season {
+			season[i] = days[rng.Intn(len(days))]
+		}
+		if simulateBankroll(config, season).Ruined {
+			ruined++
+		}
+	}
+	return float32(ruined) / float32(config.RuinSimulations)
+}
+
+// SimulateBankroll replays the result's settled bets under the staking config
+// and estimates its risk of ruin
+func (b BacktestResult) SimulateBankroll(config StakingConfig) BankrollReport {
+	config = config.withDefaults()
+	days := bettingDays(b.Bets)
+	report := simulateBankroll(config, days)
+	report.RiskOfRuin = riskOfRuin(config, days)
+	return report
+}
+
+func (r BankrollReport) print(name string) {
+	log.Println("------------------------------------------")
+	log.Printf("Strategy: %s staked %s", name, r.Policy)
+	log.Printf("%v Bets (%v skipped). Bankroll $%.2f -> $%.2f. ROI: %.2f%%", r.Bets, r.Skipped, r.StartingBankroll, r.EndingBankroll, r.ROI*100)
+	log.Printf("Max drawdown $%.2f (%.2f%%). Risk of ruin: %.2f%%", r.MaxDrawdown, r.MaxDrawdownPct*100, r.RiskOfRuin*100)
+	log.Println("------------------------------------------")
+}
@@ -0,0 +1,173 @@
+package backtesting
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mgordon34/kornet-kover/api/backtests"
+	"github.com/mgordon34/kornet-kover/api/odds"
+	"github.com/mgordon34/kornet-kover/api/players"
+	"github.com/mgordon34/kornet-kover/internal/analysis"
+)
+
+func settledBet(day int, result string, odds int) *analysis.PropPick {
+	return &analysis.PropPick{
+		Date:       time.Date(2099, 1, day, 0, 0, 0, 0, time.UTC),
+		Stat:       "points",
+		Side:       "Over",
+		BetSize:    100,
+		Result:     result,
+		PlayerLine: oddsLine(20.5, odds),
+		Analysis:   analysis.Analysis{Prediction: players.NBAAvg{Points: 25}},
+	}
+}
+
+func oddsLine(line float32, price int) odds.PlayerLine {
+	return odds.PlayerLine{Side: "Over", Line: line, Odds: price}
+}
+
+func TestStakingPolicies(t *testing.T) {
+	pick := *settledBet(1, "Win", 100)
+
+	if got := (FlatStake{}).Stake(1000, pick); got != 100 {
+		t.Fatalf("flat stake default = %v", got)
+	}
+	if got := (FlatStake{Amount: 25}).Stake(1000, pick); got != 25 {
+		t.Fatalf("flat stake amount = %v", got)
+	}
+	if got := (PercentStake{Percent: .02}).Stake(1000, pick); got != 20 {
+		t.Fatalf("percent stake = %v", got)
+	}
+
+	// 60% at even money is a 20% full kelly bet
+	kelly := KellyStake{Fraction: .5, WinProbability: func(analysis.PropPick) float64 { return .6 }}
+	if got := kelly.Stake(1000, pick); math.Abs(float64(got)-100) > 1e-3 {
+		t.Fatalf("half kelly stake = %v", got)
+	}
+	kelly.Cap = .05
+	if got := kelly.Stake(1000, pick); got != 50 {
+		t.Fatalf("capped kelly stake = %v", got)
+	}
+	if got := (KellyStake{Fraction: 1, WinProbability: func(analysis.PropPick) float64 { return .4 }}).Stake(1000, pick); got != 0 {
+		t.Fatalf("kelly without edge = %v", got)
+	}
+	if got := (KellyStake{Fraction: 1}).Stake(1000, pick); got <= 0 {
+		t.Fatalf("kelly on model probability should bet a 25 vs 20.5 over, got %v", got)
+	}
+
+	for _, policy := range []StakingPolicy{FlatStake{}, PercentStake{Percent: .01}, KellyStake{Fraction: .25}, KellyStake{Fraction: .25, Cap: .02}} {
+		if policy.Name() == "" {
+			t.Fatalf("policy %T has no name", policy)
+		}
+	}
+}
+
+func TestKellyFractionAndModelWinProbability(t *testing.T) {
+	if got := KellyFraction(.55, -110); math.Abs(got-.055) > 1e-3 {
+		t.Fatalf("KellyFraction(.55, -110) = %v", got)
+	}
+	if got := KellyFraction(.9, 0); got != 0 {
+		t.Fatalf("KellyFraction with no payout = %v", got)
+	}
+
+	over := *settledBet(1, "Win", 100)
+	under := over
+	under.Side = "Under"
+	pOver, pUnder := ModelWinProbability(over), ModelWinProbability(under)
+	if pOver <= .5 || math.Abs(pOver+pUnder-1) > 1e-9 {
+		t.Fatalf("unexpected model probabilities over=%v under=%v", pOver, pUnder)
+	}
+	if got := ModelWinProbability(analysis.PropPick{}); got != 0 {
+		t.Fatalf("missing prediction probability = %v", got)
+	}
+	zero := over
+	zero.Prediction = players.NBAAvg{}
+	if got := ModelWinProbability(zero); got != 0 {
+		t.Fatalf("zero prediction probability = %v", got)
+	}
+	fitted := over
+	fitted.Distributions = map[string]analysis.StatDistribution{over.Stat: analysis.Poisson{Lambda: 30}}
+	if want, _ := fitted.ModelProbability(); ModelWinProbability(fitted) != want {
+		t.Fatalf("expected the fitted distribution to win out, got %v want %v", ModelWinProbability(fitted), want)
+	}
+}
+
+func TestSimulateBankrollWithDailyExposure(t *testing.T) {
+	res := BacktestResult{Bets: []*analysis.PropPick{
+		settledBet(1, "Win", 100),
+		settledBet(1, "Loss", 100),
+		settledBet(1, "Win", 100),
+		settledBet(2, "Loss", 100),
+		settledBet(3, "Win", 200),
+	}}
+
+	report := res.SimulateBankroll(StakingConfig{Policy: PercentStake{Percent: .1}, StartingBankroll: 1000, MaxDailyExposure: .15, Seed: 1})
+	// day 1: 100 win, 50 loss then the limit is spent; day 2: 105 loss; day 3: 94.5 at +200
+	if report.Bets != 4 || report.Skipped != 1 || len(report.Curve) != 3 {
+		t.Fatalf("unexpected bet counts %+v", report)
+	}
+	if math.Abs(float64(report.Curve[0].Bankroll)-1050) > 1e-3 || report.Curve[0].Exposure != 150 {
+		t.Fatalf("unexpected first day %+v", report.Curve[0])
+	}
+	if math.Abs(float64(report.EndingBankroll)-1134) > 1e-2 || math.Abs(float64(report.MaxDrawdown)-105) > 1e-2 {
+		t.Fatalf("unexpected bankroll %+v", report)
+	}
+	if math.Abs(float64(report.MaxDrawdownPct)-.1) > 1e-4 || report.ROI <= 0 || report.Ruined {
+		t.Fatalf("unexpected drawdown or roi %+v", report)
+	}
+	if report.RiskOfRuin != 0 {
+		t.Fatalf("expected no ruin at 10%% stakes with a winning record, got %v", report.RiskOfRuin)
+	}
+}
+
+func TestSimulateBankrollRuin(t *testing.T) {
+	var bets []*analysis.PropPick
+	for day := 1; day <= 10; day++ {
+		bets = append(bets, settledBet(day, "Loss", 100))
+	}
+	bets = append(bets, settledBet(11, "Win", 100))
+	res := BacktestResult{Bets: bets}
+
+	report := res.SimulateBankroll(StakingConfig{Policy: FlatStake{Amount: 300}, StartingBankroll: 1000, Seed: 7})
+	if !report.Ruined || report.EndingBankroll > 100 || report.RiskOfRuin < .9 {
+		t.Fatalf("expected ruin, got %+v", report)
+	}
+	again := res.SimulateBankroll(StakingConfig{Policy: FlatStake{Amount: 300}, StartingBankroll: 1000, Seed: 7})
+	if again.RiskOfRuin != report.RiskOfRuin {
+		t.Fatalf("risk of ruin should be reproducible with a seed: %v vs %v", again.RiskOfRuin, report.RiskOfRuin)
+	}
+
+	empty := BacktestResult{}.SimulateBankroll(StakingConfig{})
+	if empty.Policy != "flat" || empty.StartingBankroll != defaultStartingBankroll || empty.EndingBankroll != defaultStartingBankroll || empty.RiskOfRuin != 0 {
+		t.Fatalf("unexpected empty report %+v", empty)
+	}
+}
+
+func TestRunBacktestReportsBankrolls(t *testing.T) {
+	date := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewBacktester(date, date.AddDate(0, 0, 1), nil, BacktesterDeps{
+		DataSource: singlePickDataSource(),
+		Store: fakeBacktestStore{saveBacktestRunFn: func(run backtests.BacktestRun, bets []backtests.BacktestBet) (int, error) {
+			return 1, nil
+		}},
+	})
+	b.Strategies = []Strategy{{PropSelector: analysis.PropSelector{StratName: "Alt", Thresholds: map[string]float32{"points": 0.1}, TresholdType: analysis.Percent, MaxOdds: 500, MaxOver: 1, BetSize: 100, MinGames: 1, MinMinutes: 1}, BacktestResult: &BacktestResult{}}}
+	b.Staking = []StakingConfig{{Policy: FlatStake{}, StartingBankroll: 1000}, {Policy: KellyStake{Fraction: .25, Cap: .02}, StartingBankroll: 1000}}
+
+	report, err := b.RunBacktest(context.Background())
+	if err != nil {
+		t.Fatalf("RunBacktest() error = %v", err)
+	}
+	if len(report.Bankrolls) != 1 || len(report.Bankrolls[0]) != 2 {
+		t.Fatalf("unexpected bankrolls %+v", report.Bankrolls)
+	}
+	flat, kelly := report.Bankrolls[0][0], report.Bankrolls[0][1]
+	if flat.EndingBankroll != 1400 || len(flat.Curve) != 2 {
+		t.Fatalf("unexpected flat bankroll %+v", flat)
+	}
+	if kelly.Curve[0].Exposure != 20 || kelly.EndingBankroll <= 1000 {
+		t.Fatalf("unexpected capped kelly bankroll %+v", kelly)
+	}
+}
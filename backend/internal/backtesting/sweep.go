@@ -0,0 +1,269 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mgordon34/kornet-kover/internal/analysis"
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
+
+// SweepParam is a range of values to try for one PropSelector field. Field is
+// either a numeric selector field such as "MinOdds" or a stat threshold
+// written as `Thresholds["points"]`. Values, when set, replaces the range.
+type SweepParam struct {
+	Field  string
+	Start  float64
+	End    float64
+	Step   float64
+	Values []float64
+}
+
+type SweepConfig struct {
+	Base   analysis.PropSelector
+	Params []SweepParam
+	// MinBets drops combinations that placed fewer bets from the ranking
+	MinBets int
+}
+
+type SweepResult struct {
+	Params   map[string]float64
+	Selector analysis.PropSelector
+	Bets     int
+	Wins     int
+	Losses   int
+	Staked   float32
+	Profit   float32
+	ROI      float32
+	WinRate  float32
+}
+
+// values expands the param into every value it should take
+func (p SweepParam) values() ([]float64, error) {
+	if len(p.Values) > 0 {
+		return p.Values, nil
+	}
+	if p.Step <= 0 {
+		return nil, fmt.Errorf("sweep param %s needs a positive step", p.Field)
+	}
+	if p.End < p.Start {
+		return nil, fmt.Errorf("sweep param %s ends before it starts", p.Field)
+	}
+
+	var values []float64
+	for i := 0; ; i++ {
+		// Round away float drift so .1 steps land on the values they read as
+		value := math.Round((p.Start+float64(i)*p.Step)*1e6) / 1e6
+		if value > p.End+1e-9 {
+			break
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// thresholdStat returns the stat named by a `Thresholds["stat"]` or
+// `Thresholds.stat` field
+func thresholdStat(field string) (string, bool) {
+	if rest, ok := strings.CutPrefix(field, "Thresholds."); ok && rest != "" {
+		return rest, true
+	}
+	if rest, ok := strings.CutPrefix(field, "Thresholds["); ok && strings.HasSuffix(rest, "]") {
+		stat := strings.Trim(strings.TrimSuffix(rest, "]"), `"'`)
+		return stat, stat != ""
+	}
+	return "", false
+}
+
+// setSelectorField sets a numeric selector field or stat threshold by name
+func setSelectorField(selector *analysis.PropSelector, field string, value float64) error {
+	if stat, ok := thresholdStat(field); ok {
+		thresholds := make(map[string]float32, len(selector.Thresholds)+1)
+		for k, v := range selector.Thresholds {
+			thresholds[k] = v
+		}
+		thresholds[stat] = float32(value)
+		selector.Thresholds = thresholds
+		return nil
+	}
+
+	f := reflect.ValueOf(selector).Elem().FieldByName(field)
+	if !f.IsValid() {
+		return fmt.Errorf("unknown selector field %s", field)
+	}
+	switch f.Kind() {
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		f.SetInt(int64(math.Round(value)))
+	case reflect.Float32, reflect.Float64:
+		f.SetFloat(value)
+	default:
+		return fmt.Errorf("selector field %s is not numeric", field)
+	}
+
+	return nil
+}
+
+// combinations builds the cartesian product of every param's values
+func (c SweepConfig) combinations() ([]map[string]float64, error) {
+	combos := []map[string]float64{{}}
+	for _, param := range c.Params {
+		values, err := param.values()
+		if err != nil {
+			return nil, err
+		}
+		probe := c.Base
+		if err := setSelectorField(&probe, param.Field, 0); err != nil {
+			return nil, err
+		}
+
+		var next []map[string]float64
+		for _, combo := range combos {
+			for _, value := range values {
+				extended := make(map[string]float64, len(combo)+1)
+				for k, v := range combo {
+					extended[k] = v
+				}
+				extended[param.Field] = value
+				next = append(next, extended)
+			}
+		}
+		combos = next
+	}
+
+	return combos, nil
+}
+
+// loadAllSnapshots loads the backtest range for tuning, where a skipped date
+// would quietly skew the results, so any date error fails the load
+func (b Backtester) loadAllSnapshots(ctx context.Context) ([]*dateSnapshot, error) {
+	snapshots, dateErrs, err := b.loadSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(dateErrs) > 0 {
+		errs := make([]error, len(dateErrs))
+		for i, dateErr := range dateErrs {
+			errs[i] = dateErr
+		}
+		return nil, fmt.Errorf("error loading %d dates: %w", len(dateErrs), errors.Join(errs...))
+	}
+
+	return snapshots, nil
+}
+
+// evaluateSelector picks and settles bets over preloaded dates of the sport
+// without logging each bet
+func evaluateSelector(sport sports.Sport, selector analysis.PropSelector, snapshots []*dateSnapshot) *BacktestResult {
+	if selector.Sport == "" {
+		selector.Sport = sport
+	}
+	result := &BacktestResult{}
+	for _, snapshot := range snapshots {
+		picks, _ := snapshot.pick(selector)
+		for _, pick := range picks {
+			if stats := snapshot.stats[pick.Analysis.PlayerIndex]; stats != nil {
+				result.settle(pick, stats)
+			}
+		}
+	}
+
+	return result
+}
+
+func newSweepResult(params map[string]float64, selector analysis.PropSelector, result *BacktestResult) SweepResult {
+	sweep := SweepResult{
+		Params:   params,
+		Selector: selector,
+		Bets:     len(result.Bets),
+		Wins:     result.Wins,
+		Losses:   result.Losses,
+		Profit:   result.Profit,
+	}
+	for _, bet := range result.Bets {
+		sweep.Staked += bet.BetSize
+	}
+	if sweep.Staked > 0 {
+		sweep.ROI = sweep.Profit / sweep.Staked
+	}
+	if sweep.Wins+sweep.Losses > 0 {
+		sweep.WinRate = float32(sweep.Wins) / float32(sweep.Wins+sweep.Losses)
+	}
+
+	return sweep
+}
+
+// sweepSnapshots evaluates every combination over the snapshots and ranks the
+// ones with enough bets by ROI, then by bet count
+func sweepSnapshots(sport sports.Sport, config SweepConfig, snapshots []*dateSnapshot) ([]SweepResult, error) {
+	combos, err := config.combinations()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SweepResult
+	for _, combo := range combos {
+		selector := config.Base
+		for _, param := range config.Params {
+			if err := setSelectorField(&selector, param.Field, combo[param.Field]); err != nil {
+				return nil, err
+			}
+		}
+		result := newSweepResult(combo, selector, evaluateSelector(sport, selector, snapshots))
+		if result.Bets < config.MinBets {
+			continue
+		}
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].ROI != results[j].ROI {
+			return results[i].ROI > results[j].ROI
+		}
+		return results[i].Bets > results[j].Bets
+	})
+
+	return results, nil
+}
+
+// RunSweep runs analyses once per date in the backtest range, then evaluates
+// every combination of the sweep params against them. Strategies on the
+// backtester are ignored.
+func (b Backtester) RunSweep(ctx context.Context, config SweepConfig) ([]SweepResult, error) {
+	if _, err := config.combinations(); err != nil {
+		return nil, err
+	}
+	snapshots, err := b.loadAllSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return sweepSnapshots(b.sport(), config, snapshots)
+}
+
+// PrintSweepResults logs the top n ranked results, or all of them when n is 0
+func PrintSweepResults(results []SweepResult, n int) {
+	if n == 0 || n > len(results) {
+		n = len(results)
+	}
+	log.Println("------------------------------------------")
+	for i, result := range results[:n] {
+		var keys []string
+		for key := range result.Params {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var params []string
+		for _, key := range keys {
+			params = append(params, fmt.Sprintf("%s=%v", key, result.Params[key]))
+		}
+		log.Printf("%d. %s: %v Bets with %.2f%% winrate. ROI: %.2f%% Profits: $%.2f", i+1, strings.Join(params, " "), result.Bets, result.WinRate*100, result.ROI*100, result.Profit)
+	}
+	log.Println("------------------------------------------")
+}
@@ -0,0 +1,165 @@
+package backtesting
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mgordon34/kornet-kover/api/odds"
+	"github.com/mgordon34/kornet-kover/api/players"
+	"github.com/mgordon34/kornet-kover/internal/analysis"
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
+
+func TestSweepParamValues(t *testing.T) {
+	values, err := SweepParam{Field: "MinDiff", Start: -.3, End: 1, Step: .1}.values()
+	if err != nil {
+		t.Fatalf("values() error = %v", err)
+	}
+	if len(values) != 14 || values[0] != -.3 || values[3] != 0 || values[13] != 1 {
+		t.Fatalf("unexpected values %v", values)
+	}
+
+	values, _ = SweepParam{Field: "MinOdds", Start: 1, End: 2, Step: 5, Values: []float64{7, 9}}.values()
+	if len(values) != 2 || values[1] != 9 {
+		t.Fatalf("explicit values not used: %v", values)
+	}
+
+	if _, err := (SweepParam{Field: "MinOdds", Start: 1, End: 2}).values(); err == nil {
+		t.Fatalf("expected zero step error")
+	}
+	if _, err := (SweepParam{Field: "MinOdds", Start: 2, End: 1, Step: 1}).values(); err == nil {
+		t.Fatalf("expected reversed range error")
+	}
+}
+
+func TestSetSelectorField(t *testing.T) {
+	base := analysis.PropSelector{Thresholds: map[string]float32{"points": 1}}
+	selector := base
+	for field, value := range map[string]float64{
+		"MinOdds":               149.6,
+		"MinDiff":               .5,
+		`Thresholds["points"]`:  .3,
+		"Thresholds.rebounds":   .2,
+		`Thresholds['assists']`: .1,
+	} {
+		if err := setSelectorField(&selector, field, value); err != nil {
+			t.Fatalf("setSelectorField(%s) error = %v", field, err)
+		}
+	}
+	if selector.MinOdds != 150 || selector.MinDiff != .5 || selector.Thresholds["points"] != .3 || selector.Thresholds["rebounds"] != .2 || selector.Thresholds["assists"] != .1 {
+		t.Fatalf("unexpected selector %+v", selector)
+	}
+	if base.Thresholds["points"] != 1 || len(base.Thresholds) != 1 {
+		t.Fatalf("base thresholds were mutated: %v", base.Thresholds)
+	}
+
+	for _, field := range []string{"Nope", "StratName", "Thresholds[]"} {
+		if err := setSelectorField(&selector, field, 1); err == nil {
+			t.Fatalf("expected error for field %s", field)
+		}
+	}
+}
+
+func TestRunSweepRanksCombinations(t *testing.T) {
+	start := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+	data := singlePickDataSource()
+	var analysisCalls atomic.Int32
+	runAnalysis := data.runAnalysisOnGameFn
+	data.runAnalysisOnGameFn = func(sport sports.Sport, roster, opponents []players.PlayerRoster, endDate time.Time, forceUpdate, storePIP bool) []analysis.Analysis {
+		analysisCalls.Add(1)
+		return runAnalysis(sport, roster, opponents, endDate, forceUpdate, storePIP)
+	}
+	var progress []int
+	b := NewBacktester(start, end, nil, BacktesterDeps{DataSource: data})
+	b.OnProgress = func(completed int, total int) { progress = append(progress, completed) }
+
+	base := analysis.PropSelector{StratName: "Sweep", Thresholds: map[string]float32{"points": 0.1}, TresholdType: analysis.Percent, MaxOdds: 500, MaxOver: 1, BetSize: 100, MinGames: 1, MinMinutes: 1}
+	results, err := b.RunSweep(context.Background(), SweepConfig{
+		Base: base,
+		Params: []SweepParam{
+			{Field: "MinOdds", Start: 100, End: 300, Step: 100},
+			{Field: `Thresholds["points"]`, Values: []float64{0.1, 0.9}},
+		},
+		MinBets: 2,
+	})
+	if err != nil {
+		t.Fatalf("RunSweep() error = %v", err)
+	}
+	// two dates, each analyzed once for both teams regardless of how many combinations run
+	if analysisCalls.Load() != 4 || len(progress) != 2 || progress[1] != 2 {
+		t.Fatalf("analyses ran %d times with progress %v", analysisCalls.Load(), progress)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 combinations with enough bets, got %+v", results)
+	}
+	for _, result := range results {
+		if result.Bets != 2 || result.Wins != 2 || result.Staked != 200 || result.Profit != 400 || result.ROI != 2 || result.WinRate != 1 {
+			t.Fatalf("unexpected result %+v", result)
+		}
+		if result.Params[`Thresholds["points"]`] != 0.1 || result.Params["MinOdds"] > 200 {
+			t.Fatalf("unexpected params %v", result.Params)
+		}
+		if result.Selector.MinOdds != int(result.Params["MinOdds"]) {
+			t.Fatalf("selector does not match params: %+v", result.Selector)
+		}
+	}
+	if base.Thresholds["points"] != 0.1 {
+		t.Fatalf("base selector was mutated")
+	}
+
+	if _, err := b.RunSweep(context.Background(), SweepConfig{Base: base, Params: []SweepParam{{Field: "Bogus", Values: []float64{1}}}}); err == nil {
+		t.Fatalf("expected unknown field error")
+	}
+}
+
+func TestSweepSnapshotsOrdering(t *testing.T) {
+	snapshot := &dateSnapshot{date: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)}
+	data := singlePickDataSource()
+	snapshot.odds, _ = data.GetAlternatePlayerOddsForDate(context.Background(), "", snapshot.date, nil)
+	snapshot.stats, _ = data.GetPlayerStatsForGames(context.Background(), sports.NBA, nil)
+	snapshot.analyses, _ = data.RunAnalysisOnGame(context.Background(), sports.NBA, nil, nil, snapshot.date, false, false)
+
+	base := analysis.PropSelector{Thresholds: map[string]float32{"points": 0.1}, TresholdType: analysis.Percent, MaxOver: 1, MinGames: 1, MinMinutes: 1}
+	results, err := sweepSnapshots(sports.NBA, SweepConfig{
+		Base:   base,
+		Params: []SweepParam{{Field: "BetSize", Values: []float64{50, 100}}, {Field: "MaxOdds", Values: []float64{100, 500}}},
+	}, []*dateSnapshot{snapshot})
+	if err != nil {
+		t.Fatalf("sweepSnapshots() error = %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected every combination without a min bet guard, got %d", len(results))
+	}
+	// combinations that bet rank ahead of ones that priced the line out
+	if results[0].ROI != 2 || results[1].ROI != 2 || results[2].Bets != 0 || results[3].Bets != 0 {
+		t.Fatalf("unexpected ordering %+v", results)
+	}
+
+	PrintSweepResults(results, 0)
+	PrintSweepResults(results, 1)
+}
+
+func TestEvaluateSelectorRanksBySport(t *testing.T) {
+	date := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := &dateSnapshot{
+		date: date,
+		odds: map[string]map[string][]odds.PlayerLine{"g1": {
+			"shots":  {{Id: 1, PlayerIndex: "g1", Stat: "shots", Side: "Over", Type: "alternate", Line: 3.5, Odds: 100}},
+			"points": {{Id: 2, PlayerIndex: "g1", Stat: "points", Side: "Over", Type: "alternate", Line: 0.5, Odds: 100}},
+		}},
+		analyses: []analysis.Analysis{{PlayerIndex: "g1", Prediction: players.NHLAvg{NumGames: 2, Shots: 5, Points: 2}}},
+		stats:    map[string]players.PlayerAvg{"g1": players.NHLAvg{NumGames: 1, Shots: 5, Points: 1}},
+	}
+
+	selector := analysis.PropSelector{Thresholds: map[string]float32{"shots": 0.1, "points": 0.1}, TresholdType: analysis.Percent, SortType: analysis.StatRankSort, MaxOver: 2, TotalMax: 1, BetSize: 100}
+	if result := evaluateSelector(sports.NHL, selector, []*dateSnapshot{snapshot}); len(result.Bets) != 1 || result.Bets[0].Stat != "shots" {
+		t.Fatalf("expected the NHL weights to rank shots first, got %+v", result.Bets)
+	}
+	selector.Sport = sports.NBA
+	if result := evaluateSelector(sports.NHL, selector, []*dateSnapshot{snapshot}); len(result.Bets) != 1 || result.Bets[0].Stat != "points" {
+		t.Fatalf("expected the selector's own sport to be kept, got %+v", result.Bets)
+	}
+}
@@ -0,0 +1,138 @@
+package backtesting
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// WalkForwardConfig splits the backtest range into rolling folds. Each fold
+// tunes the sweep on TrainDays of dates and is scored on the TestDays that
+// follow. Folds advance by StepDays, which defaults to TestDays so test
+// windows never overlap.
+type WalkForwardConfig struct {
+	Sweep     SweepConfig
+	TrainDays int
+	TestDays  int
+	StepDays  int
+}
+
+type WalkForwardFold struct {
+	TrainStart time.Time
+	TrainEnd   time.Time
+	TestStart  time.Time
+	TestEnd    time.Time
+	// Selected is nil when no combination met the min bet guard on the train fold
+	Selected *SweepResult
+	Test     SweepResult
+}
+
+// WalkForwardReport only aggregates out-of-sample performance. Train results
+// are kept on each fold for reference.
+type WalkForwardReport struct {
+	Folds []WalkForwardFold
+	Test  SweepResult
+}
+
+func (c WalkForwardConfig) validate() error {
+	if c.TrainDays <= 0 || c.TestDays <= 0 {
+		return fmt.Errorf("walk forward needs positive train and test days")
+	}
+	if c.StepDays < 0 {
+		return fmt.Errorf("walk forward step days must not be negative")
+	}
+	if _, err := c.Sweep.combinations(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// folds returns the train/test windows that fit in the backtest range. End
+// dates are exclusive.
+func (c WalkForwardConfig) folds(startDate time.Time, endDate time.Time) []WalkForwardFold {
+	step := c.StepDays
+	if step == 0 {
+		step = c.TestDays
+	}
+
+	var folds []WalkForwardFold
+	last := endDate.AddDate(0, 0, 1)
+	for trainStart := startDate; ; trainStart = trainStart.AddDate(0, 0, step) {
+		trainEnd := trainStart.AddDate(0, 0, c.TrainDays)
+		testEnd := trainEnd.AddDate(0, 0, c.TestDays)
+		if testEnd.After(last) {
+			break
+		}
+		folds = append(folds, WalkForwardFold{TrainStart: trainStart, TrainEnd: trainEnd, TestStart: trainEnd, TestEnd: testEnd})
+	}
+
+	return folds
+}
+
+func snapshotsBetween(snapshots []*dateSnapshot, start time.Time, end time.Time) []*dateSnapshot {
+	var window []*dateSnapshot
+	for _, snapshot := range snapshots {
+		if !snapshot.date.Before(start) && snapshot.date.Before(end) {
+			window = append(window, snapshot)
+		}
+	}
+	return window
+}
+
+// RunWalkForward picks the best sweep combination on each train fold and
+// reports how it performed on the following test fold. Analyses are run once
+// per date across the whole range.
+func (b Backtester) RunWalkForward(ctx context.Context, config WalkForwardConfig) (WalkForwardReport, error) {
+	if err := config.validate(); err != nil {
+		return WalkForwardReport{}, err
+	}
+	folds := config.folds(b.StartDate, b.EndDate)
+	if len(folds) == 0 {
+		return WalkForwardReport{}, fmt.Errorf("backtest range of %d days is too short for a %d day train and %d day test fold", b.TotalDays(), config.TrainDays, config.TestDays)
+	}
+
+	snapshots, err := b.loadAllSnapshots(ctx)
+	if err != nil {
+		return WalkForwardReport{}, err
+	}
+
+	report := WalkForwardReport{}
+	combined := &BacktestResult{}
+	for _, fold := range folds {
+		ranked, err := sweepSnapshots(b.sport(), config.Sweep, snapshotsBetween(snapshots, fold.TrainStart, fold.TrainEnd))
+		if err != nil {
+			return report, err
+		}
+		if len(ranked) > 0 {
+			best := ranked[0]
+			fold.Selected = &best
+			test := evaluateSelector(b.sport(), best.Selector, snapshotsBetween(snapshots, fold.TestStart, fold.TestEnd))
+			fold.Test = newSweepResult(best.Params, best.Selector, test)
+			combined.Bets = append(combined.Bets, test.Bets...)
+			combined.Wins += test.Wins
+			combined.Losses += test.Losses
+			combined.Profit += test.Profit
+		}
+		report.Folds = append(report.Folds, fold)
+	}
+	report.Test = newSweepResult(nil, config.Sweep.Base, combined)
+
+	return report, nil
+}
+
+func PrintWalkForwardReport(report WalkForwardReport) {
+	log.Println("------------------------------------------")
+	for i, fold := range report.Folds {
+		log.Printf("Fold %d: train %s..%s test %s..%s", i+1, fold.TrainStart.Format("2006-01-02"), fold.TrainEnd.AddDate(0, 0, -1).Format("2006-01-02"),
+			fold.TestStart.Format("2006-01-02"), fold.TestEnd.AddDate(0, 0, -1).Format("2006-01-02"))
+		if fold.Selected == nil {
+			log.Println("  No combination met the minimum bets on the train fold")
+			continue
+		}
+		log.Printf("  Selected %v (train ROI: %.2f%% over %v bets)", fold.Selected.Params, fold.Selected.ROI*100, fold.Selected.Bets)
+		log.Printf("  Test: %v Bets with %.2f%% winrate. ROI: %.2f%% Profits: $%.2f", fold.Test.Bets, fold.Test.WinRate*100, fold.Test.ROI*100, fold.Test.Profit)
+	}
+	log.Printf("Out of sample: %v Bets with %.2f%% winrate. ROI: %.2f%% Profits: $%.2f", report.Test.Bets, report.Test.WinRate*100, report.Test.ROI*100, report.Test.Profit)
+	log.Println("------------------------------------------")
+}
@@ -0,0 +1,111 @@
+package backtesting
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mgordon34/kornet-kover/api/games"
+	"github.com/mgordon34/kornet-kover/api/players"
+	"github.com/mgordon34/kornet-kover/internal/analysis"
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
+
+func TestWalkForwardFolds(t *testing.T) {
+	start := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 9)
+
+	folds := WalkForwardConfig{TrainDays: 4, TestDays: 2}.folds(start, end)
+	if len(folds) != 3 {
+		t.Fatalf("expected 3 folds, got %+v", folds)
+	}
+	if !folds[0].TrainEnd.Equal(start.AddDate(0, 0, 4)) || !folds[0].TestStart.Equal(folds[0].TrainEnd) || !folds[2].TestEnd.Equal(end.AddDate(0, 0, 1)) {
+		t.Fatalf("unexpected fold windows %+v", folds)
+	}
+
+	folds = WalkForwardConfig{TrainDays: 4, TestDays: 2, StepDays: 1}.folds(start, end)
+	if len(folds) != 5 || !folds[1].TrainStart.Equal(start.AddDate(0, 0, 1)) {
+		t.Fatalf("unexpected stepped folds %+v", folds)
+	}
+
+	if folds := (WalkForwardConfig{TrainDays: 8, TestDays: 3}).folds(start, end); len(folds) != 0 {
+		t.Fatalf("expected no folds to fit, got %+v", folds)
+	}
+}
+
+func TestRunWalkForwardReportsTestFolds(t *testing.T) {
+	start := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := singlePickDataSource()
+	// game ids are the day of month, p1 falls short of the line on the 3rd
+	data.getGamesForDateFn = func(sport sports.Sport, date time.Time) ([]games.Game, error) {
+		return []games.Game{{Id: date.Day(), HomeIndex: "H", AwayIndex: "A"}}, nil
+	}
+	data.getPlayerStatsForGamesFn = func(sport sports.Sport, gameIDs []string) (map[string]players.PlayerAvg, error) {
+		if gameIDs[0] == strconv.Itoa(3) {
+			return map[string]players.PlayerAvg{"p1": players.NBAAvg{NumGames: 1, Points: 18}}, nil
+		}
+		return map[string]players.PlayerAvg{"p1": players.NBAAvg{NumGames: 1, Points: 22}}, nil
+	}
+	b := NewBacktester(start, start.AddDate(0, 0, 3), nil, BacktesterDeps{DataSource: data})
+
+	config := WalkForwardConfig{
+		Sweep: SweepConfig{
+			Base:    analysis.PropSelector{Thresholds: map[string]float32{"points": 0.1}, TresholdType: analysis.Percent, MaxOver: 1, BetSize: 100, MinGames: 1, MinMinutes: 1},
+			Params:  []SweepParam{{Field: "MaxOdds", Values: []float64{100, 500}}},
+			MinBets: 1,
+		},
+		TrainDays: 2,
+		TestDays:  1,
+	}
+	report, err := b.RunWalkForward(context.Background(), config)
+	if err != nil {
+		t.Fatalf("RunWalkForward() error = %v", err)
+	}
+	if len(report.Folds) != 2 {
+		t.Fatalf("expected 2 folds, got %+v", report.Folds)
+	}
+
+	first := report.Folds[0]
+	if first.Selected == nil || first.Selected.Params["MaxOdds"] != 500 || first.Selected.ROI != 2 {
+		t.Fatalf("unexpected first fold selection %+v", first.Selected)
+	}
+	if first.Test.Bets != 1 || first.Test.Losses != 1 || first.Test.Profit != -100 {
+		t.Fatalf("unexpected first test fold %+v", first.Test)
+	}
+	second := report.Folds[1]
+	if second.Selected == nil || second.Selected.ROI != 0.5 || second.Test.Wins != 1 || second.Test.Profit != 200 {
+		t.Fatalf("unexpected second fold %+v", second)
+	}
+
+	if report.Test.Bets != 2 || report.Test.Wins != 1 || report.Test.Losses != 1 || report.Test.Profit != 100 || report.Test.ROI != 0.5 || report.Test.WinRate != 0.5 {
+		t.Fatalf("unexpected out of sample summary %+v", report.Test)
+	}
+	PrintWalkForwardReport(report)
+
+	config.Sweep.MinBets = 10
+	report, err = b.RunWalkForward(context.Background(), config)
+	if err != nil {
+		t.Fatalf("RunWalkForward() error = %v", err)
+	}
+	if report.Folds[0].Selected != nil || report.Test.Bets != 0 {
+		t.Fatalf("expected no selection under the min bet guard, got %+v", report)
+	}
+	PrintWalkForwardReport(report)
+}
+
+func TestRunWalkForwardValidation(t *testing.T) {
+	start := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewBacktester(start, start.AddDate(0, 0, 2), nil, BacktesterDeps{DataSource: singlePickDataSource()})
+
+	for _, config := range []WalkForwardConfig{
+		{TrainDays: 0, TestDays: 1},
+		{TrainDays: 1, TestDays: 1, StepDays: -1},
+		{TrainDays: 1, TestDays: 1, Sweep: SweepConfig{Params: []SweepParam{{Field: "Bogus", Values: []float64{1}}}}},
+		{TrainDays: 3, TestDays: 1},
+	} {
+		if _, err := b.RunWalkForward(context.Background(), config); err == nil {
+			t.Fatalf("expected error for %+v", config)
+		}
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"time"
 
 	"github.com/mgordon34/kornet-kover/api/games"
+	"github.com/mgordon34/kornet-kover/api/picks"
 	"github.com/mgordon34/kornet-kover/api/players"
 	"github.com/mgordon34/kornet-kover/api/teams"
+	"github.com/mgordon34/kornet-kover/internal/snapshots"
 	"github.com/mgordon34/kornet-kover/internal/sports"
 )
 
@@ -20,6 +22,8 @@ type ScraperStore interface {
 	GetTeams() ([]teams.Team, error)
 	UpdatePlayerTables(playerIndex string)
 	UpdateRosters(rosterSlots []players.PlayerRoster) error
+	GradePropPicks(sport sports.Sport, before time.Time) (int, error)
+	InvalidateSnapshots(sport sports.Sport, from time.Time) int
 }
 
 type defaultScraperSources struct{}
@@ -53,3 +57,11 @@ func (d defaultScraperStore) UpdatePlayerTables(playerIndex string) {
 func (d defaultScraperStore) UpdateRosters(rosterSlots []players.PlayerRoster) error {
 	return players.UpdateRosters(rosterSlots)
 }
+
+func (d defaultScraperStore) GradePropPicks(sport sports.Sport, before time.Time) (int, error) {
+	return picks.GradePropPicks(sport, before)
+}
+
+func (d defaultScraperStore) InvalidateSnapshots(sport sports.Sport, from time.Time) int {
+	return snapshots.InvalidateFrom(sport, from)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gocolly/colly"
 	"github.com/mgordon34/kornet-kover/api/games"
+	"github.com/mgordon34/kornet-kover/api/picks"
 	"github.com/mgordon34/kornet-kover/api/players"
 	"github.com/mgordon34/kornet-kover/api/teams"
 	"github.com/mgordon34/kornet-kover/internal/sports"
@@ -110,6 +111,62 @@ func ScrapeMLBTeams() {
 	teams.AddTeams(mlbTeams)
 }
 
+// ScrapeNHLTeams stores the teams in the standings of the season the date falls in
+func ScrapeNHLTeams(date time.Time) {
+	c := colly.NewCollector()
+	var nhlTeams []teams.Team
+
+	c.OnHTML("table.standings_table > tbody", func(t *colly.HTMLElement) {
+		t.ForEach("tr", func(i int, tr *colly.HTMLElement) {
+			href := tr.ChildAttr("a", "href")
+			if href == "" {
+				// division header
+				return
+			}
+			index := "NHL_" + strings.Split(href, "/")[2]
+			name := tr.ChildText("a")
+			nhlTeams = append(nhlTeams, teams.Team{Index: index, Name: name})
+		})
+	})
+
+	c.Visit(nhlStandingsURL(date))
+
+	teams.AddTeams(nhlTeams)
+}
+
+// nhlStandingsURL is the standings page for the NHL season the date falls in
+func nhlStandingsURL(date time.Time) string {
+	return fmt.Sprintf("%s/leagues/NHL_%d_standings.html", sports.Configs[sports.NHL].Scraper.Domain, players.SeasonYear(sports.NHL, date))
+}
+
+// ScrapeNFLTeams stores the teams in the standings of the season the date falls in
+func ScrapeNFLTeams(date time.Time) {
+	c := colly.NewCollector()
+	var nflTeams []teams.Team
+
+	c.OnHTML("table#AFC > tbody, table#NFC > tbody", func(t *colly.HTMLElement) {
+		t.ForEach("tr", func(i int, tr *colly.HTMLElement) {
+			href := tr.ChildAttr("a", "href")
+			if href == "" {
+				// division header
+				return
+			}
+			index := "NFL_" + strings.ToUpper(strings.Split(href, "/")[2])
+			name := tr.ChildText("a")
+			nflTeams = append(nflTeams, teams.Team{Index: index, Name: name})
+		})
+	})
+
+	c.Visit(nflStandingsURL(date))
+
+	teams.AddTeams(nflTeams)
+}
+
+// nflStandingsURL is the season page for the NFL season the date falls in
+func nflStandingsURL(date time.Time) string {
+	return fmt.Sprintf("%s/years/%d/", sports.Configs[sports.NFL].Scraper.Domain, players.SeasonYear(sports.NFL, date))
+}
+
 func ScrapeGames(sport sports.Sport, startDate time.Time, endDate time.Time) error {
 	sportConfig, ok := sports.Configs[sport]
 	if !ok {
@@ -158,7 +215,7 @@ func getDate(gameString string, sport sports.Sport) (time.Time, error) {
 
 	var dateStr string
 	switch sport {
-	case sports.NBA:
+	case sports.NBA, sports.NHL, sports.NFL:
 		// Format: /boxscores/202603010CHO.html
 		dateStr = parts[2][:8]
 	case sports.WNBA:
@@ -209,6 +266,10 @@ func scrapeGame(sport sports.Sport, gameString string) {
 					teams[i] = "WNBA_" + strings.Split(team, "/")[3]
 				} else if sport == sports.MLB {
 					teams[i] = "MLB_" + teams[i]
+				} else if sport == sports.NHL {
+					teams[i] = "NHL_" + teams[i]
+				} else if sport == sports.NFL {
+					teams[i] = "NFL_" + strings.ToUpper(teams[i])
 				}
 			}
 		})
@@ -259,23 +320,29 @@ func scrapeGame(sport sports.Sport, gameString string) {
 	case sports.NBA:
 		pSlice, pGames := scrapeNBAPlayerStats(playerTables, gameId)
 		players.AddPlayers(pSlice)
-		players.AddPlayerGames(pGames)
+		players.AddPlayerGames(sports.NBA, pGames)
 	case sports.WNBA:
 		pSlice, pGames := scrapeWNBAPlayerStats(playerTables, gameId)
-		for _, p := range pSlice {
-			log.Println(p)
-		}
-		for _, p := range pGames {
-			log.Println(p)
-		}
-		// players.AddPlayers(pSlice)
-		// players.AddPlayerGames(pGames)
+		players.AddPlayers(pSlice)
+		players.AddPlayerGames(sports.WNBA, pGames)
 	case sports.MLB:
 		pSlice, battingGames, pitchingGames, pbpSlice := scrapeMLBPlayerStats(commentTables, gameId, game)
 		players.AddPlayers(pSlice)
 		players.AddMLBPlayerGamesBatting(battingGames)
 		players.AddMLBPlayerGamesPitching(pitchingGames)
 		players.AddMLBPlayByPlays(pbpSlice)
+	case sports.NHL:
+		pSlice, pGames := scrapeNHLPlayerStats(playerTables, gameId, config.BoxScoreTables)
+		players.AddPlayers(pSlice)
+		if err := players.AddNHLPlayerGames(pGames); err != nil {
+			log.Printf("Error adding NHL player games: %v", err)
+		}
+	case sports.NFL:
+		pSlice, pGames := scrapeNFLPlayerStats(playerTables, commentTables, gameId)
+		players.AddPlayers(pSlice)
+		if err := players.AddNFLPlayerGames(pGames); err != nil {
+			log.Printf("Error adding NFL player games: %v", err)
+		}
 	}
 
 	// players.AddPlayers(pSlice)
@@ -506,6 +573,182 @@ func scrapeMLBPlayerStats(commentTables []*goquery.Document, gameId int, game ga
 	return pSlice, battingGames, pitchingGames, pbpSlice
 }
 
+// scrapeNHLPlayerStats reads the skater and goalie tables, ids like
+// FLA_skaters, into one line per player
+func scrapeNHLPlayerStats(playerTables []*colly.HTMLElement, gameId int, boxScoreTables []string) ([]players.Player, []players.NHLPlayerGame) {
+	var pSlice []players.Player
+	playerGames := make(map[string]players.NHLPlayerGame)
+
+	for _, t := range playerTables {
+		id := t.Attr("id")
+		split := strings.LastIndex(id, "_")
+		if split == -1 || !slices.Contains(boxScoreTables, id[split+1:]) {
+			continue
+		}
+		teamIndex := "NHL_" + id[:split]
+
+		t.ForEach("tbody > tr", func(i int, tr *colly.HTMLElement) {
+			href := tr.ChildAttr("td[data-stat='player'] a", "href")
+			if href == "" {
+				return
+			}
+			index := strings.TrimSuffix(strings.Split(href, "/")[3], ".html")
+
+			pGame, exists := playerGames[index]
+			if !exists {
+				pSlice = append(pSlice, players.Player{Index: index, Sport: "nhl", Name: tr.ChildText("td[data-stat='player'] a")})
+				pGame = players.NHLPlayerGame{PlayerIndex: index, Game: gameId, TeamIndex: teamIndex}
+			}
+			tr.ForEach("td", func(i int, td *colly.HTMLElement) {
+				pGame = addNHLPlayerStat(td.Attr("data-stat"), strings.TrimSpace(td.Text), pGame)
+			})
+			playerGames[index] = pGame
+		})
+	}
+
+	var pGames []players.NHLPlayerGame
+	for _, pGame := range playerGames {
+		if pGame.TimeOnIce == 0 {
+			continue
+		}
+		pGames = append(pGames, pGame)
+	}
+
+	return pSlice, pGames
+}
+
+func addNHLPlayerStat(stat string, value string, pGame players.NHLPlayerGame) players.NHLPlayerGame {
+	switch stat {
+	case "time_on_ice":
+		s := strings.Split(value, ":")
+		if len(s) != 2 {
+			return pGame
+		}
+		minutes, _ := strconv.Atoi(s[0])
+		seconds, _ := strconv.Atoi(s[1])
+		pGame.TimeOnIce = float32(minutes) + float32(seconds)/60
+	case "goals":
+		pGame.Goals, _ = strconv.Atoi(value)
+	case "assists":
+		pGame.Assists, _ = strconv.Atoi(value)
+	case "points":
+		pGame.Points, _ = strconv.Atoi(value)
+	case "shots":
+		pGame.Shots, _ = strconv.Atoi(value)
+	case "plus_minus":
+		pGame.PlusMinus, _ = strconv.Atoi(value)
+	case "pen_min":
+		pGame.PenaltyMins, _ = strconv.Atoi(value)
+	case "shots_against":
+		pGame.ShotsAgainst, _ = strconv.Atoi(value)
+	case "saves":
+		pGame.Saves, _ = strconv.Atoi(value)
+	case "goals_against":
+		pGame.GoalsAgainst, _ = strconv.Atoi(value)
+	}
+	return pGame
+}
+
+// scrapeNFLPlayerStats reads the offense table for each player's line and the
+// snap count tables for their offensive snaps. Pro-football-reference ships
+// some tables commented out, so both the page's and the comments' tables are
+// read.
+func scrapeNFLPlayerStats(playerTables []*colly.HTMLElement, commentTables []*goquery.Document, gameId int) ([]players.Player, []players.NFLPlayerGame) {
+	var tables []*goquery.Selection
+	for _, t := range playerTables {
+		tables = append(tables, t.DOM)
+	}
+	for _, doc := range commentTables {
+		doc.Find("table").Each(func(i int, t *goquery.Selection) {
+			tables = append(tables, t)
+		})
+	}
+
+	var pSlice []players.Player
+	playerGames := make(map[string]players.NFLPlayerGame)
+	snaps := make(map[string]int)
+	for _, t := range tables {
+		id := t.AttrOr("id", "")
+		if id != "player_offense" && !strings.HasSuffix(id, "snap_counts") {
+			continue
+		}
+
+		t.Find("tbody > tr").Each(func(i int, tr *goquery.Selection) {
+			link := tr.Find("[data-stat='player'] a")
+			href := link.AttrOr("href", "")
+			if href == "" {
+				return
+			}
+			split := strings.Split(href, "/")
+			if len(split) < 4 {
+				return
+			}
+			index := strings.TrimSuffix(split[3], ".htm")
+
+			if id != "player_offense" {
+				snaps[index], _ = strconv.Atoi(strings.TrimSpace(tr.Find("td[data-stat='offense']").Text()))
+				return
+			}
+			pGame, exists := playerGames[index]
+			if !exists {
+				pSlice = append(pSlice, players.Player{Index: index, Sport: "nfl", Name: link.Text()})
+				pGame = players.NFLPlayerGame{PlayerIndex: index, Game: gameId}
+			}
+			tr.Find("td").Each(func(i int, td *goquery.Selection) {
+				pGame = addNFLPlayerStat(td.AttrOr("data-stat", ""), strings.TrimSpace(td.Text()), pGame)
+			})
+			playerGames[index] = pGame
+		})
+	}
+	if len(snaps) == 0 {
+		log.Printf("No snap counts for game %v", gameId)
+	}
+
+	var pGames []players.NFLPlayerGame
+	for index, pGame := range playerGames {
+		// Lines are normalized per snap, so they're no use without one
+		pGame.Snaps = snaps[index]
+		if pGame.Snaps == 0 {
+			continue
+		}
+		pGames = append(pGames, pGame)
+	}
+
+	return pSlice, pGames
+}
+
+func addNFLPlayerStat(stat string, value string, pGame players.NFLPlayerGame) players.NFLPlayerGame {
+	switch stat {
+	case "team":
+		pGame.TeamIndex = "NFL_" + strings.ToUpper(value)
+	case "pass_cmp":
+		pGame.PassCompletions, _ = strconv.Atoi(value)
+	case "pass_att":
+		pGame.PassAttempts, _ = strconv.Atoi(value)
+	case "pass_yds":
+		pGame.PassYards, _ = strconv.Atoi(value)
+	case "pass_td":
+		pGame.PassTDs, _ = strconv.Atoi(value)
+	case "pass_int":
+		pGame.Interceptions, _ = strconv.Atoi(value)
+	case "rush_att":
+		pGame.RushAttempts, _ = strconv.Atoi(value)
+	case "rush_yds":
+		pGame.RushYards, _ = strconv.Atoi(value)
+	case "rush_td":
+		pGame.RushTDs, _ = strconv.Atoi(value)
+	case "targets":
+		pGame.Targets, _ = strconv.Atoi(value)
+	case "rec":
+		pGame.Receptions, _ = strconv.Atoi(value)
+	case "rec_yds":
+		pGame.RecYards, _ = strconv.Atoi(value)
+	case "rec_td":
+		pGame.RecTDs, _ = strconv.Atoi(value)
+	}
+	return pGame
+}
+
 func parseMLBPPlayByPlay(pbp players.MLBPlayByPlay, row *goquery.Selection) players.MLBPlayByPlay {
 	row.Find("td").Each(func(i int, td *goquery.Selection) {
 		dataStat := td.AttrOr("data-stat", "")
@@ -819,6 +1062,9 @@ func UpdateActiveRostersHandler(service *ScraperService) gin.HandlerFunc {
 // UpdateGames will add any new game and corresponding stats to the database
 // This is done by utilizing GetLastGame to determine the date window to perform game scraping
 // Returns the number of new games added or error
+// Once the new box scores are stored, cached analyses from the first scraped
+// date on are dropped and any outstanding prop picks before today are graded,
+// for sports whose picks can be graded
 // TODO: Optimizations for offseason could be made here
 func (s *ScraperService) UpdateGames(sport sports.Sport) error {
 	lastGame, err := s.deps.Store.GetLastGame()
@@ -829,7 +1075,21 @@ func (s *ScraperService) UpdateGames(sport sports.Sport) error {
 	lastGameDate := lastGame.Date
 	startDate := lastGameDate.AddDate(0, 0, 1)
 	endDate := s.deps.Now()
-	return s.deps.Sources.ScrapeGames(sport, startDate, endDate)
+	if err := s.deps.Sources.ScrapeGames(sport, startDate, endDate); err != nil {
+		return err
+	}
+	if dropped := s.deps.Store.InvalidateSnapshots(sport, startDate); dropped > 0 {
+		log.Printf("Dropped %d cached %s analyses from %v on", dropped, sport, startDate)
+	}
+
+	if !picks.CanGradeSport(sport) {
+		return nil
+	}
+	today := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, endDate.Location())
+	if _, err := s.deps.Store.GradePropPicks(sport, today); err != nil {
+		return err
+	}
+	return nil
 }
 
 func (s *ScraperService) UpdateActiveRosters() error {
@@ -1001,7 +1261,12 @@ func ScrapeTodaysRosters() [][]players.Roster {
 	return games
 }
 
-func ScrapeTodaysGames() [][]string {
+// ScrapeTodaysGames returns the home and visiting team of each of the sport's
+// games today. Only the NBA schedule is scraped so far.
+func ScrapeTodaysGames(sport sports.Sport) ([][]string, error) {
+	if sport != sports.NBA {
+		return nil, fmt.Errorf("%w: no schedule scraper for %s", sports.ErrUnsupportedSport, sport)
+	}
 	baseUrl := "%s/leagues/NBA_2026_games-%v.html"
 	c := colly.NewCollector()
 	var games [][]string
@@ -1031,7 +1296,7 @@ func ScrapeTodaysGames() [][]string {
 	str := fmt.Sprintf(baseUrl, sports.Configs[sports.NBA].Scraper.Domain, month)
 	c.Visit(str)
 
-	return games
+	return games, nil
 }
 
 func getRosterForTeam(teamIndex string, missingPlayers map[string]string) players.Roster {
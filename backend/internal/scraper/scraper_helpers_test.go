@@ -49,6 +49,8 @@ type fakeScraperStore struct {
 	getTeamsFn           func() ([]teams.Team, error)
 	updatePlayerTablesFn func(playerIndex string)
 	updateRostersFn      func(rosterSlots []players.PlayerRoster) error
+	gradePropPicksFn     func(sport sports.Sport, before time.Time) (int, error)
+	invalidateFn         func(sport sports.Sport, from time.Time) int
 }
 
 func (f fakeScraperStore) GetLastGame() (games.Game, error) {
@@ -78,13 +80,33 @@ func (f fakeScraperStore) UpdateRosters(rosterSlots []players.PlayerRoster) erro
 	return f.updateRostersFn(rosterSlots)
 }
 
+func (f fakeScraperStore) GradePropPicks(sport sports.Sport, before time.Time) (int, error) {
+	if f.gradePropPicksFn == nil {
+		return 0, nil
+	}
+	return f.gradePropPicksFn(sport, before)
+}
+
+func (f fakeScraperStore) InvalidateSnapshots(sport sports.Sport, from time.Time) int {
+	if f.invalidateFn == nil {
+		return 0
+	}
+	return f.invalidateFn(sport, from)
+}
+
 func TestScrapeGames_UnsupportedSport(t *testing.T) {
-	err := ScrapeGames(sports.NHL, time.Now(), time.Now())
+	err := ScrapeGames(sports.Sport("cricket"), time.Now(), time.Now())
 	if !errors.Is(err, sports.ErrUnsupportedSport) {
 		t.Fatalf("ScrapeGames() err = %v, want ErrUnsupportedSport", err)
 	}
 }
 
+func TestScrapeTodaysGames_UnsupportedSport(t *testing.T) {
+	if _, err := ScrapeTodaysGames(sports.MLB); !errors.Is(err, sports.ErrUnsupportedSport) {
+		t.Fatalf("ScrapeTodaysGames() err = %v, want ErrUnsupportedSport", err)
+	}
+}
+
 func TestGetDateBySport(t *testing.T) {
 	nbaDate, err := getDate("/boxscores/202603010CHO.html", sports.NBA)
 	if err != nil || nbaDate.Format("2006-01-02") != "2026-03-01" {
@@ -101,11 +123,36 @@ func TestGetDateBySport(t *testing.T) {
 		t.Fatalf("MLB getDate failed: %v %v", mlbDate, err)
 	}
 
+	nhlDate, err := getDate("/boxscores/202410080FLA.html", sports.NHL)
+	if err != nil || nhlDate.Format("2006-01-02") != "2024-10-08" {
+		t.Fatalf("NHL getDate failed: %v %v", nhlDate, err)
+	}
+
+	nflDate, err := getDate("/boxscores/202409050kan.htm", sports.NFL)
+	if err != nil || nflDate.Format("2006-01-02") != "2024-09-05" {
+		t.Fatalf("NFL getDate failed: %v %v", nflDate, err)
+	}
+
 	if _, err := getDate("bad", sports.NBA); err == nil {
 		t.Fatalf("expected error for invalid game string")
 	}
 }
 
+func TestTeamStandingsURLs(t *testing.T) {
+	if got := nhlStandingsURL(time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)); got != "https://www.hockey-reference.com/leagues/NHL_2026_standings.html" {
+		t.Fatalf("nhlStandingsURL() in the fall = %s", got)
+	}
+	if got := nhlStandingsURL(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)); got != "https://www.hockey-reference.com/leagues/NHL_2026_standings.html" {
+		t.Fatalf("nhlStandingsURL() in the spring = %s", got)
+	}
+	if got := nflStandingsURL(time.Date(2025, 9, 7, 0, 0, 0, 0, time.UTC)); got != "https://www.pro-football-reference.com/years/2025/" {
+		t.Fatalf("nflStandingsURL() in the fall = %s", got)
+	}
+	if got := nflStandingsURL(time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)); got != "https://www.pro-football-reference.com/years/2025/" {
+		t.Fatalf("nflStandingsURL() in the playoffs = %s", got)
+	}
+}
+
 func TestParseHomeRuns(t *testing.T) {
 	if got := parseHomeRuns(""); got != 0 {
 		t.Fatalf("empty details got %d, want 0", got)
@@ -316,6 +363,142 @@ func TestCollyTableHelpers(t *testing.T) {
 	_ = sports.NBA
 }
 
+func TestScrapeNHLPlayerStats(t *testing.T) {
+	html := `<html><body>
+	<table class="sortable stats_table" id="FLA_skaters">
+	<thead><tr><th data-stat="ranker">Rk</th><th data-stat="player">Player</th></tr></thead>
+	<tbody>
+	<tr><th data-stat="ranker">1</th><td data-stat="player"><a href="/players/r/reinhsa01.html">Sam Reinhart</a></td>
+	<td data-stat="goals">2</td><td data-stat="assists">1</td><td data-stat="points">3</td><td data-stat="plus_minus">+2</td><td data-stat="pen_min">0</td>
+	<td data-stat="shots">6</td><td data-stat="time_on_ice">21:30</td></tr>
+	<tr class="thead"><th data-stat="ranker">Rk</th><td data-stat="player">Player</td></tr>
+	<tr><th data-stat="ranker">2</th><td data-stat="player"><a href="/players/e/ekbloaa01.html">Aaron Ekblad</a></td>
+	<td data-stat="goals">0</td><td data-stat="assists">0</td><td data-stat="points">0</td><td data-stat="plus_minus">-1</td><td data-stat="pen_min">2</td>
+	<td data-stat="shots">2</td><td data-stat="time_on_ice">23:15</td></tr>
+	</tbody></table>
+	<table class="sortable stats_table" id="BOS_goalies">
+	<tbody>
+	<tr><th data-stat="ranker">1</th><td data-stat="player"><a href="/players/s/swaymje01.html">Jeremy Swayman</a></td>
+	<td data-stat="decision">L</td><td data-stat="goals_against">3</td><td data-stat="shots_against">34</td><td data-stat="saves">31</td>
+	<td data-stat="save_pct">.912</td><td data-stat="time_on_ice">58:40</td></tr>
+	</tbody></table>
+	<table class="sortable stats_table" id="FLA_adv">
+	<tbody>
+	<tr><th data-stat="ranker">1</th><td data-stat="player"><a href="/players/r/reinhsa01.html">Sam Reinhart</a></td>
+	<td data-stat="shots">99</td><td data-stat="time_on_ice">99:00</td></tr>
+	</tbody></table>
+	</body></html>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(html))
+	}))
+	defer server.Close()
+
+	c := colly.NewCollector()
+	var tables []*colly.HTMLElement
+	c.OnHTML("table.stats_table", func(e *colly.HTMLElement) {
+		tables = append(tables, e)
+	})
+	if err := c.Visit(server.URL); err != nil {
+		t.Fatalf("colly visit error: %v", err)
+	}
+
+	pSlice, pGames := scrapeNHLPlayerStats(tables, 7, sports.Configs[sports.NHL].Scraper.BoxScoreTables)
+	if len(pSlice) != 3 || len(pGames) != 3 {
+		t.Fatalf("expected two skaters and a goalie, got players=%+v games=%+v", pSlice, pGames)
+	}
+	byPlayer := map[string]players.NHLPlayerGame{}
+	for _, pGame := range pGames {
+		byPlayer[pGame.PlayerIndex] = pGame
+	}
+	reinhart := byPlayer["reinhsa01"]
+	if reinhart.Game != 7 || reinhart.TeamIndex != "NHL_FLA" || reinhart.Shots != 6 || reinhart.Points != 3 || reinhart.PlusMinus != 2 || reinhart.TimeOnIce != 21.5 {
+		t.Fatalf("unexpected skater line %+v", reinhart)
+	}
+	if ekblad := byPlayer["ekbloaa01"]; ekblad.PlusMinus != -1 || ekblad.PenaltyMins != 2 {
+		t.Fatalf("unexpected skater line %+v", ekblad)
+	}
+	swayman := byPlayer["swaymje01"]
+	if swayman.TeamIndex != "NHL_BOS" || swayman.Saves != 31 || swayman.ShotsAgainst != 34 || swayman.GoalsAgainst != 3 || swayman.Shots != 0 {
+		t.Fatalf("unexpected goalie line %+v", swayman)
+	}
+	for _, p := range pSlice {
+		if p.Sport != "nhl" || p.Name == "" {
+			t.Fatalf("unexpected player %+v", p)
+		}
+	}
+}
+
+func TestScrapeNFLPlayerStats(t *testing.T) {
+	html := `<html><body>
+	<table class="sortable stats_table" id="player_offense">
+	<thead><tr><th data-stat="player">Player</th><th data-stat="team">Tm</th></tr></thead>
+	<tbody>
+	<tr><th data-stat="player"><a href="/players/M/MahoPa00.htm">Patrick Mahomes</a></th><td data-stat="team">KAN</td>
+	<td data-stat="pass_cmp">20</td><td data-stat="pass_att">28</td><td data-stat="pass_yds">291</td><td data-stat="pass_td">1</td><td data-stat="pass_int">1</td>
+	<td data-stat="rush_att">3</td><td data-stat="rush_yds">3</td><td data-stat="rush_td">0</td>
+	<td data-stat="targets">0</td><td data-stat="rec">0</td><td data-stat="rec_yds">0</td><td data-stat="rec_td">0</td></tr>
+	<tr class="thead"><th data-stat="player">Player</th><td data-stat="team">Tm</td></tr>
+	<tr><th data-stat="player"><a href="/players/F/FlowZa00.htm">Zay Flowers</a></th><td data-stat="team">BAL</td>
+	<td data-stat="pass_cmp">0</td><td data-stat="pass_att">0</td><td data-stat="pass_yds">0</td><td data-stat="pass_td">0</td><td data-stat="pass_int">0</td>
+	<td data-stat="rush_att">1</td><td data-stat="rush_yds">6</td><td data-stat="rush_td">0</td>
+	<td data-stat="targets">10</td><td data-stat="rec">7</td><td data-stat="rec_yds">37</td><td data-stat="rec_td">1</td></tr>
+	<tr><th data-stat="player"><a href="/players/K/KickPl00.htm">No Snaps</a></th><td data-stat="team">BAL</td>
+	<td data-stat="rush_att">1</td><td data-stat="rush_yds">2</td></tr>
+	</tbody></table>
+	<div id="all_home_snap_counts"><!--
+	<table class="sortable stats_table" id="home_snap_counts"><tbody>
+	<tr><th data-stat="player"><a href="/players/M/MahoPa00.htm">Patrick Mahomes</a></th><td data-stat="pos">QB</td><td data-stat="offense">59</td><td data-stat="off_pct">100%</td></tr>
+	</tbody></table>
+	--></div>
+	<div id="all_vis_snap_counts"><!--
+	<table class="sortable stats_table" id="vis_snap_counts"><tbody>
+	<tr><th data-stat="player"><a href="/players/F/FlowZa00.htm">Zay Flowers</a></th><td data-stat="pos">WR</td><td data-stat="offense">61</td><td data-stat="off_pct">87%</td></tr>
+	</tbody></table>
+	--></div>
+	</body></html>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(html))
+	}))
+	defer server.Close()
+
+	c := colly.NewCollector()
+	var tables []*colly.HTMLElement
+	var commentTables []*goquery.Document
+	c.OnResponse(func(r *colly.Response) {
+		commentTables = parseTablesFromComments(string(r.Body))
+	})
+	c.OnHTML("table.stats_table", func(e *colly.HTMLElement) {
+		tables = append(tables, e)
+	})
+	if err := c.Visit(server.URL); err != nil {
+		t.Fatalf("colly visit error: %v", err)
+	}
+
+	pSlice, pGames := scrapeNFLPlayerStats(tables, commentTables, 9)
+	if len(pSlice) != 3 || len(pGames) != 2 {
+		t.Fatalf("expected three players and two lines with snaps, got players=%+v games=%+v", pSlice, pGames)
+	}
+	byPlayer := map[string]players.NFLPlayerGame{}
+	for _, pGame := range pGames {
+		byPlayer[pGame.PlayerIndex] = pGame
+	}
+	mahomes := byPlayer["MahoPa00"]
+	if mahomes.Game != 9 || mahomes.TeamIndex != "NFL_KAN" || mahomes.Snaps != 59 || mahomes.PassYards != 291 || mahomes.Interceptions != 1 || mahomes.RushAttempts != 3 {
+		t.Fatalf("unexpected quarterback line %+v", mahomes)
+	}
+	flowers := byPlayer["FlowZa00"]
+	if flowers.TeamIndex != "NFL_BAL" || flowers.Snaps != 61 || flowers.Targets != 10 || flowers.Receptions != 7 || flowers.RecYards != 37 || flowers.RecTDs != 1 {
+		t.Fatalf("unexpected receiver line %+v", flowers)
+	}
+	for _, p := range pSlice {
+		if p.Sport != "nfl" || p.Name == "" {
+			t.Fatalf("unexpected player %+v", p)
+		}
+	}
+}
+
 func TestUpdateGamesAndHandlersUseService(t *testing.T) {
 	svc := NewScraperService(ScraperServiceDeps{
 		Store: fakeScraperStore{
@@ -417,3 +600,62 @@ func TestGetPlayersByTime_AvailableAndRosterFilter(t *testing.T) {
 		t.Fatalf("unexpected roster output: %+v", roster[0])
 	}
 }
+
+func TestUpdateGamesGradesPicksAfterScraping(t *testing.T) {
+	var gradedSport sports.Sport
+	var gradedBefore time.Time
+	var invalidatedFrom time.Time
+	scrapeErr := error(nil)
+	svc := NewScraperService(ScraperServiceDeps{
+		Store: fakeScraperStore{
+			getLastGameFn: func() (games.Game, error) {
+				return games.Game{Date: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)}, nil
+			},
+			gradePropPicksFn: func(sport sports.Sport, before time.Time) (int, error) {
+				gradedSport = sport
+				gradedBefore = before
+				return 1, nil
+			},
+			invalidateFn: func(sport sports.Sport, from time.Time) int {
+				invalidatedFrom = from
+				return 2
+			},
+		},
+		Sources: fakeScraperSources{
+			scrapeGamesFn: func(sport sports.Sport, startDate time.Time, endDate time.Time) error {
+				return scrapeErr
+			},
+		},
+		Now: func() time.Time { return time.Date(2099, 1, 3, 15, 30, 0, 0, time.UTC) },
+	})
+
+	if err := svc.UpdateGames(sports.NBA); err != nil {
+		t.Fatalf("UpdateGames() error = %v", err)
+	}
+	if gradedSport != sports.NBA || !gradedBefore.Equal(time.Date(2099, 1, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected grading call: %v %v", gradedSport, gradedBefore)
+	}
+	if !invalidatedFrom.Equal(time.Date(2099, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected snapshots invalidated from the first scraped date, got %v", invalidatedFrom)
+	}
+
+	gradedSport = ""
+	if err := svc.UpdateGames(sports.MLB); err != nil {
+		t.Fatalf("UpdateGames() for a sport without graded picks error = %v", err)
+	}
+	if gradedSport != "" {
+		t.Fatalf("picks should not be graded for %v", gradedSport)
+	}
+
+	invalidatedFrom = time.Time{}
+	scrapeErr = errors.New("scrape failed")
+	if err := svc.UpdateGames(sports.NBA); err == nil {
+		t.Fatalf("expected scrape error")
+	}
+	if gradedSport != "" {
+		t.Fatalf("picks should not be graded when scraping fails")
+	}
+	if !invalidatedFrom.IsZero() {
+		t.Fatalf("snapshots should not be invalidated when scraping fails")
+	}
+}
@@ -0,0 +1,138 @@
+package snapshots
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
+
+// Key identifies a snapshot computed for a sport on a date with a given model
+// version and roster. Roster is an opaque description of the players that went
+// into the snapshot so lineup changes produce a new entry.
+type Key struct {
+	Sport        sports.Sport
+	Date         time.Time
+	ModelVersion int
+	Roster       string
+}
+
+func NewKey(sport sports.Sport, date time.Time, modelVersion int, roster string) Key {
+	return Key{
+		Sport:        sport,
+		Date:         time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
+		ModelVersion: modelVersion,
+		Roster:       roster,
+	}
+}
+
+// Store is an in-memory snapshot cache safe for concurrent use. It holds at
+// most limit snapshots and evicts the least recently used one to make room.
+type Store[V any] struct {
+	mu      sync.Mutex
+	limit   int
+	order   *list.List
+	entries map[Key]*list.Element
+}
+
+type entry[V any] struct {
+	key   Key
+	value V
+}
+
+type invalidator interface {
+	InvalidateFrom(sport sports.Sport, date time.Time) int
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []invalidator
+)
+
+// NewStore creates a store holding at most limit snapshots and registers it
+// so InvalidateFrom reaches it
+func NewStore[V any](limit int) *Store[V] {
+	if limit < 1 {
+		limit = 1
+	}
+	s := &Store[V]{limit: limit, order: list.New(), entries: make(map[Key]*list.Element)}
+	registryMu.Lock()
+	registry = append(registry, s)
+	registryMu.Unlock()
+	return s
+}
+
+func (s *Store[V]) Get(key Key) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	s.order.MoveToFront(elem)
+	return elem.Value.(*entry[V]).value, true
+}
+
+func (s *Store[V]) Put(key Key, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[key]; ok {
+		elem.Value.(*entry[V]).value = value
+		s.order.MoveToFront(elem)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&entry[V]{key: key, value: value})
+	for s.order.Len() > s.limit {
+		s.remove(s.order.Back())
+	}
+}
+
+func (s *Store[V]) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.entries, elem.Value.(*entry[V]).key)
+}
+
+func (s *Store[V]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+func (s *Store[V]) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.order.Init()
+	s.entries = make(map[Key]*list.Element)
+}
+
+// InvalidateFrom drops every snapshot for the sport dated on or after date.
+// Returns the number of snapshots dropped.
+func (s *Store[V]) InvalidateFrom(sport sports.Sport, date time.Time) int {
+	from := NewKey(sport, date, 0, "").Date
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dropped := 0
+	for key, elem := range s.entries {
+		if key.Sport == sport && !key.Date.Before(from) {
+			s.remove(elem)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// InvalidateFrom drops snapshots for the sport dated on or after date from
+// every store, e.g. once new box scores for those dates have been scraped
+func InvalidateFrom(sport sports.Sport, date time.Time) int {
+	registryMu.Lock()
+	stores := append([]invalidator(nil), registry...)
+	registryMu.Unlock()
+
+	dropped := 0
+	for _, store := range stores {
+		dropped += store.InvalidateFrom(sport, date)
+	}
+	return dropped
+}
@@ -0,0 +1,121 @@
+package snapshots
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mgordon34/kornet-kover/internal/sports"
+)
+
+func TestNewKeyNormalizesDate(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	a := NewKey(sports.NBA, time.Date(2025, 1, 2, 19, 30, 0, 0, loc), 1, "r")
+	b := NewKey(sports.NBA, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), 1, "r")
+	if a != b {
+		t.Fatalf("expected keys for the same day to match: %+v vs %+v", a, b)
+	}
+}
+
+func TestStoreGetPutClear(t *testing.T) {
+	store := NewStore[[]string](10)
+	key := NewKey(sports.NBA, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), 1, "r")
+
+	if _, ok := store.Get(key); ok {
+		t.Fatalf("expected miss on empty store")
+	}
+	store.Put(key, []string{"a"})
+	if got, ok := store.Get(key); !ok || got[0] != "a" || store.Len() != 1 {
+		t.Fatalf("Get() = %v, %v", got, ok)
+	}
+	other := key
+	other.ModelVersion = 2
+	if _, ok := store.Get(other); ok {
+		t.Fatalf("expected a different model version to miss")
+	}
+
+	store.Clear()
+	if store.Len() != 0 {
+		t.Fatalf("expected empty store after Clear")
+	}
+}
+
+func TestInvalidateFrom(t *testing.T) {
+	first := NewStore[int](100)
+	second := NewStore[string](100)
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	first.Put(NewKey(sports.NBA, day.AddDate(0, 0, -1), 1, ""), 1)
+	first.Put(NewKey(sports.NBA, day, 1, ""), 2)
+	first.Put(NewKey(sports.NBA, day.AddDate(0, 0, 1), 1, ""), 3)
+	first.Put(NewKey(sports.WNBA, day, 1, ""), 4)
+	second.Put(NewKey(sports.NBA, day.AddDate(0, 0, 5), 1, "x"), "a")
+
+	if dropped := InvalidateFrom(sports.NBA, day.Add(15*time.Hour)); dropped != 3 {
+		t.Fatalf("InvalidateFrom() dropped %d, want 3", dropped)
+	}
+	if first.Len() != 2 || second.Len() != 0 {
+		t.Fatalf("unexpected stores after invalidation: %d, %d", first.Len(), second.Len())
+	}
+	if _, ok := first.Get(NewKey(sports.NBA, day.AddDate(0, 0, -1), 1, "")); !ok {
+		t.Fatalf("expected earlier snapshot to survive")
+	}
+	if _, ok := first.Get(NewKey(sports.WNBA, day, 1, "")); !ok {
+		t.Fatalf("expected other sport to survive")
+	}
+}
+
+func TestStoreConcurrentAccess(t *testing.T) {
+	store := NewStore[int](100)
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := NewKey(sports.NBA, day.AddDate(0, 0, i), 1, "")
+			store.Put(key, i)
+			store.Get(key)
+			store.InvalidateFrom(sports.NBA, day.AddDate(0, 0, 30))
+		}(i)
+	}
+	wg.Wait()
+	if store.Len() != 20 {
+		t.Fatalf("expected 20 entries, got %d", store.Len())
+	}
+}
+
+func TestStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewStore[int](2)
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	first := NewKey(sports.NBA, day, 1, "")
+	second := NewKey(sports.NBA, day.AddDate(0, 0, 1), 1, "")
+	third := NewKey(sports.NBA, day.AddDate(0, 0, 2), 1, "")
+
+	store.Put(first, 1)
+	store.Put(second, 2)
+	store.Get(first)
+	store.Put(third, 3)
+
+	if store.Len() != 2 {
+		t.Fatalf("expected the store to stay at its limit, got %d", store.Len())
+	}
+	if _, ok := store.Get(second); ok {
+		t.Fatalf("expected the least recently used snapshot to be evicted")
+	}
+	if got, ok := store.Get(first); !ok || got != 1 {
+		t.Fatalf("expected the recently read snapshot to survive, got %v, %v", got, ok)
+	}
+	store.Put(first, 4)
+	if got, _ := store.Get(first); got != 4 || store.Len() != 2 {
+		t.Fatalf("expected Put to replace an existing snapshot, got %v with %d entries", got, store.Len())
+	}
+
+	tiny := NewStore[int](0)
+	tiny.Put(first, 1)
+	tiny.Put(second, 2)
+	if _, ok := tiny.Get(second); !ok || tiny.Len() != 1 {
+		t.Fatalf("expected a zero limit to still hold one snapshot, got %d", tiny.Len())
+	}
+}
@@ -12,12 +12,12 @@ var Configs = map[Sport]SportConfig{
 			LeagueName: "basketball_nba",
 			Markets: map[string]MarketConfig{
 				"mainline": {
-					Markets:   []string{"player_points", "player_rebounds"},
-					Bookmaker: "williamhill_us",
+					Markets:    []string{"player_points", "player_rebounds"},
+					Bookmakers: []string{"williamhill_us", "fanduel", "draftkings"},
 				},
 				"alternate": {
-					Markets:   []string{"player_points_alternate", "player_rebounds_alternate"},
-					Bookmaker: "fanduel",
+					Markets:    []string{"player_points_alternate", "player_rebounds_alternate"},
+					Bookmakers: []string{"fanduel", "draftkings"},
 				},
 			},
 		},
@@ -32,8 +32,9 @@ var Configs = map[Sport]SportConfig{
 		Analysis: AnalysisConfig{
 			DefaultStats: []string{"points", "rebounds", "assists"},
 			StatWeights: map[string]float64{
-				"points":  1.0,
-				"assists": 1.5,
+				"points":   3,
+				"rebounds": 2,
+				"assists":  1,
 			},
 		},
 	},
@@ -48,12 +49,12 @@ var Configs = map[Sport]SportConfig{
 			LeagueName: "basketball_wnba",
 			Markets: map[string]MarketConfig{
 				"mainline": {
-					Markets:   []string{"player_points", "player_rebounds"},
-					Bookmaker: "williamhill_us",
+					Markets:    []string{"player_points", "player_rebounds"},
+					Bookmakers: []string{"williamhill_us", "fanduel", "draftkings"},
 				},
 				"alternate": {
-					Markets:   []string{"player_points_alternate", "player_rebounds_alternate"},
-					Bookmaker: "fanduel",
+					Markets:    []string{"player_points_alternate", "player_rebounds_alternate"},
+					Bookmakers: []string{"fanduel", "draftkings"},
 				},
 			},
 		},
@@ -68,8 +69,9 @@ var Configs = map[Sport]SportConfig{
 		Analysis: AnalysisConfig{
 			DefaultStats: []string{"points", "rebounds", "assists"},
 			StatWeights: map[string]float64{
-				"points":  1.0,
-				"assists": 1.5,
+				"points":   3,
+				"rebounds": 2,
+				"assists":  1,
 			},
 		},
 	},
@@ -83,12 +85,12 @@ var Configs = map[Sport]SportConfig{
 			LeagueName: "baseball_mlb",
 			Markets: map[string]MarketConfig{
 				"mainline": {
-					Markets:   []string{"batter_home_runs", "batter_hits", "batter_rbis"},
-					Bookmaker: "draftkings",
+					Markets:    []string{"batter_home_runs", "batter_hits", "batter_rbis"},
+					Bookmakers: []string{"draftkings", "fanduel"},
 				},
 				"alternate": {
-					Markets:   []string{"batter_home_runs_alternate", "batter_hits_alternate", "batter_rbis_alternate"},
-					Bookmaker: "draftkings",
+					Markets:    []string{"batter_home_runs_alternate", "batter_hits_alternate", "batter_rbis_alternate"},
+					Bookmakers: []string{"draftkings", "fanduel"},
 				},
 			},
 		},
@@ -103,8 +105,90 @@ var Configs = map[Sport]SportConfig{
 		Analysis: AnalysisConfig{
 			DefaultStats: []string{"hits", "strikeouts", "runs"},
 			StatWeights: map[string]float64{
-				"hits":       1.0,
-				"strikeouts": 1.2,
+				"hits":      3,
+				"rbis":      2,
+				"home_runs": 1,
+			},
+		},
+	},
+	NHL: {
+		Sportsbook: SportsbookConfig{
+			StatMapping: map[string]string{
+				"player_shots_on_goal": "shots",
+				"player_points":        "points",
+				"player_goals":         "goals",
+				"player_assists":       "assists",
+				"player_total_saves":   "saves",
+			},
+			LeagueName: "icehockey_nhl",
+			Markets: map[string]MarketConfig{
+				"mainline": {
+					Markets:    []string{"player_shots_on_goal", "player_points", "player_total_saves"},
+					Bookmakers: []string{"draftkings", "fanduel"},
+				},
+				"alternate": {
+					Markets:    []string{"player_shots_on_goal_alternate", "player_points_alternate", "player_total_saves_alternate"},
+					Bookmakers: []string{"draftkings", "fanduel"},
+				},
+			},
+		},
+		Scraper: ScraperConfig{
+			Domain:      "https://www.hockey-reference.com",
+			BoxScoreURL: "/boxscores",
+			StatMapping: map[string]string{
+				"shots": "shots",
+				"saves": "saves",
+			},
+			BoxScoreTables: []string{"skaters", "goalies"},
+		},
+		Analysis: AnalysisConfig{
+			DefaultStats: []string{"shots", "points", "saves"},
+			StatWeights: map[string]float64{
+				"shots":  3,
+				"points": 2,
+				"saves":  1,
+			},
+		},
+	},
+	NFL: {
+		Sportsbook: SportsbookConfig{
+			StatMapping: map[string]string{
+				"player_pass_yds":      "pass_yards",
+				"player_rush_yds":      "rush_yards",
+				"player_receptions":    "receptions",
+				"player_reception_yds": "rec_yards",
+				"player_anytime_td":    "touchdowns",
+			},
+			LeagueName: "americanfootball_nfl",
+			Markets: map[string]MarketConfig{
+				"mainline": {
+					Markets:    []string{"player_pass_yds", "player_rush_yds", "player_receptions", "player_reception_yds", "player_anytime_td"},
+					Bookmakers: []string{"draftkings", "fanduel"},
+				},
+				"alternate": {
+					Markets:    []string{"player_pass_yds_alternate", "player_rush_yds_alternate", "player_receptions_alternate", "player_reception_yds_alternate"},
+					Bookmakers: []string{"draftkings", "fanduel"},
+				},
+			},
+		},
+		Scraper: ScraperConfig{
+			Domain:      "https://www.pro-football-reference.com",
+			BoxScoreURL: "/boxscores",
+			StatMapping: map[string]string{
+				"pass_yds": "pass_yards",
+				"rush_yds": "rush_yards",
+				"rec":      "receptions",
+				"rec_yds":  "rec_yards",
+			},
+		},
+		Analysis: AnalysisConfig{
+			DefaultStats: []string{"pass_yards", "rush_yards", "receptions", "rec_yards", "touchdowns"},
+			StatWeights: map[string]float64{
+				"receptions": 5,
+				"rec_yards":  4,
+				"rush_yards": 3,
+				"pass_yards": 2,
+				"touchdowns": 1,
 			},
 		},
 	},
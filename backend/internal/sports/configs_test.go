@@ -10,6 +10,8 @@ func TestConfigs_HasExpectedEntries(t *testing.T) {
 		{name: "NBA", sport: NBA},
 		{name: "WNBA", sport: WNBA},
 		{name: "MLB", sport: MLB},
+		{name: "NHL", sport: NHL},
+		{name: "NFL", sport: NFL},
 	}
 
 	for _, tt := range tests {
@@ -19,8 +19,34 @@ func TestGetConfig_SupportedSport(t *testing.T) {
 }
 
 func TestGetConfig_UnsupportedSport(t *testing.T) {
-	_, err := GetConfig(NHL)
+	_, err := GetConfig(Sport("cricket"))
 	if !errors.Is(err, ErrUnsupportedSport) {
 		t.Fatalf("expected ErrUnsupportedSport, got %v", err)
 	}
 }
+
+func TestGetConfig_NHL(t *testing.T) {
+	config, err := GetConfig(NHL)
+	if err != nil {
+		t.Fatalf("GetConfig(NHL) err = %v", err)
+	}
+	if config.Sportsbook.LeagueName != "icehockey_nhl" || config.Sportsbook.StatMapping["player_shots_on_goal"] != "shots" || config.Sportsbook.StatMapping["player_total_saves"] != "saves" {
+		t.Fatalf("unexpected NHL sportsbook config %+v", config.Sportsbook)
+	}
+	if len(config.Scraper.BoxScoreTables) != 2 {
+		t.Fatalf("expected skater and goalie box score tables, got %v", config.Scraper.BoxScoreTables)
+	}
+}
+
+func TestGetConfig_NFL(t *testing.T) {
+	config, err := GetConfig(NFL)
+	if err != nil {
+		t.Fatalf("GetConfig(NFL) err = %v", err)
+	}
+	if config.Sportsbook.LeagueName != "americanfootball_nfl" || config.Sportsbook.StatMapping["player_anytime_td"] != "touchdowns" || config.Sportsbook.StatMapping["player_reception_yds"] != "rec_yards" {
+		t.Fatalf("unexpected NFL sportsbook config %+v", config.Sportsbook)
+	}
+	if config.Scraper.Domain != "https://www.pro-football-reference.com" {
+		t.Fatalf("unexpected NFL scraper config %+v", config.Scraper)
+	}
+}
@@ -9,6 +9,7 @@ const (
 	WNBA Sport = "wnba"
 	MLB  Sport = "mlb"
 	NHL  Sport = "nhl"
+	NFL  Sport = "nfl"
 )
 
 var ErrUnsupportedSport = fmt.Errorf("unsupported sport")
@@ -19,20 +20,27 @@ type SportsbookConfig struct {
 	StatMapping map[string]string
 }
 
+// MarketConfig lists the markets pulled for a line type and the bookmakers
+// they are pulled from
 type MarketConfig struct {
-	Markets   []string
-	Bookmaker string
+	Markets    []string
+	Bookmakers []string
 }
 
 type ScraperConfig struct {
 	Domain      string
 	BoxScoreURL string
 	StatMapping map[string]string
+	// BoxScoreTables are the box score tables player lines are read from, by
+	// the suffix of their id. Only set for sports with a table per position.
+	BoxScoreTables []string
 }
 
 type AnalysisConfig struct {
 	DefaultStats []string
-	StatWeights  map[string]float64
+	// StatWeights ranks the sport's stats when picks are sorted, highest
+	// first. Stats without a weight rank last.
+	StatWeights map[string]float64
 }
 
 type SportConfig struct {
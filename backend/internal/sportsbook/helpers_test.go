@@ -11,6 +11,18 @@ func TestGetMarketType(t *testing.T) {
 	}
 }
 
+func TestGetLineSide(t *testing.T) {
+	if side, point := getLineSide("Yes", 0); side != "Over" || point != 0.5 {
+		t.Fatalf("getLineSide(Yes) = %q %v", side, point)
+	}
+	if side, point := getLineSide("No", 0); side != "Under" || point != 0.5 {
+		t.Fatalf("getLineSide(No) = %q %v", side, point)
+	}
+	if side, point := getLineSide("Over", 64.5); side != "Over" || point != 64.5 {
+		t.Fatalf("getLineSide(Over) = %q %v", side, point)
+	}
+}
+
 func TestParseNameFromDescription(t *testing.T) {
 	if got := parseNameFromDescription("Aaron Gordon (Rebounds)"); got != "Aaron Gordon" {
 		t.Fatalf("parseNameFromDescription() = %q", got)
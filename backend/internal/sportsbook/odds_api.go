@@ -232,7 +232,7 @@ func (s *OddsService) GetOddsForGame(sport sports.Sport, game EventInfo, config
 		addlArgs := []string{
 			"date=" + game.CommenceTime.UTC().Format("2006-01-02T15:04:05Z"),
 			"regions=us",
-			"bookmakers=" + marketConfig.Bookmaker,
+			"bookmakers=" + strings.Join(marketConfig.Bookmakers, ","),
 			"markets=" + strings.Join(marketConfig.Markets, ","),
 			"oddsFormat=" + "american",
 			"includeLinks=" + "true",
@@ -249,30 +249,34 @@ func (s *OddsService) GetOddsForGame(sport sports.Sport, game EventInfo, config
 
 		if len(oddResponse.Data.Bookmakers) == 0 {
 			log.Printf("Could not find odds for %s vs %s", game.HomeTeam, game.AwayTeam)
-			return lines
+			continue
 		}
-		for _, market := range oddResponse.Data.Bookmakers[0].Markets {
-			truncated_string := strings.ReplaceAll(market.Key, "_alternate", "")
-			stat := config.StatMapping[truncated_string]
-			for _, line := range market.Outcomes {
-				playerName := strings.Join(strings.Split(line.Description, " ")[:2], " ")
-				playerIndex, err := s.deps.Store.PlayerNameToIndex(nameMap, playerName)
-				if err != nil {
-					log.Printf("Error finding player name: %s", line.Description)
-					continue
+		for _, bookmaker := range oddResponse.Data.Bookmakers {
+			for _, market := range bookmaker.Markets {
+				truncated_string := strings.ReplaceAll(market.Key, "_alternate", "")
+				stat := config.StatMapping[truncated_string]
+				for _, line := range market.Outcomes {
+					playerName := strings.Join(strings.Split(line.Description, " ")[:2], " ")
+					playerIndex, err := s.deps.Store.PlayerNameToIndex(nameMap, playerName)
+					if err != nil {
+						log.Printf("Error finding player name: %s", line.Description)
+						continue
+					}
+					side, point := getLineSide(line.Name, line.Point)
+					line := odds.PlayerLine{
+						Sport:       string(sport),
+						PlayerIndex: playerIndex,
+						Timestamp:   market.LastUpdate,
+						Stat:        stat,
+						Side:        side,
+						Line:        point,
+						Type:        getMarketType(market.Key),
+						Odds:        line.Price,
+						Link:        line.Link,
+						Bookmaker:   bookmaker.Key,
+					}
+					lines = append(lines, line)
 				}
-				line := odds.PlayerLine{
-					Sport:       string(sport),
-					PlayerIndex: playerIndex,
-					Timestamp:   market.LastUpdate,
-					Stat:        stat,
-					Side:        line.Name,
-					Line:        line.Point,
-					Type:        getMarketType(market.Key),
-					Odds:        line.Price,
-					Link:        line.Link,
-				}
-				lines = append(lines, line)
 			}
 		}
 	}
@@ -285,14 +289,13 @@ func (s *OddsService) GetLiveOddsForGame(game EventInfo, oddsType string, apiGet
 	var lines []odds.PlayerLine
 	nameMap := make(map[string]string)
 
-	var markets, bookmakers string
+	var markets string
 	if oddsType == "alternate" {
-		bookmakers = "fanduel"
 		markets = "player_points_alternate,player_rebounds_alternate,player_assists_alternate,player_threes_alternate"
 	} else {
-		bookmakers = "williamhill_us"
 		markets = "player_points,player_rebounds,player_assists,player_threes"
 	}
+	bookmakers := strings.Join(sports.Configs[sports.NBA].Sportsbook.Markets[oddsType].Bookmakers, ",")
 
 	if apiGetter == nil {
 		apiGetter = s.deps.Sources.GetOddsAPI
@@ -319,28 +322,31 @@ func (s *OddsService) GetLiveOddsForGame(game EventInfo, oddsType string, apiGet
 		log.Printf("Could not find odds for %s vs %s", game.HomeTeam, game.AwayTeam)
 		return lines
 	}
-	for _, market := range OddsInfo.Bookmakers[0].Markets {
-		truncated_string := strings.ReplaceAll(market.Key, "_alternate", "")
-		stat := odds_markets[truncated_string]
-		for _, line := range market.Outcomes {
-			playerName := strings.Join(strings.Split(line.Description, " ")[:2], " ")
-			playerIndex, err := s.deps.Store.PlayerNameToIndex(nameMap, playerName)
-			if err != nil {
-				log.Printf("Error finding player name: %s", line.Description)
-				continue
-			}
-			line := odds.PlayerLine{
-				Sport:       "nba",
-				PlayerIndex: playerIndex,
-				Timestamp:   market.LastUpdate,
-				Stat:        stat,
-				Side:        line.Name,
-				Line:        line.Point,
-				Type:        getMarketType(market.Key),
-				Odds:        line.Price,
-				Link:        line.Link,
+	for _, bookmaker := range OddsInfo.Bookmakers {
+		for _, market := range bookmaker.Markets {
+			truncated_string := strings.ReplaceAll(market.Key, "_alternate", "")
+			stat := odds_markets[truncated_string]
+			for _, line := range market.Outcomes {
+				playerName := strings.Join(strings.Split(line.Description, " ")[:2], " ")
+				playerIndex, err := s.deps.Store.PlayerNameToIndex(nameMap, playerName)
+				if err != nil {
+					log.Printf("Error finding player name: %s", line.Description)
+					continue
+				}
+				line := odds.PlayerLine{
+					Sport:       "nba",
+					PlayerIndex: playerIndex,
+					Timestamp:   market.LastUpdate,
+					Stat:        stat,
+					Side:        line.Name,
+					Line:        line.Point,
+					Type:        getMarketType(market.Key),
+					Odds:        line.Price,
+					Link:        line.Link,
+					Bookmaker:   bookmaker.Key,
+				}
+				lines = append(lines, line)
 			}
-			lines = append(lines, line)
 		}
 	}
 
@@ -354,6 +360,18 @@ func getMarketType(market string) string {
 	return "mainline"
 }
 
+// getLineSide reads Yes/No markets, like anytime touchdown scorer, as over or
+// under half of one
+func getLineSide(name string, point float32) (string, float32) {
+	switch name {
+	case "Yes":
+		return "Over", 0.5
+	case "No":
+		return "Under", 0.5
+	}
+	return name, point
+}
+
 func (s *OddsService) GetOdds(startDate time.Time, endDate time.Time, oddsType string) {
 	sportConfig, ok := sports.Configs[sports.NBA]
 	if !ok {
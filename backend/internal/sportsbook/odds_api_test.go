@@ -4,6 +4,8 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -60,7 +62,7 @@ func TestGetLiveOddsForGame_ParsesLinesWithInjectedDependencies(t *testing.T) {
 	if len(lines) != 1 {
 		t.Fatalf("expected one line after failed player lookup skip, got %d", len(lines))
 	}
-	if lines[0].Type != "mainline" || lines[0].Stat != "points" || lines[0].PlayerIndex != "idx1" {
+	if lines[0].Type != "mainline" || lines[0].Stat != "points" || lines[0].PlayerIndex != "idx1" || lines[0].Bookmaker != "williamhill_us" {
 		t.Fatalf("unexpected line: %+v", lines[0])
 	}
 }
@@ -72,7 +74,9 @@ func TestGetLiveOddsForGame_AlternateMarketType(t *testing.T) {
 		}},
 	})
 
+	var requested []string
 	getter := func(endpoint string, addlArgs []string) (string, error) {
+		requested = addlArgs
 		return `{
 			"id":"g1",
 			"bookmakers":[{
@@ -95,6 +99,9 @@ func TestGetLiveOddsForGame_AlternateMarketType(t *testing.T) {
 	if lines[0].Type != "alternate" || lines[0].Stat != "points" || lines[0].Odds != 130 {
 		t.Fatalf("unexpected alternate line: %+v", lines[0])
 	}
+	if !slices.Contains(requested, "bookmakers=fanduel,draftkings") {
+		t.Fatalf("expected the configured alternate bookmakers, got %v", requested)
+	}
 }
 
 func TestGetLiveOddsForGame_NoBookmakers(t *testing.T) {
@@ -191,11 +198,18 @@ func TestOddsBatchFunctionsUseInjectedDependencies(t *testing.T) {
 func TestGetGamesForDateAndGetOddsForGame_WithInjectedRequester(t *testing.T) {
 	responses := map[string]string{
 		"historical/sports/basketball_nba/events/":        `{"timestamp":"2026-01-01T00:00:00Z","previous_timestamp":"2025-12-31T00:00:00Z","next_timestamp":"2026-01-02T00:00:00Z","data":[{"id":"g1","sport_key":"basketball_nba","sport_title":"NBA","commence_time":"2026-01-01T23:00:00Z","home_team":"A","away_team":"B"}]}`,
-		"historical/sports/basketball_nba/events/g1/odds": `{"timestamp":"2026-01-01T00:00:00Z","previous_timestamp":"2025-12-31T00:00:00Z","next_timestamp":"2026-01-02T00:00:00Z","data":{"id":"g1","sport_key":"basketball_nba","sport_title":"NBA","commence_time":"2026-01-01T23:00:00Z","home_team":"A","away_team":"B","bookmakers":[{"key":"fanduel","title":"FanDuel","last_update":"2026-01-01T22:00:00Z","markets":[{"key":"player_points","last_update":"2026-01-01T22:00:00Z","outcomes":[{"name":"Over","description":"Aaron Gordon","price":-110,"point":20.5,"link":"x"}]}]}]}}`,
+		"historical/sports/basketball_nba/events/g1/odds": `{"timestamp":"2026-01-01T00:00:00Z","previous_timestamp":"2025-12-31T00:00:00Z","next_timestamp":"2026-01-02T00:00:00Z","data":{"id":"g1","sport_key":"basketball_nba","sport_title":"NBA","commence_time":"2026-01-01T23:00:00Z","home_team":"A","away_team":"B","bookmakers":[{"key":"fanduel","title":"FanDuel","last_update":"2026-01-01T22:00:00Z","markets":[{"key":"player_points","last_update":"2026-01-01T22:00:00Z","outcomes":[{"name":"Over","description":"Aaron Gordon","price":-110,"point":20.5,"link":"x"}]}]},{"key":"draftkings","title":"DraftKings","last_update":"2026-01-01T22:00:00Z","markets":[{"key":"player_points","last_update":"2026-01-01T22:00:00Z","outcomes":[{"name":"Over","description":"Aaron Gordon","price":-105,"point":20.5,"link":"y"}]}]}]}}`,
 	}
 
+	var requestedBooks []string
 	svc := NewOddsService(OddsServiceDeps{
 		Sources: fakeSportsbookSources{getOddsAPIFn: func(endpoint string, addlArgs []string) (string, error) {
+			if strings.HasSuffix(endpoint, "/odds") {
+				requestedBooks = addlArgs
+			}
+			if strings.HasSuffix(endpoint, "/odds") {
+				requestedBooks = addlArgs
+			}
 			if v, ok := responses[endpoint]; ok {
 				return v, nil
 			}
@@ -211,8 +225,8 @@ func TestGetGamesForDateAndGetOddsForGame_WithInjectedRequester(t *testing.T) {
 		},
 		Markets: map[string]sports.MarketConfig{
 			"mainline": {
-				Bookmaker: "fanduel",
-				Markets:   []string{"player_points"},
+				Bookmakers: []string{"fanduel", "draftkings"},
+				Markets:    []string{"player_points"},
 			},
 		},
 	}
@@ -223,7 +237,91 @@ func TestGetGamesForDateAndGetOddsForGame_WithInjectedRequester(t *testing.T) {
 	}
 
 	lines := svc.GetOddsForGame(sports.NBA, games[0], config)
-	if len(lines) != 1 || lines[0].PlayerIndex != "idx1" || lines[0].Stat != "points" {
+	if len(lines) != 2 || lines[0].PlayerIndex != "idx1" || lines[0].Stat != "points" {
 		t.Fatalf("GetOddsForGame() = %+v", lines)
 	}
+	if lines[0].Bookmaker != "fanduel" || lines[1].Bookmaker != "draftkings" || lines[1].Odds != -105 {
+		t.Fatalf("expected a line from each bookmaker, got %+v", lines)
+	}
+	if !slices.Contains(requestedBooks, "bookmakers=fanduel,draftkings") {
+		t.Fatalf("expected every configured bookmaker to be requested, got %v", requestedBooks)
+	}
+}
+
+func TestGetOddsForGame_MapsNHLMarkets(t *testing.T) {
+	responses := map[string]string{
+		"mainline":  `{"data":{"id":"h1","bookmakers":[{"key":"draftkings","markets":[{"key":"player_shots_on_goal","last_update":"2026-01-01T22:00:00Z","outcomes":[{"name":"Over","description":"Sam Reinhart","price":-120,"point":2.5,"link":"x"}]}]}]}}`,
+		"alternate": `{"data":{"id":"h1","bookmakers":[{"key":"fanduel","markets":[{"key":"player_total_saves_alternate","last_update":"2026-01-01T22:00:00Z","outcomes":[{"name":"Over","description":"Jeremy Swayman","price":150,"point":29.5,"link":"y"}]}]}]}}`,
+	}
+
+	var endpoints []string
+	svc := NewOddsService(OddsServiceDeps{
+		Sources: fakeSportsbookSources{getOddsAPIFn: func(endpoint string, addlArgs []string) (string, error) {
+			endpoints = append(endpoints, endpoint)
+			if slices.Contains(addlArgs, "markets=player_shots_on_goal_alternate,player_points_alternate,player_total_saves_alternate") {
+				return responses["alternate"], nil
+			}
+			return responses["mainline"], nil
+		}},
+		Store: fakeSportsbookStore{playerNameToIndexFn: func(nameMap map[string]string, playerName string) (string, error) {
+			return strings.ToLower(strings.Fields(playerName)[1]), nil
+		}},
+	})
+
+	config := sports.Configs[sports.NHL].Sportsbook
+	lines := svc.GetOddsForGame(sports.NHL, EventInfo{ID: "h1", HomeTeam: "Florida Panthers", AwayTeam: "Boston Bruins"}, &config)
+	if len(endpoints) != 2 || endpoints[0] != "historical/sports/icehockey_nhl/events/h1/odds" {
+		t.Fatalf("expected the NHL league to be requested for each market type, got %v", endpoints)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected a shots and a saves line, got %+v", lines)
+	}
+	byStat := map[string]odds.PlayerLine{}
+	for _, line := range lines {
+		byStat[line.Stat] = line
+	}
+	if shots := byStat["shots"]; shots.Sport != "nhl" || shots.PlayerIndex != "reinhart" || shots.Type != "mainline" {
+		t.Fatalf("unexpected shots line %+v", shots)
+	}
+	if saves := byStat["saves"]; saves.PlayerIndex != "swayman" || saves.Type != "alternate" || saves.Line != 29.5 {
+		t.Fatalf("unexpected saves line %+v", saves)
+	}
+}
+
+func TestGetOddsForGame_MapsNFLMarkets(t *testing.T) {
+	responses := map[string]string{
+		"mainline":  `{"data":{"id":"f1","bookmakers":[{"key":"fanduel","markets":[{"key":"player_reception_yds","last_update":"2026-09-10T22:00:00Z","outcomes":[{"name":"Over","description":"Zay Flowers","price":-115,"point":58.5,"link":"x"}]},{"key":"player_anytime_td","last_update":"2026-09-10T22:00:00Z","outcomes":[{"name":"Yes","description":"Zay Flowers","price":190,"link":"y"}]}]}]}}`,
+		"alternate": `{"data":{"id":"f1","bookmakers":[]}}`,
+	}
+
+	svc := NewOddsService(OddsServiceDeps{
+		Sources: fakeSportsbookSources{getOddsAPIFn: func(endpoint string, addlArgs []string) (string, error) {
+			if !strings.HasPrefix(endpoint, "historical/sports/americanfootball_nfl/") {
+				t.Fatalf("expected the NFL league to be requested, got %q", endpoint)
+			}
+			if slices.Contains(addlArgs, "markets=player_pass_yds,player_rush_yds,player_receptions,player_reception_yds,player_anytime_td") {
+				return responses["mainline"], nil
+			}
+			return responses["alternate"], nil
+		}},
+		Store: fakeSportsbookStore{playerNameToIndexFn: func(nameMap map[string]string, playerName string) (string, error) {
+			return "FlowZa00", nil
+		}},
+	})
+
+	config := sports.Configs[sports.NFL].Sportsbook
+	lines := svc.GetOddsForGame(sports.NFL, EventInfo{ID: "f1", HomeTeam: "Kansas City Chiefs", AwayTeam: "Baltimore Ravens"}, &config)
+	if len(lines) != 2 {
+		t.Fatalf("expected a receiving yards and a touchdown line, got %+v", lines)
+	}
+	byStat := map[string]odds.PlayerLine{}
+	for _, line := range lines {
+		byStat[line.Stat] = line
+	}
+	if yards := byStat["rec_yards"]; yards.Sport != "nfl" || yards.Side != "Over" || yards.Line != 58.5 {
+		t.Fatalf("unexpected receiving yards line %+v", yards)
+	}
+	if td := byStat["touchdowns"]; td.Side != "Over" || td.Line != 0.5 || td.Odds != 190 {
+		t.Fatalf("expected anytime touchdown as over half a touchdown, got %+v", td)
+	}
 }
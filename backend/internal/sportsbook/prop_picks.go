@@ -212,6 +212,7 @@ func (s *PropPicksService) GetLinesForMarket(game Game, market string, stat stri
 					Side:        side,
 					Line:        outcome.Handicap,
 					Odds:        outcome.Odds,
+					Bookmaker:   bookie.BookieKey,
 				}
 
 				if timestamp.Before(game.Timestamp.Add(time.Minute * 20)) {
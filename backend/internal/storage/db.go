@@ -40,6 +40,30 @@ func InitTables() {
 
 	commands := []string{
 		`ALTER TABLE IF EXISTS players ADD COLUMN IF NOT EXISTS details JSONB`,
+		`ALTER TABLE IF EXISTS strategies ADD COLUMN IF NOT EXISTS settings JSONB`,
+		`ALTER TABLE IF EXISTS prop_picks ADD COLUMN IF NOT EXISTS actual REAL`,
+		`ALTER TABLE IF EXISTS prop_picks ADD COLUMN IF NOT EXISTS result VARCHAR(10)`,
+		`ALTER TABLE IF EXISTS prop_picks ADD COLUMN IF NOT EXISTS profit REAL`,
+		`ALTER TABLE IF EXISTS prop_picks ADD COLUMN IF NOT EXISTS closing_line REAL`,
+		`ALTER TABLE IF EXISTS prop_picks ADD COLUMN IF NOT EXISTS closing_odds INT`,
+		`ALTER TABLE IF EXISTS prop_picks ADD COLUMN IF NOT EXISTS clv_line REAL`,
+		`ALTER TABLE IF EXISTS prop_picks ADD COLUMN IF NOT EXISTS clv_prob REAL`,
+		`ALTER TABLE IF EXISTS backtest_runs ADD COLUMN IF NOT EXISTS clv_bets INT NOT NULL DEFAULT 0`,
+		`ALTER TABLE IF EXISTS backtest_runs ADD COLUMN IF NOT EXISTS avg_clv_line REAL NOT NULL DEFAULT 0`,
+		`ALTER TABLE IF EXISTS backtest_runs ADD COLUMN IF NOT EXISTS avg_clv_prob REAL NOT NULL DEFAULT 0`,
+		`ALTER TABLE IF EXISTS backtest_runs ADD COLUMN IF NOT EXISTS beat_close_rate REAL NOT NULL DEFAULT 0`,
+		`ALTER TABLE IF EXISTS backtest_bets ADD COLUMN IF NOT EXISTS closing_line REAL`,
+		`ALTER TABLE IF EXISTS backtest_bets ADD COLUMN IF NOT EXISTS closing_odds INT`,
+		`ALTER TABLE IF EXISTS backtest_bets ADD COLUMN IF NOT EXISTS clv_line REAL`,
+		`ALTER TABLE IF EXISTS backtest_bets ADD COLUMN IF NOT EXISTS clv_prob REAL`,
+		`ALTER TABLE IF EXISTS player_lines ADD COLUMN IF NOT EXISTS bookmaker VARCHAR(50) NOT NULL DEFAULT ''`,
+		`DO $$
+        BEGIN
+            IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'uq_prop_index') THEN
+                ALTER TABLE player_lines DROP CONSTRAINT uq_prop_index;
+                ALTER TABLE player_lines ADD CONSTRAINT uq_player_lines UNIQUE(sport, player_index, timestamp, stat, side, line, bookmaker);
+            END IF;
+        END $$`,
 		`CREATE TABLE IF NOT EXISTS teams (
             index VARCHAR(255) PRIMARY KEY,
             name VARCHAR(255) NOT NULL
@@ -131,6 +155,43 @@ func InitTables() {
             batters_faced INT NOT NULL,
             wpa REAL NOT NULL,
             CONSTRAINT uq_mlb_player_games_pitching UNIQUE(player_index, game)
+        )`,
+		`CREATE TABLE IF NOT EXISTS nhl_player_games (
+            id SERIAL PRIMARY KEY,
+            player_index VARCHAR(20) REFERENCES players(index),
+            game INT REFERENCES games(id),
+            team_index VARCHAR(255) REFERENCES teams(index),
+            time_on_ice REAL NOT NULL,
+            goals INT NOT NULL,
+            assists INT NOT NULL,
+            points INT NOT NULL,
+            shots INT NOT NULL,
+            plus_minus INT NOT NULL,
+            pen_min INT NOT NULL,
+            shots_against INT NOT NULL,
+            saves INT NOT NULL,
+            goals_against INT NOT NULL,
+            CONSTRAINT uq_nhl_player_games UNIQUE(player_index, game)
+        )`,
+		`CREATE TABLE IF NOT EXISTS nfl_player_games (
+            id SERIAL PRIMARY KEY,
+            player_index VARCHAR(20) REFERENCES players(index),
+            game INT REFERENCES games(id),
+            team_index VARCHAR(255) REFERENCES teams(index),
+            snaps INT NOT NULL,
+            pass_completions INT NOT NULL,
+            pass_attempts INT NOT NULL,
+            pass_yards INT NOT NULL,
+            pass_tds INT NOT NULL,
+            interceptions INT NOT NULL,
+            rush_attempts INT NOT NULL,
+            rush_yards INT NOT NULL,
+            rush_tds INT NOT NULL,
+            targets INT NOT NULL,
+            receptions INT NOT NULL,
+            rec_yards INT NOT NULL,
+            rec_tds INT NOT NULL,
+            CONSTRAINT uq_nfl_player_games UNIQUE(player_index, game)
         )`,
 		`CREATE TABLE IF NOT EXISTS mlb_play_by_plays (
             id SERIAL PRIMARY KEY,
@@ -156,7 +217,8 @@ func InitTables() {
             line REAL NOT NULL,
             odds INT NOT NULL,
             link VARCHAR(255),
-            CONSTRAINT uq_prop_index UNIQUE(sport, player_index, timestamp, stat, side, line)
+            bookmaker VARCHAR(50) NOT NULL DEFAULT '',
+            CONSTRAINT uq_player_lines UNIQUE(sport, player_index, timestamp, stat, side, line, bookmaker)
         )`,
 		`CREATE TABLE IF NOT EXISTS nba_pip_factors (
             id SERIAL PRIMARY KEY,
@@ -205,6 +267,64 @@ func InitTables() {
             ortg REAL NOT NULL,
             drtg REAL NOT NULL,
             CONSTRAINT uq_wnba_pip_predictions UNIQUE(player_index, date, version)
+        )`,
+		`CREATE TABLE IF NOT EXISTS mlb_pip_predictions (
+            id SERIAL PRIMARY KEY,
+            player_index VARCHAR(20) REFERENCES players(index),
+            date DATE NOT NULL,
+            version INT NOT NULL,
+            num_games INT NOT NULL,
+            at_bats REAL NOT NULL,
+            runs REAL NOT NULL,
+            hits REAL NOT NULL,
+            rbis REAL NOT NULL,
+            home_runs REAL NOT NULL,
+            walks REAL NOT NULL,
+            strikeouts REAL NOT NULL,
+            pas REAL NOT NULL,
+            pitches REAL NOT NULL,
+            strikes REAL NOT NULL,
+            ba REAL NOT NULL,
+            obp REAL NOT NULL,
+            slg REAL NOT NULL,
+            ops REAL NOT NULL,
+            wpa REAL NOT NULL,
+            CONSTRAINT uq_mlb_pip_predictions UNIQUE(player_index, date, version)
+        )`,
+		`CREATE TABLE IF NOT EXISTS nhl_pip_predictions (
+            id SERIAL PRIMARY KEY,
+            player_index VARCHAR(20) REFERENCES players(index),
+            date DATE NOT NULL,
+            version INT NOT NULL,
+            num_games INT NOT NULL,
+            time_on_ice REAL NOT NULL,
+            goals REAL NOT NULL,
+            assists REAL NOT NULL,
+            points REAL NOT NULL,
+            shots REAL NOT NULL,
+            shots_against REAL NOT NULL,
+            saves REAL NOT NULL,
+            goals_against REAL NOT NULL,
+            CONSTRAINT uq_nhl_pip_predictions UNIQUE(player_index, date, version)
+        )`,
+		`CREATE TABLE IF NOT EXISTS nfl_pip_predictions (
+            id SERIAL PRIMARY KEY,
+            player_index VARCHAR(20) REFERENCES players(index),
+            date DATE NOT NULL,
+            version INT NOT NULL,
+            num_games INT NOT NULL,
+            snaps REAL NOT NULL,
+            pass_attempts REAL NOT NULL,
+            pass_completions REAL NOT NULL,
+            pass_yards REAL NOT NULL,
+            pass_tds REAL NOT NULL,
+            rush_attempts REAL NOT NULL,
+            rush_yards REAL NOT NULL,
+            targets REAL NOT NULL,
+            receptions REAL NOT NULL,
+            rec_yards REAL NOT NULL,
+            touchdowns REAL NOT NULL,
+            CONSTRAINT uq_nfl_pip_predictions UNIQUE(player_index, date, version)
         )`,
 		`CREATE TABLE IF NOT EXISTS users (
             id SERIAL PRIMARY KEY,
@@ -215,7 +335,8 @@ func InitTables() {
 		`CREATE TABLE IF NOT EXISTS strategies (
             id SERIAL PRIMARY KEY,
             user_id INT REFERENCES users(id),
-            name VARCHAR(255) NOT NULL
+            name VARCHAR(255) NOT NULL,
+            settings JSONB
         )`,
 		`CREATE TABLE IF NOT EXISTS strategy_filters (
             id SERIAL PRIMARY KEY,
@@ -235,7 +356,72 @@ func InitTables() {
             line_id INT REFERENCES player_lines(id),
             valid BOOLEAN NOT NULL,
             date DATE NOT NULL,
+            actual REAL,
+            result VARCHAR(10),
+            profit REAL,
+            closing_line REAL,
+            closing_odds INT,
+            clv_line REAL,
+            clv_prob REAL,
             CONSTRAINT uq_prop_picks UNIQUE(strat_id, line_id, date)
+        )`,
+		`CREATE TABLE IF NOT EXISTS backtest_jobs (
+            id SERIAL PRIMARY KEY,
+            sport VARCHAR(20) NOT NULL,
+            start_date DATE NOT NULL,
+            end_date DATE NOT NULL,
+            status VARCHAR(20) NOT NULL,
+            completed_days INT NOT NULL DEFAULT 0,
+            total_days INT NOT NULL,
+            error TEXT,
+            created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+            finished_at TIMESTAMP
+        )`,
+		`CREATE TABLE IF NOT EXISTS backtest_runs (
+            id SERIAL PRIMARY KEY,
+            job_id INT REFERENCES backtest_jobs(id) ON DELETE CASCADE,
+            strat_id INT REFERENCES strategies(id) ON DELETE SET NULL,
+            strat_name VARCHAR(255) NOT NULL,
+            sport VARCHAR(20) NOT NULL,
+            config JSONB NOT NULL,
+            start_date DATE NOT NULL,
+            end_date DATE NOT NULL,
+            model_version INT NOT NULL,
+            bets INT NOT NULL,
+            wins INT NOT NULL,
+            losses INT NOT NULL,
+            staked REAL NOT NULL,
+            profit REAL NOT NULL,
+            roi REAL NOT NULL,
+            win_rate REAL NOT NULL,
+            max_drawdown REAL NOT NULL,
+            clv_bets INT NOT NULL DEFAULT 0,
+            avg_clv_line REAL NOT NULL DEFAULT 0,
+            avg_clv_prob REAL NOT NULL DEFAULT 0,
+            beat_close_rate REAL NOT NULL DEFAULT 0,
+            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+        )`,
+		`CREATE TABLE IF NOT EXISTS backtest_bets (
+            id SERIAL PRIMARY KEY,
+            run_id INT REFERENCES backtest_runs(id) ON DELETE CASCADE,
+            date DATE NOT NULL,
+            player_index VARCHAR(20) REFERENCES players(index),
+            line_id INT NOT NULL,
+            stat VARCHAR(50) NOT NULL,
+            side VARCHAR(50) NOT NULL,
+            line REAL NOT NULL,
+            odds INT NOT NULL,
+            prediction REAL NOT NULL,
+            diff REAL NOT NULL,
+            pdiff REAL NOT NULL,
+            bet_size REAL NOT NULL,
+            actual REAL NOT NULL,
+            result VARCHAR(10) NOT NULL,
+            profit REAL NOT NULL,
+            closing_line REAL,
+            closing_odds INT,
+            clv_line REAL,
+            clv_prob REAL
         )`,
 		`CREATE TABLE IF NOT EXISTS active_rosters (
             id SERIAL PRIMARY KEY,
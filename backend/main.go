@@ -1,15 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"strconv"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
-	"github.com/mgordon34/kornet-kover/api/games"
 	"github.com/mgordon34/kornet-kover/api/odds"
 	"github.com/mgordon34/kornet-kover/api/picks"
 	"github.com/mgordon34/kornet-kover/api/players"
@@ -25,12 +24,17 @@ import (
 func main() {
 	fmt.Println("Starting server")
 	storage.InitTables()
+	if err := strategies.SeedStrategySettings(); err != nil {
+		log.Fatal("Error seeding strategy settings: ", err)
+	}
 	log.Println("Initialized DB")
 
 	// runUpdateGames()
 	// runUpdateLines()
 
 	// runBacktest()
+	// runBacktestSweep()
+	// runBacktestWalkForward()
 
 	startServer()
 
@@ -57,10 +61,12 @@ func newRouter() *gin.Engine {
 	scraperService := scraper.NewScraperService(scraper.ScraperServiceDeps{})
 	strategyService := strategies.NewStrategyService(strategies.StrategyServiceDeps{})
 	picksService := picks.NewPicksService(picks.PicksServiceDeps{})
+	backtestService := backtesting.NewBacktestService(backtesting.BacktestServiceDeps{})
+	opportunityService := odds.NewOpportunityService(odds.OpportunityServiceDeps{})
 
 	config := cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"}, // Replace with your frontend domain
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: false,
@@ -74,9 +80,12 @@ func newRouter() *gin.Engine {
 	r.GET("/update-lines", sportsbook.UpdateLinesHandler(oddsService))
 	r.GET("/pick-props", analysis.GetPickProps)
 
-	r.GET("/strategies", strategyService.GetStrategiesHandler())
+	strategyService.RegisterRoutes(r)
+	backtestService.RegisterRoutes(r)
 	r.GET("/prop-picks", picksService.GetPropPicksHandler())
 	r.GET("/prop-picks/bettor", picksService.GetBettorPropPicksHandler())
+	r.GET("/prop-picks/graded", picksService.GetGradedPicksHandler())
+	r.GET("/odds/opportunities", opportunityService.GetOpportunitiesHandler())
 
 	return r
 }
@@ -117,7 +126,7 @@ func runUpdateMLBPlayerHandedness() {
 func runGetPIPPredictions() {
 	log.Println("Updating PIPPredictions...")
 	date, _ := time.Parse("2006-01-02", "2023-10-30")
-	preds, _ := players.GetPIPPredictionsForDate(date)
+	preds, _ := players.GetPIPPredictionsForDate(sports.NBA, date)
 	for _, pred := range preds {
 		log.Println(pred)
 	}
@@ -140,7 +149,7 @@ func runGetPlayerOdds() {
 		log.Fatal("Error parsing time: ", err)
 	}
 
-	oddsMap, err := odds.GetPlayerOddsForDate(sports.NBA, startDate)
+	oddsMap, err := odds.GetPlayerOddsForDate(context.Background(), sports.NBA, startDate, odds.DefaultLineSelector)
 	if err != nil {
 		log.Fatal("Error getting player odds", err)
 	}
@@ -153,7 +162,7 @@ func runGetPlayerOddsForToday() map[string]map[string]odds.PlayerOdds {
 	t := time.Now()
 	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 
-	pOdds, err := odds.GetPlayerOddsForDate(sports.NBA, today)
+	pOdds, err := odds.GetPlayerOddsForDate(context.Background(), sports.NBA, today, odds.DefaultLineSelector)
 	if err != nil {
 		log.Fatal("Error getting player odds", err)
 	}
@@ -174,7 +183,7 @@ func runGetPlayerPip() {
 	pindex := "daniedy01"
 
 	controlMap := players.GetPlayerPerByYear(sports.NBA, index, startDate, endDate)
-	affectedMap := players.GetPlayerPerWithPlayerByYear(index, pindex, players.Opponent, startDate, endDate)
+	affectedMap := players.GetPlayerPerWithPlayerByYear(sports.NBA, index, pindex, players.Opponent, startDate, endDate)
 	pipFactor := players.CalculatePIPFactor(controlMap, affectedMap)
 	prediction := controlMap[2024].PredictStats(pipFactor)
 	log.Println(pipFactor)
@@ -186,91 +195,125 @@ func backtestMLB() {
 	loc, _ := time.LoadLocation("America/New_York")
 	startDate, _ := time.ParseInLocation("2006-01-02", "2022-05-03", loc)
 	endDate, _ := time.ParseInLocation("2006-01-02", "2022-05-03", loc)
+	hPicker := analysis.PropSelector{
+		StratName: "Hits Raw",
+		Thresholds: map[string]float32{
+			"hits": .2,
+		},
+		TresholdType: analysis.Raw,
+		MinOdds:      100,
+		MaxOdds:      600,
+		MaxLine:      2.5,
+		MinGames:     0,
+		BetSize:      100,
+		MaxOver:      1000,
+		MaxUnder:     0,
+		TotalMax:     100,
+	}
+	hrPicker := analysis.PropSelector{
+		StratName: "Home Runs EV",
+		Thresholds: map[string]float32{
+			"home_runs": .05,
+		},
+		TresholdType:        analysis.EV,
+		MinOdds:             200,
+		MaxOdds:             1000,
+		BetSize:             100,
+		MaxOver:             1000,
+		MaxUnder:            0,
+		TotalMax:            100,
+		OneAlternatePerSide: true,
+	}
 
-	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
-		log.Printf("Date: %v", date)
-
-		todayGames, err := games.GetGamesForDate(sports.MLB, date)
-		if err != nil {
-			log.Fatal("Error getting games: ", err)
-		}
-		if len(todayGames) == 0 {
-			log.Printf("No games for %v", date)
-			continue
-		}
-
-		gameIds := make([]string, len(todayGames))
-		for i, game := range todayGames {
-			gameIds[i] = strconv.Itoa(game.Id)
-		}
-		// statMap, err := players.GetMLBBattingStatsForGames(gameIds)
-		// if err != nil {
-		//     log.Fatal("Error getting historical stats: ", err)
-		// }
-		// oddsMap, err := odds.GetPlayerOddsForDate(sports.MLB, date)
-		// if err != nil {
-		//     log.Fatal("Error getting player odds: ", err)
-		// }
-		// for playerIndex, odds := range oddsMap {
-		//     for stat, lines := range odds {
-		//         log.Printf("Player: %v, Stat: %v, Lines: %v", playerIndex, stat, lines)
-		//     }
-		// }
-
-		var results []analysis.Analysis
-		analysisService := analysis.NewAnalysisService(analysis.AnalysisServiceDeps{})
-		for _, game := range todayGames {
-			log.Printf("Analyzing %v vs. %v", game.HomeIndex, game.AwayIndex)
-			batterMap, err := players.GetPlayersForGame(game.Id, game.HomeIndex, "mlb_player_games_batting", "pas")
-			if err != nil {
-				log.Fatal("Error getting players for game: ", err)
-			}
-			pitcherMap, err := players.GetPlayersForGame(game.Id, game.HomeIndex, "mlb_player_games_pitching", "innings")
-			if err != nil {
-				log.Fatal("Error getting players for game: ", err)
-			}
-
-			results = append(results,
-				analysisService.RunMLBAnalysisOnGame(
-					convertPlayerMaptoPlayerRosters(batterMap["home"]),
-					convertPlayerMaptoPlayerRosters(pitcherMap["away"]),
-					date,
-					false,
-					false,
-				)...,
-			)
-			results = append(results,
-				analysisService.RunMLBAnalysisOnGame(
-					convertPlayerMaptoPlayerRosters(batterMap["away"]),
-					convertPlayerMaptoPlayerRosters(pitcherMap["home"]),
-					date,
-					false,
-					false,
-				)...,
-			)
-		}
+	b := backtesting.NewBacktester(
+		startDate,
+		endDate,
+		[]backtesting.Strategy{
+			{PropSelector: hPicker, BacktestResult: &backtesting.BacktestResult{}},
+			{PropSelector: hrPicker, BacktestResult: &backtesting.BacktestResult{}},
+		},
+		backtesting.BacktesterDeps{},
+	)
+	b.Sport = sports.MLB
+	if _, err := b.RunBacktest(context.Background()); err != nil {
+		log.Fatal("Error running MLB backtest: ", err)
+	}
+}
 
-		for _, result := range results {
-			log.Printf("Result: %v", result)
-		}
-		// for playerIndex, stat := range statMap {
-		//     log.Printf("Player: %v, Stats: %v", playerIndex, stat)
-		// }
+func runBacktestSweep() {
+	loc, _ := time.LoadLocation("America/New_York")
+	startDate, _ := time.ParseInLocation("2006-01-02", "2024-11-01", loc)
+	endDate, _ := time.ParseInLocation("2006-01-02", "2025-03-01", loc)
+	base := analysis.PropSelector{
+		StratName: "Points Sweep",
+		Thresholds: map[string]float32{
+			"points":   -100,
+			"rebounds": 100,
+			"assists":  100,
+		},
+		TresholdType: analysis.Raw,
+		MinOdds:      200,
+		MaxOdds:      1200,
+		MaxLine:      20,
+		MinGames:     40,
+		BetSize:      100,
+		MaxOver:      1000,
+		TotalMax:     200,
+	}
 
+	b := backtesting.NewBacktester(startDate, endDate, nil, backtesting.BacktesterDeps{})
+	results, err := b.RunSweep(context.Background(), backtesting.SweepConfig{
+		Base: base,
+		Params: []backtesting.SweepParam{
+			{Field: "MinOdds", Start: 100, End: 400, Step: 50},
+			{Field: `Thresholds["points"]`, Start: -.3, End: 1, Step: .1},
+		},
+		MinBets: 50,
+	})
+	if err != nil {
+		log.Fatal("Error running sweep: ", err)
 	}
+	backtesting.PrintSweepResults(results, 20)
 }
 
-func convertPlayerMaptoPlayerRosters(p []players.Player) []players.PlayerRoster {
-	var playerRosters []players.PlayerRoster
-	for _, player := range p {
-		playerRosters = append(playerRosters, players.PlayerRoster{
-			PlayerIndex: player.Index,
-			Status:      "Available",
-			AvgMins:     21,
-		})
+func runBacktestWalkForward() {
+	loc, _ := time.LoadLocation("America/New_York")
+	startDate, _ := time.ParseInLocation("2006-01-02", "2024-11-01", loc)
+	endDate, _ := time.ParseInLocation("2006-01-02", "2025-03-01", loc)
+	base := analysis.PropSelector{
+		StratName: "Points Walk Forward",
+		Thresholds: map[string]float32{
+			"points":   -100,
+			"rebounds": 100,
+			"assists":  100,
+		},
+		TresholdType: analysis.Raw,
+		MinOdds:      200,
+		MaxOdds:      1200,
+		MaxLine:      20,
+		MinGames:     40,
+		BetSize:      100,
+		MaxOver:      1000,
+		TotalMax:     200,
 	}
 
-	return playerRosters
+	b := backtesting.NewBacktester(startDate, endDate, nil, backtesting.BacktesterDeps{})
+	report, err := b.RunWalkForward(context.Background(), backtesting.WalkForwardConfig{
+		Sweep: backtesting.SweepConfig{
+			Base: base,
+			Params: []backtesting.SweepParam{
+				{Field: "MinOdds", Start: 100, End: 400, Step: 50},
+				{Field: "MaxLine", Start: 10, End: 25, Step: 5},
+			},
+			MinBets: 30,
+		},
+		TrainDays: 45,
+		TestDays:  14,
+	})
+	if err != nil {
+		log.Fatal("Error running walk forward: ", err)
+	}
+	backtesting.PrintWalkForwardReport(report)
 }
 
 func runBacktest() {
@@ -604,5 +647,13 @@ func runBacktest() {
 		},
 		backtesting.BacktesterDeps{},
 	)
-	b.RunBacktest()
+	b.Staking = []backtesting.StakingConfig{
+		{Policy: backtesting.FlatStake{}},
+		{Policy: backtesting.PercentStake{Percent: .01}, MaxDailyExposure: .1},
+		{Policy: backtesting.KellyStake{Fraction: .25}, MaxDailyExposure: .1},
+		{Policy: backtesting.KellyStake{Fraction: .25, Cap: .02}, MaxDailyExposure: .1},
+	}
+	if _, err := b.RunBacktest(context.Background()); err != nil {
+		log.Fatal("Error running backtest: ", err)
+	}
 }